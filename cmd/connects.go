@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/lin-snow/ech0/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+// connectsCmd 是管理连接的命令
+var connectsCmd = &cobra.Command{
+	Use:   "connects",
+	Short: "管理连接",
+}
+
+// connectsExportCmd 是将连接导出为 OPML 的命令
+var connectsExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "将连接导出为 OPML 文件",
+	Run: func(cmd *cobra.Command, args []string) {
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		cli.DoExportConnects(path)
+	},
+}
+
+// connectsImportCmd 是从 OPML 导入连接的命令
+var connectsImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "从 OPML 文件导入连接",
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取待导入的 OPML 文件路径
+		if len(args) < 1 {
+			cmd.Help()
+			return
+		}
+
+		cli.DoImportConnects(args[0])
+	},
+}
+
+// init 函数用于初始化根命令和子命令
+func init() {
+	connectsCmd.AddCommand(connectsExportCmd)
+	connectsCmd.AddCommand(connectsImportCmd)
+	rootCmd.AddCommand(connectsCmd)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/lin-snow/ech0/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+// keysAlgorithm 是轮换时生成的新密钥使用的签名算法
+var keysAlgorithm string
+
+// keysCmd 是管理 JWT 签名密钥的命令
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "管理 JWT 签名密钥",
+}
+
+// keysRotateCmd 是轮换 JWT 签名密钥的命令
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "生成新的签名密钥，旧密钥在宽限期内仍可校验已签发的令牌",
+	Run: func(cmd *cobra.Command, args []string) {
+		cli.DoRotateKeys(keysAlgorithm)
+	},
+}
+
+// init 函数用于初始化根命令和子命令
+func init() {
+	keysRotateCmd.Flags().StringVar(&keysAlgorithm, "alg", "", "签名算法，HS256 或 EdDSA（默认使用配置文件中的算法）")
+	keysCmd.AddCommand(keysRotateCmd)
+	rootCmd.AddCommand(keysCmd)
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/lin-snow/ech0/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+// memoriesTimezone 是计算“那年今日”所使用的时区
+var memoriesTimezone string
+
+// memoriesCmd 是查看那年今日的命令
+var memoriesCmd = &cobra.Command{
+	Use:   "memories",
+	Short: "查看那年今日发布的 Echo",
+	Run: func(cmd *cobra.Command, args []string) {
+		cli.DoOnThisDay(memoriesTimezone)
+	},
+}
+
+// init 函数用于初始化根命令和子命令
+func init() {
+	memoriesCmd.Flags().StringVar(&memoriesTimezone, "timezone", "", "IANA 时区名称，例如 Asia/Shanghai（默认使用服务器时区）")
+	rootCmd.AddCommand(memoriesCmd)
+}
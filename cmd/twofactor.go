@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/lin-snow/ech0/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+// twoFactorCmd 是管理两步验证的命令
+var twoFactorCmd = &cobra.Command{
+	Use:   "2fa",
+	Short: "管理用户的两步验证",
+}
+
+// twoFactorDisableCmd 是为无法登录的用户关闭两步验证的命令
+var twoFactorDisableCmd = &cobra.Command{
+	Use:   "disable <username>",
+	Short: "关闭指定用户的两步验证（用于丢失身份验证器和恢复码的用户）",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cli.DoDisableTwoFactor(args[0])
+	},
+}
+
+// init 函数用于初始化根命令和子命令
+func init() {
+	twoFactorCmd.AddCommand(twoFactorDisableCmd)
+	rootCmd.AddCommand(twoFactorCmd)
+}
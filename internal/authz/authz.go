@@ -0,0 +1,118 @@
+// Package authz 提供基于角色的权限控制，所有服务通过这里判断用户能否执行操作
+package authz
+
+import (
+	"errors"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+// Permission 权限
+type Permission string
+
+const (
+	PermEchoCreate         Permission = "echo:create"         // 发布 Echo
+	PermEchoEditOwn        Permission = "echo:edit-own"       // 编辑、删除自己的 Echo
+	PermEchoEditAny        Permission = "echo:edit-any"       // 编辑、删除所有 Echo
+	PermEchoReadPrivate    Permission = "echo:read-private"   // 查看所有私密 Echo
+	PermMediaUpload        Permission = "media:upload"        // 上传图片
+	PermMediaDelete        Permission = "media:delete"        // 删除已上传的图片
+	PermTodoManage         Permission = "todo:manage"         // 管理自己的 To do
+	PermProfileUpdate      Permission = "profile:update"      // 修改自己的用户信息
+	PermSettingsManage     Permission = "settings:manage"     // 修改系统设置、评论设置和背景音乐
+	PermUsersManage        Permission = "users:manage"        // 管理用户角色、删除用户、解除登录锁定
+	PermConnectManage      Permission = "connect:manage"      // 管理实例连接
+	PermWebmentionModerate Permission = "webmention:moderate" // 审核 Webmention
+	PermBackup             Permission = "backup"              // 备份和导出数据
+)
+
+// rolePermissions 角色拥有的权限
+var rolePermissions = map[userModel.Role][]Permission{
+	userModel.RoleOwner: {
+		PermEchoCreate, PermEchoEditOwn, PermEchoEditAny, PermEchoReadPrivate,
+		PermMediaUpload, PermMediaDelete, PermTodoManage, PermProfileUpdate,
+		PermSettingsManage, PermUsersManage, PermConnectManage, PermWebmentionModerate,
+		PermBackup,
+	},
+	userModel.RoleAdmin: {
+		PermEchoCreate, PermEchoEditOwn, PermEchoEditAny, PermEchoReadPrivate,
+		PermMediaUpload, PermMediaDelete, PermTodoManage, PermProfileUpdate,
+		PermSettingsManage, PermUsersManage, PermConnectManage, PermWebmentionModerate,
+	},
+	userModel.RoleAuthor: {
+		PermEchoCreate, PermEchoEditOwn, PermMediaUpload, PermTodoManage, PermProfileUpdate,
+	},
+	userModel.RoleReader: {
+		PermProfileUpdate,
+	},
+}
+
+// permissionTable 由 rolePermissions 生成的权限表，便于查询
+var permissionTable = func() map[userModel.Role]map[Permission]struct{} {
+	table := make(map[userModel.Role]map[Permission]struct{}, len(rolePermissions))
+	for role, permissions := range rolePermissions {
+		table[role] = make(map[Permission]struct{}, len(permissions))
+		for _, permission := range permissions {
+			table[role][permission] = struct{}{}
+		}
+	}
+	return table
+}()
+
+// Can 检查用户是否拥有权限
+func Can(user userModel.User, permission Permission) bool {
+	_, ok := permissionTable[user.Role][permission]
+	return ok
+}
+
+// Authorize 检查用户是否拥有权限，没有时返回 NO_PERMISSION_DENIED
+func Authorize(user userModel.User, permission Permission) error {
+	if !Can(user, permission) {
+		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	}
+	return nil
+}
+
+// AuthorizeOwned 检查用户能否操作属于 ownerID 的资源：自己的资源需要 own 权限，他人的资源需要 others 权限
+func AuthorizeOwned(user userModel.User, ownerID uint, own, others Permission) error {
+	if ownerID == user.ID && Can(user, own) {
+		return nil
+	}
+	return Authorize(user, others)
+}
+
+// CanViewPrivate 检查用户能否查看属于 ownerID 的私密内容（自己的或拥有 echo:read-private 权限）
+func CanViewPrivate(user userModel.User, ownerID uint) bool {
+	return (user.ID != 0 && ownerID == user.ID) || Can(user, PermEchoReadPrivate)
+}
+
+// AuthorizeManageUser 检查用户能否管理目标用户（修改角色、删除）
+// 不能管理自己和站长，只能管理级别比自己低的用户
+func AuthorizeManageUser(user, target userModel.User) error {
+	if err := Authorize(user, PermUsersManage); err != nil {
+		return err
+	}
+	if user.ID == target.ID || target.Role == userModel.RoleOwner {
+		return errors.New(commonModel.INVALID_PARAMS_BODY)
+	}
+	if target.Role.Rank() >= user.Role.Rank() {
+		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	}
+	return nil
+}
+
+// AuthorizeAssignRole 检查用户能否将目标用户设置为指定角色
+// 站长只有一个，不能被授予；授予的角色不能高于自己的角色
+func AuthorizeAssignRole(user, target userModel.User, role userModel.Role) error {
+	if !role.IsValid() || role == userModel.RoleOwner {
+		return errors.New(commonModel.INVALID_ROLE)
+	}
+	if err := AuthorizeManageUser(user, target); err != nil {
+		return err
+	}
+	if role.Rank() > user.Role.Rank() {
+		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	}
+	return nil
+}
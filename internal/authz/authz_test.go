@@ -0,0 +1,107 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+var allPermissions = []Permission{
+	PermEchoCreate, PermEchoEditOwn, PermEchoEditAny, PermEchoReadPrivate,
+	PermMediaUpload, PermMediaDelete, PermTodoManage, PermProfileUpdate,
+	PermSettingsManage, PermUsersManage, PermConnectManage, PermWebmentionModerate,
+	PermBackup,
+}
+
+// 每个角色与每个权限的组合都与预期一致，新增权限或角色时需要同时更新这张表
+func TestCan_RolePermissionMatrix(t *testing.T) {
+	//                                        owner  admin  author reader
+	expected := map[Permission][4]bool{
+		PermEchoCreate:         {true, true, true, false},
+		PermEchoEditOwn:        {true, true, true, false},
+		PermEchoEditAny:        {true, true, false, false},
+		PermEchoReadPrivate:    {true, true, false, false},
+		PermMediaUpload:        {true, true, true, false},
+		PermMediaDelete:        {true, true, false, false},
+		PermTodoManage:         {true, true, true, false},
+		PermProfileUpdate:      {true, true, true, true},
+		PermSettingsManage:     {true, true, false, false},
+		PermUsersManage:        {true, true, false, false},
+		PermConnectManage:      {true, true, false, false},
+		PermWebmentionModerate: {true, true, false, false},
+		PermBackup:             {true, false, false, false},
+	}
+	roles := []userModel.Role{userModel.RoleOwner, userModel.RoleAdmin, userModel.RoleAuthor, userModel.RoleReader}
+
+	assert.Len(t, expected, len(allPermissions))
+	for _, permission := range allPermissions {
+		for i, role := range roles {
+			user := userModel.User{ID: 1, Role: role}
+			assert.Equal(t, expected[permission][i], Can(user, permission), "%s %s", role, permission)
+
+			if expected[permission][i] {
+				assert.NoError(t, Authorize(user, permission))
+			} else {
+				assert.EqualError(t, Authorize(user, permission), commonModel.NO_PERMISSION_DENIED)
+			}
+		}
+	}
+}
+
+// 没有角色或角色无效的用户（包括未登录的访客）没有任何权限
+func TestCan_UnknownRole(t *testing.T) {
+	for _, role := range []userModel.Role{"", "superuser"} {
+		for _, permission := range allPermissions {
+			assert.False(t, Can(userModel.User{ID: 1, Role: role}, permission), "%q %s", role, permission)
+		}
+	}
+}
+
+// 作者只能操作自己的资源，管理员可以操作所有资源
+func TestAuthorizeOwned(t *testing.T) {
+	author := userModel.User{ID: 2, Role: userModel.RoleAuthor}
+	admin := userModel.User{ID: 3, Role: userModel.RoleAdmin}
+	reader := userModel.User{ID: 4, Role: userModel.RoleReader}
+
+	assert.NoError(t, AuthorizeOwned(author, author.ID, PermEchoEditOwn, PermEchoEditAny))
+	assert.Error(t, AuthorizeOwned(author, admin.ID, PermEchoEditOwn, PermEchoEditAny))
+	assert.NoError(t, AuthorizeOwned(admin, author.ID, PermEchoEditOwn, PermEchoEditAny))
+	// 读者没有 own 权限，即使是自己的资源也不能操作
+	assert.Error(t, AuthorizeOwned(reader, reader.ID, PermEchoEditOwn, PermEchoEditAny))
+}
+
+// 私密内容只有作者本人和拥有 echo:read-private 权限的用户可以查看
+func TestCanViewPrivate(t *testing.T) {
+	author := userModel.User{ID: 2, Role: userModel.RoleAuthor}
+
+	assert.True(t, CanViewPrivate(author, author.ID))
+	assert.False(t, CanViewPrivate(author, 3))
+	assert.True(t, CanViewPrivate(userModel.User{ID: 3, Role: userModel.RoleAdmin}, author.ID))
+	assert.False(t, CanViewPrivate(userModel.User{}, 0))
+}
+
+// 只能管理级别比自己低的用户，不能管理自己和站长，也不能授予站长或高于自己的角色
+func TestAuthorizeManageUserAndAssignRole(t *testing.T) {
+	owner := userModel.User{ID: 1, Role: userModel.RoleOwner}
+	admin := userModel.User{ID: 2, Role: userModel.RoleAdmin}
+	otherAdmin := userModel.User{ID: 3, Role: userModel.RoleAdmin}
+	author := userModel.User{ID: 4, Role: userModel.RoleAuthor}
+	reader := userModel.User{ID: 5, Role: userModel.RoleReader}
+
+	assert.NoError(t, AuthorizeManageUser(owner, admin))
+	assert.NoError(t, AuthorizeManageUser(admin, author))
+	assert.EqualError(t, AuthorizeManageUser(admin, otherAdmin), commonModel.NO_PERMISSION_DENIED)
+	assert.EqualError(t, AuthorizeManageUser(admin, owner), commonModel.INVALID_PARAMS_BODY)
+	assert.EqualError(t, AuthorizeManageUser(owner, owner), commonModel.INVALID_PARAMS_BODY)
+	assert.EqualError(t, AuthorizeManageUser(author, reader), commonModel.NO_PERMISSION_DENIED)
+
+	assert.NoError(t, AuthorizeAssignRole(owner, author, userModel.RoleAdmin))
+	assert.NoError(t, AuthorizeAssignRole(admin, reader, userModel.RoleAuthor))
+	assert.NoError(t, AuthorizeAssignRole(admin, reader, userModel.RoleAdmin))
+	assert.EqualError(t, AuthorizeAssignRole(owner, admin, userModel.RoleOwner), commonModel.INVALID_ROLE)
+	assert.EqualError(t, AuthorizeAssignRole(owner, admin, "superuser"), commonModel.INVALID_ROLE)
+	assert.EqualError(t, AuthorizeAssignRole(admin, otherAdmin, userModel.RoleReader), commonModel.NO_PERMISSION_DENIED)
+}
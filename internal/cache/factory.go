@@ -10,6 +10,9 @@ import (
 type CacheFactory struct {
 	userCache ICache[string, *userModel.User]
 	echoCache ICache[string, commonModel.PageQueryResult[[]echoModel.Echo]]
+
+	echoArchiveCache ICache[string, []echoModel.ArchiveMonth]
+	echoStatsCache   ICache[string, echoModel.EchoStats]
 }
 
 // NewCacheFactory 创建一个新的 CacheFactory 实例，并初始化所需的缓存
@@ -24,9 +27,21 @@ func NewCacheFactory() *CacheFactory {
 		panic(err)
 	}
 
+	echoArchiveCache, err := NewCache[string, []echoModel.ArchiveMonth]()
+	if err != nil {
+		panic(err)
+	}
+
+	echoStatsCache, err := NewCache[string, echoModel.EchoStats]()
+	if err != nil {
+		panic(err)
+	}
+
 	return &CacheFactory{
-		userCache: userCache,
-		echoCache: echoCache,
+		userCache:        userCache,
+		echoCache:        echoCache,
+		echoArchiveCache: echoArchiveCache,
+		echoStatsCache:   echoStatsCache,
 	}
 }
 
@@ -39,3 +54,13 @@ func (f *CacheFactory) UserCache() ICache[string, *userModel.User] {
 func (f *CacheFactory) EchoCache() ICache[string, commonModel.PageQueryResult[[]echoModel.Echo]] {
 	return f.echoCache
 }
+
+// EchoArchiveCache 返回 Echo 归档缓存实例
+func (f *CacheFactory) EchoArchiveCache() ICache[string, []echoModel.ArchiveMonth] {
+	return f.echoArchiveCache
+}
+
+// EchoStatsCache 返回 Echo 统计缓存实例
+func (f *CacheFactory) EchoStatsCache() ICache[string, echoModel.EchoStats] {
+	return f.echoStatsCache
+}
@@ -13,9 +13,33 @@ import (
 
 	"github.com/charmbracelet/huh"
 	"github.com/lin-snow/ech0/internal/backup"
+	"github.com/lin-snow/ech0/internal/cache"
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/database"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	connectModel "github.com/lin-snow/ech0/internal/model/connect"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	authRepository "github.com/lin-snow/ech0/internal/repository/auth"
+	commonRepository "github.com/lin-snow/ech0/internal/repository/common"
+	connectRepository "github.com/lin-snow/ech0/internal/repository/connect"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	fediverseRepository "github.com/lin-snow/ech0/internal/repository/fediverse"
+	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
+	userRepository "github.com/lin-snow/ech0/internal/repository/user"
+	webmentionRepository "github.com/lin-snow/ech0/internal/repository/webmention"
+	webSubRepository "github.com/lin-snow/ech0/internal/repository/websub"
 	"github.com/lin-snow/ech0/internal/server"
+	authService "github.com/lin-snow/ech0/internal/service/auth"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	connectService "github.com/lin-snow/ech0/internal/service/connect"
+	echoService "github.com/lin-snow/ech0/internal/service/echo"
+	fediverseService "github.com/lin-snow/ech0/internal/service/fediverse"
+	settingService "github.com/lin-snow/ech0/internal/service/setting"
+	userService "github.com/lin-snow/ech0/internal/service/user"
+	webmentionService "github.com/lin-snow/ech0/internal/service/webmention"
+	webSubService "github.com/lin-snow/ech0/internal/service/websub"
 	"github.com/lin-snow/ech0/internal/ssh"
+	"github.com/lin-snow/ech0/internal/transaction"
 	"github.com/lin-snow/ech0/internal/tui"
 )
 
@@ -104,6 +128,182 @@ func DoRestore(backupFilePath string) {
 	tui.PrintCLIInfo("🎉 恢复成功", "已从备份文件 "+backupFilePath+" 中恢复数据")
 }
 
+// DoOnThisDay 打印那年今日发布的 Echo
+func DoOnThisDay(timezone string) {
+	// 如果数据库尚未初始化（未启动 Web 服务），则先初始化
+	if database.DB == nil {
+		database.InitDatabase()
+	}
+
+	tm := transaction.NewTransactionManager(database.DB)
+	cacheFactory := cache.NewCacheFactory()
+	commonSvc := commonService.NewCommonService(tm, commonRepository.NewCommonRepository(database.DB))
+	settingSvc := settingService.NewSettingService(tm, commonSvc, keyvalueRepository.NewKeyValueRepository(database.DB))
+	echoRepo := echoRepository.NewEchoRepository(database.DB, cacheFactory.EchoCache(), cacheFactory.EchoArchiveCache(), cacheFactory.EchoStatsCache())
+	echoSvc := echoService.NewEchoService(
+		tm,
+		commonSvc,
+		echoRepo,
+		fediverseService.NewFediverseService(tm, fediverseRepository.NewFediverseRepository(database.DB), echoRepo, commonSvc, settingSvc),
+		webSubService.NewWebSubService(tm, webSubRepository.NewWebSubRepository(database.DB), commonSvc),
+		webmentionService.NewWebmentionService(tm, webmentionRepository.NewWebmentionRepository(database.DB), echoRepo, commonSvc, settingSvc),
+	)
+
+	// 以系统管理员身份查询，包含私密 Echo
+	sysadmin, err := commonSvc.GetSysAdmin()
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", commonModel.SIGNUP_FIRST)
+		return
+	}
+
+	echos, err := echoSvc.GetOnThisDayEchos(sysadmin, timezone)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "获取那年今日失败: "+err.Error())
+		return
+	}
+
+	if len(echos) == 0 {
+		tui.PrintCLIInfo("🗓️ 那年今日", "往年的今天还没有发布过 Echo")
+		return
+	}
+
+	now := time.Now()
+	items := make([]tui.CLIInfoItem, 0, len(echos))
+	for _, echo := range echos {
+		items = append(items, tui.CLIInfoItem{
+			Title: fmt.Sprintf("📅 %s · %d 年前", echo.CreatedAt.Format("2006-01-02 15:04"), now.Year()-echo.CreatedAt.Year()),
+			Msg:   echo.Content,
+		})
+	}
+	tui.PrintCLIWithBox(items...)
+}
+
+// DoExportConnects 将连接导出为 OPML 文件
+func DoExportConnects(path string) {
+	connectSvc, sysadmin, ok := newConnectService()
+	if !ok {
+		return
+	}
+
+	body, err := connectSvc.ExportOPML(sysadmin)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "导出连接失败: "+err.Error())
+		return
+	}
+
+	if path == "" {
+		path = connectModel.OPMLFileName
+	}
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "导出连接失败: "+err.Error())
+		return
+	}
+
+	tui.PrintCLIInfo("🎉 导出成功", path)
+}
+
+// DoImportConnects 从 OPML 文件导入连接
+func DoImportConnects(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "读取文件失败: "+err.Error())
+		return
+	}
+
+	connectSvc, sysadmin, ok := newConnectService()
+	if !ok {
+		return
+	}
+
+	result, err := connectSvc.ImportOPML(sysadmin, data)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "导入连接失败: "+err.Error())
+		return
+	}
+
+	items := []tui.CLIInfoItem{{
+		Title: "📥 导入完成",
+		Msg:   fmt.Sprintf("新建 %d 个，重复 %d 个，失败 %d 个", len(result.Created), len(result.Duplicates), len(result.Failed)),
+	}}
+	for _, connectURL := range result.Created {
+		items = append(items, tui.CLIInfoItem{Title: "✅ 已发起连接", Msg: connectURL})
+	}
+	for _, connectURL := range result.Duplicates {
+		items = append(items, tui.CLIInfoItem{Title: "♻️ 已存在", Msg: connectURL})
+	}
+	for _, failure := range result.Failed {
+		items = append(items, tui.CLIInfoItem{Title: "❌ 失败", Msg: failure.URL + "（" + failure.Reason + "）"})
+	}
+	tui.PrintCLIWithBox(items...)
+}
+
+// newConnectService 初始化数据库并创建 ConnectService，返回系统管理员
+func newConnectService() (connectService.ConnectServiceInterface, userModel.User, bool) {
+	// 如果数据库尚未初始化（未启动 Web 服务），则先初始化
+	if database.DB == nil {
+		database.InitDatabase()
+	}
+
+	tm := transaction.NewTransactionManager(database.DB)
+	cacheFactory := cache.NewCacheFactory()
+	commonSvc := commonService.NewCommonService(tm, commonRepository.NewCommonRepository(database.DB))
+	settingSvc := settingService.NewSettingService(tm, commonSvc, keyvalueRepository.NewKeyValueRepository(database.DB))
+	echoRepo := echoRepository.NewEchoRepository(database.DB, cacheFactory.EchoCache(), cacheFactory.EchoArchiveCache(), cacheFactory.EchoStatsCache())
+	connectSvc := connectService.NewConnectService(tm, connectRepository.NewConnectRepository(database.DB), echoRepo, commonSvc, settingSvc)
+
+	sysadmin, err := commonSvc.GetSysAdmin()
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", commonModel.SIGNUP_FIRST)
+		return nil, userModel.User{}, false
+	}
+
+	return connectSvc, sysadmin, true
+}
+
+// newAuthService 为命令行构建认证服务
+func newAuthService() authService.AuthServiceInterface {
+	// 如果数据库尚未初始化（未启动 Web 服务），则先初始化
+	if database.DB == nil {
+		database.InitDatabase()
+	}
+
+	tm := transaction.NewTransactionManager(database.DB)
+	cacheFactory := cache.NewCacheFactory()
+	commonSvc := commonService.NewCommonService(tm, commonRepository.NewCommonRepository(database.DB))
+	settingSvc := settingService.NewSettingService(tm, commonSvc, keyvalueRepository.NewKeyValueRepository(database.DB))
+	authRepo := authRepository.NewAuthRepository(database.DB)
+	userSvc := userService.NewUserService(tm, userRepository.NewUserRepository(database.DB, cacheFactory.UserCache()), authRepo, settingSvc)
+	return authService.NewAuthService(tm, authRepo, userSvc, settingSvc)
+}
+
+// DoRotateKeys 轮换 JWT 签名密钥
+func DoRotateKeys(algorithm string) {
+	key, err := newAuthService().RotateKeys(algorithm)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "轮换密钥失败: "+err.Error())
+		return
+	}
+
+	items := []tui.CLIInfoItem{
+		{Title: "🔑 新密钥", Msg: key.Kid + "（" + key.Algorithm + "）"},
+		{Title: "⏳ 宽限期", Msg: (time.Duration(config.Config.Auth.Jwt.GracePeriod) * time.Second).String()},
+	}
+	if len(config.JWT_SECRET) > 0 {
+		items = append(items, tui.CLIInfoItem{Title: "⚠️ 注意", Msg: "已设置环境变量 JWT_SECRET，签发令牌时仍使用该密钥"})
+	}
+	tui.PrintCLIWithBox(items...)
+}
+
+// DoDisableTwoFactor 为无法登录的用户关闭两步验证
+func DoDisableTwoFactor(username string) {
+	if err := newAuthService().ResetTwoFactor(username); err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "关闭两步验证失败: "+err.Error())
+		return
+	}
+
+	tui.PrintCLIInfo("🎉 执行结果", "已关闭用户 "+username+" 的两步验证，恢复码已全部失效")
+}
+
 // DoVersion 打印版本信息
 func DoVersion() {
 	item := struct{ Title, Msg string }{
@@ -164,6 +364,7 @@ func DoTui() {
 
 		options = append(options,
 			huh.NewOption("🦖 查看信息", "info"),
+			huh.NewOption("🗓️ 那年今日", "onthisday"),
 			huh.NewOption("📦 执行备份", "backup"),
 			huh.NewOption("💾 恢复备份", "restore"),
 			huh.NewOption("📌 查看版本", "version"),
@@ -193,6 +394,9 @@ func DoTui() {
 		case "info":
 			tui.ClearScreen()
 			DoEch0Info()
+		case "onthisday":
+			tui.ClearScreen()
+			DoOnThisDay("")
 		case "backup":
 			DoBackup()
 		case "restore":
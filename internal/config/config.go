@@ -2,11 +2,9 @@ package config
 
 import (
 	"bytes"
-	"crypto/rand"
 	_ "embed"
-	"encoding/hex"
-	"log"
 	"os"
+	"strings"
 
 	model "github.com/lin-snow/ech0/internal/model/common"
 	"github.com/spf13/viper"
@@ -15,7 +13,7 @@ import (
 // Config 全局配置变量
 var Config AppConfig
 
-// JWT_SECRET 用于JWT签名的密钥
+// JWT_SECRET 通过环境变量指定的JWT密钥，未设置时使用数据库中持久化的密钥
 var JWT_SECRET []byte
 
 // AppConfig 应用程序配置结构体
@@ -24,6 +22,8 @@ type AppConfig struct {
 		Port string `yaml:"port"` // 服务器端口
 		Host string `yaml:"host"` // 服务器主机地址
 		Mode string `yaml:"mode"` // 运行模式，可能的值为 "debug" 或 "release"
+		// TrustedProxies 可信反向代理的 IP 或 CIDR，只有来自这些地址的请求才会读取 X-Forwarded-For 等头部，为空时不信任任何代理
+		TrustedProxies []string `yaml:"trustedproxies"`
 	} `yaml:"server"`
 	Database struct {
 		Type string `yaml:"type"` // 数据库类型
@@ -31,10 +31,32 @@ type AppConfig struct {
 	} `yaml:"database"`
 	Auth struct {
 		Jwt struct {
-			Expires  int    `yaml:"expires"`  // JWT的过期时间，单位为秒
-			Issuer   string `yaml:"issuer"`   // JWT的发行者
-			Audience string `yaml:"audience"` // JWT的受众
+			Expires        int    `yaml:"expires"`        // 旧版登录接口签发的JWT的过期时间，单位为秒
+			AccessExpires  int    `yaml:"accessexpires"`  // 访问令牌的过期时间，单位为秒
+			RefreshExpires int    `yaml:"refreshexpires"` // 刷新令牌（会话）的过期时间，单位为秒
+			Issuer         string `yaml:"issuer"`         // JWT的发行者
+			Audience       string `yaml:"audience"`       // JWT的受众
+			Algorithm      string `yaml:"algorithm"`      // 生成新密钥时使用的签名算法，可能的值为 "HS256" 或 "EdDSA"
+			GracePeriod    int    `yaml:"graceperiod"`    // 密钥被轮换后仍可用于校验的时间，单位为秒
 		} `yaml:"jwt"`
+		Password struct {
+			MinLength     int  `yaml:"minlength"`     // 密码最小长度
+			MaxLength     int  `yaml:"maxlength"`     // 密码最大长度，为 0 时不限制
+			RequireUpper  bool `yaml:"requireupper"`  // 是否必须包含大写字母
+			RequireLower  bool `yaml:"requirelower"`  // 是否必须包含小写字母
+			RequireDigit  bool `yaml:"requiredigit"`  // 是否必须包含数字
+			RequireSymbol bool `yaml:"requiresymbol"` // 是否必须包含符号
+		} `yaml:"password"`
+		Lockout struct {
+			MaxAttempts int `yaml:"maxattempts"` // 时间窗口内允许失败的次数，达到后锁定，为 0 时关闭
+			Window      int `yaml:"window"`      // 统计失败次数的时间窗口，单位为秒
+			BaseDelay   int `yaml:"basedelay"`   // 首次锁定的时长，之后每次锁定翻倍，单位为秒
+			MaxDelay    int `yaml:"maxdelay"`    // 锁定时长的上限，单位为秒
+		} `yaml:"lockout"`
+		DefaultRole string `yaml:"defaultrole"` // 注册和自动创建的用户的默认角色，可能的值为 "author" 或 "reader"
+		OIDC        struct {
+			Providers []OIDCProvider `yaml:"providers"` // OpenID Connect 身份提供者
+		} `yaml:"oidc"`
 	} `yaml:"auth"`
 	Upload struct {
 		ImageMaxSize int      `yaml:"imagemaxsize"` // 图片文件的最大上传大小，单位为字节
@@ -52,6 +74,7 @@ type AppConfig struct {
 		MetingAPI     string `yaml:"metingapi"`     // Meting API 地址
 		CustomCSS     string `yaml:"customcss"`     // 自定义 CSS 样式
 		CustomJS      string `yaml:"customjs"`      // 自定义 JS 脚本
+		Timezone      string `yaml:"timezone"`      // 站点时区（IANA 时区名称），为空时使用服务器时区
 	} `yaml:"setting"`
 	Comment struct {
 		EnableComment bool   `yaml:"enablecomment"` // 是否启用评论
@@ -68,6 +91,21 @@ type AppConfig struct {
 //go:embed config.yaml
 var configData []byte
 
+// OIDCProvider OpenID Connect 身份提供者配置
+type OIDCProvider struct {
+	ID            string   `yaml:"id"`            // 提供者标识，用于登录和回调地址
+	Name          string   `yaml:"name"`          // 登录页面显示的名称
+	Issuer        string   `yaml:"issuer"`        // 发行者地址，用于获取 /.well-known/openid-configuration
+	ClientID      string   `yaml:"clientid"`      // 客户端ID
+	ClientSecret  string   `yaml:"clientsecret"`  // 客户端密钥，可通过环境变量 OIDC_<ID>_CLIENT_SECRET 指定，公开客户端留空
+	RedirectURL   string   `yaml:"redirecturl"`   // 回调地址，为空时使用 <服务器地址>/api/auth/oidc/<ID>/callback
+	Scopes        []string `yaml:"scopes"`        // 请求的范围，总是包含 openid
+	UsernameClaim string   `yaml:"usernameclaim"` // 自动创建用户时作为用户名的声明，为空时使用 preferred_username
+	GroupsClaim   string   `yaml:"groupsclaim"`   // 用户组所在的声明，为空时使用 groups
+	AdminGroup    string   `yaml:"admingroup"`    // 属于该组的用户登录时成为管理员，不在该组时取消管理员，为空时不同步
+	AutoProvision bool     `yaml:"autoprovision"` // 是否为尚未关联的身份自动创建用户
+}
+
 // LoadAppConfig 加载应用程序配置
 func LoadAppConfig() {
 	// viper.SetConfigFile("config/config.yaml")
@@ -87,18 +125,22 @@ func LoadAppConfig() {
 	JWT_SECRET = GetJWTSecret()
 }
 
-// GetJWTSecret 加载JWT密钥
+// GetJWTSecret 从环境变量加载JWT密钥，未设置时返回 nil
 func GetJWTSecret() []byte {
-	// 从环境变量中获取JWT密钥
 	secret := os.Getenv("JWT_SECRET")
-	if secret == "" { // 如果没有设置环境变量，则使用UUID生成默认密钥
-		b := make([]byte, 16)
-		_, err := rand.Read(b)
-		if err != nil {
-			log.Fatal("failed to generate random JWT secret:", err)
-		}
-		secret = hex.EncodeToString(b)
+	if secret == "" {
+		return nil
 	}
 
 	return []byte(secret)
 }
+
+// GetOIDCClientSecret 获取身份提供者的客户端密钥，环境变量 OIDC_<ID>_CLIENT_SECRET 优先于配置文件
+func GetOIDCClientSecret(provider OIDCProvider) string {
+	name := "OIDC_" + strings.ToUpper(strings.ReplaceAll(provider.ID, "-", "_")) + "_CLIENT_SECRET"
+	if secret := os.Getenv(name); secret != "" {
+		return secret
+	}
+
+	return provider.ClientSecret
+}
@@ -4,11 +4,15 @@ import (
 	"os"
 
 	"github.com/lin-snow/ech0/internal/config"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	connectModel "github.com/lin-snow/ech0/internal/model/connect"
 	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	fediverseModel "github.com/lin-snow/ech0/internal/model/fediverse"
 	todoModel "github.com/lin-snow/ech0/internal/model/todo"
 	userModel "github.com/lin-snow/ech0/internal/model/user"
+	webmentionModel "github.com/lin-snow/ech0/internal/model/webmention"
+	webSubModel "github.com/lin-snow/ech0/internal/model/websub"
 
 	util "github.com/lin-snow/ech0/internal/util/err"
 	"gorm.io/driver/sqlite"
@@ -63,14 +67,64 @@ func InitDatabase() {
 func MigrateDB() error {
 	models := []interface{}{
 		&userModel.User{},
+		&authModel.Session{},
+		&authModel.SigningKey{},
+		&authModel.AccessToken{},
+		&authModel.TwoFactor{},
+		&authModel.RecoveryCode{},
+		&authModel.LoginChallenge{},
+		&authModel.OIDCIdentity{},
+		&authModel.OIDCState{},
+		&authModel.Lockout{},
 		&echoModel.Echo{},
 		&echoModel.Image{},
 		&commonModel.KeyValue{},
 		&todoModel.Todo{},
 		&connectModel.Connected{},
+		&connectModel.TimelineEcho{},
+		&connectModel.ConnectHealth{},
+		&connectModel.PeerSuggestion{},
+		&fediverseModel.Follower{},
+		&fediverseModel.Delivery{},
+		&webSubModel.Subscription{},
+		&webmentionModel.Webmention{},
 	}
 
-	return DB.AutoMigrate(
+	if err := DB.AutoMigrate(
 		models...,
-	)
+	); err != nil {
+		return err
+	}
+
+	return migrateUserRoles()
+}
+
+// migrateUserRoles 为没有角色的用户（升级前创建）设置角色
+// 第一个管理员为站长，其余管理员为管理员，其他用户为读者
+func migrateUserRoles() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var owners int64
+		if err := tx.Model(&userModel.User{}).Where("role = ?", userModel.RoleOwner).Count(&owners).Error; err != nil {
+			return err
+		}
+		if owners == 0 {
+			var sysadmin userModel.User
+			err := tx.Where("is_admin = ?", true).Order("id").Limit(1).Find(&sysadmin).Error
+			if err != nil {
+				return err
+			}
+			if sysadmin.ID != 0 {
+				if err := tx.Model(&sysadmin).Update("role", userModel.RoleOwner).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		noRole := "role IS NULL OR role = ''"
+		if err := tx.Model(&userModel.User{}).Where(noRole).Where("is_admin = ?", true).
+			Update("role", userModel.RoleAdmin).Error; err != nil {
+			return err
+		}
+		return tx.Model(&userModel.User{}).Where(noRole).Update("role", userModel.RoleReader).Error
+	})
 }
@@ -2,14 +2,19 @@ package di
 
 import (
 	"github.com/lin-snow/ech0/internal/cache"
+	authHandler "github.com/lin-snow/ech0/internal/handler/auth"
 	backupHandler "github.com/lin-snow/ech0/internal/handler/backup"
 	commonHandler "github.com/lin-snow/ech0/internal/handler/common"
 	connectHandler "github.com/lin-snow/ech0/internal/handler/connect"
 	echoHandler "github.com/lin-snow/ech0/internal/handler/echo"
+	fediverseHandler "github.com/lin-snow/ech0/internal/handler/fediverse"
+	micropubHandler "github.com/lin-snow/ech0/internal/handler/micropub"
 	settingHandler "github.com/lin-snow/ech0/internal/handler/setting"
 	todoHandler "github.com/lin-snow/ech0/internal/handler/todo"
 	userHandler "github.com/lin-snow/ech0/internal/handler/user"
 	webHandler "github.com/lin-snow/ech0/internal/handler/web"
+	webmentionHandler "github.com/lin-snow/ech0/internal/handler/webmention"
+	webSubHandler "github.com/lin-snow/ech0/internal/handler/websub"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	echoModel "github.com/lin-snow/ech0/internal/model/echo"
 	userModel "github.com/lin-snow/ech0/internal/model/user"
@@ -26,6 +31,14 @@ type Handlers struct {
 	TodoHandler    *todoHandler.TodoHandler
 	ConnectHandler *connectHandler.ConnectHandler
 	BackupHandler  *backupHandler.BackupHandler
+
+	FediverseHandler *fediverseHandler.FediverseHandler
+	WebSubHandler    *webSubHandler.WebSubHandler
+
+	WebmentionHandler *webmentionHandler.WebmentionHandler
+	MicropubHandler   *micropubHandler.MicropubHandler
+
+	AuthHandler *authHandler.AuthHandler
 }
 
 // NewHandlers 创建Handlers实例
@@ -38,6 +51,11 @@ func NewHandlers(
 	todoHandler *todoHandler.TodoHandler,
 	connectHandler *connectHandler.ConnectHandler,
 	backupHandler *backupHandler.BackupHandler,
+	fediverseHandler *fediverseHandler.FediverseHandler,
+	webSubHandler *webSubHandler.WebSubHandler,
+	webmentionHandler *webmentionHandler.WebmentionHandler,
+	micropubHandler *micropubHandler.MicropubHandler,
+	authHandler *authHandler.AuthHandler,
 ) *Handlers {
 	return &Handlers{
 		WebHandler:     webHandler,
@@ -48,6 +66,14 @@ func NewHandlers(
 		TodoHandler:    todoHandler,
 		ConnectHandler: connectHandler,
 		BackupHandler:  backupHandler,
+
+		FediverseHandler: fediverseHandler,
+		WebSubHandler:    webSubHandler,
+
+		WebmentionHandler: webmentionHandler,
+		MicropubHandler:   micropubHandler,
+
+		AuthHandler: authHandler,
 	}
 }
 
@@ -61,6 +87,16 @@ func ProvideEchoCache(factory *cache.CacheFactory) cache.ICache[string, commonMo
 	return factory.EchoCache()
 }
 
+// ProvideEchoArchiveCache 提供 Echo 归档缓存实例给 wire 注入
+func ProvideEchoArchiveCache(factory *cache.CacheFactory) cache.ICache[string, []echoModel.ArchiveMonth] {
+	return factory.EchoArchiveCache()
+}
+
+// ProvideEchoStatsCache 提供 Echo 统计缓存实例给 wire 注入
+func ProvideEchoStatsCache(factory *cache.CacheFactory) cache.ICache[string, echoModel.EchoStats] {
+	return factory.EchoStatsCache()
+}
+
 // ProvideTransactionManager 提供事务管理器实例给 wire 注入
 func ProvideTransactionManager(factory *transaction.TransactionManagerFactory) transaction.TransactionManager {
 	return factory.TransactionManager()
@@ -6,27 +6,41 @@ package di
 import (
 	"github.com/google/wire"
 	"github.com/lin-snow/ech0/internal/cache"
+	authHandler "github.com/lin-snow/ech0/internal/handler/auth"
 	backupHandler "github.com/lin-snow/ech0/internal/handler/backup"
 	commonHandler "github.com/lin-snow/ech0/internal/handler/common"
 	connectHandler "github.com/lin-snow/ech0/internal/handler/connect"
 	echoHandler "github.com/lin-snow/ech0/internal/handler/echo"
+	fediverseHandler "github.com/lin-snow/ech0/internal/handler/fediverse"
+	micropubHandler "github.com/lin-snow/ech0/internal/handler/micropub"
 	settingHandler "github.com/lin-snow/ech0/internal/handler/setting"
 	todoHandler "github.com/lin-snow/ech0/internal/handler/todo"
 	userHandler "github.com/lin-snow/ech0/internal/handler/user"
 	webHandler "github.com/lin-snow/ech0/internal/handler/web"
+	webmentionHandler "github.com/lin-snow/ech0/internal/handler/webmention"
+	webSubHandler "github.com/lin-snow/ech0/internal/handler/websub"
+	authRepository "github.com/lin-snow/ech0/internal/repository/auth"
 	commonRepository "github.com/lin-snow/ech0/internal/repository/common"
 	connectRepository "github.com/lin-snow/ech0/internal/repository/connect"
 	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	fediverseRepository "github.com/lin-snow/ech0/internal/repository/fediverse"
 	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
 	todoRepository "github.com/lin-snow/ech0/internal/repository/todo"
 	userRepository "github.com/lin-snow/ech0/internal/repository/user"
+	webmentionRepository "github.com/lin-snow/ech0/internal/repository/webmention"
+	webSubRepository "github.com/lin-snow/ech0/internal/repository/websub"
+	authService "github.com/lin-snow/ech0/internal/service/auth"
 	backupService "github.com/lin-snow/ech0/internal/service/backup"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
 	connectService "github.com/lin-snow/ech0/internal/service/connect"
 	echoService "github.com/lin-snow/ech0/internal/service/echo"
+	fediverseService "github.com/lin-snow/ech0/internal/service/fediverse"
+	micropubService "github.com/lin-snow/ech0/internal/service/micropub"
 	settingService "github.com/lin-snow/ech0/internal/service/setting"
 	todoService "github.com/lin-snow/ech0/internal/service/todo"
 	userService "github.com/lin-snow/ech0/internal/service/user"
+	webmentionService "github.com/lin-snow/ech0/internal/service/webmention"
+	webSubService "github.com/lin-snow/ech0/internal/service/websub"
 	"github.com/lin-snow/ech0/internal/transaction"
 	"gorm.io/gorm"
 )
@@ -48,6 +62,11 @@ func BuildHandlers(
 		TodoSet,
 		ConnectSet,
 		BackupSet,
+		FediverseSet,
+		WebSubSet,
+		WebmentionSet,
+		MicropubSet,
+		AuthSet,
 		NewHandlers, // NewHandlers 聚合各个模块的Handler
 	)
 
@@ -58,6 +77,8 @@ func BuildHandlers(
 var CacheSet = wire.NewSet(
 	ProvideUserCache,
 	ProvideEchoCache,
+	ProvideEchoArchiveCache,
+	ProvideEchoStatsCache,
 )
 
 // TransactionManagerSet 包含了构建事务管理器所需的所有 Provider
@@ -117,3 +138,37 @@ var BackupSet = wire.NewSet(
 	backupHandler.NewBackupHandler,
 	backupService.NewBackupService,
 )
+
+// FediverseSet 包含了构建 FediverseHandler 所需的所有 Provider
+var FediverseSet = wire.NewSet(
+	fediverseRepository.NewFediverseRepository,
+	fediverseService.NewFediverseService,
+	fediverseHandler.NewFediverseHandler,
+)
+
+// WebSubSet 包含了构建 WebSubHandler 所需的所有 Provider
+var WebSubSet = wire.NewSet(
+	webSubRepository.NewWebSubRepository,
+	webSubService.NewWebSubService,
+	webSubHandler.NewWebSubHandler,
+)
+
+// WebmentionSet 包含了构建 WebmentionHandler 所需的所有 Provider
+var WebmentionSet = wire.NewSet(
+	webmentionRepository.NewWebmentionRepository,
+	webmentionService.NewWebmentionService,
+	webmentionHandler.NewWebmentionHandler,
+)
+
+// MicropubSet 包含了构建 MicropubHandler 所需的所有 Provider
+var MicropubSet = wire.NewSet(
+	micropubService.NewMicropubService,
+	micropubHandler.NewMicropubHandler,
+)
+
+// AuthSet 包含了构建 AuthHandler 所需的所有 Provider
+var AuthSet = wire.NewSet(
+	authRepository.NewAuthRepository,
+	authService.NewAuthService,
+	authHandler.NewAuthHandler,
+)
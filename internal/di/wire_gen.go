@@ -9,27 +9,41 @@ package di
 import (
 	"github.com/google/wire"
 	"github.com/lin-snow/ech0/internal/cache"
+	handler13 "github.com/lin-snow/ech0/internal/handler/auth"
 	handler8 "github.com/lin-snow/ech0/internal/handler/backup"
 	handler4 "github.com/lin-snow/ech0/internal/handler/common"
 	handler7 "github.com/lin-snow/ech0/internal/handler/connect"
 	handler3 "github.com/lin-snow/ech0/internal/handler/echo"
+	handler9 "github.com/lin-snow/ech0/internal/handler/fediverse"
+	handler12 "github.com/lin-snow/ech0/internal/handler/micropub"
 	handler5 "github.com/lin-snow/ech0/internal/handler/setting"
 	handler6 "github.com/lin-snow/ech0/internal/handler/todo"
 	handler2 "github.com/lin-snow/ech0/internal/handler/user"
 	"github.com/lin-snow/ech0/internal/handler/web"
+	handler11 "github.com/lin-snow/ech0/internal/handler/webmention"
+	handler10 "github.com/lin-snow/ech0/internal/handler/websub"
+	repository9 "github.com/lin-snow/ech0/internal/repository/auth"
 	repository2 "github.com/lin-snow/ech0/internal/repository/common"
 	repository5 "github.com/lin-snow/ech0/internal/repository/connect"
 	repository3 "github.com/lin-snow/ech0/internal/repository/echo"
+	repository6 "github.com/lin-snow/ech0/internal/repository/fediverse"
 	"github.com/lin-snow/ech0/internal/repository/keyvalue"
 	repository4 "github.com/lin-snow/ech0/internal/repository/todo"
 	"github.com/lin-snow/ech0/internal/repository/user"
+	repository8 "github.com/lin-snow/ech0/internal/repository/webmention"
+	repository7 "github.com/lin-snow/ech0/internal/repository/websub"
+	service12 "github.com/lin-snow/ech0/internal/service/auth"
 	service7 "github.com/lin-snow/ech0/internal/service/backup"
 	"github.com/lin-snow/ech0/internal/service/common"
 	service6 "github.com/lin-snow/ech0/internal/service/connect"
 	service4 "github.com/lin-snow/ech0/internal/service/echo"
+	service8 "github.com/lin-snow/ech0/internal/service/fediverse"
+	service11 "github.com/lin-snow/ech0/internal/service/micropub"
 	service2 "github.com/lin-snow/ech0/internal/service/setting"
 	service5 "github.com/lin-snow/ech0/internal/service/todo"
 	service3 "github.com/lin-snow/ech0/internal/service/user"
+	service10 "github.com/lin-snow/ech0/internal/service/webmention"
+	service9 "github.com/lin-snow/ech0/internal/service/websub"
 	"github.com/lin-snow/ech0/internal/transaction"
 	"gorm.io/gorm"
 )
@@ -46,11 +60,20 @@ func BuildHandlers(db *gorm.DB, cacheFactory *cache.CacheFactory, tmFactory *tra
 	commonServiceInterface := service.NewCommonService(transactionManager, commonRepositoryInterface)
 	keyValueRepositoryInterface := keyvalue.NewKeyValueRepository(db)
 	settingServiceInterface := service2.NewSettingService(transactionManager, commonServiceInterface, keyValueRepositoryInterface)
-	userServiceInterface := service3.NewUserService(transactionManager, userRepositoryInterface, settingServiceInterface)
+	authRepositoryInterface := repository9.NewAuthRepository(db)
+	userServiceInterface := service3.NewUserService(transactionManager, userRepositoryInterface, authRepositoryInterface, settingServiceInterface)
 	userHandler := handler2.NewUserHandler(userServiceInterface)
 	cacheICache := ProvideEchoCache(cacheFactory)
-	echoRepositoryInterface := repository3.NewEchoRepository(db, cacheICache)
-	echoServiceInterface := service4.NewEchoService(transactionManager, commonServiceInterface, echoRepositoryInterface)
+	iCache2 := ProvideEchoArchiveCache(cacheFactory)
+	iCache3 := ProvideEchoStatsCache(cacheFactory)
+	echoRepositoryInterface := repository3.NewEchoRepository(db, cacheICache, iCache2, iCache3)
+	fediverseRepositoryInterface := repository6.NewFediverseRepository(db)
+	fediverseServiceInterface := service8.NewFediverseService(transactionManager, fediverseRepositoryInterface, echoRepositoryInterface, commonServiceInterface, settingServiceInterface)
+	webSubRepositoryInterface := repository7.NewWebSubRepository(db)
+	webSubServiceInterface := service9.NewWebSubService(transactionManager, webSubRepositoryInterface, commonServiceInterface)
+	webmentionRepositoryInterface := repository8.NewWebmentionRepository(db)
+	webmentionServiceInterface := service10.NewWebmentionService(transactionManager, webmentionRepositoryInterface, echoRepositoryInterface, commonServiceInterface, settingServiceInterface)
+	echoServiceInterface := service4.NewEchoService(transactionManager, commonServiceInterface, echoRepositoryInterface, fediverseServiceInterface, webSubServiceInterface, webmentionServiceInterface)
 	echoHandler := handler3.NewEchoHandler(echoServiceInterface)
 	commonHandler := handler4.NewCommonHandler(commonServiceInterface)
 	settingHandler := handler5.NewSettingHandler(settingServiceInterface)
@@ -62,7 +85,14 @@ func BuildHandlers(db *gorm.DB, cacheFactory *cache.CacheFactory, tmFactory *tra
 	connectHandler := handler7.NewConnectHandler(connectServiceInterface)
 	backupServiceInterface := service7.NewBackupService(commonServiceInterface)
 	backupHandler := handler8.NewBackupHandler(backupServiceInterface)
-	handlers := NewHandlers(webHandler, userHandler, echoHandler, commonHandler, settingHandler, todoHandler, connectHandler, backupHandler)
+	fediverseHandler := handler9.NewFediverseHandler(fediverseServiceInterface)
+	webSubHandler := handler10.NewWebSubHandler(webSubServiceInterface)
+	webmentionHandler := handler11.NewWebmentionHandler(webmentionServiceInterface)
+	micropubServiceInterface := service11.NewMicropubService(echoServiceInterface, commonServiceInterface, settingServiceInterface)
+	micropubHandler := handler12.NewMicropubHandler(micropubServiceInterface)
+	authServiceInterface := service12.NewAuthService(transactionManager, authRepositoryInterface, userServiceInterface, settingServiceInterface)
+	authHandler := handler13.NewAuthHandler(authServiceInterface)
+	handlers := NewHandlers(webHandler, userHandler, echoHandler, commonHandler, settingHandler, todoHandler, connectHandler, backupHandler, fediverseHandler, webSubHandler, webmentionHandler, micropubHandler, authHandler)
 	return handlers, nil
 }
 
@@ -72,6 +102,8 @@ func BuildHandlers(db *gorm.DB, cacheFactory *cache.CacheFactory, tmFactory *tra
 var CacheSet = wire.NewSet(
 	ProvideUserCache,
 	ProvideEchoCache,
+	ProvideEchoArchiveCache,
+	ProvideEchoStatsCache,
 )
 
 // TransactionManagerSet 包含了构建事务管理器所需的所有 Provider
@@ -102,3 +134,18 @@ var ConnectSet = wire.NewSet(repository5.NewConnectRepository, service6.NewConne
 
 // BackupSet 包含了构建 BackupHandler 所需的所有 Provider
 var BackupSet = wire.NewSet(handler8.NewBackupHandler, service7.NewBackupService)
+
+// FediverseSet 包含了构建 FediverseHandler 所需的所有 Provider
+var FediverseSet = wire.NewSet(repository6.NewFediverseRepository, service8.NewFediverseService, handler9.NewFediverseHandler)
+
+// WebSubSet 包含了构建 WebSubHandler 所需的所有 Provider
+var WebSubSet = wire.NewSet(repository7.NewWebSubRepository, service9.NewWebSubService, handler10.NewWebSubHandler)
+
+// WebmentionSet 包含了构建 WebmentionHandler 所需的所有 Provider
+var WebmentionSet = wire.NewSet(repository8.NewWebmentionRepository, service10.NewWebmentionService, handler11.NewWebmentionHandler)
+
+// MicropubSet 包含了构建 MicropubHandler 所需的所有 Provider
+var MicropubSet = wire.NewSet(service11.NewMicropubService, handler12.NewMicropubHandler)
+
+// AuthSet 包含了构建 AuthHandler 所需的所有 Provider
+var AuthSet = wire.NewSet(repository9.NewAuthRepository, service12.NewAuthService, handler13.NewAuthHandler)
@@ -0,0 +1,901 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	res "github.com/lin-snow/ech0/internal/handler/response"
+	"github.com/lin-snow/ech0/internal/middleware"
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	service "github.com/lin-snow/ech0/internal/service/auth"
+	errorUtil "github.com/lin-snow/ech0/internal/util/err"
+)
+
+type AuthHandler struct {
+	authService service.AuthServiceInterface
+}
+
+// NewAuthHandler AuthHandler 的构造函数
+func NewAuthHandler(authService service.AuthServiceInterface) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+// ParseAccessToken 解析访问令牌，供鉴权中间件使用
+func (authHandler *AuthHandler) ParseAccessToken(token string) (*model.MyClaims, error) {
+	return authHandler.authService.ParseAccessToken(token)
+}
+
+// ValidateAccessToken 检查访问令牌所属的会话是否仍然有效，供鉴权中间件使用
+func (authHandler *AuthHandler) ValidateAccessToken(claims *model.MyClaims) error {
+	return authHandler.authService.ValidateAccessToken(claims)
+}
+
+// ValidatePersonalToken 校验个人访问令牌，供鉴权中间件使用
+func (authHandler *AuthHandler) ValidatePersonalToken(token string, ip string) (model.AccessToken, error) {
+	return authHandler.authService.ValidatePersonalToken(token, ip)
+}
+
+// LoadUser 加载令牌所属的用户，供鉴权中间件使用
+func (authHandler *AuthHandler) LoadUser(userid uint) (userModel.User, error) {
+	return authHandler.authService.LoadUser(userid)
+}
+
+// Register 用户注册
+// @Summary 用户注册
+// @Description 通过提交用户名、密码等信息完成注册，同一 IP 的注册请求过多时暂时锁定
+// @Tags 用户认证
+// @Accept application/json
+// @Produce application/json
+// @Param register body model.RegisterDto true "注册请求体"
+// @Success 200 {object} res.Response "注册成功"
+// @Failure 200 {object} res.Response "请求参数错误或注册失败"
+// @Router /register [post]
+func (authHandler *AuthHandler) Register() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var registerDto model.RegisterDto
+		if err := ctx.ShouldBindJSON(&registerDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		if err := authHandler.authService.Register(&registerDto, clientInfo(ctx)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.REGISTER_SUCCESS,
+		}
+	})
+}
+
+// LegacyLogin 用户登录（旧版接口）
+// @Summary 用户登录接口
+// @Description 用户通过用户名和密码登录，返回长期有效的 JWT Token（兼容旧版客户端，推荐使用 /auth/login）
+// @Tags 用户认证
+// @Accept application/json
+// @Produce application/json
+// @Param login body model.LoginDto true "登录请求体"
+// @Success 200 {object} res.Response "登录成功，返回JWT Token"
+// @Failure 200 {object} res.Response "登录失败，返回错误信息"
+// @Router /login [post]
+func (authHandler *AuthHandler) LegacyLogin() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 从请求体获取用户名和密码
+		var loginDto model.LoginDto
+		if err := ctx.ShouldBindJSON(&loginDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		token, err := authHandler.authService.LegacyLogin(&loginDto, clientInfo(ctx))
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		// 返回成功响应， 包含 JWT Token
+		return res.Response{
+			Data: token,
+			Msg:  commonModel.LOGIN_SUCCESS,
+		}
+	})
+}
+
+// Login 用户登录
+// @Summary 用户登录
+// @Description 用户通过用户名和密码登录，返回短期有效的访问令牌和用于续期的刷新令牌
+// @Tags 用户认证
+// @Accept application/json
+// @Produce application/json
+// @Param login body model.LoginDto true "登录请求体"
+// @Success 200 {object} res.Response{data=model.LoginResult} "登录成功，开启两步验证时返回验证凭据"
+// @Failure 200 {object} res.Response "登录失败，返回错误信息"
+// @Router /auth/login [post]
+func (authHandler *AuthHandler) Login() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var loginDto model.LoginDto
+		if err := ctx.ShouldBindJSON(&loginDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		result, err := authHandler.authService.Login(&loginDto, clientInfo(ctx))
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		if result.TwoFactorRequired {
+			return res.Response{
+				Data: result,
+				Msg:  commonModel.TWO_FACTOR_CHALLENGE,
+			}
+		}
+
+		return res.Response{
+			Data: result,
+			Msg:  commonModel.LOGIN_SUCCESS,
+		}
+	})
+}
+
+// Refresh 刷新令牌
+// @Summary 刷新令牌
+// @Description 使用刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌随即失效
+// @Tags 用户认证
+// @Accept application/json
+// @Produce application/json
+// @Param refresh body model.RefreshDto true "刷新令牌"
+// @Success 200 {object} res.Response{data=model.TokenPair} "刷新成功"
+// @Failure 200 {object} res.Response "刷新令牌无效"
+// @Router /auth/refresh [post]
+func (authHandler *AuthHandler) Refresh() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var refreshDto model.RefreshDto
+		if err := ctx.ShouldBindJSON(&refreshDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		tokens, err := authHandler.authService.Refresh(refreshDto.RefreshToken, clientInfo(ctx))
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: tokens,
+			Msg:  commonModel.REFRESH_TOKEN_SUCCESS,
+		}
+	})
+}
+
+// Logout 退出登录
+// @Summary 退出登录
+// @Description 撤销当前会话，访问令牌和刷新令牌立即失效
+// @Tags 用户认证
+// @Produce application/json
+// @Success 200 {object} res.Response "退出成功"
+// @Failure 200 {object} res.Response "退出失败"
+// @Router /auth/logout [post]
+func (authHandler *AuthHandler) Logout() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		if err := authHandler.authService.Logout(userId, ctx.GetString("sessionid")); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.LOGOUT_SUCCESS,
+		}
+	})
+}
+
+// GetSessions 获取当前用户的会话
+// @Summary 获取会话列表
+// @Description 获取当前用户的有效会话，包括设备、IP 和最近使用时间
+// @Tags 用户认证
+// @Produce application/json
+// @Success 200 {object} res.Response{data=[]model.Session} "获取成功"
+// @Failure 200 {object} res.Response "获取失败"
+// @Router /auth/sessions [get]
+func (authHandler *AuthHandler) GetSessions() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		sessions, err := authHandler.authService.GetSessions(userId, ctx.GetString("sessionid"))
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: sessions,
+			Msg:  commonModel.GET_SESSIONS_SUCCESS,
+		}
+	})
+}
+
+// RevokeSession 撤销指定会话
+// @Summary 撤销会话
+// @Description 撤销当前用户的指定会话，该会话的令牌立即失效
+// @Tags 用户认证
+// @Produce application/json
+// @Param id path string true "会话ID"
+// @Success 200 {object} res.Response "撤销成功"
+// @Failure 200 {object} res.Response "撤销失败"
+// @Router /auth/sessions/{id} [delete]
+func (authHandler *AuthHandler) RevokeSession() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		if err := authHandler.authService.RevokeSession(userId, ctx.Param("id")); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.REVOKE_SESSION_SUCCESS,
+		}
+	})
+}
+
+// RevokeAllSessions 撤销所有会话
+// @Summary 撤销所有会话
+// @Description 撤销当前用户的所有会话（包括当前会话），所有设备都需要重新登录
+// @Tags 用户认证
+// @Produce application/json
+// @Success 200 {object} res.Response "撤销成功"
+// @Failure 200 {object} res.Response "撤销失败"
+// @Router /auth/sessions [delete]
+func (authHandler *AuthHandler) RevokeAllSessions() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		if err := authHandler.authService.RevokeAllSessions(userId); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.REVOKE_ALL_SESSIONS_SUCCESS,
+		}
+	})
+}
+
+// CreateAccessToken 创建个人访问令牌
+// @Summary 创建个人访问令牌
+// @Description 创建用于脚本和自动化的个人访问令牌，令牌明文只在创建时返回一次
+// @Tags 用户认证
+// @Accept application/json
+// @Produce application/json
+// @Param token body model.CreateAccessTokenDto true "令牌名称、权限范围和有效天数"
+// @Success 200 {object} res.Response{data=model.CreatedAccessToken} "创建成功"
+// @Failure 200 {object} res.Response "创建失败"
+// @Router /auth/tokens [post]
+func (authHandler *AuthHandler) CreateAccessToken() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		var dto model.CreateAccessTokenDto
+		if err := ctx.ShouldBindJSON(&dto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		token, err := authHandler.authService.CreateAccessToken(userId, &dto)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: token,
+			Msg:  commonModel.CREATE_ACCESS_TOKEN_SUCCESS,
+		}
+	})
+}
+
+// GetAccessTokens 获取个人访问令牌
+// @Summary 获取个人访问令牌列表
+// @Description 获取当前用户的个人访问令牌，包括权限范围、有效期和最近使用情况
+// @Tags 用户认证
+// @Produce application/json
+// @Success 200 {object} res.Response{data=[]model.AccessToken} "获取成功"
+// @Failure 200 {object} res.Response "获取失败"
+// @Router /auth/tokens [get]
+func (authHandler *AuthHandler) GetAccessTokens() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		tokens, err := authHandler.authService.GetAccessTokens(userId)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: tokens,
+			Msg:  commonModel.GET_ACCESS_TOKENS_SUCCESS,
+		}
+	})
+}
+
+// DeleteAccessToken 删除个人访问令牌
+// @Summary 删除个人访问令牌
+// @Description 删除当前用户的个人访问令牌，令牌立即失效
+// @Tags 用户认证
+// @Produce application/json
+// @Param id path int true "令牌ID"
+// @Success 200 {object} res.Response "删除成功"
+// @Failure 200 {object} res.Response "删除失败"
+// @Router /auth/tokens/{id} [delete]
+func (authHandler *AuthHandler) DeleteAccessToken() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS_BODY,
+				Err: err,
+			}
+		}
+
+		if err := authHandler.authService.DeleteAccessToken(userId, uint(id)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.DELETE_ACCESS_TOKEN_SUCCESS,
+		}
+	})
+}
+
+// VerifyTwoFactor 完成两步验证
+// @Summary 完成两步验证
+// @Description 使用登录时返回的凭据和身份验证器中的验证码（或恢复码）完成登录
+// @Tags 用户认证
+// @Accept application/json
+// @Produce application/json
+// @Param verify body model.TwoFactorVerifyDto true "验证凭据和验证码"
+// @Success 200 {object} res.Response{data=model.TokenPair} "登录成功"
+// @Failure 200 {object} res.Response "验证失败"
+// @Router /auth/2fa/verify [post]
+func (authHandler *AuthHandler) VerifyTwoFactor() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var dto model.TwoFactorVerifyDto
+		if err := ctx.ShouldBindJSON(&dto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		tokens, err := authHandler.authService.VerifyTwoFactor(&dto, clientInfo(ctx))
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: tokens,
+			Msg:  commonModel.LOGIN_SUCCESS,
+		}
+	})
+}
+
+// GetTwoFactorStatus 获取两步验证状态
+// @Summary 获取两步验证状态
+// @Description 获取当前用户是否开启两步验证以及剩余的恢复码数量
+// @Tags 用户认证
+// @Produce application/json
+// @Success 200 {object} res.Response{data=model.TwoFactorStatus} "获取成功"
+// @Failure 200 {object} res.Response "获取失败"
+// @Router /auth/2fa [get]
+func (authHandler *AuthHandler) GetTwoFactorStatus() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		status, err := authHandler.authService.GetTwoFactorStatus(userId)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: status,
+			Msg:  commonModel.GET_TWO_FACTOR_SUCCESS,
+		}
+	})
+}
+
+// SetupTwoFactor 开始绑定两步验证
+// @Summary 开始绑定两步验证
+// @Description 生成新的 TOTP 密钥，返回密钥和 otpauth URI（二维码内容），提交验证码后才会启用
+// @Tags 用户认证
+// @Produce application/json
+// @Success 200 {object} res.Response{data=model.TwoFactorSetup} "生成成功"
+// @Failure 200 {object} res.Response "生成失败"
+// @Router /auth/2fa/setup [post]
+func (authHandler *AuthHandler) SetupTwoFactor() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		setup, err := authHandler.authService.SetupTwoFactor(userId)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: setup,
+			Msg:  commonModel.SETUP_TWO_FACTOR_SUCCESS,
+		}
+	})
+}
+
+// EnableTwoFactor 启用两步验证
+// @Summary 启用两步验证
+// @Description 提交身份验证器中的验证码以启用两步验证，返回只显示一次的恢复码
+// @Tags 用户认证
+// @Accept application/json
+// @Produce application/json
+// @Param code body model.TwoFactorCodeDto true "验证码"
+// @Success 200 {object} res.Response{data=[]string} "启用成功，返回恢复码"
+// @Failure 200 {object} res.Response "启用失败"
+// @Router /auth/2fa/enable [post]
+func (authHandler *AuthHandler) EnableTwoFactor() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		var dto model.TwoFactorCodeDto
+		if err := ctx.ShouldBindJSON(&dto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		codes, err := authHandler.authService.EnableTwoFactor(userId, dto.Code)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: codes,
+			Msg:  commonModel.ENABLE_TWO_FACTOR_SUCCESS,
+		}
+	})
+}
+
+// DisableTwoFactor 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 提交验证码或恢复码以关闭两步验证
+// @Tags 用户认证
+// @Accept application/json
+// @Produce application/json
+// @Param code body model.TwoFactorCodeDto true "验证码或恢复码"
+// @Success 200 {object} res.Response "关闭成功"
+// @Failure 200 {object} res.Response "关闭失败"
+// @Router /auth/2fa/disable [post]
+func (authHandler *AuthHandler) DisableTwoFactor() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		var dto model.TwoFactorCodeDto
+		if err := ctx.ShouldBindJSON(&dto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		if err := authHandler.authService.DisableTwoFactor(userId, dto.Code); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.DISABLE_TWO_FACTOR_SUCCESS,
+		}
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 提交验证码或恢复码以重新生成恢复码，旧的恢复码全部失效
+// @Tags 用户认证
+// @Accept application/json
+// @Produce application/json
+// @Param code body model.TwoFactorCodeDto true "验证码或恢复码"
+// @Success 200 {object} res.Response{data=[]string} "生成成功，返回恢复码"
+// @Failure 200 {object} res.Response "生成失败"
+// @Router /auth/2fa/recovery-codes [post]
+func (authHandler *AuthHandler) RegenerateRecoveryCodes() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		var dto model.TwoFactorCodeDto
+		if err := ctx.ShouldBindJSON(&dto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		codes, err := authHandler.authService.RegenerateRecoveryCodes(userId, dto.Code)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: codes,
+			Msg:  commonModel.RECOVERY_CODES_SUCCESS,
+		}
+	})
+}
+
+// GetOIDCProviders 获取身份提供者
+// @Summary 获取身份提供者
+// @Description 获取已配置的 OpenID Connect 身份提供者，用于在登录页面展示登录按钮
+// @Tags 用户认证
+// @Produce application/json
+// @Success 200 {object} res.Response{data=[]model.OIDCProviderInfo} "获取成功"
+// @Router /auth/oidc/providers [get]
+func (authHandler *AuthHandler) GetOIDCProviders() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		return res.Response{
+			Data: authHandler.authService.GetOIDCProviders(),
+			Msg:  commonModel.GET_OIDC_PROVIDERS_SUCCESS,
+		}
+	})
+}
+
+// OIDCLogin 通过身份提供者登录
+// @Summary 通过身份提供者登录
+// @Description 跳转到身份提供者的授权页面（授权码模式，使用 PKCE），完成后回调到 /auth/oidc/{provider}/callback
+// @Tags 用户认证
+// @Param provider path string true "身份提供者标识"
+// @Success 302 "跳转到身份提供者"
+// @Router /auth/oidc/{provider}/login [get]
+func (authHandler *AuthHandler) OIDCLogin() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		authorization, err := authHandler.authService.AuthorizeOIDC(ctx.Param("provider"), 0)
+		if err != nil {
+			redirectOIDCError(ctx, model.OIDCLoginRedirect, err)
+			return res.Response{}
+		}
+
+		setOIDCStateCookie(ctx, authorization.State, int(model.OIDCStateExpiry.Seconds()))
+		ctx.Redirect(http.StatusFound, authorization.URL)
+		return res.Response{}
+	})
+}
+
+// LinkOIDC 关联身份提供者
+// @Summary 关联身份提供者
+// @Description 为当前用户发起关联身份的授权请求，返回身份提供者的授权地址，前端跳转后完成关联
+// @Tags 用户认证
+// @Produce application/json
+// @Param provider path string true "身份提供者标识"
+// @Success 200 {object} res.Response{data=model.OIDCAuthorization} "发起成功"
+// @Failure 200 {object} res.Response "发起失败"
+// @Router /auth/oidc/{provider}/link [post]
+func (authHandler *AuthHandler) LinkOIDC() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		authorization, err := authHandler.authService.AuthorizeOIDC(ctx.Param("provider"), userId)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		setOIDCStateCookie(ctx, authorization.State, int(model.OIDCStateExpiry.Seconds()))
+		return res.Response{
+			Data: authorization,
+			Msg:  commonModel.OIDC_AUTHORIZE_SUCCESS,
+		}
+	})
+}
+
+// OIDCCallback 身份提供者回调
+// @Summary 身份提供者回调
+// @Description 校验授权结果后跳转回前端：登录时跳转到 /auth 并在 URL 片段中附带令牌，关联身份时跳转到 /panel，失败时在片段中附带 error
+// @Tags 用户认证
+// @Param provider path string true "身份提供者标识"
+// @Param code query string false "授权码"
+// @Param state query string true "授权请求的 state"
+// @Success 302 "跳转回前端"
+// @Router /auth/oidc/{provider}/callback [get]
+func (authHandler *AuthHandler) OIDCCallback() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		cookieState, _ := ctx.Cookie(model.OIDCStateCookie)
+		setOIDCStateCookie(ctx, "", -1)
+
+		// 身份提供者返回的错误（例如用户拒绝授权）
+		if providerErr := ctx.Query("error"); providerErr != "" {
+			redirectOIDCError(ctx, model.OIDCLoginRedirect, errors.New(commonModel.OIDC_LOGIN_FAILED+": "+providerErr))
+			return res.Response{}
+		}
+
+		result, err := authHandler.authService.HandleOIDCCallback(
+			ctx.Param("provider"), ctx.Query("state"), cookieState, ctx.Query("code"), clientInfo(ctx),
+		)
+		if err != nil {
+			redirectOIDCError(ctx, model.OIDCLoginRedirect, err)
+			return res.Response{}
+		}
+
+		if result.Linked {
+			ctx.Redirect(http.StatusFound, model.OIDCLinkRedirect+"#"+url.Values{"oidc_linked": {ctx.Param("provider")}}.Encode())
+			return res.Response{}
+		}
+
+		fragment := url.Values{
+			"access_token":  {result.Tokens.AccessToken},
+			"refresh_token": {result.Tokens.RefreshToken},
+			"token_type":    {result.Tokens.TokenType},
+			"expires_in":    {strconv.Itoa(result.Tokens.ExpiresIn)},
+		}
+		ctx.Redirect(http.StatusFound, model.OIDCLoginRedirect+"#"+fragment.Encode())
+		return res.Response{}
+	})
+}
+
+// GetOIDCIdentities 获取关联的身份
+// @Summary 获取关联的身份
+// @Description 获取当前用户关联的身份提供者身份
+// @Tags 用户认证
+// @Produce application/json
+// @Success 200 {object} res.Response{data=[]model.OIDCIdentity} "获取成功"
+// @Failure 200 {object} res.Response "获取失败"
+// @Router /auth/oidc/identities [get]
+func (authHandler *AuthHandler) GetOIDCIdentities() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		identities, err := authHandler.authService.GetOIDCIdentities(userId)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: identities,
+			Msg:  commonModel.GET_OIDC_IDENTITIES_SUCCESS,
+		}
+	})
+}
+
+// DeleteOIDCIdentity 取消关联身份
+// @Summary 取消关联身份
+// @Description 取消当前用户与身份提供者身份的关联
+// @Tags 用户认证
+// @Produce application/json
+// @Param id path int true "身份ID"
+// @Success 200 {object} res.Response "取消成功"
+// @Failure 200 {object} res.Response "取消失败"
+// @Router /auth/oidc/identities/{id} [delete]
+func (authHandler *AuthHandler) DeleteOIDCIdentity() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS_BODY,
+				Err: err,
+			}
+		}
+
+		if err := authHandler.authService.DeleteOIDCIdentity(userId, uint(id)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.DELETE_OIDC_IDENTITY_SUCCESS,
+		}
+	})
+}
+
+// GetLockouts 获取锁定记录
+// @Summary 获取锁定记录
+// @Description 获取按 IP 和用户名统计的登录、注册失败次数和锁定状态（仅管理员）
+// @Tags 用户认证
+// @Produce application/json
+// @Success 200 {object} res.Response{data=[]model.Lockout} "获取成功"
+// @Failure 200 {object} res.Response "获取失败"
+// @Router /auth/lockouts [get]
+func (authHandler *AuthHandler) GetLockouts() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		lockouts, err := authHandler.authService.GetLockouts(user)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: lockouts,
+			Msg:  commonModel.GET_LOCKOUTS_SUCCESS,
+		}
+	})
+}
+
+// ClearLockout 解除锁定
+// @Summary 解除锁定
+// @Description 解除指定 IP 或用户名的锁定并清空失败次数（仅管理员）
+// @Tags 用户认证
+// @Produce application/json
+// @Param id path int true "锁定记录ID"
+// @Success 200 {object} res.Response "解除成功"
+// @Failure 200 {object} res.Response "解除失败"
+// @Router /auth/lockouts/{id} [delete]
+func (authHandler *AuthHandler) ClearLockout() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS_BODY,
+				Err: err,
+			}
+		}
+
+		if err := authHandler.authService.ClearLockout(user, uint(id)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.CLEAR_LOCKOUT_SUCCESS,
+		}
+	})
+}
+
+// ClearAllLockouts 解除所有锁定
+// @Summary 解除所有锁定
+// @Description 解除所有 IP 和用户名的锁定并清空失败次数（仅管理员）
+// @Tags 用户认证
+// @Produce application/json
+// @Success 200 {object} res.Response "解除成功"
+// @Failure 200 {object} res.Response "解除失败"
+// @Router /auth/lockouts [delete]
+func (authHandler *AuthHandler) ClearAllLockouts() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		if err := authHandler.authService.ClearAllLockouts(user); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.CLEAR_LOCKOUT_SUCCESS,
+		}
+	})
+}
+
+// GetJWKS 获取签名公钥
+// @Summary 获取签名公钥
+// @Description 以 JWKS 格式返回仍在使用的 Ed25519 签名密钥的公钥，供其它服务校验本实例签发的令牌
+// @Tags 用户认证
+// @Produce application/json
+// @Success 200 {object} model.JWKS "签名公钥"
+// @Router /.well-known/jwks.json [get]
+func (authHandler *AuthHandler) GetJWKS() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		jwks, err := authHandler.authService.GetJWKS()
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		// JWKS 需要保持标准格式，不使用统一的响应结构
+		ctx.JSON(http.StatusOK, jwks)
+		return res.Response{}
+	})
+}
+
+// clientInfo 获取发起请求的客户端信息
+func clientInfo(ctx *gin.Context) model.ClientInfo {
+	return model.ClientInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IP:        ctx.ClientIP(),
+	}
+}
+
+// setOIDCStateCookie 设置绑定授权请求与浏览器的 Cookie，maxAge 小于 0 时删除
+func setOIDCStateCookie(ctx *gin.Context, state string, maxAge int) {
+	secure := ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https"
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(model.OIDCStateCookie, state, maxAge, model.OIDCCookiePath, "", secure, true)
+}
+
+// redirectOIDCError 跳转回前端页面，并在 URL 片段中附带错误信息
+func redirectOIDCError(ctx *gin.Context, path string, err error) {
+	msg := errorUtil.HandleError(&commonModel.ServerError{
+		Msg: "",
+		Err: err,
+	})
+	ctx.Redirect(http.StatusFound, path+"#"+url.Values{"error": {msg}}.Encode())
+}
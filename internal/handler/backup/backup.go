@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	res "github.com/lin-snow/ech0/internal/handler/response"
+	"github.com/lin-snow/ech0/internal/middleware"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	service "github.com/lin-snow/ech0/internal/service/backup"
 )
@@ -31,8 +32,9 @@ func NewBackupHandler(backupService service.BackupServiceInterface) *BackupHandl
 // @Router /backup [get]
 func (backupHandler *BackupHandler) Backup() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		userId := ctx.MustGet("userid").(uint)
-		if err := backupHandler.backupService.Backup(userId); err != nil {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+		if err := backupHandler.backupService.Backup(user); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -57,8 +59,9 @@ func (backupHandler *BackupHandler) Backup() gin.HandlerFunc {
 // @Router /backup/export [get]
 func (backupHandler *BackupHandler) ExportBackup() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		userId := ctx.MustGet("userid").(uint)
-		if err := backupHandler.backupService.ExportBackup(userId, ctx); err != nil {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+		if err := backupHandler.backupService.ExportBackup(user, ctx); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
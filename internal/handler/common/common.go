@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	res "github.com/lin-snow/ech0/internal/handler/response"
+	"github.com/lin-snow/ech0/internal/middleware"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	service "github.com/lin-snow/ech0/internal/service/common"
 	errorUtil "github.com/lin-snow/ech0/internal/util/err"
@@ -58,11 +62,11 @@ func (commonHandler *CommonHandler) UploadImage() gin.HandlerFunc {
 			}
 		}
 
-		// 提取userid
-		userId := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		// 调用 CommonService 上传文件
-		imageUrl, err := commonHandler.commonService.UploadImage(userId, file)
+		imageUrl, err := commonHandler.commonService.UploadImage(user, file)
 		if err != nil {
 			return res.Response{
 				Msg: "",
@@ -90,7 +94,8 @@ func (commonHandler *CommonHandler) UploadImage() gin.HandlerFunc {
 // @Router /images/delete [delete]
 func (commonHandler *CommonHandler) DeleteImage() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		userId := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		var imageDto commonModel.ImageDto
 		if err := ctx.ShouldBindJSON(&imageDto); err != nil {
@@ -100,7 +105,7 @@ func (commonHandler *CommonHandler) DeleteImage() gin.HandlerFunc {
 			}
 		}
 
-		if err := commonHandler.commonService.DeleteImage(userId, imageDto.URL, imageDto.SOURCE); err != nil {
+		if err := commonHandler.commonService.DeleteImage(user, imageDto.URL, imageDto.SOURCE); err != nil {
 			ctx.JSON(http.StatusOK, commonModel.Fail[string](errorUtil.HandleError(&commonModel.ServerError{
 				Msg: "",
 				Err: err,
@@ -155,17 +160,35 @@ func (commonHandler *CommonHandler) GetStatus() gin.HandlerFunc {
 // GetHeatMap 获取热力图数据
 //
 // @Summary 获取热力图数据
-// @Description 获取系统活动热力图数据，用于展示用户活动分布情况
+// @Description 获取系统活动热力图数据，用于展示用户活动分布情况。默认返回最近 30 天，可通过 days、year 或 start/end 指定范围，并按 timezone 时区分组
 // @Tags 通用功能
 // @Accept json
 // @Produce json
-// @Success 200 {object} res.Response{data=object} "获取热力图数据成功"
+// @Param days query int false "最近多少天（默认 30，最多 366）"
+// @Param year query int false "年份，返回该年全年数据"
+// @Param start query string false "开始日期 (YYYY-MM-DD)"
+// @Param end query string false "结束日期 (YYYY-MM-DD)"
+// @Param timezone query string false "IANA 时区名称，默认使用站点设置的时区"
+// @Param user_id query int false "只统计指定用户"
+// @Param private query bool false "是否包含私密 Echo（仅管理员有效）"
+// @Success 200 {object} res.Response{data=[]model.Heatmap} "获取热力图数据成功"
 // @Failure 200 {object} res.Response "获取热力图数据失败"
 // @Router /heatmap [get]
 func (commonHandler *CommonHandler) GetHeatMap() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		var heatmapQueryDto commonModel.HeatmapQueryDto
+		if err := ctx.ShouldBindQuery(&heatmapQueryDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
 		// 调用 Service 层获取热力图数据
-		heatMap, err := commonHandler.commonService.GetHeatMap()
+		heatMap, err := commonHandler.commonService.GetHeatMap(user, heatmapQueryDto)
 		if err != nil {
 			return res.Response{
 				Msg: "",
@@ -181,18 +204,122 @@ func (commonHandler *CommonHandler) GetHeatMap() gin.HandlerFunc {
 
 }
 
+// GetUserHeatMap 获取指定用户的热力图数据
+//
+// @Summary 获取用户的热力图数据
+// @Description 获取指定用户的发布热力图，参数与 /heatmap 相同，本人和管理员可以统计私密 Echo
+// @Tags 通用功能
+// @Produce json
+// @Param username path string true "用户名"
+// @Param days query int false "最近多少天（默认 30，最多 366）"
+// @Param year query int false "年份，返回该年全年数据"
+// @Param start query string false "开始日期 (YYYY-MM-DD)"
+// @Param end query string false "结束日期 (YYYY-MM-DD)"
+// @Param timezone query string false "IANA 时区名称，默认使用站点设置的时区"
+// @Param private query bool false "是否包含私密 Echo（仅本人和管理员有效）"
+// @Success 200 {object} res.Response{data=[]model.Heatmap} "获取热力图数据成功"
+// @Failure 200 {object} res.Response "用户不存在或获取热力图数据失败"
+// @Router /users/{username}/heatmap [get]
+func (commonHandler *CommonHandler) GetUserHeatMap() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		var heatmapQueryDto commonModel.HeatmapQueryDto
+		if err := ctx.ShouldBindQuery(&heatmapQueryDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		heatMap, err := commonHandler.commonService.GetUserHeatMap(user, ctx.Param("username"), heatmapQueryDto)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: heatMap,
+			Msg:  commonModel.GET_HEATMAP_SUCCESS,
+		}
+	})
+}
+
 // GetRss 获取RSS
 //
 // @Summary 获取RSS订阅源
-// @Description 获取系统的RSS订阅源（Atom格式），用于订阅最新动态
+// @Description 获取系统的订阅源（Atom格式），用于订阅最新动态，支持按标签和用户过滤以及条件请求（If-None-Match）
 // @Tags 通用功能
 // @Accept json
-// @Produce application/rss+xml
-// @Success 200 {string} string "返回RSS内容（xml格式）"
+// @Produce application/atom+xml
+// @Param limit query int false "条目数量，默认 20，最多 100"
+// @Param tag query string false "只包含带有指定标签的 Echo"
+// @Param user query string false "只包含指定用户发布的 Echo"
+// @Success 200 {string} string "返回订阅源内容（xml格式）"
+// @Success 304 {string} string "订阅源未修改"
 // @Failure 200 {object} res.Response "获取RSS失败"
 // @Router /rss [get]
 func (commonHandler *CommonHandler) GetRss(ctx *gin.Context) {
-	atom, err := commonHandler.commonService.GenerateRSS(ctx)
+	commonHandler.serveFeed(ctx, commonModel.FeedFormatAtom)
+}
+
+// GetFeed 获取指定格式的订阅源
+//
+// @Summary 获取订阅源
+// @Description 获取指定格式（atom、rss、json）的订阅源，支持按标签和用户过滤以及条件请求（If-None-Match）
+// @Tags 通用功能
+// @Accept json
+// @Produce application/atom+xml,application/rss+xml,application/feed+json
+// @Param format path string true "订阅源格式（atom、rss、json）"
+// @Param limit query int false "条目数量，默认 20，最多 100"
+// @Param tag query string false "只包含带有指定标签的 Echo"
+// @Param user query string false "只包含指定用户发布的 Echo"
+// @Success 200 {string} string "返回订阅源内容"
+// @Success 304 {string} string "订阅源未修改"
+// @Failure 200 {object} res.Response "获取订阅源失败"
+// @Router /feed/{format} [get]
+func (commonHandler *CommonHandler) GetFeed(ctx *gin.Context) {
+	commonHandler.serveFeed(ctx, commonModel.FeedFormat(ctx.Param("format")))
+}
+
+// GetUserFeed 获取指定用户的订阅源
+//
+// @Summary 获取用户的订阅源
+// @Description 获取指定用户发布的公开 Echo 的订阅源（atom、rss、json），支持按标签过滤以及条件请求（If-None-Match）
+// @Tags 通用功能
+// @Produce application/atom+xml,application/rss+xml,application/feed+json
+// @Param username path string true "用户名"
+// @Param format path string true "订阅源格式（atom、rss、json）"
+// @Param limit query int false "条目数量，默认 20，最多 100"
+// @Param tag query string false "只包含带有指定标签的 Echo"
+// @Success 200 {string} string "订阅源内容"
+// @Success 304 {string} string "内容未修改"
+// @Failure 200 {object} res.Response "用户不存在或订阅格式不支持"
+// @Router /users/{username}/feed/{format} [get]
+func (commonHandler *CommonHandler) GetUserFeed(ctx *gin.Context) {
+	commonHandler.serveFeed(ctx, commonModel.FeedFormat(ctx.Param("format")))
+}
+
+// serveFeed 生成订阅源并处理条件请求，内容未修改时返回 304
+func (commonHandler *CommonHandler) serveFeed(ctx *gin.Context, format commonModel.FeedFormat) {
+	var feedQueryDto commonModel.FeedQueryDto
+	if err := ctx.ShouldBindQuery(&feedQueryDto); err != nil {
+		ctx.JSON(http.StatusOK, commonModel.Fail[string](errorUtil.HandleError(&commonModel.ServerError{
+			Msg: commonModel.INVALID_QUERY_PARAMS,
+			Err: err,
+		})))
+		return
+	}
+
+	// 用户的订阅源只包含该用户发布的 Echo
+	if username := ctx.Param("username"); username != "" {
+		feedQueryDto.Username = username
+	}
+
+	feed, err := commonHandler.commonService.GenerateFeed(ctx, format, feedQueryDto)
 	if err != nil {
 		ctx.JSON(http.StatusOK, commonModel.Fail[string](errorUtil.HandleError(&commonModel.ServerError{
 			Msg: "",
@@ -201,7 +328,29 @@ func (commonHandler *CommonHandler) GetRss(ctx *gin.Context) {
 		return
 	}
 
-	ctx.Data(http.StatusOK, "application/rss+xml; charset=utf-8", []byte(atom))
+	// WebSub 发现（hub/self）
+	ctx.Header("Link", fmt.Sprintf("<%s>; rel=\"hub\", <%s>; rel=\"self\"", feed.HubURL, feed.SelfURL))
+
+	// 根据内容生成 ETag，并设置 Last-Modified
+	etag := fmt.Sprintf("\"%x\"", sha256.Sum256(feed.Content))
+	ctx.Header("ETag", etag)
+	if !feed.LastModified.IsZero() {
+		ctx.Header("Last-Modified", feed.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	// 只根据 ETag 返回 304：Last-Modified 取自最新条目的发布时间，
+	// 编辑或删除 Echo 不会改变它，按 If-Modified-Since 判断会返回过期的内容
+	if ifNoneMatch := ctx.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				ctx.Status(http.StatusNotModified)
+				return
+			}
+		}
+	}
+
+	ctx.Data(http.StatusOK, feed.ContentType, feed.Content)
 }
 
 // UploadAudio 上传音频
@@ -217,8 +366,8 @@ func (commonHandler *CommonHandler) GetRss(ctx *gin.Context) {
 // @Router /audios/upload [post]
 func (commonHandler *CommonHandler) UploadAudio() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 提取userid
-		userId := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		// 提取上传的 File数据
 		file, err := ctx.FormFile("file")
@@ -229,7 +378,7 @@ func (commonHandler *CommonHandler) UploadAudio() gin.HandlerFunc {
 			}
 		}
 
-		audioUrl, err := commonHandler.commonService.UploadMusic(userId, file)
+		audioUrl, err := commonHandler.commonService.UploadMusic(user, file)
 		if err != nil {
 			return res.Response{
 				Msg: "",
@@ -256,10 +405,10 @@ func (commonHandler *CommonHandler) UploadAudio() gin.HandlerFunc {
 // @Router /audios/delete [delete]
 func (commonHandler *CommonHandler) DeleteAudio() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 提取userid
-		userId := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
-		if err := commonHandler.commonService.DeleteMusic(userId); err != nil {
+		if err := commonHandler.commonService.DeleteMusic(user); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	model "github.com/lin-snow/ech0/internal/model/common"
+	service "github.com/lin-snow/ech0/internal/service/common"
+)
+
+// fakeCommonService 返回固定格式的订阅源，内容可以在测试中修改
+type fakeCommonService struct {
+	service.CommonServiceInterface
+	content      string
+	lastModified time.Time
+	query        model.FeedQueryDto
+}
+
+func (s *fakeCommonService) GenerateFeed(ctx *gin.Context, format model.FeedFormat, feedQueryDto model.FeedQueryDto) (model.Feed, error) {
+	s.query = feedQueryDto
+	return model.Feed{
+		Content:      []byte(s.content),
+		ContentType:  "application/" + string(format) + "+xml",
+		LastModified: s.lastModified,
+		SelfURL:      "https://ech0.example/api/feed/" + string(format),
+		HubURL:       "https://ech0.example/api/websub",
+	}, nil
+}
+
+func newFeedEngine(feeds *fakeCommonService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewCommonHandler(feeds)
+	engine := gin.New()
+	engine.GET("/feed/:format", handler.GetFeed)
+	engine.GET("/users/:username/feed/:format", handler.GetUserFeed)
+	return engine
+}
+
+func getFeed(engine *gin.Engine, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+// 订阅源携带 ETag，If-None-Match 匹配时返回 304，内容变化后返回新的内容
+func TestServeFeed_ConditionalRequest(t *testing.T) {
+	published := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	feeds := &fakeCommonService{content: "<feed>v1</feed>", lastModified: published}
+	engine := newFeedEngine(feeds)
+
+	w := getFeed(engine, "/feed/atom", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<feed>v1</feed>", w.Body.String())
+	assert.Equal(t, "application/atom+xml", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Wed, 01 May 2024 08:00:00 GMT", w.Header().Get("Last-Modified"))
+	assert.Equal(t, `<https://ech0.example/api/websub>; rel="hub", <https://ech0.example/api/feed/atom>; rel="self"`, w.Header().Get("Link"))
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"same etag", etag, http.StatusNotModified},
+		{"weak etag in list", `"other", W/` + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"other etag", `"other"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := getFeed(engine, "/feed/atom", http.Header{"If-None-Match": {tt.ifNoneMatch}})
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			if tt.want == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+
+	// 编辑 Echo 不会改变 Last-Modified，只根据 ETag 判断
+	feeds.content = "<feed>v2</feed>"
+	w = getFeed(engine, "/feed/atom", http.Header{
+		"If-None-Match":     {etag},
+		"If-Modified-Since": {published.Format(http.TimeFormat)},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<feed>v2</feed>", w.Body.String())
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	w = getFeed(engine, "/feed/atom", http.Header{"If-Modified-Since": {published.Format(http.TimeFormat)}})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// 用户的订阅源使用路径中的用户名过滤
+func TestServeFeed_UserFeed(t *testing.T) {
+	feeds := &fakeCommonService{content: "<rss/>"}
+	engine := newFeedEngine(feeds)
+
+	w := getFeed(engine, "/users/alice/feed/rss?user=bob&tag=go", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "alice", feeds.query.Username)
+	assert.Equal(t, "go", feeds.query.Tag)
+	assert.Empty(t, w.Header().Get("Last-Modified"))
+}
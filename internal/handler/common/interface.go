@@ -18,6 +18,9 @@ type CommonHandlerInterface interface {
 	// GetHeatMap 获取热力图
 	GetHeatMap() gin.HandlerFunc
 
+	// GetUserHeatMap 获取指定用户的热力图
+	GetUserHeatMap() gin.HandlerFunc
+
 	// UploadAudio 上传音频
 	UploadAudio() gin.HandlerFunc
 
@@ -33,6 +36,12 @@ type CommonHandlerInterface interface {
 	// GetRss 获取RSS
 	GetRss(ctx *gin.Context)
 
+	// GetFeed 获取指定格式的订阅源
+	GetFeed(ctx *gin.Context)
+
+	// GetUserFeed 获取指定用户的订阅源
+	GetUserFeed(ctx *gin.Context)
+
 	// PlayMusic 播放音乐
 	PlayMusic(ctx *gin.Context)
 }
@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"errors"
+	"io"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	res "github.com/lin-snow/ech0/internal/handler/response"
+	"github.com/lin-snow/ech0/internal/middleware"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/connect"
 	service "github.com/lin-snow/ech0/internal/service/connect"
@@ -34,7 +38,8 @@ func NewConnectHandler(connectService service.ConnectServiceInterface) *ConnectH
 // @Router /addConnect [post]
 func (connectHandler *ConnectHandler) AddConnect() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		userId := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		var connected model.Connected
 		if err := ctx.ShouldBindJSON(&connected); err != nil {
@@ -43,7 +48,7 @@ func (connectHandler *ConnectHandler) AddConnect() gin.HandlerFunc {
 			}
 		}
 
-		if err := connectHandler.connectService.AddConnect(userId, connected); err != nil {
+		if err := connectHandler.connectService.AddConnect(user, connected); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -70,7 +75,8 @@ func (connectHandler *ConnectHandler) AddConnect() gin.HandlerFunc {
 // @Router /delConnect/{id} [delete]
 func (connectHandler *ConnectHandler) DeleteConnect() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		userId := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		// 从 URL 参数获取 ID
 		idStr := ctx.Param("id")
@@ -81,7 +87,7 @@ func (connectHandler *ConnectHandler) DeleteConnect() gin.HandlerFunc {
 			}
 		}
 
-		if err := connectHandler.connectService.DeleteConnect(userId, uint(id)); err != nil {
+		if err := connectHandler.connectService.DeleteConnect(user, uint(id)); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -97,11 +103,11 @@ func (connectHandler *ConnectHandler) DeleteConnect() gin.HandlerFunc {
 // GetConnectsInfo 获取所有添加的连接的信息
 //
 // @Summary 获取所有添加的连接信息
-// @Description 获取当前用户所有已添加的连接的详细信息
+// @Description 获取已建立连接的实例信息，数据来自后台定时健康检查的缓存，离线的实例不会返回
 // @Tags 连接管理
 // @Accept json
 // @Produce json
-// @Success 200 {object} res.Response{data=[]model.Connected} "获取连接信息成功"
+// @Success 200 {object} res.Response{data=[]model.Connect} "获取连接信息成功"
 // @Failure 200 {object} res.Response "获取连接信息失败"
 // @Router /connects/info [get]
 func (connectHandler *ConnectHandler) GetConnectsInfo() gin.HandlerFunc {
@@ -176,3 +182,487 @@ func (connectHandler *ConnectHandler) GetConnects() gin.HandlerFunc {
 		}
 	})
 }
+
+// GetTimeline 获取聚合时间线
+//
+// @Summary 获取聚合时间线
+// @Description 分页获取从已连接实例定时拉取的公开 Echo，按发布时间倒序排列，可按来源实例过滤
+// @Tags 连接管理
+// @Produce json
+// @Param page query int false "页码"
+// @Param pageSize query int false "每页大小"
+// @Param source query string false "来源实例的连接地址"
+// @Success 200 {object} res.Response{data=commonModel.PageQueryResult[[]model.TimelineEcho]} "获取时间线成功"
+// @Failure 200 {object} res.Response "获取时间线失败"
+// @Router /connects/timeline [get]
+func (connectHandler *ConnectHandler) GetTimeline() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var timelineQueryDto model.TimelineQueryDto
+		if err := ctx.ShouldBindQuery(&timelineQueryDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		timeline, err := connectHandler.connectService.GetTimeline(timelineQueryDto)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: timeline,
+			Msg:  commonModel.GET_TIMELINE_SUCCESS,
+		}
+	})
+}
+
+// AcceptConnect 接受连接请求
+//
+// @Summary 接受连接请求
+// @Description 管理员接受其它实例发起的连接请求，接受后会通知对方实例
+// @Tags 连接管理
+// @Produce json
+// @Param id path int true "连接ID"
+// @Success 200 {object} res.Response "已接受连接请求"
+// @Failure 200 {object} res.Response "接受连接请求失败"
+// @Router /connects/{id}/accept [put]
+func (connectHandler *ConnectHandler) AcceptConnect() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+
+		if err := connectHandler.connectService.AcceptConnect(user, uint(id)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.ACCEPT_CONNECT_SUCCESS,
+		}
+	})
+}
+
+// RejectConnect 拒绝连接请求
+//
+// @Summary 拒绝连接请求
+// @Description 管理员拒绝其它实例发起的连接请求，并尽力通知对方实例
+// @Tags 连接管理
+// @Produce json
+// @Param id path int true "连接ID"
+// @Success 200 {object} res.Response "已拒绝连接请求"
+// @Failure 200 {object} res.Response "拒绝连接请求失败"
+// @Router /connects/{id}/reject [put]
+func (connectHandler *ConnectHandler) RejectConnect() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+
+		if err := connectHandler.connectService.RejectConnect(user, uint(id)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.REJECT_CONNECT_SUCCESS,
+		}
+	})
+}
+
+// GetAllConnects 获取所有状态的连接
+//
+// @Summary 获取所有状态的连接
+// @Description 管理员获取所有连接，包括等待确认、已拒绝和已撤销的连接
+// @Tags 连接管理
+// @Produce json
+// @Success 200 {object} res.Response{data=[]model.Connected} "获取连接列表成功"
+// @Failure 200 {object} res.Response "获取连接列表失败"
+// @Router /connects [get]
+func (connectHandler *ConnectHandler) GetAllConnects() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		connects, err := connectHandler.connectService.GetAllConnects(user)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: connects,
+			Msg:  commonModel.GET_CONNECTED_LIST_SUCCESS,
+		}
+	})
+}
+
+// Handshake 处理其它实例发来的握手消息
+//
+// @Summary 连接握手
+// @Description 实例之间交换公钥、确认、拒绝或撤销连接，消息体需使用发送方的 Ed25519 私钥签名并放在 X-Ech0-Signature 请求头中
+// @Tags 连接管理
+// @Accept json
+// @Produce json
+// @Param X-Ech0-Signature header string true "消息体的 Base64 签名"
+// @Param message body model.HandshakeMessage true "握手消息"
+// @Success 200 {object} res.Response{data=model.HandshakeResult} "握手成功"
+// @Failure 200 {object} res.Response "握手失败"
+// @Router /connect/handshake [post]
+func (connectHandler *ConnectHandler) Handshake() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 签名针对原始消息体，需要先读取原始内容
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, model.MaxHandshakeBodySize))
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		signature := ctx.GetHeader(model.SignatureHeader)
+		if signature == "" {
+			return res.Response{
+				Msg: commonModel.INVALID_CONNECT_SIGN,
+				Err: errors.New(commonModel.INVALID_CONNECT_SIGN),
+			}
+		}
+
+		result, err := connectHandler.connectService.HandleHandshake(body, signature)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: result,
+			Msg:  commonModel.HANDSHAKE_SUCCESS,
+		}
+	})
+}
+
+// GetConnectsHealth 获取所有连接的健康状态
+//
+// @Summary 获取连接健康状态
+// @Description 管理员获取后台健康检查缓存的连接状态，包括最近在线时间、响应耗时、最近错误和连续失败次数
+// @Tags 连接管理
+// @Produce json
+// @Success 200 {object} res.Response{data=[]model.ConnectHealth} "获取连接状态成功"
+// @Failure 200 {object} res.Response "获取连接状态失败"
+// @Router /connects/health [get]
+func (connectHandler *ConnectHandler) GetConnectsHealth() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		healths, err := connectHandler.connectService.GetConnectsHealth(user)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: healths,
+			Msg:  commonModel.GET_CONNECTS_HEALTH,
+		}
+	})
+}
+
+// RefreshConnectsHealth 立即刷新所有连接的健康状态
+//
+// @Summary 刷新连接健康状态
+// @Description 管理员手动触发一次连接健康检查，检查完成后返回最新的连接状态
+// @Tags 连接管理
+// @Produce json
+// @Success 200 {object} res.Response{data=[]model.ConnectHealth} "刷新连接状态成功"
+// @Failure 200 {object} res.Response "刷新连接状态失败"
+// @Router /connects/health/refresh [post]
+func (connectHandler *ConnectHandler) RefreshConnectsHealth() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		healths, err := connectHandler.connectService.RefreshConnectsHealth(user)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: healths,
+			Msg:  commonModel.REFRESH_CONNECTS_HEALTH,
+		}
+	})
+}
+
+// GetPeers 提供当前实例机器可读的连接列表
+//
+// @Summary 获取实例连接列表
+// @Description 公开当前实例已建立的连接，供其它实例发现新的实例；关闭发现时 discoverable 为 false 且不返回连接
+// @Tags 连接管理
+// @Produce json
+// @Success 200 {object} res.Response{data=model.PeerList} "获取实例连接列表成功"
+// @Failure 200 {object} res.Response "获取实例连接列表失败"
+// @Router /connect/peers [get]
+func (connectHandler *ConnectHandler) GetPeers() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		peerList, err := connectHandler.connectService.GetPeers()
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: peerList,
+			Msg:  commonModel.GET_PEERS_SUCCESS,
+		}
+	})
+}
+
+// GetSuggestions 获取推荐实例
+//
+// @Summary 获取推荐实例
+// @Description 管理员获取通过爬取连接的连接发现的推荐实例，默认只返回等待处理的推荐
+// @Tags 连接管理
+// @Produce json
+// @Param status query string false "推荐状态（new、dismissed）"
+// @Success 200 {object} res.Response{data=[]model.PeerSuggestion} "获取推荐实例成功"
+// @Failure 200 {object} res.Response "获取推荐实例失败"
+// @Router /connects/suggestions [get]
+func (connectHandler *ConnectHandler) GetSuggestions() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		status := model.SuggestionStatus(ctx.Query("status"))
+		suggestions, err := connectHandler.connectService.GetSuggestions(user, status)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: suggestions,
+			Msg:  commonModel.GET_SUGGESTIONS_SUCCESS,
+		}
+	})
+}
+
+// AcceptSuggestion 向推荐实例发起连接
+//
+// @Summary 接受推荐实例
+// @Description 管理员向推荐实例发起连接握手，发起成功后移除该推荐
+// @Tags 连接管理
+// @Produce json
+// @Param id path int true "推荐实例ID"
+// @Success 200 {object} res.Response "已向推荐实例发起连接"
+// @Failure 200 {object} res.Response "接受推荐实例失败"
+// @Router /connects/suggestions/{id}/accept [put]
+func (connectHandler *ConnectHandler) AcceptSuggestion() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+
+		if err := connectHandler.connectService.AcceptSuggestion(user, uint(id)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.ACCEPT_SUGGESTION_SUCCESS,
+		}
+	})
+}
+
+// DismissSuggestion 忽略推荐实例
+//
+// @Summary 忽略推荐实例
+// @Description 管理员忽略推荐实例，之后再次发现时不会重新推荐
+// @Tags 连接管理
+// @Produce json
+// @Param id path int true "推荐实例ID"
+// @Success 200 {object} res.Response "已忽略推荐实例"
+// @Failure 200 {object} res.Response "忽略推荐实例失败"
+// @Router /connects/suggestions/{id}/dismiss [put]
+func (connectHandler *ConnectHandler) DismissSuggestion() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+
+		if err := connectHandler.connectService.DismissSuggestion(user, uint(id)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.DISMISS_SUGGESTION_SUCCESS,
+		}
+	})
+}
+
+// DiscoverPeers 立即发现实例
+//
+// @Summary 发现实例
+// @Description 管理员手动触发一次后台发现，从已建立的连接出发爬取连接的连接（有深度和频率限制）
+// @Tags 连接管理
+// @Produce json
+// @Success 200 {object} res.Response "已开始发现实例"
+// @Failure 200 {object} res.Response "发现实例失败"
+// @Router /connects/discover [post]
+func (connectHandler *ConnectHandler) DiscoverPeers() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		if err := connectHandler.connectService.DiscoverPeers(user); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.DISCOVER_PEERS_SUCCESS,
+		}
+	})
+}
+
+// ExportOPML 将连接导出为 OPML
+//
+// @Summary 导出连接
+// @Description 管理员将已建立和等待确认的连接（包含订阅源地址）导出为 OPML 文件
+// @Tags 连接管理
+// @Produce text/x-opml
+// @Success 200 {string} string "OPML 文件"
+// @Failure 200 {object} res.Response "导出连接失败"
+// @Router /connects/opml [get]
+func (connectHandler *ConnectHandler) ExportOPML() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		body, err := connectHandler.connectService.ExportOPML(user)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		ctx.Header("Content-Disposition", "attachment; filename="+model.OPMLFileName)
+		ctx.Data(http.StatusOK, model.OPMLContentType, body)
+		return res.Response{}
+	})
+}
+
+// ImportOPML 从 OPML 导入连接
+//
+// @Summary 导入连接
+// @Description 管理员上传 OPML 文件导入连接，每个实例都会先校验 /api/connect 再发起握手，返回已创建、重复和失败的实例
+// @Tags 连接管理
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "OPML 文件"
+// @Success 200 {object} res.Response{data=model.ImportResult} "导入连接完成"
+// @Failure 200 {object} res.Response "导入连接失败"
+// @Router /connects/opml [post]
+func (connectHandler *ConnectHandler) ImportOPML() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		file, err := ctx.FormFile("file")
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+		if file.Size > model.MaxOPMLSize {
+			return res.Response{
+				Msg: "",
+				Err: errors.New(commonModel.INVALID_OPML),
+			}
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+		defer src.Close()
+
+		data, err := io.ReadAll(io.LimitReader(src, model.MaxOPMLSize))
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		result, err := connectHandler.connectService.ImportOPML(user, data)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: result,
+			Msg:  commonModel.IMPORT_OPML_SUCCESS,
+		}
+	})
+}
@@ -9,6 +9,18 @@ type ConnectHandlerInterface interface {
 	// DeleteConnect 删除连接
 	DeleteConnect() gin.HandlerFunc
 
+	// AcceptConnect 接受连接请求
+	AcceptConnect() gin.HandlerFunc
+
+	// RejectConnect 拒绝连接请求
+	RejectConnect() gin.HandlerFunc
+
+	// GetAllConnects 获取所有状态的连接
+	GetAllConnects() gin.HandlerFunc
+
+	// Handshake 处理其它实例发来的握手消息
+	Handshake() gin.HandlerFunc
+
 	// GetConnectsInfo 获取所有添加的连接的信息
 	GetConnectsInfo() gin.HandlerFunc
 
@@ -17,4 +29,34 @@ type ConnectHandlerInterface interface {
 
 	// GetConnects 获取当前实例添加的所有连接
 	GetConnects() gin.HandlerFunc
+
+	// GetConnectsHealth 获取所有连接的健康状态
+	GetConnectsHealth() gin.HandlerFunc
+
+	// RefreshConnectsHealth 立即刷新所有连接的健康状态
+	RefreshConnectsHealth() gin.HandlerFunc
+
+	// GetPeers 提供当前实例机器可读的连接列表
+	GetPeers() gin.HandlerFunc
+
+	// GetSuggestions 获取推荐实例
+	GetSuggestions() gin.HandlerFunc
+
+	// AcceptSuggestion 向推荐实例发起连接
+	AcceptSuggestion() gin.HandlerFunc
+
+	// DismissSuggestion 忽略推荐实例
+	DismissSuggestion() gin.HandlerFunc
+
+	// DiscoverPeers 立即发现实例
+	DiscoverPeers() gin.HandlerFunc
+
+	// ExportOPML 将连接导出为 OPML
+	ExportOPML(ctx *gin.Context)
+
+	// ImportOPML 从 OPML 导入连接
+	ImportOPML() gin.HandlerFunc
+
+	// GetTimeline 获取聚合时间线
+	GetTimeline() gin.HandlerFunc
 }
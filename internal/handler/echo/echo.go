@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	res "github.com/lin-snow/ech0/internal/handler/response"
+	"github.com/lin-snow/ech0/internal/middleware"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/echo"
 	service "github.com/lin-snow/ech0/internal/service/echo"
@@ -43,8 +44,9 @@ func (echoHandler *EchoHandler) PostEcho() gin.HandlerFunc {
 			}
 		}
 
-		userId := ctx.MustGet("userid").(uint)
-		if err := echoHandler.echoService.PostEcho(userId, &newEcho); err != nil {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+		if err := echoHandler.echoService.PostEcho(user, &newEcho); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -106,9 +108,49 @@ func (echoHandler *EchoHandler) GetEchosByPage() gin.HandlerFunc {
 			}
 		}
 
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
-		result, err := echoHandler.echoService.GetEchosByPage(userid, pageRequest)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+		result, err := echoHandler.echoService.GetEchosByPage(user, pageRequest)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: result,
+			Msg:  commonModel.GET_ECHOS_BY_PAGE_SUCCESS,
+		}
+	})
+}
+
+// GetUserEchos 获取指定用户发布的Echo列表
+//
+// @Summary 获取用户的Echo列表（分页）
+// @Description 获取指定用户发布的Echo，支持分页和搜索，本人和管理员可以看到私密Echo
+// @Tags Echo
+// @Produce json
+// @Param username path string true "用户名"
+// @Param page query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param search query string false "搜索关键字"
+// @Success 200 {object} res.Response{data=object} "获取成功"
+// @Failure 200 {object} res.Response "用户不存在或获取失败"
+// @Router /users/{username}/echos [get]
+func (echoHandler *EchoHandler) GetUserEchos() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var pageRequest commonModel.PageQueryDto
+		if err := ctx.ShouldBindQuery(&pageRequest); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		// 获取当前用户（可能未登录）
+		user := middleware.CurrentUser(ctx)
+		result, err := echoHandler.echoService.GetUserEchos(user, ctx.Param("username"), pageRequest)
 		if err != nil {
 			return res.Response{
 				Msg: "",
@@ -136,8 +178,8 @@ func (echoHandler *EchoHandler) GetEchosByPage() gin.HandlerFunc {
 // @Router /echo/{id} [delete]
 func (echoHandler *EchoHandler) DeleteEcho() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		// 从 URL 参数获取Echo ID
 		idStr := ctx.Param("id")
@@ -148,7 +190,7 @@ func (echoHandler *EchoHandler) DeleteEcho() gin.HandlerFunc {
 			}
 		}
 
-		if err := echoHandler.echoService.DeleteEchoById(userid, uint(id)); err != nil {
+		if err := echoHandler.echoService.DeleteEchoById(user, uint(id)); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -173,9 +215,9 @@ func (echoHandler *EchoHandler) DeleteEcho() gin.HandlerFunc {
 // @Router /echo/today [get]
 func (echoHandler *EchoHandler) GetTodayEchos() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
-		result, err := echoHandler.echoService.GetTodayEchos(userid)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+		result, err := echoHandler.echoService.GetTodayEchos(user)
 		if err != nil {
 			return res.Response{
 				Msg: "",
@@ -211,8 +253,9 @@ func (echoHandler *EchoHandler) UpdateEcho() gin.HandlerFunc {
 			}
 		}
 
-		userId := ctx.MustGet("userid").(uint)
-		if err := echoHandler.echoService.UpdateEcho(userId, &updateEcho); err != nil {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+		if err := echoHandler.echoService.UpdateEcho(user, &updateEcho); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -282,9 +325,10 @@ func (echoHandler *EchoHandler) GetEchoById() gin.HandlerFunc {
 			}
 		}
 
-		userId := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
-		echo, err := echoHandler.echoService.GetEchoById(userId, uint(id))
+		echo, err := echoHandler.echoService.GetEchoById(user, uint(id))
 		if err != nil {
 			return res.Response{
 				Msg: "",
@@ -298,3 +342,203 @@ func (echoHandler *EchoHandler) GetEchoById() gin.HandlerFunc {
 		}
 	})
 }
+
+// GetArchive 获取Echo归档统计
+//
+// @Summary 获取Echo归档
+// @Description 获取按年月分组的Echo数量，未登录或非管理员时不统计私密Echo
+// @Tags Echo
+// @Accept json
+// @Produce json
+// @Success 200 {object} res.Response{data=[]model.ArchiveMonth} "获取成功"
+// @Failure 200 {object} res.Response "获取失败"
+// @Router /echo/archive [get]
+func (echoHandler *EchoHandler) GetArchive() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		archive, err := echoHandler.echoService.GetArchive(user)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: archive,
+			Msg:  commonModel.GET_ECHO_ARCHIVE_SUCCESS,
+		}
+	})
+}
+
+// GetEchosByMonth 获取指定年月的Echo列表
+//
+// @Summary 获取指定年月的Echo列表（分页）
+// @Description 根据年份和月份获取该月发布的Echo列表，支持分页
+// @Tags Echo
+// @Accept json
+// @Produce json
+// @Param year path int true "年份"
+// @Param month path int true "月份"
+// @Param page query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Success 200 {object} res.Response{data=object} "获取成功"
+// @Failure 200 {object} res.Response "获取失败"
+// @Router /echo/archive/{year}/{month} [get]
+func (echoHandler *EchoHandler) GetEchosByMonth() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 从 URL 参数获取年份和月份
+		year, err := strconv.Atoi(ctx.Param("year"))
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+		month, err := strconv.Atoi(ctx.Param("month"))
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+
+		var pageRequest commonModel.PageQueryDto
+		if err := ctx.ShouldBindQuery(&pageRequest); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+		result, err := echoHandler.echoService.GetEchosByMonth(user, year, month, pageRequest)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: result,
+			Msg:  commonModel.GET_ECHOS_BY_MONTH_SUCCESS,
+		}
+	})
+}
+
+// GetOnThisDayEchos 获取那年今日的Echo列表
+//
+// @Summary 获取那年今日
+// @Description 获取往年同一天发布的Echo列表，按指定时区计算日期
+// @Tags Echo
+// @Accept json
+// @Produce json
+// @Param timezone query string false "IANA 时区名称，默认使用站点设置的时区"
+// @Success 200 {object} res.Response{data=[]model.Echo} "获取成功"
+// @Failure 200 {object} res.Response "获取失败"
+// @Router /echo/onthisday [get]
+func (echoHandler *EchoHandler) GetOnThisDayEchos() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var digestQuery model.DigestQueryDto
+		if err := ctx.ShouldBindQuery(&digestQuery); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+		echos, err := echoHandler.echoService.GetOnThisDayEchos(user, digestQuery.Timezone)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: echos,
+			Msg:  commonModel.GET_ON_THIS_DAY_SUCCESS,
+		}
+	})
+}
+
+// GetDailyDigest 获取每日摘要
+//
+// @Summary 获取每日摘要
+// @Description 获取当天发布的Echo，可选附带往年同一天发布的Echo
+// @Tags Echo
+// @Accept json
+// @Produce json
+// @Param timezone query string false "IANA 时区名称，默认使用站点设置的时区"
+// @Param memories query bool false "是否包含那年今日"
+// @Success 200 {object} res.Response{data=model.DailyDigest} "获取成功"
+// @Failure 200 {object} res.Response "获取失败"
+// @Router /echo/digest [get]
+func (echoHandler *EchoHandler) GetDailyDigest() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var digestQuery model.DigestQueryDto
+		if err := ctx.ShouldBindQuery(&digestQuery); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+		digest, err := echoHandler.echoService.GetDailyDigest(user, digestQuery)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: digest,
+			Msg:  commonModel.GET_DAILY_DIGEST_SUCCESS,
+		}
+	})
+}
+
+// GetEchoStats 获取发布统计
+//
+// @Summary 获取发布统计
+// @Description 获取发布统计数据，包括每周/每月发布数量、连续发布天数、按小时和星期的分布、扩展类型分布、图片数量、平均内容长度和常用标签。未登录或非管理员时不统计私密Echo
+// @Tags Echo
+// @Accept json
+// @Produce json
+// @Param timezone query string false "IANA 时区名称，默认使用站点设置的时区"
+// @Success 200 {object} res.Response{data=model.EchoStats} "获取成功"
+// @Failure 200 {object} res.Response "获取失败"
+// @Router /stats [get]
+func (echoHandler *EchoHandler) GetEchoStats() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var statsQuery model.StatsQueryDto
+		if err := ctx.ShouldBindQuery(&statsQuery); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+		stats, err := echoHandler.echoService.GetEchoStats(user, statsQuery)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: stats,
+			Msg:  commonModel.GET_ECHO_STATS_SUCCESS,
+		}
+	})
+}
@@ -9,6 +9,9 @@ type EchoHandlerInterface interface {
 	// GetEchosByPage 获取 Echo 列表，支持分页
 	GetEchosByPage() gin.HandlerFunc
 
+	// GetUserEchos 获取指定用户发布的 Echo 列表
+	GetUserEchos() gin.HandlerFunc
+
 	// DeleteEcho 删除 Echo
 	DeleteEcho() gin.HandlerFunc
 
@@ -23,4 +26,19 @@ type EchoHandlerInterface interface {
 
 	// GetEchoById 获取指定 ID 的 Echo
 	GetEchoById() gin.HandlerFunc
+
+	// GetArchive 获取 Echo 归档统计
+	GetArchive() gin.HandlerFunc
+
+	// GetEchosByMonth 获取指定年月的 Echo 列表
+	GetEchosByMonth() gin.HandlerFunc
+
+	// GetOnThisDayEchos 获取那年今日的 Echo 列表
+	GetOnThisDayEchos() gin.HandlerFunc
+
+	// GetDailyDigest 获取每日摘要
+	GetDailyDigest() gin.HandlerFunc
+
+	// GetEchoStats 获取发布统计
+	GetEchoStats() gin.HandlerFunc
 }
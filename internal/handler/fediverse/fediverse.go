@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	service "github.com/lin-snow/ech0/internal/service/fediverse"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+)
+
+type FediverseHandler struct {
+	fediverseService service.FediverseServiceInterface
+}
+
+// NewFediverseHandler FediverseHandler 的构造函数
+func NewFediverseHandler(fediverseService service.FediverseServiceInterface) *FediverseHandler {
+	return &FediverseHandler{
+		fediverseService: fediverseService,
+	}
+}
+
+// GetWebFinger 获取 WebFinger 信息
+//
+// @Summary 获取 WebFinger 信息
+// @Description 根据 acct 资源获取实例管理员的 Actor 地址，用于联邦宇宙中的账号发现
+// @Tags 联邦宇宙
+// @Produce application/jrd+json
+// @Param resource query string true "acct:用户名@域名"
+// @Success 200 {object} model.WebFinger "WebFinger 信息"
+// @Failure 400 {object} map[string]string "无效的资源"
+// @Failure 404 {object} map[string]string "用户不存在"
+// @Router /.well-known/webfinger [get]
+func (fediverseHandler *FediverseHandler) GetWebFinger(ctx *gin.Context) {
+	webFinger, err := fediverseHandler.fediverseService.GetWebFinger(ctx.Query("resource"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, model.JRDContentType, webFinger)
+}
+
+// GetActor 获取 Actor 文档
+//
+// @Summary 获取 Actor 文档
+// @Description 获取实例管理员的 ActivityPub Actor 文档（包含公钥）
+// @Tags 联邦宇宙
+// @Produce application/activity+json
+// @Param username path string true "用户名"
+// @Success 200 {object} model.Actor "Actor 文档"
+// @Failure 404 {object} map[string]string "用户不存在"
+// @Router /ap/users/{username} [get]
+func (fediverseHandler *FediverseHandler) GetActor(ctx *gin.Context) {
+	actor, err := fediverseHandler.fediverseService.GetActor(ctx.Param("username"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, model.ActivityContentType, actor)
+}
+
+// GetOutbox 获取发件箱
+//
+// @Summary 获取发件箱
+// @Description 获取公开 Echo 的 Create 活动，不带 page 参数时返回集合信息
+// @Tags 联邦宇宙
+// @Produce application/activity+json
+// @Param username path string true "用户名"
+// @Param page query int false "页码"
+// @Success 200 {object} model.OrderedCollectionPage "发件箱"
+// @Failure 404 {object} map[string]string "用户不存在"
+// @Router /ap/users/{username}/outbox [get]
+func (fediverseHandler *FediverseHandler) GetOutbox(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.Query("page"))
+	outbox, err := fediverseHandler.fediverseService.GetOutbox(ctx.Param("username"), page)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, model.ActivityContentType, outbox)
+}
+
+// GetFollowers 获取关注者集合
+//
+// @Summary 获取关注者集合
+// @Description 获取关注者数量（不公开关注者列表）
+// @Tags 联邦宇宙
+// @Produce application/activity+json
+// @Param username path string true "用户名"
+// @Success 200 {object} model.OrderedCollection "关注者集合"
+// @Failure 404 {object} map[string]string "用户不存在"
+// @Router /ap/users/{username}/followers [get]
+func (fediverseHandler *FediverseHandler) GetFollowers(ctx *gin.Context) {
+	followers, err := fediverseHandler.fediverseService.GetFollowers(ctx.Param("username"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, model.ActivityContentType, followers)
+}
+
+// GetNote 获取 Echo 对应的 Note 对象
+//
+// @Summary 获取 Note 对象
+// @Description 获取公开 Echo 对应的 ActivityPub Note 对象
+// @Tags 联邦宇宙
+// @Produce application/activity+json
+// @Param id path int true "Echo ID"
+// @Success 200 {object} model.Note "Note 对象"
+// @Failure 404 {object} map[string]string "找不到Echo"
+// @Router /ap/echos/{id} [get]
+func (fediverseHandler *FediverseHandler) GetNote(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		writeError(ctx, errors.New(commonModel.ECHO_NOT_FOUND))
+		return
+	}
+
+	note, err := fediverseHandler.fediverseService.GetNote(uint(id))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, model.ActivityContentType, note)
+}
+
+// PostInbox 接收其它实例投递的活动
+//
+// @Summary 收件箱
+// @Description 接收其它实例投递的活动（需要 HTTP 签名），目前处理 Follow 与 Undo(Follow)
+// @Tags 联邦宇宙
+// @Accept application/activity+json
+// @Param username path string true "用户名"
+// @Success 202 "活动已接收"
+// @Failure 400 {object} map[string]string "无效的活动"
+// @Failure 401 {object} map[string]string "HTTP 签名校验失败"
+// @Router /ap/users/{username}/inbox [post]
+func (fediverseHandler *FediverseHandler) PostInbox(ctx *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, model.MaxInboxBodySize))
+	if err != nil {
+		writeError(ctx, errors.New(commonModel.INVALID_ACTIVITY))
+		return
+	}
+
+	if err := fediverseHandler.fediverseService.HandleInbox(ctx.Param("username"), ctx.Request, body); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusAccepted)
+}
+
+// writeJSON 以指定的内容类型输出 JSON
+func writeJSON(ctx *gin.Context, status int, contentType string, data any) {
+	body, err := jsonUtil.JSONMarshal(data)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.Data(status, contentType+"; charset=utf-8", body)
+}
+
+// writeError 根据错误类型输出对应的 HTTP 状态码（联邦协议依赖状态码而非响应体）
+func writeError(ctx *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch err.Error() {
+	case commonModel.USER_NOTFOUND, commonModel.ECHO_NOT_FOUND:
+		status = http.StatusNotFound
+	case commonModel.INVALID_WEBFINGER_RESOURCE, commonModel.INVALID_ACTIVITY:
+		status = http.StatusBadRequest
+	case commonModel.INVALID_HTTP_SIGNATURE:
+		status = http.StatusUnauthorized
+	case commonModel.SERVER_URL_NOT_SET:
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,23 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type FediverseHandlerInterface interface {
+	// GetWebFinger 获取 WebFinger 信息
+	GetWebFinger(ctx *gin.Context)
+
+	// GetActor 获取 Actor 文档
+	GetActor(ctx *gin.Context)
+
+	// GetOutbox 获取发件箱
+	GetOutbox(ctx *gin.Context)
+
+	// GetFollowers 获取关注者集合
+	GetFollowers(ctx *gin.Context)
+
+	// GetNote 获取 Echo 对应的 Note 对象
+	GetNote(ctx *gin.Context)
+
+	// PostInbox 接收其它实例投递的活动
+	PostInbox(ctx *gin.Context)
+}
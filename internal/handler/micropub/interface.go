@@ -0,0 +1,14 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type MicropubHandlerInterface interface {
+	// Query 处理 Micropub 查询（q=config、q=source、q=syndicate-to）
+	Query(ctx *gin.Context)
+
+	// Post 处理 Micropub 的创建、更新、删除请求
+	Post(ctx *gin.Context)
+
+	// UploadMedia 上传媒体文件
+	UploadMedia(ctx *gin.Context)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lin-snow/ech0/internal/middleware"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/micropub"
+	service "github.com/lin-snow/ech0/internal/service/micropub"
+)
+
+type MicropubHandler struct {
+	micropubService service.MicropubServiceInterface
+}
+
+// NewMicropubHandler MicropubHandler 的构造函数
+func NewMicropubHandler(micropubService service.MicropubServiceInterface) *MicropubHandler {
+	return &MicropubHandler{
+		micropubService: micropubService,
+	}
+}
+
+// Query 处理 Micropub 查询
+//
+// @Summary Micropub 查询
+// @Description 支持 q=config（端点配置）、q=source（Echo 的 Micropub 属性）和 q=syndicate-to
+// @Tags Micropub
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "查询类型（config、source、syndicate-to）"
+// @Param url query string false "q=source 时 Echo 的地址"
+// @Param properties[] query []string false "q=source 时需要返回的属性"
+// @Success 200 {object} model.Config "查询成功"
+// @Failure 400 {object} model.Error "查询失败"
+// @Failure 401 {object} model.Error "未授权"
+// @Router /micropub [get]
+func (micropubHandler *MicropubHandler) Query(ctx *gin.Context) {
+	// 获取当前用户
+	user := middleware.CurrentUser(ctx)
+
+	switch ctx.Query("q") {
+	case model.QueryConfig:
+		ctx.JSON(http.StatusOK, micropubHandler.micropubService.GetConfig())
+	case model.QuerySyndicateTo:
+		ctx.JSON(http.StatusOK, gin.H{"syndicate-to": []any{}})
+	case model.QuerySource:
+		properties := ctx.QueryArray("properties[]")
+		if len(properties) == 0 {
+			properties = ctx.QueryArray("properties")
+		}
+		source, err := micropubHandler.micropubService.GetSource(user, ctx.Query("url"), properties)
+		if err != nil {
+			writeError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, source)
+	default:
+		ctx.JSON(http.StatusBadRequest, model.Error{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: commonModel.INVALID_MICROPUB_QUERY,
+		})
+	}
+}
+
+// Post 处理 Micropub 的创建、更新、删除请求
+//
+// @Summary Micropub 发布
+// @Description 支持表单（x-www-form-urlencoded、multipart/form-data）和 JSON 请求，创建成功返回 201 和 Location，更新、删除成功返回 204
+// @Tags Micropub
+// @Accept json,x-www-form-urlencoded,mpfd
+// @Produce json
+// @Security BearerAuth
+// @Success 201 "创建成功"
+// @Success 204 "更新或删除成功"
+// @Failure 400 {object} model.Error "请求无效"
+// @Failure 401 {object} model.Error "未授权"
+// @Failure 403 {object} model.Error "没有权限"
+// @Router /micropub [post]
+func (micropubHandler *MicropubHandler) Post(ctx *gin.Context) {
+	// 获取当前用户
+	user := middleware.CurrentUser(ctx)
+
+	request, photos, err := parseRequest(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.Error{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: commonModel.INVALID_REQUEST_BODY,
+		})
+		return
+	}
+
+	location, err := micropubHandler.micropubService.Post(user, request, photos)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	if location == "" {
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+	ctx.Header("Location", location)
+	ctx.Status(http.StatusCreated)
+}
+
+// UploadMedia 上传媒体文件
+//
+// @Summary Micropub 媒体端点
+// @Description 使用与图片上传相同的存储方式保存文件，成功后返回 201 和文件地址
+// @Tags Micropub
+// @Accept mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "媒体文件"
+// @Success 201 "上传成功"
+// @Failure 400 {object} model.Error "上传失败"
+// @Failure 401 {object} model.Error "未授权"
+// @Router /micropub/media [post]
+func (micropubHandler *MicropubHandler) UploadMedia(ctx *gin.Context) {
+	// 获取当前用户
+	user := middleware.CurrentUser(ctx)
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.Error{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: commonModel.NO_FILE_UPLOAD_ERROR,
+		})
+		return
+	}
+
+	location, err := micropubHandler.micropubService.UploadMedia(user, file)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.Header("Location", location)
+	ctx.Status(http.StatusCreated)
+}
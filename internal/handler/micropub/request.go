@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/micropub"
+)
+
+// reservedFormKeys 表单中不属于 Echo 属性的字段
+var reservedFormKeys = map[string]struct{}{
+	"h":            {},
+	"action":       {},
+	"url":          {},
+	"access_token": {},
+}
+
+// parseRequest 将表单或 JSON 请求转换为统一的 Micropub 请求，并取出表单中直接上传的图片
+func parseRequest(ctx *gin.Context) (model.Request, []*multipart.FileHeader, error) {
+	var request model.Request
+	if ctx.ContentType() == gin.MIMEJSON {
+		err := ctx.ShouldBindJSON(&request)
+		return request, nil, err
+	}
+
+	var photos []*multipart.FileHeader
+	if ctx.ContentType() == gin.MIMEMultipartPOSTForm {
+		form, err := ctx.MultipartForm()
+		if err != nil {
+			return request, nil, err
+		}
+		photos = append(form.File["photo"], form.File["photo[]"]...)
+	} else if err := ctx.Request.ParseForm(); err != nil {
+		return request, nil, err
+	}
+
+	request.Action = ctx.PostForm("action")
+	request.URL = ctx.PostForm("url")
+	if h := ctx.PostForm("h"); h != "" {
+		request.Type = []string{"h-" + h}
+	}
+
+	request.Properties = make(map[string][]any)
+	for key, values := range ctx.Request.PostForm {
+		if _, ok := reservedFormKeys[key]; ok {
+			continue
+		}
+		// category[]=a&category[]=b 与 category=a&category=b 等价
+		name := strings.TrimSuffix(key, "[]")
+		for _, value := range values {
+			request.Properties[name] = append(request.Properties[name], value)
+		}
+	}
+
+	return request, photos, nil
+}
+
+// writeError 将业务错误转换为 Micropub 错误响应
+func writeError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case commonModel.NO_PERMISSION_DENIED:
+		ctx.JSON(http.StatusForbidden, model.Error{
+			Error:            model.ErrorForbidden,
+			ErrorDescription: err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusBadRequest, model.Error{
+			Error:            model.ErrorInvalidRequest,
+			ErrorDescription: err.Error(),
+		})
+	}
+}
@@ -27,9 +27,13 @@ type Response struct {
 }
 
 // Execute 包装器，自动根据 Response 返回统一格式的 HTTP 响应 (仅处理返回类型为JSON的handler)
+// handler 已经自行写入响应（跳转、文件下载等）时不再处理
 func Execute(fn func(ctx *gin.Context) Response) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		res := fn(ctx)
+		if ctx.Writer.Written() {
+			return
+		}
 		if res.Err != nil {
 			ctx.JSON(http.StatusOK, commonModel.Fail[string](
 				errorUtil.HandleError(&commonModel.ServerError{
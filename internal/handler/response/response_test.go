@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+func serve(handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	logUtil.Logger = zap.NewNop()
+	engine := gin.New()
+	engine.GET("/", handler)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w
+}
+
+// 返回的 Response 按统一格式写入 JSON
+func TestExecute_WritesResponse(t *testing.T) {
+	w := serve(Execute(func(ctx *gin.Context) Response {
+		return Response{Data: "data", Msg: "ok"}
+	}))
+	assert.JSONEq(t, `{"code":1,"msg":"ok","data":"data"}`, w.Body.String())
+
+	w = serve(Execute(func(ctx *gin.Context) Response {
+		return Response{Msg: "failed", Err: errors.New("boom")}
+	}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+}
+
+// handler 自行写入的跳转和文件不会再追加 JSON
+func TestExecute_KeepsWrittenResponse(t *testing.T) {
+	w := serve(Execute(func(ctx *gin.Context) Response {
+		ctx.Redirect(http.StatusFound, "/auth#error=denied")
+		return Response{}
+	}))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/auth#error=denied", w.Header().Get("Location"))
+	assert.NotContains(t, w.Body.String(), `"code"`)
+
+	w = serve(Execute(func(ctx *gin.Context) Response {
+		ctx.Data(http.StatusOK, "text/x-opml", []byte("<opml/>"))
+		return Response{}
+	}))
+	assert.Equal(t, "<opml/>", w.Body.String())
+}
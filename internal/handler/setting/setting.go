@@ -3,6 +3,7 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	res "github.com/lin-snow/ech0/internal/handler/response"
+	"github.com/lin-snow/ech0/internal/middleware"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/setting"
 	service "github.com/lin-snow/ech0/internal/service/setting"
@@ -60,8 +61,8 @@ func (settingHandler *SettingHandler) GetSettings() gin.HandlerFunc {
 // @Router /settings [put]
 func (settingHandler *SettingHandler) UpdateSettings() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		// 解析请求体中的参数
 		var newSettings model.SystemSettingDto
@@ -72,7 +73,7 @@ func (settingHandler *SettingHandler) UpdateSettings() gin.HandlerFunc {
 			}
 		}
 
-		if err := settingHandler.settingService.UpdateSetting(userid, &newSettings); err != nil {
+		if err := settingHandler.settingService.UpdateSetting(user, &newSettings); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -125,8 +126,8 @@ func (settingHandler *SettingHandler) GetCommentSettings() gin.HandlerFunc {
 // @Router /comment/settings [put]
 func (settingHandler *SettingHandler) UpdateCommentSettings() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		// 解析请求体中的参数
 		var newCommentSettings model.CommentSettingDto
@@ -137,7 +138,7 @@ func (settingHandler *SettingHandler) UpdateCommentSettings() gin.HandlerFunc {
 			}
 		}
 
-		if err := settingHandler.settingService.UpdateCommentSetting(userid, &newCommentSettings); err != nil {
+		if err := settingHandler.settingService.UpdateCommentSetting(user, &newCommentSettings); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	res "github.com/lin-snow/ech0/internal/handler/response"
+	"github.com/lin-snow/ech0/internal/middleware"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/todo"
 	service "github.com/lin-snow/ech0/internal/service/todo"
@@ -32,8 +33,8 @@ func NewTodoHandler(todoService service.TodoServiceInterface) *TodoHandler {
 // @Router /todo [post]
 func (todoHandler *TodoHandler) AddTodo() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		var todo model.Todo
 		if err := ctx.ShouldBindJSON(&todo); err != nil {
@@ -43,7 +44,7 @@ func (todoHandler *TodoHandler) AddTodo() gin.HandlerFunc {
 			}
 		}
 
-		if err := todoHandler.todoService.AddTodo(userid, &todo); err != nil {
+		if err := todoHandler.todoService.AddTodo(user, &todo); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -69,8 +70,8 @@ func (todoHandler *TodoHandler) AddTodo() gin.HandlerFunc {
 // @Router /todo/{id} [put]
 func (todoHandler *TodoHandler) UpdateTodo() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		// 从 URL 参数获取Echo ID
 		idStr := ctx.Param("id")
@@ -82,7 +83,7 @@ func (todoHandler *TodoHandler) UpdateTodo() gin.HandlerFunc {
 			}
 		}
 
-		if err := todoHandler.todoService.UpdateTodo(userid, int64(id)); err != nil {
+		if err := todoHandler.todoService.UpdateTodo(user, int64(id)); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -108,8 +109,8 @@ func (todoHandler *TodoHandler) UpdateTodo() gin.HandlerFunc {
 // @Router /todo/{id} [delete]
 func (todoHandler *TodoHandler) DeleteTodo() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		// 从 URL 参数获取Echo ID
 		idStr := ctx.Param("id")
@@ -121,7 +122,7 @@ func (todoHandler *TodoHandler) DeleteTodo() gin.HandlerFunc {
 			}
 		}
 
-		if err := todoHandler.todoService.DeleteTodo(userid, int64(id)); err != nil {
+		if err := todoHandler.todoService.DeleteTodo(user, int64(id)); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -146,10 +147,10 @@ func (todoHandler *TodoHandler) DeleteTodo() gin.HandlerFunc {
 // @Router /todo [get]
 func (todoHandler *TodoHandler) GetTodoList() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
-		todos, err := todoHandler.todoService.GetTodoList(userid)
+		todos, err := todoHandler.todoService.GetTodoList(user)
 		if err != nil {
 			return res.Response{
 				Msg: "",
@@ -3,18 +3,15 @@ package handler
 import "github.com/gin-gonic/gin"
 
 type UserHandlerInterface interface {
-	// Login 用户登录
-	Login() gin.HandlerFunc
-
-	// Register 用户注册
-	Register() gin.HandlerFunc
-
 	// UpdateUser 更新用户信息
 	UpdateUser() gin.HandlerFunc
 
 	// UpdateUserAdmin 更新用户权限
 	UpdateUserAdmin() gin.HandlerFunc
 
+	// UpdateUserRole 修改用户角色
+	UpdateUserRole() gin.HandlerFunc
+
 	// GetAllUsers 获取所有用户
 	GetAllUsers() gin.HandlerFunc
 
@@ -23,4 +20,7 @@ type UserHandlerInterface interface {
 
 	// GetUserInfo 获取用户信息
 	GetUserInfo() gin.HandlerFunc
+
+	// GetUserProfile 获取用户的公开资料
+	GetUserProfile() gin.HandlerFunc
 }
@@ -5,7 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	res "github.com/lin-snow/ech0/internal/handler/response"
-	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	"github.com/lin-snow/ech0/internal/middleware"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/user"
 	service "github.com/lin-snow/ech0/internal/service/user"
@@ -22,68 +22,32 @@ func NewUserHandler(userService service.UserServiceInterface) *UserHandler {
 	}
 }
 
-// Login 用户登录
-// @Summary 用户登录接口
-// @Description 用户通过用户名和密码登录，返回 JWT Token
-// @Tags 用户认证
-// @Accept application/json
-// @Produce application/json
-// @Param login body authModel.LoginDto true "登录请求体"
-// @Success 200 {object} res.Response "登录成功，返回JWT Token"
-// @Failure 200 {object} res.Response "登录失败，返回错误信息"
-// @Router /login [post]
-func (userHandler *UserHandler) Login() gin.HandlerFunc {
-	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 从请求体获取用户名和密码
-		var loginDto authModel.LoginDto
-		if err := ctx.ShouldBindJSON(&loginDto); err != nil {
-			return res.Response{
-				Msg: commonModel.INVALID_REQUEST_BODY,
-				Err: err,
-			}
-		}
-
-		// 调用 Service 层处理登陆
-		token, err := userHandler.userService.Login(&loginDto)
-		if err != nil {
-			return res.Response{
-				Msg: "",
-				Err: err,
-			}
-		}
-
-		// 返回成功响应， 包含 JWT Token
-		return res.Response{
-			Data: token,
-			Msg:  commonModel.LOGIN_SUCCESS,
-		}
-	})
-
-}
-
-// Register 用户注册
+// UpdateUser 更新用户信息
 //
-// @Summary 用户注册
-// @Description 通过提交用户名、密码等信息完成注册
-// @Tags 用户认证
+// @Summary 更新当前用户的信息
+// @Description 接口会根据请求体更新用户相关字段，需携带有效的用户身份（如 JWT）
+// @Tags 用户管理
 // @Accept json
 // @Produce json
-// @Param register body authModel.RegisterDto true "注册请求体"
-// @Success 200 {object} res.Response "注册成功，code=1，msg=REGISTER_SUCCESS"
-// @Failure 200 {object} res.Response "请求参数错误或注册失败，code=0，msg错误描述"
-// @Router /register [post]
-func (userHandler *UserHandler) Register() gin.HandlerFunc {
+// @Param user body model.UserInfoDto true "用户更新信息"
+// @Success 200 {object} res.Response "更新成功，code=1，msg=UPDATE_USER_SUCCESS"
+// @Failure 200 {object} res.Response "请求参数错误或更新失败，code=0，msg错误描述"
+// @Security ApiKeyAuth
+// @Router /user [put]
+func (userHandler *UserHandler) UpdateUser() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		var registerDto authModel.RegisterDto
-		if err := ctx.ShouldBindJSON(&registerDto); err != nil {
+		// 解析用户请求体中的参数
+		var userdto model.UserInfoDto
+		if err := ctx.ShouldBindJSON(&userdto); err != nil {
 			return res.Response{
 				Msg: commonModel.INVALID_REQUEST_BODY,
 				Err: err,
 			}
 		}
 
-		// 调用 Service 层处理注册
-		if err := userHandler.userService.Register(&registerDto); err != nil {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+		if err := userHandler.userService.UpdateUser(user, ctx.GetString("sessionid"), userdto); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -91,38 +55,39 @@ func (userHandler *UserHandler) Register() gin.HandlerFunc {
 		}
 
 		return res.Response{
-			Msg: commonModel.REGISTER_SUCCESS,
+			Msg: commonModel.UPDATE_USER_SUCCESS,
 		}
 	})
 
 }
 
-// UpdateUser 更新用户信息
+// UpdateUserAdmin 更新用户权限
 //
-// @Summary 更新当前用户的信息
-// @Description 接口会根据请求体更新用户相关字段，需携带有效的用户身份（如 JWT）
+// @Summary 更新用户权限（管理员权限）
+// @Description 通过用户ID切换其管理员权限，取消时恢复为默认角色，接口调用者需拥有相应权限
 // @Tags 用户管理
 // @Accept json
 // @Produce json
-// @Param user body model.UserInfoDto true "用户更新信息"
+// @Param id path int true "用户ID"
 // @Success 200 {object} res.Response "更新成功，code=1，msg=UPDATE_USER_SUCCESS"
-// @Failure 200 {object} res.Response "请求参数错误或更新失败，code=0，msg错误描述"
+// @Failure 200 {object} res.Response "参数错误或更新失败，code=0，msg错误描述"
 // @Security ApiKeyAuth
-// @Router /user [put]
-func (userHandler *UserHandler) UpdateUser() gin.HandlerFunc {
+// @Router /user/admin/{id} [put]
+func (userHandler *UserHandler) UpdateUserAdmin() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 解析用户请求体中的参数
-		var userdto model.UserInfoDto
-		if err := ctx.ShouldBindJSON(&userdto); err != nil {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		idStr := ctx.Param("id")
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
 			return res.Response{
-				Msg: commonModel.INVALID_REQUEST_BODY,
+				Msg: commonModel.INVALID_PARAMS,
 				Err: err,
 			}
 		}
 
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
-		if err := userHandler.userService.UpdateUser(userid, userdto); err != nil {
+		if err := userHandler.userService.UpdateUserAdmin(user, uint(id)); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -136,25 +101,25 @@ func (userHandler *UserHandler) UpdateUser() gin.HandlerFunc {
 
 }
 
-// UpdateUserAdmin 更新用户权限
+// UpdateUserRole 修改用户角色
 //
-// @Summary 更新用户权限（管理员权限）
-// @Description 通过用户ID更新其管理员权限，接口调用者需拥有相应权限
+// @Summary 修改用户角色
+// @Description 将用户设置为 admin、author 或 reader，只能修改级别比自己低的用户，授予的角色不能高于自己的角色
 // @Tags 用户管理
 // @Accept json
 // @Produce json
 // @Param id path int true "用户ID"
-// @Success 200 {object} res.Response "更新成功，code=1，msg=UPDATE_USER_SUCCESS"
+// @Param role body model.UserRoleDto true "新的角色"
+// @Success 200 {object} res.Response "更新成功，code=1，msg=UPDATE_ROLE_SUCCESS"
 // @Failure 200 {object} res.Response "参数错误或更新失败，code=0，msg错误描述"
 // @Security ApiKeyAuth
-// @Router /user/admin/{id} [put]
-func (userHandler *UserHandler) UpdateUserAdmin() gin.HandlerFunc {
+// @Router /user/{id}/role [put]
+func (userHandler *UserHandler) UpdateUserRole() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
-		idStr := ctx.Param("id")
-		id, err := strconv.ParseUint(idStr, 10, 64)
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
 		if err != nil {
 			return res.Response{
 				Msg: commonModel.INVALID_PARAMS,
@@ -162,7 +127,15 @@ func (userHandler *UserHandler) UpdateUserAdmin() gin.HandlerFunc {
 			}
 		}
 
-		if err := userHandler.userService.UpdateUserAdmin(userid, uint(id)); err != nil {
+		var roleDto model.UserRoleDto
+		if err := ctx.ShouldBindJSON(&roleDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		if err := userHandler.userService.UpdateUserRole(user, uint(id), roleDto.Role); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -170,10 +143,9 @@ func (userHandler *UserHandler) UpdateUserAdmin() gin.HandlerFunc {
 		}
 
 		return res.Response{
-			Msg: commonModel.UPDATE_USER_SUCCESS,
+			Msg: commonModel.UPDATE_ROLE_SUCCESS,
 		}
 	})
-
 }
 
 // GetAllUsers 获取所有用户
@@ -219,8 +191,8 @@ func (userHandler *UserHandler) GetAllUsers() gin.HandlerFunc {
 // @Router /user/{id} [delete]
 func (userHandler *UserHandler) DeleteUser() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
-		// 获取当前用户 ID
-		userid := ctx.MustGet("userid").(uint)
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
 
 		idStr := ctx.Param("id")
 		id, err := strconv.ParseUint(idStr, 10, 64)
@@ -231,7 +203,7 @@ func (userHandler *UserHandler) DeleteUser() gin.HandlerFunc {
 			}
 		}
 
-		if err := userHandler.userService.DeleteUser(userid, uint(id)); err != nil {
+		if err := userHandler.userService.DeleteUser(user, uint(id)); err != nil {
 			return res.Response{
 				Msg: "",
 				Err: err,
@@ -279,3 +251,33 @@ func (userHandler *UserHandler) GetUserInfo() gin.HandlerFunc {
 	})
 
 }
+
+// GetUserProfile 获取用户的公开资料
+//
+// @Summary 获取用户主页
+// @Description 获取指定用户的公开资料（昵称、头像、个人简介）和发布统计，未登录时只统计公开 Echo
+// @Tags 用户管理
+// @Produce json
+// @Param username path string true "用户名"
+// @Success 200 {object} res.Response{data=model.UserProfile} "获取成功，code=1，包含用户资料"
+// @Failure 200 {object} res.Response "用户不存在，code=0，msg错误描述"
+// @Router /users/{username} [get]
+func (userHandler *UserHandler) GetUserProfile() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户（可能未登录）
+		user := middleware.CurrentUser(ctx)
+
+		profile, err := userHandler.userService.GetUserProfile(user, ctx.Param("username"))
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: profile,
+			Msg:  commonModel.GET_PROFILE_SUCCESS,
+		}
+	})
+}
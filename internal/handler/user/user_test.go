@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/cache"
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/middleware"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/user"
+	authRepository "github.com/lin-snow/ech0/internal/repository/auth"
+	userRepository "github.com/lin-snow/ech0/internal/repository/user"
+	authService "github.com/lin-snow/ech0/internal/service/auth"
+	service "github.com/lin-snow/ech0/internal/service/user"
+	"github.com/lin-snow/ech0/internal/transaction"
+	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+const testPassword = "old-password"
+
+// testServer 使用临时数据库和真实的鉴权中间件处理 PUT /user，当前用户由中间件加载
+type testServer struct {
+	engine      *gin.Engine
+	authService authService.AuthServiceInterface
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logUtil.Logger = zap.NewNop()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "user.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&model.User{},
+		&authModel.Session{},
+		&authModel.SigningKey{},
+		&authModel.TwoFactor{},
+		&authModel.RecoveryCode{},
+		&authModel.Lockout{},
+		&authModel.AccessToken{},
+	))
+
+	original := config.Config.Auth
+	config.Config.Auth.Jwt.AccessExpires = 900
+	config.Config.Auth.Jwt.RefreshExpires = 3600
+	t.Cleanup(func() { config.Config.Auth = original })
+
+	userCache, err := cache.NewCache[string, *model.User]()
+	require.NoError(t, err)
+	hash, err := cryptoUtil.HashPassword(testPassword)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&model.User{Username: "alice", Password: hash, Role: model.RoleAuthor}).Error)
+
+	tm := transaction.NewTransactionManager(db)
+	authRepo := authRepository.NewAuthRepository(db)
+	users := service.NewUserService(tm, userRepository.NewUserRepository(db, userCache), authRepo, nil)
+	auth := authService.NewAuthService(tm, authRepo, users, nil)
+
+	engine := gin.New()
+	engine.PUT("/user", middleware.JWTAuthMiddleware(auth), NewUserHandler(users).UpdateUser())
+	return &testServer{engine: engine, authService: auth}
+}
+
+// login 使用用户名和密码登录，返回访问令牌
+func (s *testServer) login(t *testing.T, password string) (string, error) {
+	t.Helper()
+
+	result, err := s.authService.Login(&authModel.LoginDto{Username: "alice", Password: password}, authModel.ClientInfo{IP: "203.0.113.7"})
+	if err != nil {
+		return "", err
+	}
+	require.NotNil(t, result.TokenPair)
+	return result.TokenPair.AccessToken, nil
+}
+
+// updateUser 携带令牌请求 PUT /user，返回响应
+func (s *testServer) updateUser(t *testing.T, token, body string) commonModel.Result[any] {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPut, "/user", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, req)
+
+	var result commonModel.Result[any]
+	require.NoError(t, jsonUtil.JSONUnmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+// 中间件加载的当前用户不含密码哈希，修改个人资料后仍然可以使用原密码登录
+func TestUpdateUser_ProfileChangeKeepsPassword(t *testing.T) {
+	server := newTestServer(t)
+	token, err := server.login(t, testPassword)
+	require.NoError(t, err)
+
+	result := server.updateUser(t, token, `{"bio":"hi"}`)
+	assert.Equal(t, commonModel.UPDATE_USER_SUCCESS, result.Message)
+
+	_, err = server.login(t, testPassword)
+	assert.NoError(t, err)
+}
+
+// 修改密码时当前密码与数据库中的哈希比较，修改后旧密码失效，当前会话保持登录
+func TestUpdateUser_ChangePassword(t *testing.T) {
+	server := newTestServer(t)
+	token, err := server.login(t, testPassword)
+	require.NoError(t, err)
+
+	result := server.updateUser(t, token, `{"password":"new-password","current_password":"wrong"}`)
+	assert.Equal(t, commonModel.CURRENT_PASSWORD_INCORRECT, result.Message)
+
+	result = server.updateUser(t, token, `{"password":"new-password","current_password":"`+testPassword+`"}`)
+	require.Equal(t, commonModel.UPDATE_USER_SUCCESS, result.Message)
+
+	_, err = server.login(t, testPassword)
+	assert.EqualError(t, err, commonModel.PASSWORD_INCORRECT)
+	_, err = server.login(t, "new-password")
+	assert.NoError(t, err)
+
+	result = server.updateUser(t, token, `{"bio":"still signed in"}`)
+	assert.Equal(t, commonModel.UPDATE_USER_SUCCESS, result.Message)
+}
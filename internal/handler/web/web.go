@@ -49,6 +49,8 @@ func (webHandler *WebHandler) Templates() gin.HandlerFunc {
 			defer func() { _ = fallback.Close() }()
 			fallbackStat, _ := fallback.Stat()
 			ctx.Header("Content-Type", "text/html; charset=utf-8")
+			// 声明 Webmention、Micropub 端点（SPA 页面中无法通过 HTML 发现）
+			ctx.Header("Link", "</webmention>; rel=\"webmention\", </micropub>; rel=\"micropub\"")
 			http.ServeContent(ctx.Writer, ctx.Request, "index.html", fallbackStat.ModTime(), fallback)
 			return
 		}
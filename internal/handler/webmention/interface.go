@@ -0,0 +1,20 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type WebmentionHandlerInterface interface {
+	// ReceiveWebmention 接收 Webmention
+	ReceiveWebmention(ctx *gin.Context)
+
+	// GetEchoSource 获取作为 source 的 Echo 页面
+	GetEchoSource(ctx *gin.Context)
+
+	// GetWebmentions 获取 Webmention 列表
+	GetWebmentions() gin.HandlerFunc
+
+	// UpdateWebmentionStatus 审核 Webmention
+	UpdateWebmentionStatus() gin.HandlerFunc
+
+	// DeleteWebmention 删除 Webmention
+	DeleteWebmention() gin.HandlerFunc
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	res "github.com/lin-snow/ech0/internal/handler/response"
+	"github.com/lin-snow/ech0/internal/middleware"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/webmention"
+	service "github.com/lin-snow/ech0/internal/service/webmention"
+)
+
+type WebmentionHandler struct {
+	webmentionService service.WebmentionServiceInterface
+}
+
+// NewWebmentionHandler WebmentionHandler 的构造函数
+func NewWebmentionHandler(webmentionService service.WebmentionServiceInterface) *WebmentionHandler {
+	return &WebmentionHandler{
+		webmentionService: webmentionService,
+	}
+}
+
+// ReceiveWebmention 接收 Webmention
+//
+// @Summary 接收 Webmention
+// @Description 接收其它站点发送的 Webmention，target 必须是本站公开的 Echo，source 会在后台异步验证，通过后进入待审核状态
+// @Tags Webmention
+// @Accept application/x-www-form-urlencoded
+// @Produce plain
+// @Param source formData string true "提及来源页面"
+// @Param target formData string true "被提及的 Echo 页面"
+// @Success 202 {string} string "已接收，等待验证"
+// @Failure 400 {string} string "无效的 source 或 target"
+// @Failure 429 {string} string "等待验证的 Webmention 过多"
+// @Router /webmention [post]
+func (webmentionHandler *WebmentionHandler) ReceiveWebmention(ctx *gin.Context) {
+	var webmentionDto model.WebmentionDto
+	if err := ctx.ShouldBind(&webmentionDto); err != nil {
+		ctx.String(http.StatusBadRequest, commonModel.INVALID_REQUEST_BODY)
+		return
+	}
+
+	if err := webmentionHandler.webmentionService.ReceiveWebmention(ctx.Request.Host, webmentionDto); err != nil {
+		if err.Error() == commonModel.WEBMENTION_QUEUE_FULL {
+			ctx.String(http.StatusTooManyRequests, err.Error())
+			return
+		}
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
+
+// GetEchoSource 获取作为 source 的 Echo 页面
+//
+// @Summary 获取 Echo 的 h-entry 页面
+// @Description 服务端渲染的 Echo 页面（包含 h-entry），发送 Webmention 时作为 source；Echo 不存在或为私密时返回 410
+// @Tags Webmention
+// @Produce html
+// @Param id path int true "Echo ID"
+// @Success 200 {string} string "Echo 页面"
+// @Failure 410 {string} string "Echo 已删除"
+// @Router /webmention/echos/{id} [get]
+func (webmentionHandler *WebmentionHandler) GetEchoSource(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.String(http.StatusNotFound, commonModel.ECHO_NOT_FOUND)
+		return
+	}
+
+	page, err := webmentionHandler.webmentionService.RenderEchoSource(uint(id))
+	if err != nil {
+		if err.Error() == commonModel.ECHO_NOT_FOUND {
+			ctx.String(http.StatusGone, commonModel.ECHO_NOT_FOUND)
+			return
+		}
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// GetWebmentions 获取 Webmention 列表
+//
+// @Summary 获取 Webmention 列表
+// @Description 管理员分页获取收到的 Webmention，可按审核状态过滤
+// @Tags Webmention
+// @Produce json
+// @Param status query string false "审核状态（pending、approved、rejected）"
+// @Param page query int false "页码"
+// @Param pageSize query int false "每页大小"
+// @Success 200 {object} res.Response{data=commonModel.PageQueryResult[[]model.Webmention]} "获取成功"
+// @Failure 200 {object} res.Response "获取失败"
+// @Router /webmentions [get]
+func (webmentionHandler *WebmentionHandler) GetWebmentions() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		var webmentionQueryDto model.WebmentionQueryDto
+		if err := ctx.ShouldBindQuery(&webmentionQueryDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		result, err := webmentionHandler.webmentionService.GetWebmentions(user, webmentionQueryDto)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: result,
+			Msg:  commonModel.GET_WEBMENTIONS_SUCCESS,
+		}
+	})
+}
+
+// UpdateWebmentionStatus 审核 Webmention
+//
+// @Summary 审核 Webmention
+// @Description 管理员修改 Webmention 的审核状态，只有 approved 的 Webmention 会随 Echo 详情返回
+// @Tags Webmention
+// @Accept json
+// @Produce json
+// @Param id path int true "Webmention ID"
+// @Param status body model.UpdateStatusDto true "审核状态"
+// @Success 200 {object} res.Response "审核成功"
+// @Failure 200 {object} res.Response "审核失败"
+// @Router /webmentions/{id} [put]
+func (webmentionHandler *WebmentionHandler) UpdateWebmentionStatus() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+
+		var updateStatusDto model.UpdateStatusDto
+		if err := ctx.ShouldBindJSON(&updateStatusDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		if err := webmentionHandler.webmentionService.UpdateWebmentionStatus(user, uint(id), updateStatusDto.Status); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.UPDATE_WEBMENTION_SUCCESS,
+		}
+	})
+}
+
+// DeleteWebmention 删除 Webmention
+//
+// @Summary 删除 Webmention
+// @Description 管理员删除收到的 Webmention
+// @Tags Webmention
+// @Produce json
+// @Param id path int true "Webmention ID"
+// @Success 200 {object} res.Response "删除成功"
+// @Failure 200 {object} res.Response "删除失败"
+// @Router /webmentions/{id} [delete]
+func (webmentionHandler *WebmentionHandler) DeleteWebmention() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户
+		user := middleware.CurrentUser(ctx)
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+
+		if err := webmentionHandler.webmentionService.DeleteWebmention(user, uint(id)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.DELETE_WEBMENTION_SUCCESS,
+		}
+	})
+}
@@ -0,0 +1,8 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type WebSubHandlerInterface interface {
+	// Hub 处理 WebSub 订阅/取消订阅请求
+	Hub(ctx *gin.Context)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/websub"
+	service "github.com/lin-snow/ech0/internal/service/websub"
+)
+
+type WebSubHandler struct {
+	webSubService service.WebSubServiceInterface
+}
+
+// NewWebSubHandler WebSubHandler 的构造函数
+func NewWebSubHandler(webSubService service.WebSubServiceInterface) *WebSubHandler {
+	return &WebSubHandler{
+		webSubService: webSubService,
+	}
+}
+
+// Hub 处理 WebSub 订阅/取消订阅请求
+//
+// @Summary WebSub Hub
+// @Description 订阅本站的订阅源（/rss、/feed/{format}），Hub 会异步向回调地址验证订阅意图，订阅源变化时推送最新内容
+// @Tags 通用功能
+// @Accept application/x-www-form-urlencoded
+// @Produce plain
+// @Param hub.mode formData string true "subscribe 或 unsubscribe"
+// @Param hub.topic formData string true "订阅源地址"
+// @Param hub.callback formData string true "订阅者的回调地址"
+// @Param hub.lease_seconds formData int false "订阅有效期（秒），默认 10 天"
+// @Param hub.secret formData string false "用于签名推送内容的密钥（X-Hub-Signature）"
+// @Success 202 {string} string "请求已接收，等待验证订阅意图"
+// @Failure 400 {string} string "无效的订阅请求"
+// @Failure 429 {string} string "等待验证的订阅请求过多"
+// @Router /websub/hub [post]
+func (webSubHandler *WebSubHandler) Hub(ctx *gin.Context) {
+	var subscriptionDto model.SubscriptionDto
+	if err := ctx.ShouldBind(&subscriptionDto); err != nil {
+		ctx.String(http.StatusBadRequest, commonModel.INVALID_REQUEST_BODY)
+		return
+	}
+
+	if err := webSubHandler.webSubService.HandleSubscription(ctx.Request.Host, subscriptionDto); err != nil {
+		if err.Error() == commonModel.WEBSUB_QUEUE_FULL {
+			ctx.String(http.StatusTooManyRequests, err.Error())
+			return
+		}
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
@@ -5,14 +5,63 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lin-snow/ech0/internal/authz"
 	authModel "github.com/lin-snow/ech0/internal/model/auth"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	errUtil "github.com/lin-snow/ech0/internal/util/err"
-	jwtUtil "github.com/lin-snow/ech0/internal/util/jwt"
 )
 
-// JWTAuthMiddleware JWT 拦截器中间件
-func JWTAuthMiddleware() gin.HandlerFunc {
+// currentUserKey 当前用户在请求上下文中的键
+const currentUserKey = "user"
+
+// TokenValidator 解析令牌并检查令牌所属的会话是否仍然有效（会话被撤销或过期后令牌立即失效）
+type TokenValidator interface {
+	ParseAccessToken(token string) (*authModel.MyClaims, error)
+	ValidateAccessToken(claims *authModel.MyClaims) error
+	ValidatePersonalToken(token string, ip string) (authModel.AccessToken, error)
+}
+
+// Authenticator 校验令牌，并加载令牌所属的用户
+type Authenticator interface {
+	TokenValidator
+	LoadUser(userid uint) (userModel.User, error)
+}
+
+// JWTAuthMiddleware 鉴权中间件，要求请求携带有效的令牌
+func JWTAuthMiddleware(authenticator Authenticator) gin.HandlerFunc {
+	return authMiddleware(authenticator, false)
+}
+
+// OptionalAuthMiddleware 可选鉴权中间件，未携带令牌时按未登录处理（携带的令牌无效时仍返回错误）
+func OptionalAuthMiddleware(authenticator Authenticator) gin.HandlerFunc {
+	return authMiddleware(authenticator, true)
+}
+
+// RequirePermission 要求当前用户拥有权限，需要在鉴权中间件之后使用
+func RequirePermission(permission authz.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := authz.Authorize(CurrentUser(ctx), permission); err != nil {
+			ctx.JSON(http.StatusOK, commonModel.Fail[any](err.Error()))
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// CurrentUser 获取鉴权中间件加载的当前用户，未登录时返回零值（没有任何权限）
+func CurrentUser(ctx *gin.Context) userModel.User {
+	if value, ok := ctx.Get(currentUserKey); ok {
+		if user, ok := value.(userModel.User); ok {
+			return user
+		}
+	}
+	return userModel.User{}
+}
+
+// authMiddleware 解析令牌并将当前用户存入上下文
+func authMiddleware(authenticator Authenticator, optional bool) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// 获取 Authorization 头部信息
 		auth := ctx.Request.Header.Get("Authorization")
@@ -20,33 +69,15 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		// 将 Authorization 头部信息分割成两部分
 		parts := strings.SplitN(auth, " ", 2)
 
-		// 如果 Authorization 头部信息为空，或者格式不正确，或者 token 为空，则返回错误
+		// 如果 Authorization 头部信息为空，或者格式不正确，或者 token 为空
 		if auth == "" || len(parts) != 2 || len(parts[1]) == 0 || parts[1] == "null" || parts[1] == "undefined" {
-			// 如果只是分页获取首页Echo，则不需要鉴权
-			if strings.HasPrefix(ctx.Request.URL.Path, "/api/echo/page") {
-				// 设置 userid 为 NO_USER_LOGINED
-				ctx.Set("userid", authModel.NO_USER_LOGINED)
-				ctx.Next()
-				return
-			}
-
-			// 获取当日Echo
-			if strings.HasPrefix(ctx.Request.URL.Path, "/api/echo/today") {
-				// 设置 userid 为 NO_USER_LOGINED
-				ctx.Set("userid", authModel.NO_USER_LOGINED)
-				ctx.Next()
-				return
-			}
-
-			// 查看Echo详情
-			if strings.HasPrefix(ctx.Request.URL.Path, "/api/echo") && ctx.Request.Method == http.MethodGet {
-				// 设置 userid 为 NO_USER_LOGINED
-				ctx.Set("userid", authModel.NO_USER_LOGINED)
+			// 可选鉴权的路由按未登录处理
+			if optional {
+				setAnonymous(ctx)
 				ctx.Next()
 				return
 			}
 
-			// 如果 Authorization 头部信息为空，或者格式不正确，或者 token 为空，则返回错误
 			ctx.JSON(http.StatusOK, commonModel.Fail[any](errUtil.HandleError(&commonModel.ServerError{
 				Msg: commonModel.TOKEN_NOT_FOUND,
 				Err: nil,
@@ -56,7 +87,7 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		}
 
 		// 如果 Authorization 头部信息格式不正确，或者 token 格式不正确，则返回错误
-		if len(parts) != 2 && parts[0] != "Bearer" {
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
 			ctx.JSON(http.StatusOK, commonModel.Fail[any](errUtil.HandleError(&commonModel.ServerError{
 				Msg: commonModel.TOKEN_NOT_VALID,
 				Err: nil,
@@ -65,8 +96,27 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 个人访问令牌
+		if strings.HasPrefix(parts[1], authModel.PersonalTokenPrefix) {
+			userid, ok := authenticatePersonalToken(ctx, authenticator, parts[1])
+			if !ok {
+				return
+			}
+			if userid == authModel.NO_USER_LOGINED {
+				setAnonymous(ctx)
+				ctx.Next()
+				return
+			}
+			if err := setCurrentUser(ctx, authenticator, userid); err != nil {
+				abortWithError(ctx, err)
+				return
+			}
+			ctx.Next()
+			return
+		}
+
 		// 解析 token
-		mc, err := jwtUtil.ParseToken(parts[1])
+		mc, err := authenticator.ParseAccessToken(parts[1])
 		if err != nil {
 			// 如果 token 解析失败，则返回错误
 			ctx.JSON(http.StatusOK, commonModel.Fail[any](errUtil.HandleError(&commonModel.ServerError{
@@ -77,8 +127,45 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 如果 token 解析成功，则将用户 ID 存入上下文
-		ctx.Set("userid", mc.Userid)
+		// 检查令牌所属的会话是否已被撤销或过期
+		if err := authenticator.ValidateAccessToken(mc); err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		// 如果 token 解析成功，则加载当前用户，并将会话 ID 存入上下文
+		if err := setCurrentUser(ctx, authenticator, mc.Userid); err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+		ctx.Set("sessionid", mc.SessionID)
 		ctx.Next()
 	}
 }
+
+// setCurrentUser 加载用户并将用户和用户 ID 存入上下文，整个请求只加载一次
+func setCurrentUser(ctx *gin.Context, loader Authenticator, userid uint) error {
+	user, err := loader.LoadUser(userid)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(currentUserKey, user)
+	ctx.Set("userid", user.ID)
+	return nil
+}
+
+// setAnonymous 将当前请求标记为未登录
+func setAnonymous(ctx *gin.Context) {
+	ctx.Set(currentUserKey, userModel.User{})
+	ctx.Set("userid", authModel.NO_USER_LOGINED)
+}
+
+// abortWithError 返回错误并中止请求
+func abortWithError(ctx *gin.Context, err error) {
+	ctx.JSON(http.StatusOK, commonModel.Fail[any](errUtil.HandleError(&commonModel.ServerError{
+		Msg: err.Error(),
+		Err: err,
+	})))
+	ctx.Abort()
+}
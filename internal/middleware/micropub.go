@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	micropubModel "github.com/lin-snow/ech0/internal/model/micropub"
+)
+
+// MicropubAuthMiddleware Micropub 鉴权中间件
+// 支持 Authorization 头部和表单中的 access_token（JWT 或个人访问令牌），失败时按 Micropub 规范返回 401
+func MicropubAuthMiddleware(authenticator Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token := ""
+		if scheme, value, ok := strings.Cut(ctx.Request.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(value)
+		} else if ctx.Request.Method == http.MethodPost {
+			token = ctx.PostForm("access_token")
+		}
+
+		if token == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, micropubModel.Error{
+				Error:            micropubModel.ErrorUnauthorized,
+				ErrorDescription: commonModel.TOKEN_NOT_FOUND,
+			})
+			return
+		}
+
+		// 个人访问令牌需要拥有对应的权限范围
+		if strings.HasPrefix(token, authModel.PersonalTokenPrefix) {
+			accessToken, err := authenticator.ValidatePersonalToken(token, ctx.ClientIP())
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, micropubModel.Error{
+					Error:            micropubModel.ErrorUnauthorized,
+					ErrorDescription: err.Error(),
+				})
+				return
+			}
+			if scope, ok := micropubScopes[routeKey(ctx)]; !ok || !accessToken.HasScope(scope) {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, micropubModel.Error{
+					Error:            micropubModel.ErrorInsufficientScope,
+					ErrorDescription: commonModel.ACCESS_TOKEN_SCOPE_DENIED,
+				})
+				return
+			}
+
+			setMicropubUser(ctx, authenticator, accessToken.UserID)
+			return
+		}
+
+		mc, err := authenticator.ParseAccessToken(token)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, micropubModel.Error{
+				Error:            micropubModel.ErrorUnauthorized,
+				ErrorDescription: commonModel.TOKEN_PARSE_ERROR,
+			})
+			return
+		}
+		if err := authenticator.ValidateAccessToken(mc); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, micropubModel.Error{
+				Error:            micropubModel.ErrorUnauthorized,
+				ErrorDescription: err.Error(),
+			})
+			return
+		}
+
+		setMicropubUser(ctx, authenticator, mc.Userid)
+	}
+}
+
+// setMicropubUser 加载当前用户并继续处理请求，用户不存在时按 Micropub 规范返回 401
+func setMicropubUser(ctx *gin.Context, authenticator Authenticator, userid uint) {
+	if err := setCurrentUser(ctx, authenticator, userid); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, micropubModel.Error{
+			Error:            micropubModel.ErrorUnauthorized,
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+	ctx.Next()
+}
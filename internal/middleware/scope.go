@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+)
+
+// tokenScopes 个人访问令牌可以访问的接口及所需的权限范围（"方法 路由"），未列出的接口不接受个人访问令牌
+var tokenScopes = map[string]authModel.Scope{
+	// Echo
+	"POST /api/echo":       authModel.ScopeEchoWrite,
+	"PUT /api/echo":        authModel.ScopeEchoWrite,
+	"DELETE /api/echo/:id": authModel.ScopeEchoWrite,
+
+	// 读取 Echo（没有 echo:read-private 时按未登录处理，只能读取公开内容）
+	"GET /api/echo/page":                 authModel.ScopeEchoReadPrivate,
+	"POST /api/echo/page":                authModel.ScopeEchoReadPrivate,
+	"GET /api/echo/today":                authModel.ScopeEchoReadPrivate,
+	"GET /api/echo/:id":                  authModel.ScopeEchoReadPrivate,
+	"GET /api/echo/archive":              authModel.ScopeEchoReadPrivate,
+	"GET /api/echo/archive/:year/:month": authModel.ScopeEchoReadPrivate,
+	"GET /api/echo/onthisday":            authModel.ScopeEchoReadPrivate,
+	"GET /api/echo/digest":               authModel.ScopeEchoReadPrivate,
+	"GET /api/stats":                     authModel.ScopeEchoReadPrivate,
+	"GET /api/heatmap":                   authModel.ScopeEchoReadPrivate,
+
+	// 媒体
+	"POST /api/images/upload":   authModel.ScopeMediaUpload,
+	"DELETE /api/images/delete": authModel.ScopeMediaUpload,
+	"POST /api/audios/upload":   authModel.ScopeMediaUpload,
+	"DELETE /api/audios/delete": authModel.ScopeMediaUpload,
+
+	// 设置
+	"PUT /api/settings":         authModel.ScopeSettings,
+	"PUT /api/comment/settings": authModel.ScopeSettings,
+
+	// 备份
+	"GET /api/backup":        authModel.ScopeBackup,
+	"GET /api/backup/export": authModel.ScopeBackup,
+}
+
+// micropubScopes Micropub 接口所需的权限范围
+var micropubScopes = map[string]authModel.Scope{
+	"GET /micropub":        authModel.ScopeEchoWrite,
+	"POST /micropub":       authModel.ScopeEchoWrite,
+	"POST /micropub/media": authModel.ScopeMediaUpload,
+}
+
+// routeKey 获取当前请求匹配的路由
+func routeKey(ctx *gin.Context) string {
+	return ctx.Request.Method + " " + ctx.FullPath()
+}
+
+// authenticatePersonalToken 校验个人访问令牌及其权限范围，返回令牌所属的用户 ID
+// 没有读取私密内容的权限时返回 NO_USER_LOGINED（按未登录处理），校验失败时中止请求并返回 false
+// 令牌有效但缺少权限范围（包括访问未列出的接口）时返回 403
+func authenticatePersonalToken(ctx *gin.Context, validator TokenValidator, token string) (uint, bool) {
+	accessToken, err := validator.ValidatePersonalToken(token, ctx.ClientIP())
+	if err != nil {
+		abortWithError(ctx, err)
+		return 0, false
+	}
+
+	scope, ok := tokenScopes[routeKey(ctx)]
+	if !ok {
+		ctx.JSON(http.StatusForbidden, commonModel.Fail[any](commonModel.ACCESS_TOKEN_SCOPE_DENIED))
+		ctx.Abort()
+		return 0, false
+	}
+
+	if !accessToken.HasScope(scope) {
+		// 没有读取私密内容的权限时，按未登录处理
+		if scope == authModel.ScopeEchoReadPrivate {
+			return authModel.NO_USER_LOGINED, true
+		}
+
+		ctx.JSON(http.StatusForbidden, commonModel.Fail[any](commonModel.ACCESS_TOKEN_SCOPE_DENIED))
+		ctx.Abort()
+		return 0, false
+	}
+
+	return accessToken.UserID, true
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	authRepository "github.com/lin-snow/ech0/internal/repository/auth"
+	authService "github.com/lin-snow/ech0/internal/service/auth"
+	userService "github.com/lin-snow/ech0/internal/service/user"
+	"github.com/lin-snow/ech0/internal/transaction"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+const testUserID = 1
+
+// fakeUserService 只提供加载当前用户需要的方法
+type fakeUserService struct {
+	userService.UserServiceInterface
+}
+
+func (fakeUserService) GetUserByID(id int) (userModel.User, error) {
+	if id != testUserID {
+		return userModel.User{}, gorm.ErrRecordNotFound
+	}
+	return userModel.User{ID: testUserID, Username: "alice", Role: userModel.RoleAuthor}, nil
+}
+
+// scopeTestEnv 使用真实的认证服务校验个人访问令牌，路由返回中间件识别出的用户 ID
+type scopeTestEnv struct {
+	db     *gorm.DB
+	auth   authService.AuthServiceInterface
+	engine *gin.Engine
+}
+
+func newScopeTestEnv(t *testing.T) *scopeTestEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logUtil.Logger = zap.NewNop()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "scope.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&authModel.AccessToken{}))
+
+	auth := authService.NewAuthService(transaction.NewTransactionManager(db), authRepository.NewAuthRepository(db), fakeUserService{}, nil)
+
+	userID := func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, commonModel.OK(ctx.GetUint("userid")))
+	}
+	engine := gin.New()
+	engine.POST("/api/echo", JWTAuthMiddleware(auth), userID)
+	engine.GET("/api/echo/page", OptionalAuthMiddleware(auth), userID)
+	engine.GET("/api/user", JWTAuthMiddleware(auth), userID)
+
+	return &scopeTestEnv{db: db, auth: auth, engine: engine}
+}
+
+// createToken 为测试用户创建拥有指定权限范围的个人访问令牌
+func (env *scopeTestEnv) createToken(t *testing.T, scopes ...authModel.Scope) authModel.CreatedAccessToken {
+	t.Helper()
+
+	token, err := env.auth.CreateAccessToken(testUserID, &authModel.CreateAccessTokenDto{Name: "script", Scopes: scopes, ExpiresIn: 30})
+	require.NoError(t, err)
+	return token
+}
+
+// request 携带令牌发送请求，返回状态码和响应
+func (env *scopeTestEnv) request(t *testing.T, method, path, token string) (int, commonModel.Result[uint]) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	env.engine.ServeHTTP(w, req)
+
+	var result commonModel.Result[uint]
+	require.NoError(t, jsonUtil.JSONUnmarshal(w.Body.Bytes(), &result))
+	return w.Code, result
+}
+
+// 拥有权限范围的令牌可以访问接口，缺少权限范围时返回 403
+func TestPersonalToken_RequiresScope(t *testing.T) {
+	env := newScopeTestEnv(t)
+
+	writer := env.createToken(t, authModel.ScopeEchoWrite)
+	code, result := env.request(t, http.MethodPost, "/api/echo", writer.Token)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint(testUserID), result.Data)
+
+	uploader := env.createToken(t, authModel.ScopeMediaUpload)
+	code, result = env.request(t, http.MethodPost, "/api/echo", uploader.Token)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, commonModel.ACCESS_TOKEN_SCOPE_DENIED, result.Message)
+}
+
+// 没有 echo:read-private 的令牌读取 Echo 时按未登录处理，而不是拒绝请求
+func TestPersonalToken_ReadPrivateFallback(t *testing.T) {
+	env := newScopeTestEnv(t)
+
+	reader := env.createToken(t, authModel.ScopeEchoReadPrivate)
+	code, result := env.request(t, http.MethodGet, "/api/echo/page", reader.Token)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint(testUserID), result.Data)
+
+	writer := env.createToken(t, authModel.ScopeEchoWrite)
+	code, result = env.request(t, http.MethodGet, "/api/echo/page", writer.Token)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint(authModel.NO_USER_LOGINED), result.Data)
+}
+
+// 未在权限范围表中列出的接口不接受个人访问令牌，即使令牌拥有所有权限范围
+func TestPersonalToken_DeniesUnlistedRoute(t *testing.T) {
+	env := newScopeTestEnv(t)
+
+	token := env.createToken(t, authModel.AllScopes...)
+	code, result := env.request(t, http.MethodGet, "/api/user", token.Token)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, commonModel.ACCESS_TOKEN_SCOPE_DENIED, result.Message)
+}
+
+// 过期和已删除的令牌被拒绝
+func TestPersonalToken_RejectsExpiredAndRevoked(t *testing.T) {
+	env := newScopeTestEnv(t)
+
+	expired := env.createToken(t, authModel.ScopeEchoWrite)
+	require.NoError(t, env.db.Model(&authModel.AccessToken{}).Where("id = ?", expired.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, result := env.request(t, http.MethodPost, "/api/echo", expired.Token)
+	assert.Equal(t, commonModel.ACCESS_TOKEN_EXPIRED, result.Message)
+
+	revoked := env.createToken(t, authModel.ScopeEchoWrite)
+	require.NoError(t, env.auth.DeleteAccessToken(testUserID, revoked.ID))
+	_, result = env.request(t, http.MethodPost, "/api/echo", revoked.Token)
+	assert.Equal(t, commonModel.TOKEN_NOT_VALID, result.Message)
+
+	// 可选鉴权的接口同样拒绝无效的令牌，而不是按未登录处理
+	_, result = env.request(t, http.MethodGet, "/api/echo/page", revoked.Token)
+	assert.Equal(t, commonModel.TOKEN_NOT_VALID, result.Message)
+}
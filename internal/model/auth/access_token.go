@@ -0,0 +1,56 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Scope 定义个人访问令牌的权限范围
+type Scope string
+
+const (
+	ScopeEchoWrite       Scope = "echo:write"        // 发布、修改和删除 Echo（包括 Micropub）
+	ScopeEchoReadPrivate Scope = "echo:read-private" // 读取私密 Echo，没有该权限时只能读取公开内容
+	ScopeMediaUpload     Scope = "media:upload"      // 上传和删除图片、音频
+	ScopeSettings        Scope = "settings"          // 修改系统设置
+	ScopeBackup          Scope = "backup"            // 备份和导出数据
+)
+
+// AllScopes 所有可用的权限范围
+var AllScopes = []Scope{ScopeEchoWrite, ScopeEchoReadPrivate, ScopeMediaUpload, ScopeSettings, ScopeBackup}
+
+// AccessToken 定义用于脚本和自动化的个人访问令牌（只保存令牌的哈希）
+type AccessToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index;not null" json:"user_id"`
+	Name       string     `gorm:"type:varchar(64);not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(32)" json:"prefix"` // 令牌的开头部分，方便辨认
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Scopes     []Scope    `gorm:"type:text;serializer:json" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // 为空时永不过期
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `gorm:"type:varchar(64)" json:"last_used_ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope 检查令牌是否拥有指定的权限范围
+func (token AccessToken) HasScope(scope Scope) bool {
+	return slices.Contains(token.Scopes, scope)
+}
+
+// CreatedAccessToken 定义新创建的个人访问令牌，令牌明文只在创建时返回一次
+type CreatedAccessToken struct {
+	AccessToken
+	Token string `json:"token"`
+}
+
+const (
+	// PersonalTokenPrefix 个人访问令牌的前缀，用于和 JWT 区分
+	PersonalTokenPrefix = "ech0_pat_"
+	// PersonalTokenBytes 个人访问令牌的随机字节数
+	PersonalTokenBytes = 32
+	// PersonalTokenDisplayLength 列表中展示的令牌开头部分的长度
+	PersonalTokenDisplayLength = len(PersonalTokenPrefix) + 6
+	// MaxAccessTokens 每个用户最多创建的个人访问令牌数量
+	MaxAccessTokens = 50
+)
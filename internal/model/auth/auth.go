@@ -8,6 +8,8 @@ import (
 type MyClaims struct {
 	Userid   uint   `json:"user_id"`
 	Username string `json:"username"`
+	// SessionID 令牌所属的会话，撤销会话后令牌立即失效
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
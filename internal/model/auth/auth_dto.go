@@ -4,6 +4,7 @@ package model
 type LoginDto struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	Code     string `json:"code,omitempty"` // 开启两步验证时可以直接附带验证码或恢复码
 }
 
 // RegisterDto 是用户注册时的请求数据传输对象
@@ -11,3 +12,26 @@ type RegisterDto struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+// RefreshDto 是刷新令牌时的请求数据传输对象
+type RefreshDto struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// CreateAccessTokenDto 是创建个人访问令牌的请求数据传输对象
+type CreateAccessTokenDto struct {
+	Name      string  `json:"name" binding:"required"`
+	Scopes    []Scope `json:"scopes" binding:"required"`
+	ExpiresIn int     `json:"expires_in"` // 有效天数，为 0 时永不过期
+}
+
+// TwoFactorCodeDto 是提交两步验证码的请求数据传输对象
+type TwoFactorCodeDto struct {
+	Code string `json:"code" binding:"required"` // 验证码或恢复码
+}
+
+// TwoFactorVerifyDto 是登录时完成两步验证的请求数据传输对象
+type TwoFactorVerifyDto struct {
+	Challenge string `json:"challenge" binding:"required"`
+	Code      string `json:"code" binding:"required"` // 验证码或恢复码
+}
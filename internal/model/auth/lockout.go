@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Lockout 定义按 IP 或用户名统计的失败次数和锁定状态
+type Lockout struct {
+	ID            uint         `gorm:"primaryKey" json:"id"`
+	Scope         LockoutScope `gorm:"type:varchar(16);uniqueIndex:idx_lockout_target;not null" json:"scope"`
+	Target        string       `gorm:"type:varchar(255);uniqueIndex:idx_lockout_target;not null" json:"target"` // IP 或用户名
+	Failures      int          `json:"failures"`                                                                // 当前时间窗口内的失败次数
+	Level         int          `json:"level"`                                                                   // 已被锁定的次数，锁定时长按该值指数增长
+	LastFailureAt time.Time    `json:"last_failure_at"`
+	LockedUntil   time.Time    `json:"locked_until"`
+	UpdatedAt     time.Time    `gorm:"index" json:"updated_at"`
+	Locked        bool         `gorm:"-" json:"locked"` // 当前是否处于锁定状态
+}
+
+// LockoutScope 失败次数的统计范围
+type LockoutScope string
+
+const (
+	LockoutScopeLoginIP    LockoutScope = "login_ip"    // 按 IP 统计的登录失败
+	LockoutScopeUsername   LockoutScope = "username"    // 按用户名统计的登录失败
+	LockoutScopeRegisterIP LockoutScope = "register_ip" // 按 IP 统计的注册请求
+)
+
+const (
+	// LockoutRetention 超过该时间没有失败的记录会被清理（同时重置锁定时长）
+	LockoutRetention = 24 * time.Hour
+)
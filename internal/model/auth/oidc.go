@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// OIDCIdentity 定义与本地用户关联的 OpenID Connect 身份
+type OIDCIdentity struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Provider    string    `gorm:"type:varchar(64);uniqueIndex:idx_oidc_identity;not null" json:"provider"` // 身份提供者标识
+	Subject     string    `gorm:"type:varchar(255);uniqueIndex:idx_oidc_identity;not null" json:"-"`       // 身份提供者中的用户标识（sub）
+	UserID      uint      `gorm:"index;not null" json:"-"`
+	Email       string    `gorm:"type:varchar(255)" json:"email"` // 关联时的邮箱，仅用于展示
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+// OIDCState 定义等待身份提供者回调的授权请求
+type OIDCState struct {
+	State        string    `gorm:"type:varchar(64);primaryKey"`
+	Provider     string    `gorm:"type:varchar(64);not null"`
+	Nonce        string    `gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null"` // PKCE 校验码
+	UserID       uint      // 不为 0 时表示为该用户关联身份，而不是登录
+	ExpiresAt    time.Time `gorm:"index"`
+	CreatedAt    time.Time
+}
+
+// OIDCProviderInfo 定义登录页面展示的身份提供者
+type OIDCProviderInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// OIDCAuthorization 定义发起授权请求的结果
+type OIDCAuthorization struct {
+	URL   string `json:"url"` // 身份提供者的授权地址
+	State string `json:"-"`   // 需要写入 Cookie 的 state
+}
+
+// OIDCResult 定义身份提供者回调的处理结果
+type OIDCResult struct {
+	Tokens TokenPair // 登录时签发的令牌
+	Linked bool      // 是否为关联身份
+}
+
+const (
+	// OIDCStateExpiry 授权请求的有效期
+	OIDCStateExpiry = 10 * time.Minute
+	// OIDCStateBytes state 和 nonce 的随机字节数
+	OIDCStateBytes = 32
+	// OIDCVerifierBytes PKCE 校验码的随机字节数
+	OIDCVerifierBytes = 48
+	// OIDCStateCookie 绑定授权请求与浏览器的 Cookie
+	OIDCStateCookie = "ech0_oidc_state"
+	// OIDCCookiePath OIDC 相关 Cookie 的路径
+	OIDCCookiePath = "/api/auth/oidc"
+	// OIDCCallbackPath 回调地址的路径格式
+	OIDCCallbackPath = "/api/auth/oidc/%s/callback"
+	// OIDCLoginRedirect 登录完成后跳转的前端页面（令牌放在 URL 片段中）
+	OIDCLoginRedirect = "/auth"
+	// OIDCLinkRedirect 关联身份完成后跳转的前端页面
+	OIDCLinkRedirect = "/panel"
+	// OIDCMetadataTTL 身份提供者元数据和公钥的缓存时间
+	OIDCMetadataTTL = time.Hour
+	// OIDCKeyRefreshInterval 遇到未知签名密钥时重新获取公钥的最小间隔
+	OIDCKeyRefreshInterval = 10 * time.Second
+	// OIDCDefaultUsernameClaim 默认作为用户名的声明
+	OIDCDefaultUsernameClaim = "preferred_username"
+	// OIDCDefaultGroupsClaim 默认的用户组声明
+	OIDCDefaultGroupsClaim = "groups"
+)
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Session 定义登录会话（只保存刷新令牌的哈希）
+type Session struct {
+	ID                string     `gorm:"type:varchar(36);primaryKey" json:"id"`
+	UserID            uint       `gorm:"index;not null" json:"user_id"`
+	RefreshTokenHash  string     `gorm:"type:varchar(64);index" json:"-"` // 当前刷新令牌的哈希（旧版登录接口创建的会话为空）
+	PreviousTokenHash string     `gorm:"type:varchar(64);index" json:"-"` // 上一个刷新令牌的哈希，用于发现被重复使用的刷新令牌
+	UserAgent         string     `gorm:"type:varchar(512)" json:"user_agent"`
+	IP                string     `gorm:"type:varchar(64)" json:"ip"`
+	CreatedAt         time.Time  `json:"created_at"`
+	LastUsedAt        time.Time  `json:"last_used_at"`
+	ExpiresAt         time.Time  `gorm:"index" json:"expires_at"`
+	RevokedAt         *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	Current           bool       `gorm:"-" json:"current"` // 是否为发起请求的会话
+}
+
+// ClientInfo 定义创建或刷新会话的客户端信息
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// TokenPair 定义登录或刷新后签发的令牌
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // 访问令牌的有效期（秒）
+}
+
+const (
+	// TokenTypeBearer 令牌类型
+	TokenTypeBearer = "Bearer"
+	// RefreshTokenBytes 刷新令牌的随机字节数
+	RefreshTokenBytes = 32
+	// SessionTouchInterval 更新会话最近使用时间的最小间隔，避免每个请求都写数据库
+	SessionTouchInterval = time.Minute
+)
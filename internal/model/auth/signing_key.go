@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// SigningKey 定义签发 JWT 使用的密钥（Base64 编码），通过 kid 区分以支持轮换
+type SigningKey struct {
+	ID         uint       `gorm:"primaryKey" json:"-"`
+	Kid        string     `gorm:"type:varchar(32);uniqueIndex;not null" json:"kid"`
+	Algorithm  string     `gorm:"type:varchar(16);not null" json:"algorithm"` // HS256 或 EdDSA
+	PrivateKey string     `gorm:"type:text;not null" json:"-"`                // HS256 的密钥或 Ed25519 的私钥
+	PublicKey  string     `gorm:"type:text" json:"public_key,omitempty"`      // Ed25519 的公钥（HS256 为空）
+	Active     bool       `gorm:"index" json:"active"`                        // 是否为当前用于签发的密钥
+	CreatedAt  time.Time  `json:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"` // 被轮换下来的时间，宽限期内仍可用于校验
+}
+
+// JWKS 定义公开的 JSON Web Key Set（只包含非对称密钥的公钥）
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK 定义 JSON Web Key（RFC 8037 中的 Ed25519 公钥）
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+const (
+	// AlgorithmHS256 对称签名算法
+	AlgorithmHS256 = "HS256"
+	// AlgorithmEdDSA 非对称签名算法（Ed25519）
+	AlgorithmEdDSA = "EdDSA"
+
+	// EnvKeyID 通过环境变量 JWT_SECRET 指定的密钥的 kid
+	EnvKeyID = "env"
+	// HMACKeyBytes 生成的 HS256 密钥的字节数
+	HMACKeyBytes = 32
+	// KeyIDBytes 生成的 kid 的随机字节数
+	KeyIDBytes = 8
+	// KeyReloadInterval 从数据库重新加载密钥的间隔，使其它进程轮换的密钥能及时生效
+	KeyReloadInterval = time.Minute
+	// KeyMissReloadInterval 遇到未知 kid 时重新加载密钥的最小间隔
+	KeyMissReloadInterval = time.Second
+)
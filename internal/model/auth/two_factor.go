@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// TwoFactor 定义用户的 TOTP 两步验证
+type TwoFactor struct {
+	UserID      uint      `gorm:"primaryKey" json:"-"`
+	Secret      string    `gorm:"type:varchar(64);not null" json:"-"` // Base32 编码的 TOTP 密钥
+	Enabled     bool      `json:"enabled"`                            // 是否已完成绑定并启用
+	LastCounter int64     `json:"-"`                                  // 最近一次使用的时间步，防止验证码被重放
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RecoveryCode 定义一次性的恢复码（只保存哈希）
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"type:varchar(64);index;not null"`
+	CreatedAt time.Time
+}
+
+// LoginChallenge 定义密码验证通过、等待两步验证的登录请求
+type LoginChallenge struct {
+	ID        string    `gorm:"type:varchar(36);primaryKey"`
+	UserID    uint      `gorm:"index;not null"`
+	Attempts  int       // 已尝试的次数
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// TwoFactorSetup 定义开始绑定两步验证时返回的信息
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`      // 无法扫码时手动输入的密钥
+	URI    string `json:"otpauth_uri"` // 二维码内容
+}
+
+// TwoFactorStatus 定义两步验证的状态
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// LoginResult 定义登录结果，开启两步验证时返回验证凭据而不是令牌
+type LoginResult struct {
+	*TokenPair
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	Challenge         string `json:"challenge,omitempty"` // 提交验证码时使用的凭据
+}
+
+const (
+	// TOTPIssuer 身份验证器应用中显示的发行者
+	TOTPIssuer = "Ech0"
+	// RecoveryCodeCount 每次生成的恢复码数量
+	RecoveryCodeCount = 10
+	// RecoveryCodeBytes 恢复码的随机字节数
+	RecoveryCodeBytes = 5
+	// ChallengeExpiry 两步验证凭据的有效期
+	ChallengeExpiry = 5 * time.Minute
+	// MaxChallengeAttempts 每个两步验证凭据允许尝试的次数
+	MaxChallengeAttempts = 5
+)
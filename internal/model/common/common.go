@@ -1,10 +1,17 @@
 package model
 
+import (
+	"time"
+
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
 // UserStatus 用于存储用户状态信息
 type UserStatus struct {
-	UserID   uint   `json:"user_id"`  // 用户ID
-	UserName string `json:"username"` // 用户名
-	IsAdmin  bool   `json:"is_admin"` // 是否是管理员
+	UserID   uint           `json:"user_id"`  // 用户ID
+	UserName string         `json:"username"` // 用户名
+	Role     userModel.Role `json:"role"`     // 角色
+	IsAdmin  bool           `json:"is_admin"` // 是否是管理员
 }
 
 // Status 用于存储Echo状态信息
@@ -22,6 +29,39 @@ type Heatmap struct {
 	Count int    `json:"count"` // Echo数量
 }
 
+const (
+	// DefaultHeatmapDays 默认热力图天数
+	DefaultHeatmapDays = 30
+	// MaxHeatmapDays 热力图最多支持的天数（一整年）
+	MaxHeatmapDays = 366
+)
+
+// FeedFormat 订阅源格式
+type FeedFormat string
+
+const (
+	FeedFormatAtom FeedFormat = "atom" // Atom 1.0
+	FeedFormatRSS  FeedFormat = "rss"  // RSS 2.0
+	FeedFormatJSON FeedFormat = "json" // JSON Feed 1.1
+
+	// DefaultFeedLimit 订阅源默认条目数量
+	DefaultFeedLimit = 20
+	// MaxFeedLimit 订阅源最多条目数量
+	MaxFeedLimit = 100
+
+	// WebSubHubPath 内置 WebSub Hub 的路径
+	WebSubHubPath = "/websub/hub"
+)
+
+// Feed 定义生成的订阅源
+type Feed struct {
+	Content      []byte    // 订阅源内容
+	ContentType  string    // 内容类型
+	LastModified time.Time // 最新条目的发布时间
+	SelfURL      string    // 订阅源自身的地址（WebSub topic）
+	HubURL       string    // WebSub Hub 的地址
+}
+
 // File 相关
 type UploadFileType string
 type FileStorageType string
@@ -17,3 +17,53 @@ type ImageDto struct {
 	URL    string `json:"url" binding:"required"`
 	SOURCE string `json:"source" binding:"required"`
 }
+
+// HeatmapQueryDto 用于获取热力图的查询参数
+//
+// swagger:model HeatmapQueryDto
+type HeatmapQueryDto struct {
+	// 最近多少天（含今天），默认 30 天，最多 366 天
+	// example: 365
+	Days int `json:"days" form:"days"`
+
+	// 年份，指定后返回该年全年的数据（优先级高于 days）
+	// example: 2025
+	Year int `json:"year" form:"year"`
+
+	// 开始日期 (YYYY-MM-DD)，与 end 一同指定时优先级最高
+	// example: 2025-01-01
+	Start string `json:"start" form:"start"`
+
+	// 结束日期 (YYYY-MM-DD)
+	// example: 2025-12-31
+	End string `json:"end" form:"end"`
+
+	// IANA 时区名称，为空时使用站点设置的时区
+	// example: Asia/Shanghai
+	Timezone string `json:"timezone" form:"timezone"`
+
+	// 只统计指定用户发布的 Echo，0 表示所有用户
+	// example: 1
+	UserID uint `json:"user_id" form:"user_id"`
+
+	// 是否包含私密 Echo（仅管理员有效）
+	// example: false
+	Private bool `json:"private" form:"private"`
+}
+
+// FeedQueryDto 用于获取订阅源的查询参数
+//
+// swagger:model FeedQueryDto
+type FeedQueryDto struct {
+	// 条目数量，默认 20，最多 100
+	// example: 20
+	Limit int `json:"limit" form:"limit"`
+
+	// 只包含带有指定标签的 Echo（不含 #）
+	// example: golang
+	Tag string `json:"tag" form:"tag"`
+
+	// 只包含指定用户发布的 Echo
+	// example: admin
+	Username string `json:"username" form:"user"`
+}
@@ -19,6 +19,7 @@ const (
 const (
 	USERNAME_OR_PASSWORD_NOT_BE_EMPTY = "用户名或密码不能为空"
 	PASSWORD_INCORRECT                = "密码错误"
+	CURRENT_PASSWORD_INCORRECT        = "当前密码错误"
 	USER_NOTFOUND                     = "用户不存在"
 	USER_COUNT_EXCEED_LIMIT           = "用户数量超过限制"
 	USERNAME_HAS_EXISTS               = "用户名已存在"
@@ -26,6 +27,34 @@ const (
 	TOKEN_NOT_VALID                   = "令牌无效，请重新登录"
 	TOKEN_PARSE_ERROR                 = "令牌解析失败，请尝试重新登陆"
 	USER_REGISTER_NOT_ALLOW           = "当前系统禁止注册新用户"
+	PASSWORD_TOO_SHORT                = "密码长度不足"
+	PASSWORD_TOO_LONG                 = "密码长度超过限制"
+	PASSWORD_TOO_WEAK                 = "密码强度不足，请按要求包含大小写字母、数字或符号"
+	SESSION_NOT_FOUND                 = "找不到会话"
+	SESSION_EXPIRED                   = "登录已失效，请重新登录"
+	REFRESH_TOKEN_INVALID             = "刷新令牌无效，请重新登录"
+	ACCESS_TOKEN_NOT_FOUND            = "找不到访问令牌"
+	ACCESS_TOKEN_EXPIRED              = "访问令牌已过期"
+	ACCESS_TOKEN_NAME_TOO_LONG        = "访问令牌名称过长"
+	ACCESS_TOKEN_COUNT_EXCEED_LIMIT   = "访问令牌数量超过限制"
+	ACCESS_TOKEN_SCOPE_DENIED         = "访问令牌没有访问该接口的权限"
+	INVALID_SCOPE                     = "无效的权限范围"
+	INVALID_EXPIRES                   = "无效的有效期"
+	TWO_FACTOR_REQUIRED               = "该账号已开启两步验证，请输入验证码"
+	TWO_FACTOR_CODE_INVALID           = "验证码或恢复码错误"
+	TWO_FACTOR_NOT_SETUP              = "请先开始绑定两步验证"
+	TWO_FACTOR_NOT_ENABLED            = "未开启两步验证"
+	TWO_FACTOR_ALREADY_ENABLED        = "已开启两步验证"
+	TWO_FACTOR_CHALLENGE_INVALID      = "两步验证已过期，请重新登录"
+	OIDC_PROVIDER_NOT_FOUND           = "找不到身份提供者"
+	OIDC_STATE_INVALID                = "登录请求已失效，请重新登录"
+	OIDC_LOGIN_FAILED                 = "身份提供者登录失败"
+	OIDC_USER_NOT_LINKED              = "该身份尚未关联用户，请先使用密码登录后关联"
+	OIDC_IDENTITY_LINKED              = "该身份已关联其他用户"
+	OIDC_IDENTITY_NOT_FOUND           = "找不到关联的身份"
+	TOO_MANY_ATTEMPTS                 = "尝试次数过多，请稍后再试"
+	LOCKOUT_NOT_FOUND                 = "找不到锁定记录"
+	INVALID_ROLE                      = "无效的角色"
 )
 
 // Echo 错误相关常量
@@ -33,6 +62,7 @@ const (
 	NO_PERMISSION_DENIED  = "没有权限,请联系系统管理员"
 	ECHO_CAN_NOT_BE_EMPTY = "ECHO 内容不能为空"
 	ECHO_NOT_FOUND        = "找不到Echo"
+	INVALID_ARCHIVE_DATE  = "无效的归档日期"
 )
 
 // Common 错误相关常量
@@ -44,11 +74,16 @@ const (
 	IMAGE_NOT_FOUND        = "图片未找到"
 	INVALID_PARAMS         = "错误的参数"
 	SIGNUP_FIRST           = "请先注册用户"
+	INVALID_HEATMAP_RANGE  = "无效的热力图时间范围"
+	INVALID_TIMEZONE       = "无效的时区"
+	INVALID_FEED_FORMAT    = "不支持的订阅格式"
 )
 
 // User 错误相关常量
 const (
 	USERNAME_ALREADY_EXISTS = "用户名已存在"
+	DISPLAY_NAME_TOO_LONG   = "昵称长度超过限制"
+	BIO_TOO_LONG            = "个人简介长度超过限制"
 )
 
 // TO DO 错误相关常量
@@ -60,9 +95,52 @@ const (
 const (
 	INVALID_CONNECTION_URL = "connect url不能为空"
 	CONNECT_HAS_EXISTS     = "connect 已经存在"
+	CONNECT_NOT_FOUND      = "找不到连接"
+	CONNECT_TO_SELF        = "不能连接到当前实例"
+	INVALID_CONNECT_STATE  = "当前连接状态不允许该操作"
+	INVALID_HANDSHAKE      = "无效的连接握手消息"
+	INVALID_CONNECT_SIGN   = "连接握手签名校验失败"
+	CONNECT_HANDSHAKE_FAIL = "连接握手失败"
+	SUGGESTION_NOT_FOUND   = "找不到推荐的实例"
+	CONNECT_UNREACHABLE    = "无法获取对方实例的连接信息"
+	INVALID_OPML           = "无效的 OPML 文件"
 )
 
 // Setting 错误相关常量
 const (
 	NO_SUCH_COMMENT_PROVIDER = "无效的评论服务提供者"
 )
+
+// Fediverse 错误相关常量
+const (
+	SERVER_URL_NOT_SET         = "请先在系统设置中配置服务器地址"
+	INVALID_WEBFINGER_RESOURCE = "无效的 WebFinger 资源"
+	INVALID_ACTIVITY           = "无效的 ActivityPub 活动"
+	INVALID_HTTP_SIGNATURE     = "HTTP 签名校验失败"
+)
+
+// WebSub 错误相关常量
+const (
+	INVALID_WEBSUB_MODE     = "无效的 hub.mode"
+	INVALID_WEBSUB_TOPIC    = "无效的 hub.topic，只支持本站的订阅源"
+	INVALID_WEBSUB_CALLBACK = "无效的 hub.callback"
+	INVALID_WEBSUB_SECRET   = "hub.secret 长度不能超过 199 字节"
+	WEBSUB_QUEUE_FULL       = "等待验证的订阅请求过多，请稍后再试"
+)
+
+// Webmention 错误相关常量
+const (
+	INVALID_WEBMENTION_SOURCE = "无效的 source"
+	INVALID_WEBMENTION_TARGET = "无效的 target，只接受本站公开 Echo 的链接"
+	INVALID_WEBMENTION_STATUS = "无效的审核状态"
+	WEBMENTION_NOT_FOUND      = "找不到 Webmention"
+	WEBMENTION_QUEUE_FULL     = "等待验证的 Webmention 过多，请稍后再试"
+)
+
+// Micropub 错误相关常量
+const (
+	INVALID_MICROPUB_ACTION = "不支持的 Micropub 操作"
+	INVALID_MICROPUB_TYPE   = "只支持创建 h-entry"
+	INVALID_MICROPUB_QUERY  = "不支持的 Micropub 查询"
+	INVALID_MICROPUB_URL    = "无效的 url，只支持本站 Echo 的链接"
+)
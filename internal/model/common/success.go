@@ -7,19 +7,44 @@ const (
 
 // Auth 成功相关常量
 const (
-	LOGIN_SUCCESS    = "登陆成功"
-	REGISTER_SUCCESS = "注册成功"
+	LOGIN_SUCCESS                = "登陆成功"
+	REGISTER_SUCCESS             = "注册成功"
+	REFRESH_TOKEN_SUCCESS        = "刷新令牌成功"
+	LOGOUT_SUCCESS               = "已退出登录"
+	GET_SESSIONS_SUCCESS         = "获取会话列表成功"
+	REVOKE_SESSION_SUCCESS       = "会话已撤销"
+	REVOKE_ALL_SESSIONS_SUCCESS  = "已撤销所有会话"
+	CREATE_ACCESS_TOKEN_SUCCESS  = "创建访问令牌成功"
+	GET_ACCESS_TOKENS_SUCCESS    = "获取访问令牌列表成功"
+	DELETE_ACCESS_TOKEN_SUCCESS  = "访问令牌已删除"
+	GET_TWO_FACTOR_SUCCESS       = "获取两步验证状态成功"
+	SETUP_TWO_FACTOR_SUCCESS     = "请使用身份验证器扫描二维码"
+	ENABLE_TWO_FACTOR_SUCCESS    = "已开启两步验证，请妥善保存恢复码"
+	DISABLE_TWO_FACTOR_SUCCESS   = "已关闭两步验证"
+	RECOVERY_CODES_SUCCESS       = "已重新生成恢复码，旧的恢复码已失效"
+	TWO_FACTOR_CHALLENGE         = "请输入两步验证码"
+	GET_OIDC_PROVIDERS_SUCCESS   = "获取身份提供者成功"
+	OIDC_AUTHORIZE_SUCCESS       = "请前往身份提供者完成授权"
+	GET_OIDC_IDENTITIES_SUCCESS  = "获取关联身份成功"
+	DELETE_OIDC_IDENTITY_SUCCESS = "已取消关联身份"
+	GET_LOCKOUTS_SUCCESS         = "获取锁定记录成功"
+	CLEAR_LOCKOUT_SUCCESS        = "已解除锁定"
 )
 
 // Echo 成功相关常量
 const (
-	POST_ECHO_SUCCESS         = "发布Echo成功！"
-	GET_ECHOS_BY_PAGE_SUCCESS = "获取Echos成功！"
-	DELETE_ECHO_SUCCESS       = "删除Echo成功"
-	GET_TODAY_ECHOS_SUCCESS   = "获取当日Echos成功"
-	UPDATE_ECHO_SUCCESS       = "更新Echo成功"
-	LIKE_ECHO_SUCCESS         = "点赞Echo成功"
-	GET_ECHO_BY_ID_SUCCESS    = "获取Echo成功"
+	POST_ECHO_SUCCESS          = "发布Echo成功！"
+	GET_ECHOS_BY_PAGE_SUCCESS  = "获取Echos成功！"
+	DELETE_ECHO_SUCCESS        = "删除Echo成功"
+	GET_TODAY_ECHOS_SUCCESS    = "获取当日Echos成功"
+	UPDATE_ECHO_SUCCESS        = "更新Echo成功"
+	LIKE_ECHO_SUCCESS          = "点赞Echo成功"
+	GET_ECHO_BY_ID_SUCCESS     = "获取Echo成功"
+	GET_ECHO_ARCHIVE_SUCCESS   = "获取归档成功"
+	GET_ECHOS_BY_MONTH_SUCCESS = "获取归档Echos成功"
+	GET_ON_THIS_DAY_SUCCESS    = "获取那年今日成功"
+	GET_DAILY_DIGEST_SUCCESS   = "获取每日摘要成功"
+	GET_ECHO_STATS_SUCCESS     = "获取发布统计成功"
 )
 
 // Common 成功相关常量
@@ -54,6 +79,8 @@ const (
 	GET_USER_SUCCESS      = "获取用户列表成功"
 	GET_USER_INFO_SUCCESS = "获取用户信息成功"
 	DELETE_USER_SUCCESS   = "删除用户成功"
+	UPDATE_ROLE_SUCCESS   = "更新用户角色成功"
+	GET_PROFILE_SUCCESS   = "获取用户主页成功"
 )
 
 // Conenct 成功相关常量
@@ -63,6 +90,18 @@ const (
 	DELETE_CONNECT_SUCCESS     = "连接已取消"
 	GET_CONNECT_INFO_SUCCESS   = "获取 Connect 信息成功"
 	GET_CONNECTED_LIST_SUCCESS = "获取连接列表成功"
+	GET_TIMELINE_SUCCESS       = "获取时间线成功"
+	ACCEPT_CONNECT_SUCCESS     = "已接受连接请求"
+	REJECT_CONNECT_SUCCESS     = "已拒绝连接请求"
+	HANDSHAKE_SUCCESS          = "握手成功"
+	GET_CONNECTS_HEALTH        = "获取连接状态成功"
+	REFRESH_CONNECTS_HEALTH    = "刷新连接状态成功"
+	GET_PEERS_SUCCESS          = "获取实例连接列表成功"
+	GET_SUGGESTIONS_SUCCESS    = "获取推荐实例成功"
+	ACCEPT_SUGGESTION_SUCCESS  = "已向推荐实例发起连接"
+	DISMISS_SUGGESTION_SUCCESS = "已忽略推荐实例"
+	DISCOVER_PEERS_SUCCESS     = "已开始发现实例"
+	IMPORT_OPML_SUCCESS        = "导入连接完成"
 )
 
 // Backup 成功相关常量
@@ -71,3 +110,10 @@ const (
 	EXPORT_BACKUP_SUCCESS = "导出备份成功"
 	IMPORT_BACKUP_SUCCESS = "导入备份成功"
 )
+
+// Webmention 成功相关常量
+const (
+	GET_WEBMENTIONS_SUCCESS   = "获取 Webmention 列表成功"
+	UPDATE_WEBMENTION_SUCCESS = "审核 Webmention 成功"
+	DELETE_WEBMENTION_SUCCESS = "删除 Webmention 成功"
+)
@@ -1,17 +1,96 @@
 package model
 
+import "time"
+
 // Connect 定义可读取的连接信息
 type Connect struct {
-	ServerName  string `json:"server_name"`  // 服务器名称
-	ServerURL   string `json:"server_url"`   // 服务器地址
-	Logo        string `json:"logo"`         // 站点logo
-	TotalEchos  int    `json:"total_echos"`  // 总共发布数量
-	TodayEchos  int    `json:"today_echos"`  // 今日发布数量
-	SysUsername string `json:"sys_username"` // 系统管理员用户名
+	ServerName  string `json:"server_name"`          // 服务器名称
+	ServerURL   string `json:"server_url"`           // 服务器地址
+	Logo        string `json:"logo"`                 // 站点logo
+	TotalEchos  int    `json:"total_echos"`          // 总共发布数量
+	TodayEchos  int    `json:"today_echos"`          // 今日发布数量
+	SysUsername string `json:"sys_username"`         // 系统管理员用户名
+	PublicKey   string `json:"public_key,omitempty"` // 连接握手使用的公钥（Base64 编码的 Ed25519 公钥）
 }
 
 // Connected 定义添加的连接信息
 type Connected struct {
-	ID         uint   `gorm:"primaryKey" json:"id"`
-	ConnectURL string `json:"connect_url"` // 连接地址
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ConnectURL string    `json:"connect_url"`                                          // 连接地址
+	Direction  Direction `gorm:"type:varchar(16);default:outgoing" json:"direction"`   // 发起方向
+	State      State     `gorm:"type:varchar(16);default:accepted;index" json:"state"` // 连接状态（旧版本添加的连接视为已接受）
+	ServerName string    `gorm:"type:varchar(255)" json:"server_name,omitempty"`       // 对方实例的名称
+	PublicKey  string    `gorm:"type:varchar(128)" json:"public_key,omitempty"`        // 对方实例的公钥
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
+
+// Direction 连接的发起方向
+type Direction string
+
+const (
+	DirectionOutgoing Direction = "outgoing" // 本实例发起的连接
+	DirectionIncoming Direction = "incoming" // 对方实例发起的连接
+)
+
+// State 连接状态
+type State string
+
+const (
+	StatePending  State = "pending"  // 等待对方确认
+	StateAccepted State = "accepted" // 已接受
+	StateRejected State = "rejected" // 已拒绝
+	StateRevoked  State = "revoked"  // 已撤销
+)
+
+// HandshakeType 握手消息类型
+type HandshakeType string
+
+const (
+	HandshakeRequest HandshakeType = "request" // 请求建立连接
+	HandshakeAccept  HandshakeType = "accept"  // 接受连接请求
+	HandshakeReject  HandshakeType = "reject"  // 拒绝连接请求
+	HandshakeRevoke  HandshakeType = "revoke"  // 撤销连接
+)
+
+// HandshakeMessage 定义实例之间的握手消息（消息体使用发送方私钥签名）
+type HandshakeMessage struct {
+	Type       HandshakeType `json:"type"`
+	ServerURL  string        `json:"server_url"`            // 发送方地址
+	Target     string        `json:"target"`                // 接收方地址，防止消息被转发给其它实例
+	ServerName string        `json:"server_name,omitempty"` // 发送方名称
+	PublicKey  string        `json:"public_key,omitempty"`  // 发送方公钥（仅请求建立连接时携带）
+	Timestamp  int64         `json:"timestamp"`             // 发送时间（Unix 秒）
+	Nonce      string        `json:"nonce"`                 // 每条消息唯一的随机数，防止消息在有效期内被重放
+}
+
+// HandshakeResult 定义握手的处理结果
+type HandshakeResult struct {
+	State State `json:"state"` // 接收方记录的连接状态
+}
+
+// KeyPair 定义连接握手使用的 Ed25519 密钥对（Base64 编码）
+type KeyPair struct {
+	PrivateKey string `json:"private_key"`
+	PublicKey  string `json:"public_key"`
+}
+
+const (
+	// KeyPairKey 密钥对在键值表中的键
+	KeyPairKey = "connect_key_pair"
+
+	// SignatureHeader 握手消息签名所在的请求头
+	SignatureHeader = "X-Ech0-Signature"
+	// HandshakeMaxSkew 握手消息允许的最大时间偏差
+	HandshakeMaxSkew = 5 * time.Minute
+	// HandshakeTimeout 发送握手消息的超时时间
+	HandshakeTimeout = 10 * time.Second
+	// MaxHandshakeBodySize 握手消息的最大字节数
+	MaxHandshakeBodySize = 64 << 10
+	// HandshakeNonceBytes 握手消息 nonce 的随机字节数
+	HandshakeNonceBytes = 16
+	// MaxHandshakeNonceLength 接受的 nonce 最大长度
+	MaxHandshakeNonceLength = 64
+	// MaxSeenHandshakeNonces 记录的 nonce 数量上限，已满时拒绝新的握手消息
+	MaxSeenHandshakeNonces = 4096
+)
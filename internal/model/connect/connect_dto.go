@@ -0,0 +1,16 @@
+package model
+
+// TimelineQueryDto 用于分页获取聚合时间线的查询参数
+//
+// swagger:model TimelineQueryDto
+type TimelineQueryDto struct {
+	// 页码，从1开始
+	Page int `json:"page" form:"page"`
+
+	// 每页大小
+	PageSize int `json:"pageSize" form:"pageSize"`
+
+	// 只查看指定来源实例（连接地址）的 Echo
+	// example: https://memo.example.com
+	Source string `json:"source" form:"source"`
+}
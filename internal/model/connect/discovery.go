@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// PeerList 定义公开的机器可读连接列表，供其它实例发现新的实例
+type PeerList struct {
+	ServerName   string `json:"server_name"`  // 服务器名称
+	ServerURL    string `json:"server_url"`   // 服务器地址
+	Logo         string `json:"logo"`         // 站点logo
+	Discoverable bool   `json:"discoverable"` // 为 false 时（类似 robots.txt 的 noindex, nofollow）爬虫不应推荐本实例，也不应继续访问本实例的连接
+	Peers        []Peer `json:"peers"`        // 已建立的连接（不允许发现时为空）
+}
+
+// Peer 定义连接列表中的实例
+type Peer struct {
+	ServerName string `json:"server_name"` // 服务器名称
+	ServerURL  string `json:"server_url"`  // 服务器地址
+}
+
+// PeerSuggestion 定义通过爬取连接的连接发现的推荐实例
+type PeerSuggestion struct {
+	ID         uint             `gorm:"primaryKey" json:"id"`
+	ServerURL  string           `gorm:"type:varchar(512);uniqueIndex;not null" json:"server_url"` // 服务器地址
+	ServerName string           `gorm:"type:varchar(255)" json:"server_name"`                     // 服务器名称
+	Logo       string           `gorm:"type:varchar(512)" json:"logo"`                            // 站点logo
+	Via        string           `gorm:"type:varchar(512)" json:"via"`                             // 通过哪个实例发现
+	Depth      int              `json:"depth"`                                                    // 与当前实例之间的跳数
+	Status     SuggestionStatus `gorm:"type:varchar(16);default:new;index" json:"status"`         // 推荐状态
+	LastSeenAt time.Time        `json:"last_seen_at"`                                             // 最近一次被发现的时间
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+// SuggestionStatus 推荐实例的状态
+type SuggestionStatus string
+
+const (
+	SuggestionNew       SuggestionStatus = "new"       // 等待管理员处理
+	SuggestionDismissed SuggestionStatus = "dismissed" // 已忽略，之后再次发现时不会重新推荐
+)
+
+const (
+	// DiscoveryInterval 后台发现实例的间隔
+	DiscoveryInterval = 24 * time.Hour
+	// DiscoveryMaxDepth 从当前实例出发最多爬取的跳数（已建立的连接为第 1 跳）
+	DiscoveryMaxDepth = 3
+	// DiscoveryMaxInstances 每次发现最多访问的实例数量
+	DiscoveryMaxInstances = 100
+	// DiscoveryMaxPeers 每个实例最多跟随的连接数量
+	DiscoveryMaxPeers = 50
+	// DiscoveryRequestInterval 相邻两次请求之间的最小间隔
+	DiscoveryRequestInterval = time.Second
+	// SuggestionExpiry 超过该时间未再被发现的推荐实例会被清理
+	SuggestionExpiry = 7 * 24 * time.Hour
+	// DiscoveryUserAgent 发现实例时使用的 User-Agent，方便对方识别
+	DiscoveryUserAgent = "Ech0-Discovery"
+)
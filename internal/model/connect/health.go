@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// ConnectHealth 定义后台健康检查缓存的连接状态
+type ConnectHealth struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	ConnectID     uint       `gorm:"uniqueIndex;not null" json:"connect_id"` // 对应的连接 ID
+	ConnectURL    string     `gorm:"type:varchar(512)" json:"connect_url"`   // 连接地址
+	Info          Connect    `gorm:"type:text;serializer:json" json:"info"`  // 最近一次成功获取的连接信息
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`              // 最近一次检查的时间
+	LastSeenAt    *time.Time `json:"last_seen_at,omitempty"`                 // 最近一次检查成功的时间
+	LatencyMs     int64      `json:"latency_ms"`                             // 最近一次检查成功的响应耗时（毫秒）
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`  // 最近一次检查失败的原因
+	FailureStreak int        `gorm:"default:0" json:"failure_streak"`        // 连续失败次数
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+const (
+	// HealthCheckInterval 连接健康检查的间隔
+	HealthCheckInterval = 5 * time.Minute
+	// OfflineFailureStreak 连续失败达到该次数后视为离线，不再出现在连接信息中
+	OfflineFailureStreak = 3
+	// PeerRequestTimeout 请求其它实例接口的超时时间
+	PeerRequestTimeout = 5 * time.Second
+	// MaxPeerResponseSize 其它实例接口响应的最大字节数
+	MaxPeerResponseSize = 4 << 20
+)
@@ -0,0 +1,58 @@
+package model
+
+import "encoding/xml"
+
+// OPML 定义连接导入导出使用的 OPML 文档
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    OPMLHead `xml:"head"`
+	Body    OPMLBody `xml:"body"`
+}
+
+// OPMLHead 定义 OPML 文档头
+type OPMLHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+// OPMLBody 定义 OPML 文档主体
+type OPMLBody struct {
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+// OPMLOutline 定义 OPML 条目（阅读器导出的文件中条目可能按分类嵌套）
+type OPMLOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+// ImportResult 定义 OPML 导入结果
+type ImportResult struct {
+	Created    []string        `json:"created"`    // 已发起连接的实例地址
+	Duplicates []string        `json:"duplicates"` // 已存在的实例地址
+	Failed     []ImportFailure `json:"failed"`     // 导入失败的实例
+}
+
+// ImportFailure 定义导入失败的实例
+type ImportFailure struct {
+	URL    string `json:"url"`    // 实例地址
+	Reason string `json:"reason"` // 失败原因
+}
+
+const (
+	// OPMLVersion 导出的 OPML 版本
+	OPMLVersion = "2.0"
+	// OPMLContentType 导出的 OPML 内容类型
+	OPMLContentType = "text/x-opml; charset=utf-8"
+	// OPMLFileName 导出的 OPML 文件名
+	OPMLFileName = "ech0-connects.opml"
+	// MaxOPMLSize 导入的 OPML 文件的最大字节数
+	MaxOPMLSize = 1 << 20
+	// FeedPath 实例订阅源的路径
+	FeedPath = "/rss"
+)
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+// TimelineEcho 定义从已连接实例拉取并缓存的公开 Echo
+type TimelineEcho struct {
+	ID            uint              `gorm:"primaryKey" json:"id"`
+	SourceURL     string            `gorm:"type:varchar(512);not null;uniqueIndex:idx_timeline_source_echo" json:"source_url"` // 来源实例的连接地址
+	RemoteID      uint              `gorm:"not null;uniqueIndex:idx_timeline_source_echo" json:"remote_id"`                    // Echo 在来源实例中的 ID
+	URL           string            `gorm:"type:text" json:"url"`                                                              // Echo 在来源实例中的地址
+	ServerName    string            `gorm:"type:varchar(255)" json:"server_name"`                                              // 来源实例的名称
+	Logo          string            `gorm:"type:text" json:"logo"`                                                             // 来源实例的 Logo
+	Username      string            `gorm:"type:varchar(100)" json:"username,omitempty"`
+	Content       string            `gorm:"type:text" json:"content"`
+	Images        []echoModel.Image `gorm:"type:text;serializer:json" json:"images,omitempty"` // 图片（已转换为绝对地址）
+	Extension     string            `gorm:"type:text" json:"extension,omitempty"`
+	ExtensionType string            `gorm:"type:varchar(100)" json:"extension_type,omitempty"`
+	FavCount      int               `json:"fav_count"`
+	CreatedAt     time.Time         `gorm:"index" json:"created_at"` // 在来源实例中的发布时间
+	FetchedAt     time.Time         `json:"fetched_at"`              // 拉取时间
+}
+
+const (
+	// TimelineSyncInterval 时间线的同步间隔
+	TimelineSyncInterval = 10 * time.Minute
+	// TimelineFetchSize 每次从每个实例拉取的 Echo 数量
+	TimelineFetchSize = 30
+)
@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	webmentionModel "github.com/lin-snow/ech0/internal/model/webmention"
+)
 
 // Echo 定义Echo实体
 type Echo struct {
@@ -14,6 +18,8 @@ type Echo struct {
 	ExtensionType string    `gorm:"type:varchar(100)" json:"extension_type,omitempty"`
 	FavCount      int       `gorm:"default:0" json:"fav_count"`
 	CreatedAt     time.Time `json:"created_at"`
+
+	Webmentions []webmentionModel.Webmention `gorm:"-" json:"webmentions,omitempty"` // 已通过审核的 Webmention（仅在获取详情时返回）
 }
 
 // Message 定义Message实体
@@ -39,6 +45,65 @@ type Image struct {
 	ImageSource string `gorm:"type:varchar(20)" json:"image_source"`
 }
 
+// ArchiveMonth 定义归档中某年某月发布的Echo数量
+type ArchiveMonth struct {
+	Year  int `json:"year"`  // 年份
+	Month int `json:"month"` // 月份
+	Count int `json:"count"` // Echo数量
+}
+
+// DailyDigest 定义每日摘要
+type DailyDigest struct {
+	Date      string `json:"date"`                  // 日期 (YYYY-MM-DD)
+	Today     []Echo `json:"today"`                 // 当日发布的Echo
+	OnThisDay []Echo `json:"on_this_day,omitempty"` // 往年同一天发布的Echo
+}
+
+// PeriodCount 定义某个时间段（周或月）内发布的Echo数量
+type PeriodCount struct {
+	Period string `json:"period"` // 时间段，周为当周周一的日期 (YYYY-MM-DD)，月为 YYYY-MM
+	Count  int    `json:"count"`  // Echo数量
+}
+
+// ExtensionCount 定义某种扩展类型的Echo数量
+type ExtensionCount struct {
+	Type  string `json:"type"`  // 扩展类型
+	Count int64  `json:"count"` // Echo数量
+}
+
+// TagCount 定义某个标签出现的次数
+type TagCount struct {
+	Tag   string `json:"tag"`   // 标签（不含 #）
+	Count int    `json:"count"` // 使用该标签的Echo数量
+}
+
+// EchoStats 定义发布统计数据
+type EchoStats struct {
+	TotalEchos          int64            `json:"total_echos"`          // Echo总数
+	TotalImages         int64            `json:"total_images"`         // 图片总数
+	EchosWithImages     int64            `json:"echos_with_images"`    // 带图片的Echo数量
+	AverageLength       float64          `json:"average_length"`       // 平均内容长度（字符数）
+	CurrentStreak       int              `json:"current_streak"`       // 当前连续发布天数
+	LongestStreak       int              `json:"longest_streak"`       // 最长连续发布天数
+	LongestStreakStart  string           `json:"longest_streak_start"` // 最长连续发布的开始日期
+	LongestStreakEnd    string           `json:"longest_streak_end"`   // 最长连续发布的结束日期
+	Weekly              []PeriodCount    `json:"weekly"`               // 最近若干周每周发布数量
+	Monthly             []PeriodCount    `json:"monthly"`              // 最近若干月每月发布数量
+	HourDistribution    [24]int          `json:"hour_distribution"`    // 按小时 (0-23) 分布
+	WeekdayDistribution [7]int           `json:"weekday_distribution"` // 按星期分布（0 为周日）
+	ExtensionTypes      []ExtensionCount `json:"extension_types"`      // 扩展类型分布
+	TopTags             []TagCount       `json:"top_tags"`             // 最常用的标签
+}
+
+const (
+	// StatsRecentWeeks 统计最近多少周的发布数量
+	StatsRecentWeeks = 12
+	// StatsRecentMonths 统计最近多少个月的发布数量
+	StatsRecentMonths = 12
+	// StatsTopTagsLimit 返回最常用标签的数量
+	StatsTopTagsLimit = 10
+)
+
 const (
 	Extension_MUSIC      = "MUSIC"
 	Extension_VIDEO      = "VIDEO"
@@ -0,0 +1,23 @@
+package model
+
+// DigestQueryDto 用于获取那年今日、每日摘要的查询参数
+//
+// swagger:model DigestQueryDto
+type DigestQueryDto struct {
+	// IANA 时区名称，为空时使用站点设置的时区
+	// example: Asia/Shanghai
+	Timezone string `json:"timezone" form:"timezone"`
+
+	// 每日摘要中是否包含那年今日
+	// example: true
+	Memories bool `json:"memories" form:"memories"`
+}
+
+// StatsQueryDto 用于获取发布统计的查询参数
+//
+// swagger:model StatsQueryDto
+type StatsQueryDto struct {
+	// IANA 时区名称，为空时使用站点设置的时区
+	// example: Asia/Shanghai
+	Timezone string `json:"timezone" form:"timezone"`
+}
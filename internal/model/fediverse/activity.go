@@ -0,0 +1,124 @@
+package model
+
+// WebFinger 定义 WebFinger 响应（JRD）
+type WebFinger struct {
+	Subject string          `json:"subject"`
+	Aliases []string        `json:"aliases,omitempty"`
+	Links   []WebFingerLink `json:"links"`
+}
+
+// WebFingerLink 定义 WebFinger 响应中的链接
+type WebFingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
+
+// Actor 定义 ActivityPub Actor 文档
+type Actor struct {
+	Context                   []string   `json:"@context"`
+	ID                        string     `json:"id"`
+	Type                      string     `json:"type"`
+	PreferredUsername         string     `json:"preferredUsername"`
+	Name                      string     `json:"name"`
+	Summary                   string     `json:"summary,omitempty"`
+	URL                       string     `json:"url"`
+	Inbox                     string     `json:"inbox"`
+	Outbox                    string     `json:"outbox"`
+	Followers                 string     `json:"followers"`
+	Icon                      *Image     `json:"icon,omitempty"`
+	PublicKey                 PublicKey  `json:"publicKey"`
+	Endpoints                 *Endpoints `json:"endpoints,omitempty"`
+	ManuallyApprovesFollowers bool       `json:"manuallyApprovesFollowers"`
+	Discoverable              bool       `json:"discoverable"`
+}
+
+// PublicKey 定义 Actor 的公钥
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+// Endpoints 定义 Actor 的附加端点
+type Endpoints struct {
+	SharedInbox string `json:"sharedInbox,omitempty"`
+}
+
+// Image 定义图片对象（头像、附件）
+type Image struct {
+	Type      string `json:"type"`
+	MediaType string `json:"mediaType,omitempty"`
+	URL       string `json:"url"`
+}
+
+// Note 定义 Echo 对应的 Note 对象
+type Note struct {
+	Context      any      `json:"@context,omitempty"`
+	ID           string   `json:"id"`
+	Type         string   `json:"type"`
+	AttributedTo string   `json:"attributedTo"`
+	Content      string   `json:"content"`
+	Published    string   `json:"published"`
+	Updated      string   `json:"updated,omitempty"`
+	URL          string   `json:"url"`
+	To           []string `json:"to"`
+	Cc           []string `json:"cc,omitempty"`
+	Attachment   []Image  `json:"attachment,omitempty"`
+}
+
+// Tombstone 定义已删除对象的墓碑
+type Tombstone struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// Activity 定义发出的 ActivityPub 活动
+type Activity struct {
+	Context   any      `json:"@context,omitempty"`
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	Actor     string   `json:"actor"`
+	Published string   `json:"published,omitempty"`
+	To        []string `json:"to,omitempty"`
+	Cc        []string `json:"cc,omitempty"`
+	Object    any      `json:"object"`
+}
+
+// IncomingActivity 定义收件箱收到的活动（object 可能是链接或内嵌对象）
+type IncomingActivity struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Actor  string `json:"actor"`
+	Object any    `json:"object"`
+}
+
+// RemoteActor 定义从远程实例获取到的 Actor（只解析需要的字段）
+type RemoteActor struct {
+	ID        string    `json:"id"`
+	Inbox     string    `json:"inbox"`
+	PublicKey PublicKey `json:"publicKey"`
+	Endpoints Endpoints `json:"endpoints"`
+}
+
+// OrderedCollection 定义有序集合（发件箱、关注者）
+type OrderedCollection struct {
+	Context    string `json:"@context"`
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	TotalItems int64  `json:"totalItems"`
+	First      string `json:"first,omitempty"`
+	Last       string `json:"last,omitempty"`
+}
+
+// OrderedCollectionPage 定义有序集合的分页
+type OrderedCollectionPage struct {
+	Context      string     `json:"@context"`
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	PartOf       string     `json:"partOf"`
+	TotalItems   int64      `json:"totalItems"`
+	Next         string     `json:"next,omitempty"`
+	Prev         string     `json:"prev,omitempty"`
+	OrderedItems []Activity `json:"orderedItems"`
+}
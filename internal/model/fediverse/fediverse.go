@@ -0,0 +1,86 @@
+package model
+
+import "time"
+
+// Follower 定义关注本实例的联邦宇宙账号
+type Follower struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ActorID     string    `gorm:"type:varchar(512);uniqueIndex;not null" json:"actor_id"` // 关注者的 Actor 地址
+	Inbox       string    `gorm:"type:varchar(512);not null" json:"inbox"`                // 关注者的收件箱
+	SharedInbox string    `gorm:"type:varchar(512)" json:"shared_inbox,omitempty"`        // 关注者所在实例的共享收件箱
+	FollowID    string    `gorm:"type:varchar(512)" json:"follow_id,omitempty"`           // 关注活动的 ID，用于匹配只引用 ID 的取消关注
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Delivery 定义待投递的 ActivityPub 活动（投递队列）
+type Delivery struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Inbox         string    `gorm:"type:varchar(512);not null" json:"inbox"` // 目标收件箱
+	Payload       string    `gorm:"type:text;not null" json:"payload"`       // 活动内容（JSON）
+	Attempts      int       `gorm:"default:0" json:"attempts"`               // 已尝试投递的次数
+	NextAttemptAt time.Time `gorm:"index" json:"next_attempt_at"`            // 下次尝试投递的时间
+	LastError     string    `gorm:"type:text" json:"last_error,omitempty"`   // 最近一次投递失败的原因
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// KeyPair 定义实例 Actor 的签名密钥对（PEM 格式）
+type KeyPair struct {
+	PrivateKey string `json:"private_key"`
+	PublicKey  string `json:"public_key"`
+}
+
+const (
+	// KeyPairKey 密钥对在键值表中的键
+	KeyPairKey = "fediverse_key_pair"
+
+	// ActivityContentType ActivityPub 的内容类型
+	ActivityContentType = "application/activity+json"
+	// LDJSONContentType ActivityPub 可接受的 JSON-LD 内容类型
+	LDJSONContentType = `application/ld+json; profile="https://www.w3.org/ns/activitystreams"`
+	// JRDContentType WebFinger 的内容类型
+	JRDContentType = "application/jrd+json"
+
+	// ActivityStreamsContext ActivityStreams 的 JSON-LD 上下文
+	ActivityStreamsContext = "https://www.w3.org/ns/activitystreams"
+	// SecurityContext 公钥相关的 JSON-LD 上下文
+	SecurityContext = "https://w3id.org/security/v1"
+	// PublicAddress 表示公开可见的特殊地址
+	PublicAddress = "https://www.w3.org/ns/activitystreams#Public"
+
+	// OutboxPageSize 发件箱每页的活动数量
+	OutboxPageSize = 20
+	// MaxInboxBodySize 收件箱请求体的最大字节数
+	MaxInboxBodySize = 1 << 20
+
+	// MaxDeliveryAttempts 最大投递次数，超过后放弃投递
+	MaxDeliveryAttempts = 8
+	// DeliveryRetryBaseDelay 投递失败后首次重试的等待时间，之后每次翻倍
+	DeliveryRetryBaseDelay = time.Minute
+	// DeliveryRetryMaxDelay 投递失败后重试的最长等待时间
+	DeliveryRetryMaxDelay = 12 * time.Hour
+	// DeliveryPollInterval 投递队列的轮询间隔
+	DeliveryPollInterval = 30 * time.Second
+	// DeliveryBatchSize 每次从队列中取出的投递数量
+	DeliveryBatchSize = 50
+
+	// RequestTimeout 与其它实例通信的超时时间
+	RequestTimeout = 10 * time.Second
+	// RemoteActorCacheTTL 远程 Actor（包括公钥）的缓存时间
+	RemoteActorCacheTTL = time.Hour
+	// RemoteActorRefreshInterval 签名校验失败时，缓存超过该时间才重新获取 Actor（应对密钥轮换）
+	RemoteActorRefreshInterval = time.Minute
+	// MaxCachedRemoteActors 最多缓存的远程 Actor 数量
+	MaxCachedRemoteActors = 1024
+)
+
+// ActivityType ActivityPub 活动类型
+type ActivityType string
+
+const (
+	ActivityCreate ActivityType = "Create"
+	ActivityUpdate ActivityType = "Update"
+	ActivityDelete ActivityType = "Delete"
+	ActivityFollow ActivityType = "Follow"
+	ActivityAccept ActivityType = "Accept"
+	ActivityUndo   ActivityType = "Undo"
+)
@@ -0,0 +1,81 @@
+package model
+
+// Request 定义统一后的 Micropub 请求（表单请求和 JSON 请求都会转换为该结构）
+type Request struct {
+	Action     string           `json:"action,omitempty"`     // 操作类型，为空时表示创建
+	URL        string           `json:"url,omitempty"`        // 更新、删除时的目标地址
+	Type       []string         `json:"type,omitempty"`       // 创建时的对象类型，只支持 h-entry
+	Properties map[string][]any `json:"properties,omitempty"` // 创建时的属性
+	Replace    map[string][]any `json:"replace,omitempty"`    // 更新时需要替换的属性
+	Add        map[string][]any `json:"add,omitempty"`        // 更新时需要追加的属性值
+	Delete     any              `json:"delete,omitempty"`     // 更新时需要删除的属性（属性名列表，或属性名到属性值的映射）
+}
+
+// Source 定义 q=source 的响应
+type Source struct {
+	Type       []string         `json:"type,omitempty"`
+	Properties map[string][]any `json:"properties"`
+}
+
+// Config 定义 q=config 的响应
+type Config struct {
+	MediaEndpoint string     `json:"media-endpoint"`
+	SyndicateTo   []any      `json:"syndicate-to"`
+	PostTypes     []PostType `json:"post-types"`
+	Q             []string   `json:"q"`
+}
+
+// PostType 定义支持发布的内容类型
+type PostType struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// Error 定义 Micropub 的错误响应
+type Error struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+const (
+	// EndpointPath Micropub 端点的路径
+	EndpointPath = "/micropub"
+	// MediaEndpointPath 媒体端点的路径
+	MediaEndpointPath = "/micropub/media"
+
+	// EntryType 支持创建的对象类型
+	EntryType = "h-entry"
+)
+
+// Micropub 操作类型
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionDelete   = "delete"
+	ActionUndelete = "undelete"
+)
+
+// Micropub 查询类型
+const (
+	QueryConfig      = "config"
+	QuerySource      = "source"
+	QuerySyndicateTo = "syndicate-to"
+)
+
+// Micropub 错误码
+const (
+	ErrorInvalidRequest    = "invalid_request"
+	ErrorUnauthorized      = "unauthorized"
+	ErrorForbidden         = "forbidden"
+	ErrorInsufficientScope = "insufficient_scope"
+)
+
+// Micropub 属性的可见性
+const (
+	VisibilityPublic   = "public"
+	VisibilityPrivate  = "private"
+	VisibilityUnlisted = "unlisted"
+
+	// PostStatusDraft 草稿状态，按私密 Echo 处理
+	PostStatusDraft = "draft"
+)
@@ -10,6 +10,9 @@ type SystemSetting struct {
 	MetingAPI     string `json:"meting_api"`     // Meting API 地址
 	CustomCSS     string `json:"custom_css"`     // 自定义 CSS
 	CustomJS      string `json:"custom_js"`      // 自定义 JS
+	Timezone      string `json:"timezone"`       // 站点时区（IANA 时区名称），为空时使用服务器时区
+	// DisableDiscovery 是否禁止其它实例通过连接列表发现本实例
+	DisableDiscovery bool `json:"disable_discovery"`
 }
 
 // CommentSetting 定义评论设置实体
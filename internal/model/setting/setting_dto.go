@@ -11,6 +11,9 @@ type SystemSettingDto struct {
 	CommentAPI    string `json:"comment_api"`    // 评论 API 地址
 	CustomCSS     string `json:"custom_css"`     // 自定义 CSS
 	CustomJS      string `json:"custom_js"`      // 自定义 JS
+	Timezone      string `json:"timezone"`       // 站点时区（IANA 时区名称）
+	// DisableDiscovery 是否禁止其它实例通过连接列表发现本实例
+	DisableDiscovery bool `json:"disable_discovery"`
 }
 
 type CommentSettingDto struct {
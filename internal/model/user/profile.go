@@ -0,0 +1,19 @@
+package model
+
+// UserProfile 用户的公开资料
+type UserProfile struct {
+	ID          uint         `json:"id"`
+	Username    string       `json:"username"`
+	DisplayName string       `json:"display_name"`
+	Avatar      string       `json:"avatar"`
+	Bio         string       `json:"bio"`
+	Role        Role         `json:"role"`
+	Stats       ProfileStats `json:"stats"`
+}
+
+// ProfileStats 用户发布内容的统计（访客只统计公开 Echo）
+type ProfileStats struct {
+	EchoCount  int64 `json:"echo_count"`  // Echo 数量
+	ImageCount int64 `json:"image_count"` // 图片数量
+	LikeCount  int64 `json:"like_count"`  // 收到的点赞数
+}
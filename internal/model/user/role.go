@@ -0,0 +1,39 @@
+package model
+
+// Role 用户角色
+type Role string
+
+const (
+	// RoleOwner 站长（系统管理员），拥有全部权限，只有第一个注册的用户是站长
+	RoleOwner Role = "owner"
+	// RoleAdmin 管理员，可以管理站点、用户和所有 Echo
+	RoleAdmin Role = "admin"
+	// RoleAuthor 作者，可以发布 Echo，只能编辑和删除自己的 Echo
+	RoleAuthor Role = "author"
+	// RoleReader 读者，只能浏览公开内容
+	RoleReader Role = "reader"
+)
+
+// roleRanks 角色的级别，级别高的用户才能管理级别低的用户
+var roleRanks = map[Role]int{
+	RoleOwner:  4,
+	RoleAdmin:  3,
+	RoleAuthor: 2,
+	RoleReader: 1,
+}
+
+// IsValid 检查角色是否有效
+func (role Role) IsValid() bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// IsAdmin 检查角色是否为管理员（站长或管理员）
+func (role Role) IsAdmin() bool {
+	return role == RoleOwner || role == RoleAdmin
+}
+
+// Rank 获取角色的级别，无效的角色为 0
+func (role Role) Rank() int {
+	return roleRanks[role]
+}
@@ -2,6 +2,9 @@ package model
 
 const (
 	USER_NOT_EXISTS_ID = 0
+
+	MaxDisplayNameLength = 32  // 昵称的最大长度（字符数）
+	MaxBioLength         = 280 // 个人简介的最大长度（字符数）
 )
 
 // User 定义用户实体
@@ -9,6 +12,16 @@ type User struct {
 	ID       uint   `gorm:"primaryKey" json:"id"`
 	Username string `gorm:"size:255;not null;unique" json:"username"`
 	Password string `gorm:"size:255;not null" json:"password"`
-	IsAdmin  bool   `gorm:"bool" json:"is_admin"`
+	Role     Role   `gorm:"type:varchar(16);index" json:"role"`
+	IsAdmin  bool   `gorm:"bool" json:"is_admin"` // 由角色决定，保留用于兼容
 	Avatar   string `gorm:"size:255" json:"avatar"`
+
+	DisplayName string `gorm:"size:255" json:"display_name"` // 昵称，为空时显示用户名
+	Bio         string `gorm:"type:text" json:"bio"`         // 个人简介
+}
+
+// SetRole 设置用户角色，并同步是否为管理员
+func (user *User) SetRole(role Role) {
+	user.Role = role
+	user.IsAdmin = role.IsAdmin()
 }
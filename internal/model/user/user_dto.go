@@ -12,6 +12,10 @@ type UserInfoDto struct {
 	// example: 123456
 	Password string `json:"password"`
 
+	// 当前密码，修改密码时必须提供
+	// example: 654321
+	CurrentPassword string `json:"current_password"`
+
 	// 是否为管理员
 	// example: false
 	IsAdmin bool `json:"is_admin"`
@@ -19,4 +23,21 @@ type UserInfoDto struct {
 	// 头像地址
 	// example: https://example.com/avatar.png
 	Avatar string `json:"avatar"`
+
+	// 昵称，不传时不修改，传空字符串时清空
+	// example: Lin Snow
+	DisplayName *string `json:"display_name"`
+
+	// 个人简介，不传时不修改，传空字符串时清空
+	// example: 记录生活的碎片
+	Bio *string `json:"bio"`
+}
+
+// UserRoleDto 修改用户角色的数据传输对象
+//
+// swagger:model UserRoleDto
+type UserRoleDto struct {
+	// 角色，可能的值为 "admin"、"author" 或 "reader"
+	// example: author
+	Role Role `json:"role" binding:"required"`
 }
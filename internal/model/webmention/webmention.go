@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Webmention 定义收到的 Webmention
+type Webmention struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EchoID     uint      `gorm:"index;not null" json:"echo_id"`                                                     // 被提及的 Echo
+	Source     string    `gorm:"type:varchar(512);uniqueIndex:idx_webmention_source_target;not null" json:"source"` // 提及来源页面
+	Target     string    `gorm:"type:varchar(512);uniqueIndex:idx_webmention_source_target;not null" json:"target"` // 被提及的页面
+	Title      string    `gorm:"type:varchar(255)" json:"title,omitempty"`                                          // 来源页面标题
+	Excerpt    string    `gorm:"type:text" json:"excerpt,omitempty"`                                                // 来源页面摘要
+	AuthorName string    `gorm:"type:varchar(255)" json:"author_name,omitempty"`                                    // 来源页面作者
+	Status     Status    `gorm:"type:varchar(16);index;default:pending" json:"status"`                              // 审核状态
+	VerifiedAt time.Time `json:"verified_at"`                                                                       // 最近一次验证通过的时间
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Status Webmention 的审核状态
+type Status string
+
+const (
+	StatusPending  Status = "pending"  // 待审核
+	StatusApproved Status = "approved" // 已通过
+	StatusRejected Status = "rejected" // 已拒绝
+)
+
+const (
+	// EndpointPath 接收 Webmention 的路径
+	EndpointPath = "/webmention"
+	// SourcePathPrefix 发送 Webmention 时作为 source 的 Echo 页面路径前缀（服务端渲染，包含 h-entry）
+	SourcePathPrefix = "/webmention/echos/"
+
+	// MaxBodySize 获取远程页面时读取的最大字节数
+	MaxBodySize = 1 << 20
+	// MaxExcerptLength 摘要的最大字符数
+	MaxExcerptLength = 280
+	// RequestTimeout 请求远程页面的超时时间
+	RequestTimeout = 10 * time.Second
+	// Workers 同时验证和发送 Webmention 的协程数量
+	Workers = 4
+	// QueueSize 最多等待验证或发送的任务数量，超过时拒绝新的任务
+	QueueSize = 256
+)
@@ -0,0 +1,19 @@
+package model
+
+// WebmentionDto 定义接收 Webmention 的请求
+type WebmentionDto struct {
+	Source string `form:"source"`
+	Target string `form:"target"`
+}
+
+// WebmentionQueryDto 定义查询 Webmention 列表的请求
+type WebmentionQueryDto struct {
+	Status   Status `form:"status"`   // 按审核状态过滤，为空时返回全部
+	Page     int    `form:"page"`     // 页码，从1开始
+	PageSize int    `form:"pageSize"` // 每页大小
+}
+
+// UpdateStatusDto 定义审核 Webmention 的请求
+type UpdateStatusDto struct {
+	Status Status `json:"status" binding:"required"`
+}
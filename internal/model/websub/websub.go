@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Subscription 定义 WebSub 订阅
+type Subscription struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Topic        string    `gorm:"type:varchar(512);uniqueIndex:idx_websub_topic_callback;not null" json:"topic"`    // 订阅的订阅源地址
+	Callback     string    `gorm:"type:varchar(512);uniqueIndex:idx_websub_topic_callback;not null" json:"callback"` // 订阅者的回调地址
+	Secret       string    `gorm:"type:varchar(200)" json:"-"`                                                       // 用于签名推送内容的密钥
+	LeaseSeconds int       `json:"lease_seconds"`                                                                    // 订阅有效期（秒）
+	ExpiresAt    time.Time `gorm:"index" json:"expires_at"`                                                          // 订阅过期时间
+	ContentHash  string    `gorm:"type:varchar(64)" json:"-"`                                                        // 最近一次推送的内容摘要
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SubscriptionMode 订阅请求的类型
+type SubscriptionMode string
+
+const (
+	ModeSubscribe   SubscriptionMode = "subscribe"
+	ModeUnsubscribe SubscriptionMode = "unsubscribe"
+)
+
+const (
+	// DefaultLeaseSeconds 默认订阅有效期（10 天）
+	DefaultLeaseSeconds = 10 * 24 * 60 * 60
+	// MinLeaseSeconds 最短订阅有效期（1 小时）
+	MinLeaseSeconds = 60 * 60
+	// MaxLeaseSeconds 最长订阅有效期（30 天）
+	MaxLeaseSeconds = 30 * 24 * 60 * 60
+	// MaxSecretLength 订阅密钥的最大长度（规范要求小于 200 字节）
+	MaxSecretLength = 199
+
+	// NotifyInterval 定期检查订阅源变化的间隔（同时用于推送失败后的重试）
+	NotifyInterval = 5 * time.Minute
+	// RequestTimeout 验证订阅与推送内容的超时时间
+	RequestTimeout = 10 * time.Second
+	// VerifyWorkers 同时验证订阅者意图的协程数量
+	VerifyWorkers = 4
+	// VerifyQueueSize 最多等待验证的订阅请求数量，超过时拒绝新的请求
+	VerifyQueueSize = 256
+)
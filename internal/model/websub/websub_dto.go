@@ -0,0 +1,10 @@
+package model
+
+// SubscriptionDto 定义 WebSub 订阅请求
+type SubscriptionDto struct {
+	Mode         SubscriptionMode `form:"hub.mode"`
+	Topic        string           `form:"hub.topic"`
+	Callback     string           `form:"hub.callback"`
+	LeaseSeconds int              `form:"hub.lease_seconds"`
+	Secret       string           `form:"hub.secret"`
+}
@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	"github.com/lin-snow/ech0/internal/transaction"
+	"gorm.io/gorm"
+)
+
+type AuthRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthRepository(db *gorm.DB) AuthRepositoryInterface {
+	return &AuthRepository{
+		db: db,
+	}
+}
+
+// getDB 从上下文中获取事务
+func (authRepository *AuthRepository) getDB(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(transaction.TxKey).(*gorm.DB); ok {
+		return tx
+	}
+	return authRepository.db
+}
+
+// CreateSession 创建会话
+func (authRepository *AuthRepository) CreateSession(ctx context.Context, session *model.Session) error {
+	return authRepository.getDB(ctx).Create(session).Error
+}
+
+// GetSessionByID 根据 ID 获取会话
+func (authRepository *AuthRepository) GetSessionByID(id string) (model.Session, error) {
+	var session model.Session
+	err := authRepository.db.Where("id = ?", id).First(&session).Error
+	return session, err
+}
+
+// GetSessionByRefreshHash 根据当前刷新令牌的哈希获取会话
+func (authRepository *AuthRepository) GetSessionByRefreshHash(hash string) (model.Session, error) {
+	var session model.Session
+	err := authRepository.db.Where("refresh_token_hash = ?", hash).First(&session).Error
+	return session, err
+}
+
+// GetSessionByPreviousHash 根据上一个刷新令牌的哈希获取会话
+func (authRepository *AuthRepository) GetSessionByPreviousHash(hash string) (model.Session, error) {
+	var session model.Session
+	err := authRepository.db.Where("previous_token_hash = ?", hash).First(&session).Error
+	return session, err
+}
+
+// UpdateSession 更新会话
+func (authRepository *AuthRepository) UpdateSession(ctx context.Context, session *model.Session) error {
+	return authRepository.getDB(ctx).Save(session).Error
+}
+
+// TouchSession 更新会话最近使用的时间
+func (authRepository *AuthRepository) TouchSession(ctx context.Context, id string, lastUsedAt time.Time) error {
+	return authRepository.getDB(ctx).Model(&model.Session{}).
+		Where("id = ?", id).
+		Update("last_used_at", lastUsedAt).Error
+}
+
+// GetActiveSessions 获取用户未撤销且未过期的会话，最近使用的排在前面
+func (authRepository *AuthRepository) GetActiveSessions(userID uint) ([]model.Session, error) {
+	var sessions []model.Session
+	if err := authRepository.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeUserSessions 撤销用户的所有会话
+func (authRepository *AuthRepository) RevokeUserSessions(ctx context.Context, userID uint) error {
+	return authRepository.getDB(ctx).Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeOtherSessions 撤销用户除指定会话以外的所有会话
+func (authRepository *AuthRepository) RevokeOtherSessions(ctx context.Context, userID uint, keepID string) error {
+	return authRepository.getDB(ctx).Model(&model.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteStaleSessions 删除已过期或已撤销的会话
+func (authRepository *AuthRepository) DeleteStaleSessions(ctx context.Context) error {
+	return authRepository.getDB(ctx).
+		Where("expires_at < ? OR revoked_at IS NOT NULL", time.Now()).
+		Delete(&model.Session{}).Error
+}
+
+// GetSigningKeys 获取所有签名密钥，最新创建的排在前面
+func (authRepository *AuthRepository) GetSigningKeys() ([]model.SigningKey, error) {
+	var keys []model.SigningKey
+	if err := authRepository.db.Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// CreateSigningKey 创建签名密钥
+func (authRepository *AuthRepository) CreateSigningKey(ctx context.Context, key *model.SigningKey) error {
+	return authRepository.getDB(ctx).Create(key).Error
+}
+
+// RetireSigningKeys 将当前用于签发的密钥标记为已轮换
+func (authRepository *AuthRepository) RetireSigningKeys(ctx context.Context, retiredAt time.Time) error {
+	return authRepository.getDB(ctx).Model(&model.SigningKey{}).
+		Where("active = ?", true).
+		Updates(map[string]any{"active": false, "retired_at": retiredAt}).Error
+}
+
+// DeleteRetiredSigningKeys 删除在指定时间之前被轮换的密钥
+func (authRepository *AuthRepository) DeleteRetiredSigningKeys(ctx context.Context, before time.Time) error {
+	return authRepository.getDB(ctx).
+		Where("active = ? AND retired_at < ?", false, before).
+		Delete(&model.SigningKey{}).Error
+}
+
+// GetAccessTokens 获取用户的个人访问令牌，最新创建的排在前面
+func (authRepository *AuthRepository) GetAccessTokens(userID uint) ([]model.AccessToken, error) {
+	var tokens []model.AccessToken
+	if err := authRepository.db.Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// GetAccessTokenByID 根据 ID 获取个人访问令牌
+func (authRepository *AuthRepository) GetAccessTokenByID(id uint) (model.AccessToken, error) {
+	var token model.AccessToken
+	err := authRepository.db.First(&token, id).Error
+	return token, err
+}
+
+// GetAccessTokenByHash 根据令牌的哈希获取个人访问令牌
+func (authRepository *AuthRepository) GetAccessTokenByHash(hash string) (model.AccessToken, error) {
+	var token model.AccessToken
+	err := authRepository.db.Where("token_hash = ?", hash).First(&token).Error
+	return token, err
+}
+
+// CreateAccessToken 创建个人访问令牌
+func (authRepository *AuthRepository) CreateAccessToken(ctx context.Context, token *model.AccessToken) error {
+	return authRepository.getDB(ctx).Create(token).Error
+}
+
+// TouchAccessToken 记录个人访问令牌最近使用的时间和 IP
+func (authRepository *AuthRepository) TouchAccessToken(ctx context.Context, id uint, lastUsedAt time.Time, ip string) error {
+	return authRepository.getDB(ctx).Model(&model.AccessToken{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"last_used_at": lastUsedAt, "last_used_ip": ip}).Error
+}
+
+// DeleteAccessToken 删除个人访问令牌
+func (authRepository *AuthRepository) DeleteAccessToken(ctx context.Context, id uint) error {
+	return authRepository.getDB(ctx).Delete(&model.AccessToken{}, id).Error
+}
+
+// DeleteUserAccessTokens 删除用户的所有个人访问令牌
+func (authRepository *AuthRepository) DeleteUserAccessTokens(ctx context.Context, userID uint) error {
+	return authRepository.getDB(ctx).Where("user_id = ?", userID).Delete(&model.AccessToken{}).Error
+}
+
+// GetTwoFactor 获取用户的两步验证
+func (authRepository *AuthRepository) GetTwoFactor(userID uint) (model.TwoFactor, error) {
+	var twoFactor model.TwoFactor
+	err := authRepository.db.Where("user_id = ?", userID).First(&twoFactor).Error
+	return twoFactor, err
+}
+
+// SaveTwoFactor 保存用户的两步验证
+func (authRepository *AuthRepository) SaveTwoFactor(ctx context.Context, twoFactor *model.TwoFactor) error {
+	return authRepository.getDB(ctx).Save(twoFactor).Error
+}
+
+// AdvanceTOTPCounter 记录最近一次使用的时间步，时间步没有前进（验证码被重放）时返回 false
+func (authRepository *AuthRepository) AdvanceTOTPCounter(ctx context.Context, userID uint, counter int64) (bool, error) {
+	result := authRepository.getDB(ctx).Model(&model.TwoFactor{}).
+		Where("user_id = ? AND last_counter < ?", userID, counter).
+		Update("last_counter", counter)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteTwoFactor 删除用户的两步验证、恢复码和等待验证的登录请求
+func (authRepository *AuthRepository) DeleteTwoFactor(ctx context.Context, userID uint) error {
+	db := authRepository.getDB(ctx)
+	if err := db.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("user_id = ?", userID).Delete(&model.LoginChallenge{}).Error; err != nil {
+		return err
+	}
+	return db.Where("user_id = ?", userID).Delete(&model.TwoFactor{}).Error
+}
+
+// CountRecoveryCodes 获取用户剩余的恢复码数量
+func (authRepository *AuthRepository) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := authRepository.db.Model(&model.RecoveryCode{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// ReplaceRecoveryCodes 使用新的恢复码替换用户的所有恢复码
+func (authRepository *AuthRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error {
+	db := authRepository.getDB(ctx)
+	if err := db.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]model.RecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		codes = append(codes, model.RecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return db.Create(&codes).Error
+}
+
+// UseRecoveryCode 使用恢复码（使用后删除），恢复码不存在时返回 false
+func (authRepository *AuthRepository) UseRecoveryCode(ctx context.Context, userID uint, hash string) (bool, error) {
+	result := authRepository.getDB(ctx).
+		Where("user_id = ? AND code_hash = ?", userID, hash).
+		Delete(&model.RecoveryCode{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CreateChallenge 创建等待两步验证的登录请求
+func (authRepository *AuthRepository) CreateChallenge(ctx context.Context, challenge *model.LoginChallenge) error {
+	return authRepository.getDB(ctx).Create(challenge).Error
+}
+
+// GetChallenge 获取等待两步验证的登录请求
+func (authRepository *AuthRepository) GetChallenge(id string) (model.LoginChallenge, error) {
+	var challenge model.LoginChallenge
+	err := authRepository.db.Where("id = ?", id).First(&challenge).Error
+	return challenge, err
+}
+
+// IncrementChallengeAttempts 增加登录请求的尝试次数
+func (authRepository *AuthRepository) IncrementChallengeAttempts(ctx context.Context, id string) error {
+	return authRepository.getDB(ctx).Model(&model.LoginChallenge{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// DeleteChallenge 删除等待两步验证的登录请求
+func (authRepository *AuthRepository) DeleteChallenge(ctx context.Context, id string) error {
+	return authRepository.getDB(ctx).Where("id = ?", id).Delete(&model.LoginChallenge{}).Error
+}
+
+// DeleteExpiredChallenges 删除已过期的登录请求
+func (authRepository *AuthRepository) DeleteExpiredChallenges(ctx context.Context) error {
+	return authRepository.getDB(ctx).Where("expires_at < ?", time.Now()).Delete(&model.LoginChallenge{}).Error
+}
+
+// CreateOIDCState 创建等待身份提供者回调的授权请求
+func (authRepository *AuthRepository) CreateOIDCState(ctx context.Context, state *model.OIDCState) error {
+	return authRepository.getDB(ctx).Create(state).Error
+}
+
+// GetOIDCState 获取等待身份提供者回调的授权请求
+func (authRepository *AuthRepository) GetOIDCState(state string) (model.OIDCState, error) {
+	var oidcState model.OIDCState
+	err := authRepository.db.Where("state = ?", state).First(&oidcState).Error
+	return oidcState, err
+}
+
+// DeleteOIDCState 删除授权请求，请求已被使用（不存在）时返回 false
+func (authRepository *AuthRepository) DeleteOIDCState(ctx context.Context, state string) (bool, error) {
+	result := authRepository.getDB(ctx).Where("state = ?", state).Delete(&model.OIDCState{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteExpiredOIDCStates 删除已过期的授权请求
+func (authRepository *AuthRepository) DeleteExpiredOIDCStates(ctx context.Context) error {
+	return authRepository.getDB(ctx).Where("expires_at < ?", time.Now()).Delete(&model.OIDCState{}).Error
+}
+
+// GetOIDCIdentity 根据身份提供者和用户标识获取关联的身份
+func (authRepository *AuthRepository) GetOIDCIdentity(provider, subject string) (model.OIDCIdentity, error) {
+	var identity model.OIDCIdentity
+	err := authRepository.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	return identity, err
+}
+
+// GetOIDCIdentitiesByUserID 获取用户关联的所有身份
+func (authRepository *AuthRepository) GetOIDCIdentitiesByUserID(userID uint) ([]model.OIDCIdentity, error) {
+	var identities []model.OIDCIdentity
+	err := authRepository.db.Where("user_id = ?", userID).Order("id ASC").Find(&identities).Error
+	return identities, err
+}
+
+// SaveOIDCIdentity 保存关联的身份
+func (authRepository *AuthRepository) SaveOIDCIdentity(ctx context.Context, identity *model.OIDCIdentity) error {
+	return authRepository.getDB(ctx).Save(identity).Error
+}
+
+// DeleteOIDCIdentity 删除用户关联的身份
+func (authRepository *AuthRepository) DeleteOIDCIdentity(ctx context.Context, userID, id uint) (bool, error) {
+	result := authRepository.getDB(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&model.OIDCIdentity{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetLockout 获取 IP 或用户名的失败记录
+func (authRepository *AuthRepository) GetLockout(scope model.LockoutScope, target string) (model.Lockout, error) {
+	var lockout model.Lockout
+	err := authRepository.db.Where("scope = ? AND target = ?", scope, target).First(&lockout).Error
+	return lockout, err
+}
+
+// GetLockouts 获取所有失败记录
+func (authRepository *AuthRepository) GetLockouts() ([]model.Lockout, error) {
+	var lockouts []model.Lockout
+	err := authRepository.db.Order("updated_at DESC").Find(&lockouts).Error
+	return lockouts, err
+}
+
+// SaveLockout 保存失败记录
+func (authRepository *AuthRepository) SaveLockout(ctx context.Context, lockout *model.Lockout) error {
+	return authRepository.getDB(ctx).Save(lockout).Error
+}
+
+// DeleteLockout 删除失败记录，记录不存在时返回 false
+func (authRepository *AuthRepository) DeleteLockout(ctx context.Context, id uint) (bool, error) {
+	result := authRepository.getDB(ctx).Delete(&model.Lockout{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteLockoutByTarget 删除 IP 或用户名的失败记录
+func (authRepository *AuthRepository) DeleteLockoutByTarget(ctx context.Context, scope model.LockoutScope, target string) error {
+	return authRepository.getDB(ctx).Where("scope = ? AND target = ?", scope, target).Delete(&model.Lockout{}).Error
+}
+
+// DeleteAllLockouts 删除所有失败记录
+func (authRepository *AuthRepository) DeleteAllLockouts(ctx context.Context) error {
+	return authRepository.getDB(ctx).Where("1 = 1").Delete(&model.Lockout{}).Error
+}
+
+// DeleteStaleLockouts 删除长时间没有失败的记录
+func (authRepository *AuthRepository) DeleteStaleLockouts(ctx context.Context, before time.Time) error {
+	return authRepository.getDB(ctx).Where("updated_at < ?", before).Delete(&model.Lockout{}).Error
+}
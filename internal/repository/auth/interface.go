@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/auth"
+)
+
+type AuthRepositoryInterface interface {
+	// CreateSession 创建会话
+	CreateSession(ctx context.Context, session *model.Session) error
+
+	// GetSessionByID 根据 ID 获取会话
+	GetSessionByID(id string) (model.Session, error)
+
+	// GetSessionByRefreshHash 根据当前刷新令牌的哈希获取会话
+	GetSessionByRefreshHash(hash string) (model.Session, error)
+
+	// GetSessionByPreviousHash 根据上一个刷新令牌的哈希获取会话
+	GetSessionByPreviousHash(hash string) (model.Session, error)
+
+	// UpdateSession 更新会话
+	UpdateSession(ctx context.Context, session *model.Session) error
+
+	// TouchSession 更新会话最近使用的时间
+	TouchSession(ctx context.Context, id string, lastUsedAt time.Time) error
+
+	// GetActiveSessions 获取用户未撤销且未过期的会话
+	GetActiveSessions(userID uint) ([]model.Session, error)
+
+	// RevokeUserSessions 撤销用户的所有会话
+	RevokeUserSessions(ctx context.Context, userID uint) error
+
+	// RevokeOtherSessions 撤销用户除指定会话以外的所有会话
+	RevokeOtherSessions(ctx context.Context, userID uint, keepID string) error
+
+	// DeleteStaleSessions 删除已过期或已撤销的会话
+	DeleteStaleSessions(ctx context.Context) error
+
+	// GetSigningKeys 获取所有签名密钥
+	GetSigningKeys() ([]model.SigningKey, error)
+
+	// CreateSigningKey 创建签名密钥
+	CreateSigningKey(ctx context.Context, key *model.SigningKey) error
+
+	// RetireSigningKeys 将当前用于签发的密钥标记为已轮换
+	RetireSigningKeys(ctx context.Context, retiredAt time.Time) error
+
+	// DeleteRetiredSigningKeys 删除在指定时间之前被轮换的密钥
+	DeleteRetiredSigningKeys(ctx context.Context, before time.Time) error
+
+	// GetAccessTokens 获取用户的个人访问令牌
+	GetAccessTokens(userID uint) ([]model.AccessToken, error)
+
+	// GetAccessTokenByID 根据 ID 获取个人访问令牌
+	GetAccessTokenByID(id uint) (model.AccessToken, error)
+
+	// GetAccessTokenByHash 根据令牌的哈希获取个人访问令牌
+	GetAccessTokenByHash(hash string) (model.AccessToken, error)
+
+	// CreateAccessToken 创建个人访问令牌
+	CreateAccessToken(ctx context.Context, token *model.AccessToken) error
+
+	// TouchAccessToken 记录个人访问令牌最近使用的时间和 IP
+	TouchAccessToken(ctx context.Context, id uint, lastUsedAt time.Time, ip string) error
+
+	// DeleteAccessToken 删除个人访问令牌
+	DeleteAccessToken(ctx context.Context, id uint) error
+
+	// DeleteUserAccessTokens 删除用户的所有个人访问令牌
+	DeleteUserAccessTokens(ctx context.Context, userID uint) error
+
+	// GetTwoFactor 获取用户的两步验证
+	GetTwoFactor(userID uint) (model.TwoFactor, error)
+
+	// SaveTwoFactor 保存用户的两步验证
+	SaveTwoFactor(ctx context.Context, twoFactor *model.TwoFactor) error
+
+	// AdvanceTOTPCounter 记录最近一次使用的时间步，时间步没有前进（验证码被重放）时返回 false
+	AdvanceTOTPCounter(ctx context.Context, userID uint, counter int64) (bool, error)
+
+	// DeleteTwoFactor 删除用户的两步验证、恢复码和等待验证的登录请求
+	DeleteTwoFactor(ctx context.Context, userID uint) error
+
+	// CountRecoveryCodes 获取用户剩余的恢复码数量
+	CountRecoveryCodes(userID uint) (int64, error)
+
+	// ReplaceRecoveryCodes 使用新的恢复码替换用户的所有恢复码
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error
+
+	// UseRecoveryCode 使用恢复码（使用后删除），恢复码不存在时返回 false
+	UseRecoveryCode(ctx context.Context, userID uint, hash string) (bool, error)
+
+	// CreateChallenge 创建等待两步验证的登录请求
+	CreateChallenge(ctx context.Context, challenge *model.LoginChallenge) error
+
+	// GetChallenge 获取等待两步验证的登录请求
+	GetChallenge(id string) (model.LoginChallenge, error)
+
+	// IncrementChallengeAttempts 增加登录请求的尝试次数
+	IncrementChallengeAttempts(ctx context.Context, id string) error
+
+	// DeleteChallenge 删除等待两步验证的登录请求
+	DeleteChallenge(ctx context.Context, id string) error
+
+	// DeleteExpiredChallenges 删除已过期的登录请求
+	DeleteExpiredChallenges(ctx context.Context) error
+
+	// CreateOIDCState 创建等待身份提供者回调的授权请求
+	CreateOIDCState(ctx context.Context, state *model.OIDCState) error
+
+	// GetOIDCState 获取等待身份提供者回调的授权请求
+	GetOIDCState(state string) (model.OIDCState, error)
+
+	// DeleteOIDCState 删除授权请求，请求已被使用（不存在）时返回 false
+	DeleteOIDCState(ctx context.Context, state string) (bool, error)
+
+	// DeleteExpiredOIDCStates 删除已过期的授权请求
+	DeleteExpiredOIDCStates(ctx context.Context) error
+
+	// GetOIDCIdentity 根据身份提供者和用户标识获取关联的身份
+	GetOIDCIdentity(provider, subject string) (model.OIDCIdentity, error)
+
+	// GetOIDCIdentitiesByUserID 获取用户关联的所有身份
+	GetOIDCIdentitiesByUserID(userID uint) ([]model.OIDCIdentity, error)
+
+	// SaveOIDCIdentity 保存关联的身份
+	SaveOIDCIdentity(ctx context.Context, identity *model.OIDCIdentity) error
+
+	// DeleteOIDCIdentity 删除用户关联的身份
+	DeleteOIDCIdentity(ctx context.Context, userID, id uint) (bool, error)
+
+	// GetLockout 获取 IP 或用户名的失败记录
+	GetLockout(scope model.LockoutScope, target string) (model.Lockout, error)
+
+	// GetLockouts 获取所有失败记录
+	GetLockouts() ([]model.Lockout, error)
+
+	// SaveLockout 保存失败记录
+	SaveLockout(ctx context.Context, lockout *model.Lockout) error
+
+	// DeleteLockout 删除失败记录，记录不存在时返回 false
+	DeleteLockout(ctx context.Context, id uint) (bool, error)
+
+	// DeleteLockoutByTarget 删除 IP 或用户名的失败记录
+	DeleteLockoutByTarget(ctx context.Context, scope model.LockoutScope, target string) error
+
+	// DeleteAllLockouts 删除所有失败记录
+	DeleteAllLockouts(ctx context.Context) error
+
+	// DeleteStaleLockouts 删除长时间没有失败的记录
+	DeleteStaleLockouts(ctx context.Context, before time.Time) error
+}
@@ -2,10 +2,17 @@ package repository
 
 import (
 	"context"
+	"sort"
+	"time"
+
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
 	userModel "github.com/lin-snow/ech0/internal/model/user"
 	"github.com/lin-snow/ech0/internal/transaction"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	tagUtil "github.com/lin-snow/ech0/internal/util/tag"
+	timeUtil "github.com/lin-snow/ech0/internal/util/time"
 	"gorm.io/gorm"
 )
 
@@ -40,7 +47,7 @@ func (commonRepository *CommonRepository) GetUserByUserId(userId uint) (userMode
 func (commonRepository *CommonRepository) GetSysAdmin() (userModel.User, error) {
 	// 获取系统管理员（首个注册的用户）
 	user := userModel.User{}
-	err := commonRepository.db.Where("is_admin = ?", true).First(&user).Error
+	err := commonRepository.db.Where("role = ?", userModel.RoleOwner).First(&user).Error
 	if err != nil {
 		return userModel.User{}, err
 	}
@@ -76,22 +83,106 @@ func (commonRepository *CommonRepository) GetAllEchos(showPrivate bool) ([]echoM
 	return echos, nil
 }
 
-// GetHeatMap 获取热力图数据
-func (commonRepository *CommonRepository) GetHeatMap(startDate, endDate string) ([]commonModel.Heatmap, error) {
-	var results []commonModel.Heatmap
+// GetUserByUsername 根据用户名获取用户信息
+func (commonRepository *CommonRepository) GetUserByUsername(username string) (userModel.User, error) {
+	var user userModel.User
+	if err := commonRepository.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
+// GetFeedEchos 获取订阅源中的公开 Echo，按发布时间倒序排列，最多 limit 条
+func (commonRepository *CommonRepository) GetFeedEchos(limit int, tag string, userID uint) ([]echoModel.Echo, error) {
+	var echos []echoModel.Echo
 
-	// 查询数据
-	// 执行查询
-	err := commonRepository.db.Table("echos").
-		Select("DATE(created_at) as date, COUNT(*) as count").
-		Where("DATE(created_at) >= ? AND DATE(created_at) <= ?", startDate, endDate).
-		Group("DATE(created_at)").
-		Order("date ASC").
-		Scan(&results).Error
+	query := commonRepository.db.Preload("Images").
+		Where("private = ?", false).
+		Order("created_at DESC")
 
-	if err != nil {
+	// 只包含指定用户发布的 Echo
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	// 没有指定标签时直接在数据库中分页
+	if tag == "" {
+		if err := query.Limit(limit).Find(&echos).Error; err != nil {
+			return nil, err
+		}
+		return echos, nil
+	}
+
+	// 先用 LIKE 粗略筛选，再精确匹配标签（避免 #go 匹配到 #golang）
+	var candidates []echoModel.Echo
+	if err := query.Where("content LIKE ?", "%#"+tag+"%").Find(&candidates).Error; err != nil {
 		return nil, err
 	}
+	for _, echo := range candidates {
+		if len(echos) >= limit {
+			break
+		}
+		if tagUtil.HasTag(echo.Content, tag) {
+			echos = append(echos, echo)
+		}
+	}
+
+	return echos, nil
+}
+
+// GetSystemSetting 获取系统设置（直接读取键值表，避免依赖 SettingService 造成循环依赖）
+func (commonRepository *CommonRepository) GetSystemSetting() (settingModel.SystemSetting, error) {
+	var setting settingModel.SystemSetting
+	var kv commonModel.KeyValue
+	if err := commonRepository.db.Where("key = ?", commonModel.SystemSettingsKey).First(&kv).Error; err != nil {
+		return setting, err
+	}
+
+	if err := jsonUtil.JSONUnmarshal([]byte(kv.Value), &setting); err != nil {
+		return setting, err
+	}
+
+	return setting, nil
+}
+
+// GetHeatMap 获取热力图数据，按 loc 时区的日期分组（只返回 count >= 1 的日期）
+// 查询范围按夏令时拆分为偏移量不变的若干段，每段在数据库中按平移后的日期分组
+func (commonRepository *CommonRepository) GetHeatMap(start, end time.Time, loc *time.Location, userID uint, showPrivate bool) ([]commonModel.Heatmap, error) {
+	counts := make(map[string]int)
+	for _, span := range timeUtil.SplitByOffset(start, end, loc) {
+		query := commonRepository.db.Model(&echoModel.Echo{}).
+			Select("strftime('%Y-%m-%d', created_at, ?) AS date, COUNT(*) AS count", span.SQLiteModifier()).
+			Where(timeUtil.CreatedAtInSpanSQL, span.Start.Unix(), span.End.Unix())
+
+		// 如果不是管理员，过滤私密Echo
+		if !showPrivate {
+			query = query.Where("private = ?", false)
+		}
+
+		// 只统计指定用户
+		if userID != 0 {
+			query = query.Where("user_id = ?", userID)
+		}
+
+		var rows []commonModel.Heatmap
+		if err := query.Group("date").Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			counts[row.Date] += row.Count
+		}
+	}
+
+	results := make([]commonModel.Heatmap, 0, len(counts))
+	for date, count := range counts {
+		results = append(results, commonModel.Heatmap{
+			Date:  date,
+			Count: count,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Date < results[j].Date
+	})
 
 	return results, nil
 }
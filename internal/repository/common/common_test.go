@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+// 在数据库中按日期分组的热力图与逐条按时区换算的结果一致，并且跨越夏令时切换
+func TestGetHeatMap_GroupsByLocalDate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&echoModel.Echo{}))
+	repository := &CommonRepository{db: db}
+
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	start := time.Date(2024, 3, 28, 0, 0, 0, 0, loc)
+	end := time.Date(2024, 4, 3, 0, 0, 0, 0, loc)
+
+	// 每隔 97 分钟一条，时间以不同的偏移量保存，范围外的记录不计入
+	expected := make(map[string]int)
+	zones := []*time.Location{time.UTC, time.FixedZone("", 8*3600), time.FixedZone("", -5*3600)}
+	for i, createdAt := 0, start.Add(-6*time.Hour); createdAt.Before(end.Add(6 * time.Hour)); i, createdAt = i+1, createdAt.Add(97*time.Minute) {
+		echo := echoModel.Echo{Content: "hi", UserID: uint(1 + i%2), Private: i%7 == 0, CreatedAt: createdAt.In(zones[i%len(zones)])}
+		require.NoError(t, db.Create(&echo).Error)
+		if echo.UserID == 1 && !echo.Private && !createdAt.Before(start) && createdAt.Before(end) {
+			expected[createdAt.In(loc).Format("2006-01-02")]++
+		}
+	}
+
+	heatmap, err := repository.GetHeatMap(start, end, loc, 1, false)
+	require.NoError(t, err)
+
+	actual := make(map[string]int)
+	for i, day := range heatmap {
+		actual[day.Date] = day.Count
+		if i > 0 {
+			assert.Less(t, heatmap[i-1].Date, day.Date)
+		}
+	}
+	assert.Equal(t, expected, actual)
+}
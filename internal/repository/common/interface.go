@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"time"
+
 	model "github.com/lin-snow/ech0/internal/model/common"
 	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
 	userModel "github.com/lin-snow/ech0/internal/model/user"
 )
 
@@ -19,6 +22,15 @@ type CommonRepositoryInterface interface {
 	// GetAllEchos 获取所有Echo
 	GetAllEchos(showPrivate bool) ([]echoModel.Echo, error)
 
+	// GetUserByUsername 根据用户名获取用户信息
+	GetUserByUsername(username string) (userModel.User, error)
+
+	// GetFeedEchos 获取订阅源中的公开 Echo
+	GetFeedEchos(limit int, tag string, userID uint) ([]echoModel.Echo, error)
+
+	// GetSystemSetting 获取系统设置
+	GetSystemSetting() (settingModel.SystemSetting, error)
+
 	// GetHeatMap 获取热力图数据
-	GetHeatMap(startDate, endDate string) ([]model.Heatmap, error)
+	GetHeatMap(start, end time.Time, loc *time.Location, userID uint, showPrivate bool) ([]model.Heatmap, error)
 }
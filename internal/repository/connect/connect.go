@@ -2,9 +2,14 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/connect"
 	"github.com/lin-snow/ech0/internal/transaction"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ConnectRepository struct {
@@ -40,6 +45,62 @@ func (connectRepository *ConnectRepository) GetAllConnects() ([]model.Connected,
 	return connects, nil
 }
 
+// GetConnectsByState 获取指定状态的连接
+func (connectRepository *ConnectRepository) GetConnectsByState(state model.State) ([]model.Connected, error) {
+	var connects []model.Connected
+	if err := connectRepository.db.Where("state = ?", state).Find(&connects).Error; err != nil {
+		return nil, err
+	}
+	return connects, nil
+}
+
+// GetConnectByID 根据 ID 获取连接
+func (connectRepository *ConnectRepository) GetConnectByID(id uint) (model.Connected, error) {
+	var connect model.Connected
+	err := connectRepository.db.First(&connect, id).Error
+	return connect, err
+}
+
+// GetConnectByURL 根据连接地址获取连接
+func (connectRepository *ConnectRepository) GetConnectByURL(connectURL string) (model.Connected, error) {
+	var connect model.Connected
+	err := connectRepository.db.Where("connect_url = ?", connectURL).First(&connect).Error
+	return connect, err
+}
+
+// UpdateConnect 更新连接
+func (connectRepository *ConnectRepository) UpdateConnect(ctx context.Context, connect *model.Connected) error {
+	return connectRepository.getDB(ctx).Save(connect).Error
+}
+
+// GetKeyPair 获取连接握手使用的密钥对
+func (connectRepository *ConnectRepository) GetKeyPair() (model.KeyPair, error) {
+	var keyPair model.KeyPair
+	var kv commonModel.KeyValue
+	if err := connectRepository.db.Where("key = ?", model.KeyPairKey).First(&kv).Error; err != nil {
+		return keyPair, err
+	}
+
+	if err := jsonUtil.JSONUnmarshal([]byte(kv.Value), &keyPair); err != nil {
+		return keyPair, err
+	}
+
+	return keyPair, nil
+}
+
+// SaveKeyPair 保存连接握手使用的密钥对
+func (connectRepository *ConnectRepository) SaveKeyPair(ctx context.Context, keyPair model.KeyPair) error {
+	keyPairToJSON, err := jsonUtil.JSONMarshal(keyPair)
+	if err != nil {
+		return err
+	}
+
+	return connectRepository.getDB(ctx).Create(&commonModel.KeyValue{
+		Key:   model.KeyPairKey,
+		Value: string(keyPairToJSON),
+	}).Error
+}
+
 // CreateConnect 创建一个新的连接
 func (connectRepository *ConnectRepository) CreateConnect(ctx context.Context, connect *model.Connected) error {
 	if err := connectRepository.getDB(ctx).Create(connect).Error; err != nil {
@@ -57,3 +118,126 @@ func (connectRepository *ConnectRepository) DeleteConnect(ctx context.Context, i
 
 	return nil
 }
+
+// GetConnectHealths 获取所有连接的健康状态
+func (connectRepository *ConnectRepository) GetConnectHealths() ([]model.ConnectHealth, error) {
+	var healths []model.ConnectHealth
+	if err := connectRepository.db.Order("connect_id ASC").Find(&healths).Error; err != nil {
+		return nil, err
+	}
+	return healths, nil
+}
+
+// SaveConnectHealth 保存连接的健康状态（已存在时更新）
+func (connectRepository *ConnectRepository) SaveConnectHealth(ctx context.Context, health *model.ConnectHealth) error {
+	if health.ID != 0 {
+		return connectRepository.getDB(ctx).Save(health).Error
+	}
+
+	return connectRepository.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "connect_id"}},
+		UpdateAll: true,
+	}).Create(health).Error
+}
+
+// DeleteConnectHealthsExcept 删除不在给定连接中的健康状态
+func (connectRepository *ConnectRepository) DeleteConnectHealthsExcept(ctx context.Context, connectIDs []uint) error {
+	db := connectRepository.getDB(ctx)
+	if len(connectIDs) == 0 {
+		return db.Where("1 = 1").Delete(&model.ConnectHealth{}).Error
+	}
+
+	return db.Where("connect_id NOT IN ?", connectIDs).Delete(&model.ConnectHealth{}).Error
+}
+
+// GetTimelineEchosByPage 分页获取缓存的时间线，sourceURL 不为空时只获取该来源的 Echo
+func (connectRepository *ConnectRepository) GetTimelineEchosByPage(page, pageSize int, sourceURL string) ([]model.TimelineEcho, int64, error) {
+	var echos []model.TimelineEcho
+	var total int64
+
+	query := connectRepository.db.Model(&model.TimelineEcho{})
+	if sourceURL != "" {
+		query = query.Where("source_url = ?", sourceURL)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC, id DESC").Limit(pageSize).Offset(offset).Find(&echos).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return echos, total, nil
+}
+
+// ReplaceTimelineEchos 使用最新拉取的 Echo 替换指定来源的缓存
+func (connectRepository *ConnectRepository) ReplaceTimelineEchos(ctx context.Context, sourceURL string, echos []model.TimelineEcho) error {
+	db := connectRepository.getDB(ctx)
+	if err := db.Where("source_url = ?", sourceURL).Delete(&model.TimelineEcho{}).Error; err != nil {
+		return err
+	}
+
+	if len(echos) == 0 {
+		return nil
+	}
+
+	return db.Create(&echos).Error
+}
+
+// DeleteTimelineEchosExcept 删除不在给定来源中的缓存（已删除的连接）
+func (connectRepository *ConnectRepository) DeleteTimelineEchosExcept(ctx context.Context, sourceURLs []string) error {
+	db := connectRepository.getDB(ctx)
+	if len(sourceURLs) == 0 {
+		return db.Where("1 = 1").Delete(&model.TimelineEcho{}).Error
+	}
+
+	return db.Where("source_url NOT IN ?", sourceURLs).Delete(&model.TimelineEcho{}).Error
+}
+
+// GetSuggestions 获取推荐实例，status 为空时获取所有推荐实例
+func (connectRepository *ConnectRepository) GetSuggestions(status model.SuggestionStatus) ([]model.PeerSuggestion, error) {
+	var suggestions []model.PeerSuggestion
+
+	query := connectRepository.db.Model(&model.PeerSuggestion{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("depth ASC, last_seen_at DESC").Find(&suggestions).Error; err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// GetSuggestionByID 根据 ID 获取推荐实例
+func (connectRepository *ConnectRepository) GetSuggestionByID(id uint) (model.PeerSuggestion, error) {
+	var suggestion model.PeerSuggestion
+	err := connectRepository.db.First(&suggestion, id).Error
+	return suggestion, err
+}
+
+// SaveSuggestion 保存推荐实例
+func (connectRepository *ConnectRepository) SaveSuggestion(ctx context.Context, suggestion *model.PeerSuggestion) error {
+	return connectRepository.getDB(ctx).Save(suggestion).Error
+}
+
+// DeleteSuggestion 删除推荐实例
+func (connectRepository *ConnectRepository) DeleteSuggestion(ctx context.Context, id uint) error {
+	return connectRepository.getDB(ctx).Delete(&model.PeerSuggestion{}, id).Error
+}
+
+// DeleteStaleSuggestions 删除长时间未再被发现的推荐实例（已忽略的除外），以及已经建立连接的推荐实例
+func (connectRepository *ConnectRepository) DeleteStaleSuggestions(ctx context.Context, before time.Time, connectedURLs []string) error {
+	db := connectRepository.getDB(ctx)
+	if err := db.Where("status = ? AND last_seen_at < ?", model.SuggestionNew, before).
+		Delete(&model.PeerSuggestion{}).Error; err != nil {
+		return err
+	}
+
+	if len(connectedURLs) == 0 {
+		return nil
+	}
+	return db.Where("server_url IN ?", connectedURLs).Delete(&model.PeerSuggestion{}).Error
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	model "github.com/lin-snow/ech0/internal/model/connect"
 )
 
@@ -9,9 +11,60 @@ type ConnectRepositoryInterface interface {
 	// GetAllConnects 获取所有连接
 	GetAllConnects() ([]model.Connected, error)
 
+	// GetConnectsByState 获取指定状态的连接
+	GetConnectsByState(state model.State) ([]model.Connected, error)
+
+	// GetConnectByID 根据 ID 获取连接
+	GetConnectByID(id uint) (model.Connected, error)
+
+	// GetConnectByURL 根据连接地址获取连接
+	GetConnectByURL(connectURL string) (model.Connected, error)
+
+	// UpdateConnect 更新连接
+	UpdateConnect(ctx context.Context, connect *model.Connected) error
+
+	// GetKeyPair 获取连接握手使用的密钥对
+	GetKeyPair() (model.KeyPair, error)
+
+	// SaveKeyPair 保存连接握手使用的密钥对
+	SaveKeyPair(ctx context.Context, keyPair model.KeyPair) error
+
 	// CreateConnect 创建一个新的连接
 	CreateConnect(ctx context.Context, connect *model.Connected) error
 
 	// DeleteConnect 删除连接
 	DeleteConnect(ctx context.Context, id uint) error
+
+	// GetConnectHealths 获取所有连接的健康状态
+	GetConnectHealths() ([]model.ConnectHealth, error)
+
+	// SaveConnectHealth 保存连接的健康状态（已存在时更新）
+	SaveConnectHealth(ctx context.Context, health *model.ConnectHealth) error
+
+	// DeleteConnectHealthsExcept 删除不在给定连接中的健康状态
+	DeleteConnectHealthsExcept(ctx context.Context, connectIDs []uint) error
+
+	// GetTimelineEchosByPage 分页获取缓存的时间线，sourceURL 不为空时只获取该来源的 Echo
+	GetTimelineEchosByPage(page, pageSize int, sourceURL string) ([]model.TimelineEcho, int64, error)
+
+	// ReplaceTimelineEchos 使用最新拉取的 Echo 替换指定来源的缓存
+	ReplaceTimelineEchos(ctx context.Context, sourceURL string, echos []model.TimelineEcho) error
+
+	// DeleteTimelineEchosExcept 删除不在给定来源中的缓存（已删除的连接）
+	DeleteTimelineEchosExcept(ctx context.Context, sourceURLs []string) error
+
+	// GetSuggestions 获取推荐实例，status 为空时获取所有推荐实例
+	GetSuggestions(status model.SuggestionStatus) ([]model.PeerSuggestion, error)
+
+	// GetSuggestionByID 根据 ID 获取推荐实例
+	GetSuggestionByID(id uint) (model.PeerSuggestion, error)
+
+	// SaveSuggestion 保存推荐实例
+	SaveSuggestion(ctx context.Context, suggestion *model.PeerSuggestion) error
+
+	// DeleteSuggestion 删除推荐实例
+	DeleteSuggestion(ctx context.Context, id uint) error
+
+	// DeleteStaleSuggestions 删除长时间未再被发现的推荐实例，以及已经建立连接的推荐实例
+	DeleteStaleSuggestions(ctx context.Context, before time.Time, connectedURLs []string) error
 }
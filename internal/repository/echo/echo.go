@@ -2,24 +2,34 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"github.com/lin-snow/ech0/internal/transaction"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/lin-snow/ech0/internal/cache"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/echo"
+	timeUtil "github.com/lin-snow/ech0/internal/util/time"
 	"gorm.io/gorm"
 )
 
 type EchoRepository struct {
-	db    *gorm.DB
-	cache cache.ICache[string, commonModel.PageQueryResult[[]model.Echo]]
+	db           *gorm.DB
+	cache        cache.ICache[string, commonModel.PageQueryResult[[]model.Echo]]
+	archiveCache cache.ICache[string, []model.ArchiveMonth]
+	statsCache   cache.ICache[string, model.EchoStats]
 }
 
-func NewEchoRepository(db *gorm.DB, cache cache.ICache[string, commonModel.PageQueryResult[[]model.Echo]]) EchoRepositoryInterface {
-	return &EchoRepository{db: db, cache: cache}
+func NewEchoRepository(
+	db *gorm.DB,
+	cache cache.ICache[string, commonModel.PageQueryResult[[]model.Echo]],
+	archiveCache cache.ICache[string, []model.ArchiveMonth],
+	statsCache cache.ICache[string, model.EchoStats],
+) EchoRepositoryInterface {
+	return &EchoRepository{db: db, cache: cache, archiveCache: archiveCache, statsCache: statsCache}
 }
 
 // getDB 从上下文中获取事务
@@ -40,6 +50,8 @@ func (echoRepository *EchoRepository) CreateEcho(ctx context.Context, echo *mode
 	}
 
 	ClearEchoPageCache(echoRepository.cache)
+	ClearEchoArchiveCache(echoRepository.archiveCache)
+	ClearEchoStatsCache(echoRepository.statsCache)
 
 	return nil
 }
@@ -83,7 +95,7 @@ func (echoRepository *EchoRepository) GetEchosByPage(page, pageSize int, search
 		Find(&echos)
 
 	// 保存到缓存
-	echoKeyList = append(echoKeyList, cacheKey) // 记录缓存键
+	echoKeyList.Add(cacheKey) // 记录缓存键
 	echoRepository.cache.Set(cacheKey, commonModel.PageQueryResult[[]model.Echo]{
 		Items: echos,
 		Total: total,
@@ -123,19 +135,20 @@ func (echoRepository *EchoRepository) DeleteEchoById(ctx context.Context, id uin
 
 	// 清除相关缓存
 	ClearEchoPageCache(echoRepository.cache)
+	ClearEchoArchiveCache(echoRepository.archiveCache)
+	ClearEchoStatsCache(echoRepository.statsCache)
 
 	return nil
 }
 
 // GetTodayEchos 获取今天的 Echo 列表
-func (echoRepository *EchoRepository) GetTodayEchos(showPrivate bool) []model.Echo {
+func (echoRepository *EchoRepository) GetTodayEchos(showPrivate bool, loc *time.Location) []model.Echo {
 	// 查询数据库
 	var echos []model.Echo
 
-	// 获取当天开始和结束时间
-	today := time.Now()
-	startOfDay := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
+	// 获取指定时区下当天开始和结束时间（与写入时保持一致，使用本地时区比较）
+	startOfDay := timeUtil.StartOfDay(time.Now(), loc)
+	endOfDay := startOfDay.AddDate(0, 0, 1)
 
 	query := echoRepository.db.Model(&model.Echo{})
 	// 如果不是管理员，过滤私密Echo
@@ -144,7 +157,7 @@ func (echoRepository *EchoRepository) GetTodayEchos(showPrivate bool) []model.Ec
 	}
 
 	// 添加当天的时间过滤
-	query = query.Where("created_at >= ? AND created_at < ?", startOfDay, endOfDay)
+	query = query.Where("created_at >= ? AND created_at < ?", startOfDay.Local(), endOfDay.Local())
 
 	// 获取总数并进行分页查询
 	query.
@@ -160,6 +173,8 @@ func (echoRepository *EchoRepository) GetTodayEchos(showPrivate bool) []model.Ec
 func (echoRepository *EchoRepository) UpdateEcho(ctx context.Context, echo *model.Echo) error {
 	// 清空缓存
 	ClearEchoPageCache(echoRepository.cache)
+	ClearEchoArchiveCache(echoRepository.archiveCache)
+	ClearEchoStatsCache(echoRepository.statsCache)
 
 	// 开启事务确保数据一致性
 	tx := echoRepository.db.Begin()
@@ -235,3 +250,195 @@ func (echoRepository *EchoRepository) LikeEcho(ctx context.Context, id uint) err
 
 	return nil
 }
+
+// GetArchive 获取按年月分组的 Echo 数量，按时间倒序排列
+func (echoRepository *EchoRepository) GetArchive(showPrivate bool, loc *time.Location) ([]model.ArchiveMonth, error) {
+	// 查找缓存
+	cacheKey := GetEchoArchiveCacheKey(showPrivate, loc)
+	if cachedResult, err := echoRepository.archiveCache.Get(cacheKey); err == nil {
+		return cachedResult, nil
+	}
+
+	// 可见的 Echo（如果不是管理员，过滤私密Echo）
+	visibleEchos := func() *gorm.DB {
+		query := echoRepository.db.Model(&model.Echo{})
+		if !showPrivate {
+			query = query.Where("private = ?", false)
+		}
+		return query
+	}
+
+	first, last, err := createdAtBounds(visibleEchos())
+	if err != nil {
+		return nil, err
+	}
+
+	// 按夏令时拆分为偏移量不变的若干段，每段在数据库中按平移后的年月分组
+	counts := make(map[[2]int]int)
+	for _, span := range timeUtil.SplitByOffset(first, last, loc) {
+		var rows []model.ArchiveMonth
+		if err := visibleEchos().
+			Select("CAST(strftime('%Y', created_at, ?) AS INTEGER) AS year, CAST(strftime('%m', created_at, ?) AS INTEGER) AS month, COUNT(*) AS count",
+				span.SQLiteModifier(), span.SQLiteModifier()).
+			Where(timeUtil.CreatedAtInSpanSQL, span.Start.Unix(), span.End.Unix()).
+			Group("year, month").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			counts[[2]int{row.Year, row.Month}] += row.Count
+		}
+	}
+
+	archive := make([]model.ArchiveMonth, 0, len(counts))
+	for yearMonth, count := range counts {
+		archive = append(archive, model.ArchiveMonth{
+			Year:  yearMonth[0],
+			Month: yearMonth[1],
+			Count: count,
+		})
+	}
+	sort.Slice(archive, func(i, j int) bool {
+		if archive[i].Year != archive[j].Year {
+			return archive[i].Year > archive[j].Year
+		}
+		return archive[i].Month > archive[j].Month
+	})
+
+	// 保存到缓存
+	echoArchiveKeyList.Add(cacheKey) // 记录缓存键
+	echoRepository.archiveCache.Set(cacheKey, archive, 1)
+
+	return archive, nil
+}
+
+// createdAtBounds 获取查询范围内最早和最晚的创建时间，返回的区间 [first, last) 包含所有记录，没有记录时为空区间
+func createdAtBounds(query *gorm.DB) (time.Time, time.Time, error) {
+	var bounds struct {
+		First sql.NullInt64
+		Last  sql.NullInt64
+	}
+	if err := query.
+		Select("MIN(CAST(strftime('%s', created_at) AS INTEGER)) AS first, MAX(CAST(strftime('%s', created_at) AS INTEGER)) AS last").
+		Scan(&bounds).Error; err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !bounds.First.Valid || !bounds.Last.Valid {
+		return time.Time{}, time.Time{}, nil
+	}
+
+	return time.Unix(bounds.First.Int64, 0), time.Unix(bounds.Last.Int64+1, 0), nil
+}
+
+// GetEchosByTimeRange 获取指定时间范围 [start, end) 内的 Echo 列表，支持分页
+func (echoRepository *EchoRepository) GetEchosByTimeRange(start, end time.Time, page, pageSize int, showPrivate bool) ([]model.Echo, int64) {
+	// 查找缓存
+	cacheKey := GetEchoMonthCacheKey(start, page, pageSize, showPrivate)
+	if cachedResult, err := echoRepository.cache.Get(cacheKey); err == nil {
+		return cachedResult.Items, cachedResult.Total
+	}
+
+	// 计算偏移量
+	offset := (page - 1) * pageSize
+
+	var echos []model.Echo
+	var total int64
+
+	query := echoRepository.db.Model(&model.Echo{})
+
+	// 如果不是管理员，过滤私密Echo
+	if !showPrivate {
+		query = query.Where("private = ?", false)
+	}
+
+	// 添加时间范围过滤（与写入时保持一致，使用本地时区比较）
+	query = query.Where("created_at >= ? AND created_at < ?", start.Local(), end.Local())
+
+	query.Count(&total).
+		Preload("Images").
+		Limit(pageSize).
+		Offset(offset).
+		Order("created_at DESC").
+		Find(&echos)
+
+	// 保存到缓存，与分页缓存一同失效
+	echoKeyList.Add(cacheKey) // 记录缓存键
+	echoRepository.cache.Set(cacheKey, commonModel.PageQueryResult[[]model.Echo]{
+		Items: echos,
+		Total: total,
+	}, 1)
+
+	return echos, total
+}
+
+// GetEchosByUser 获取指定用户发布的 Echo 列表，支持分页和搜索
+func (echoRepository *EchoRepository) GetEchosByUser(userID uint, page, pageSize int, search string, showPrivate bool) ([]model.Echo, int64) {
+	// 查找缓存
+	cacheKey := GetEchoUserCacheKey(userID, page, pageSize, search, showPrivate)
+	if cachedResult, err := echoRepository.cache.Get(cacheKey); err == nil {
+		return cachedResult.Items, cachedResult.Total
+	}
+
+	// 计算偏移量
+	offset := (page - 1) * pageSize
+
+	var echos []model.Echo
+	var total int64
+
+	query := echoRepository.db.Model(&model.Echo{}).Where("user_id = ?", userID)
+
+	// 如果 search 不为空，添加模糊查询条件
+	if search != "" {
+		query = query.Where("content LIKE ?", "%"+search+"%")
+	}
+
+	// 没有查看权限时过滤私密Echo
+	if !showPrivate {
+		query = query.Where("private = ?", false)
+	}
+
+	query.Count(&total).
+		Preload("Images").
+		Limit(pageSize).
+		Offset(offset).
+		Order("created_at DESC").
+		Find(&echos)
+
+	// 保存到缓存，与分页缓存一同失效
+	echoKeyList.Add(cacheKey) // 记录缓存键
+	echoRepository.cache.Set(cacheKey, commonModel.PageQueryResult[[]model.Echo]{
+		Items: echos,
+		Total: total,
+	}, 1)
+
+	return echos, total
+}
+
+// GetEchosByTimeRanges 获取落在任一时间范围 [start, end) 内的 Echo 列表，按时间倒序排列
+func (echoRepository *EchoRepository) GetEchosByTimeRanges(ranges [][2]time.Time, showPrivate bool) []model.Echo {
+	var echos []model.Echo
+	if len(ranges) == 0 {
+		return echos
+	}
+
+	query := echoRepository.db.Model(&model.Echo{})
+
+	// 如果不是管理员，过滤私密Echo
+	if !showPrivate {
+		query = query.Where("private = ?", false)
+	}
+
+	// 拼接多个时间范围条件（与写入时保持一致，使用本地时区比较）
+	rangeQuery := echoRepository.db.Where("created_at >= ? AND created_at < ?", ranges[0][0].Local(), ranges[0][1].Local())
+	for _, r := range ranges[1:] {
+		rangeQuery = rangeQuery.Or("created_at >= ? AND created_at < ?", r[0].Local(), r[1].Local())
+	}
+	query = query.Where(rangeQuery)
+
+	query.
+		Preload("Images").
+		Order("created_at DESC").
+		Find(&echos)
+
+	return echos
+}
@@ -2,16 +2,54 @@ package repository
 
 import (
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/lin-snow/ech0/internal/cache"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/echo"
 )
 
-var echoKeyList = []string{}
+// cacheKeySet 记录写入过缓存的键，清除缓存时逐个删除，可以被多个请求并发使用
+type cacheKeySet struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+// Add 记录缓存键
+func (set *cacheKeySet) Add(key string) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if set.keys == nil {
+		set.keys = make(map[string]struct{})
+	}
+	set.keys[key] = struct{}{}
+}
+
+// Drain 取出并清空所有记录的缓存键
+func (set *cacheKeySet) Drain() []string {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	keys := make([]string, 0, len(set.keys))
+	for key := range set.keys {
+		keys = append(keys, key)
+	}
+	set.keys = nil
+	return keys
+}
+
+var echoKeyList = &cacheKeySet{}
+
+var echoArchiveKeyList = &cacheKeySet{}
+
+var echoStatsKeyList = &cacheKeySet{}
 
 const (
-	EchoPageCacheKeyPrefix = "echo_page" // echo_page:page:pageSize:search:showPrivate
+	EchoPageCacheKeyPrefix    = "echo_page"    // echo_page:page:pageSize:search:showPrivate
+	EchoArchiveCacheKeyPrefix = "echo_archive" // echo_archive:showPrivate:location
+	EchoMonthCacheKeyPrefix   = "echo_month"   // echo_month:start:page:pageSize:showPrivate
+	EchoUserCacheKeyPrefix    = "echo_user"    // echo_user:userID:page:pageSize:search:showPrivate
+	EchoStatsCacheKeyPrefix   = "echo_stats"   // echo_stats:showPrivate:location:date
 )
 
 func GetEchoPageCacheKey(page, pageSize int, search string, showPrivate bool) string {
@@ -24,9 +62,37 @@ func GetEchoPageCacheKey(page, pageSize int, search string, showPrivate bool) st
 	return EchoPageCacheKeyPrefix + ":" + strconv.Itoa(page) + ":" + strconv.Itoa(pageSize) + ":" + search + ":" + showPrivateStr
 }
 
+func GetEchoArchiveCacheKey(showPrivate bool, loc *time.Location) string {
+	return EchoArchiveCacheKeyPrefix + ":" + strconv.FormatBool(showPrivate) + ":" + loc.String()
+}
+
+func GetEchoMonthCacheKey(start time.Time, page, pageSize int, showPrivate bool) string {
+	return EchoMonthCacheKeyPrefix + ":" + strconv.FormatInt(start.Unix(), 10) + ":" + strconv.Itoa(page) + ":" + strconv.Itoa(pageSize) + ":" + strconv.FormatBool(showPrivate)
+}
+
+func GetEchoUserCacheKey(userID uint, page, pageSize int, search string, showPrivate bool) string {
+	return EchoUserCacheKeyPrefix + ":" + strconv.FormatUint(uint64(userID), 10) + ":" + strconv.Itoa(page) + ":" + strconv.Itoa(pageSize) + ":" + search + ":" + strconv.FormatBool(showPrivate)
+}
+
+// GetEchoStatsCacheKey 统计数据中的连续发布天数与"今天"有关，因此缓存键包含当天日期
+func GetEchoStatsCacheKey(showPrivate bool, loc *time.Location, now time.Time) string {
+	return EchoStatsCacheKeyPrefix + ":" + strconv.FormatBool(showPrivate) + ":" + loc.String() + ":" + now.In(loc).Format("2006-01-02")
+}
+
 func ClearEchoPageCache(cache cache.ICache[string, commonModel.PageQueryResult[[]model.Echo]]) {
-	for _, key := range echoKeyList {
+	for _, key := range echoKeyList.Drain() {
+		cache.Delete(key)
+	}
+}
+
+func ClearEchoArchiveCache(cache cache.ICache[string, []model.ArchiveMonth]) {
+	for _, key := range echoArchiveKeyList.Drain() {
+		cache.Delete(key)
+	}
+}
+
+func ClearEchoStatsCache(cache cache.ICache[string, model.EchoStats]) {
+	for _, key := range echoStatsKeyList.Drain() {
 		cache.Delete(key)
 	}
-	echoKeyList = []string{}
 }
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/echo"
+	tagUtil "github.com/lin-snow/ech0/internal/util/tag"
+	timeUtil "github.com/lin-snow/ech0/internal/util/time"
+	"gorm.io/gorm"
+)
+
+// GetEchoStats 获取发布统计数据，now 用于计算连续发布天数与最近的周/月统计
+func (echoRepository *EchoRepository) GetEchoStats(showPrivate bool, loc *time.Location, now time.Time) (model.EchoStats, error) {
+	// 查找缓存
+	cacheKey := GetEchoStatsCacheKey(showPrivate, loc, now)
+	if cachedResult, err := echoRepository.statsCache.Get(cacheKey); err == nil {
+		return cachedResult, nil
+	}
+
+	// 可见的 Echo（如果不是管理员，过滤私密Echo）
+	visibleEchos := func() *gorm.DB {
+		query := echoRepository.db.Model(&model.Echo{})
+		if !showPrivate {
+			query = query.Where("echos.private = ?", false)
+		}
+		return query
+	}
+
+	var stats model.EchoStats
+
+	// 总数与平均内容长度
+	var summary struct {
+		Total         int64
+		AverageLength float64
+	}
+	if err := visibleEchos().
+		Select("COUNT(*) AS total, COALESCE(AVG(LENGTH(content)), 0) AS average_length").
+		Scan(&summary).Error; err != nil {
+		return stats, err
+	}
+	stats.TotalEchos = summary.Total
+	stats.AverageLength = summary.AverageLength
+
+	// 图片数量
+	if err := visibleEchos().
+		Joins("JOIN images ON images.message_id = echos.id").
+		Count(&stats.TotalImages).Error; err != nil {
+		return stats, err
+	}
+	if err := visibleEchos().
+		Joins("JOIN images ON images.message_id = echos.id").
+		Distinct("echos.id").
+		Count(&stats.EchosWithImages).Error; err != nil {
+		return stats, err
+	}
+
+	// 扩展类型分布
+	stats.ExtensionTypes = []model.ExtensionCount{}
+	if err := visibleEchos().
+		Select("extension_type AS type, COUNT(*) AS count").
+		Where("extension_type IS NOT NULL AND extension_type <> ''").
+		Group("extension_type").
+		Order("count DESC").
+		Scan(&stats.ExtensionTypes).Error; err != nil {
+		return stats, err
+	}
+
+	// 时间相关的统计：按夏令时拆分为偏移量不变的若干段，每段在数据库中按本地日期和小时分组
+	first, last, err := createdAtBounds(visibleEchos())
+	if err != nil {
+		return stats, err
+	}
+
+	days := make(map[string]struct{})
+	weekly := make(map[string]int)
+	monthly := make(map[string]int)
+	for _, span := range timeUtil.SplitByOffset(first, last, loc) {
+		var buckets []struct {
+			Day   string
+			Hour  int
+			Count int
+		}
+		if err := visibleEchos().
+			Select("strftime('%Y-%m-%d', created_at, ?) AS day, CAST(strftime('%H', created_at, ?) AS INTEGER) AS hour, COUNT(*) AS count",
+				span.SQLiteModifier(), span.SQLiteModifier()).
+			Where(timeUtil.CreatedAtInSpanSQL, span.Start.Unix(), span.End.Unix()).
+			Group("day, hour").
+			Scan(&buckets).Error; err != nil {
+			return stats, err
+		}
+
+		for _, bucket := range buckets {
+			date, err := time.ParseInLocation("2006-01-02", bucket.Day, loc)
+			if err != nil {
+				return stats, err
+			}
+			days[bucket.Day] = struct{}{}
+			weekly[startOfWeek(date).Format("2006-01-02")] += bucket.Count
+			monthly[date.Format("2006-01")] += bucket.Count
+			stats.HourDistribution[bucket.Hour] += bucket.Count
+			stats.WeekdayDistribution[date.Weekday()] += bucket.Count
+		}
+	}
+
+	// 标签只能从内容中提取，逐行读取包含 # 的内容，不一次性载入所有 Echo
+	tags := make(map[string]int)
+	rows, err := visibleEchos().Select("content").Where("content LIKE ?", "%#%").Rows()
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var content string
+		if err := rows.Scan(&content); err != nil {
+			return stats, err
+		}
+		for _, tag := range tagUtil.ExtractTags(content) {
+			tags[tag]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	// 最近若干周/月的发布数量（按时间正序，缺失的补 0）
+	today := timeUtil.StartOfDay(now, loc)
+	thisWeek := startOfWeek(today)
+	for i := model.StatsRecentWeeks - 1; i >= 0; i-- {
+		period := thisWeek.AddDate(0, 0, -7*i).Format("2006-01-02")
+		stats.Weekly = append(stats.Weekly, model.PeriodCount{Period: period, Count: weekly[period]})
+	}
+	thisMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc)
+	for i := model.StatsRecentMonths - 1; i >= 0; i-- {
+		period := thisMonth.AddDate(0, -i, 0).Format("2006-01")
+		stats.Monthly = append(stats.Monthly, model.PeriodCount{Period: period, Count: monthly[period]})
+	}
+
+	// 连续发布天数
+	computeStreaks(&stats, days, today)
+
+	// 最常用的标签
+	stats.TopTags = make([]model.TagCount, 0, len(tags))
+	for tag, count := range tags {
+		stats.TopTags = append(stats.TopTags, model.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(stats.TopTags, func(i, j int) bool {
+		if stats.TopTags[i].Count != stats.TopTags[j].Count {
+			return stats.TopTags[i].Count > stats.TopTags[j].Count
+		}
+		return stats.TopTags[i].Tag < stats.TopTags[j].Tag
+	})
+	if len(stats.TopTags) > model.StatsTopTagsLimit {
+		stats.TopTags = stats.TopTags[:model.StatsTopTagsLimit]
+	}
+
+	// 保存到缓存
+	echoStatsKeyList.Add(cacheKey) // 记录缓存键
+	echoRepository.statsCache.Set(cacheKey, stats, 1)
+
+	return stats, nil
+}
+
+// startOfWeek 返回 t 所在周的周一零点
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// computeStreaks 根据有发布的日期计算当前与最长连续发布天数
+func computeStreaks(stats *model.EchoStats, days map[string]struct{}, today time.Time) {
+	sortedDays := make([]string, 0, len(days))
+	for day := range days {
+		sortedDays = append(sortedDays, day)
+	}
+	sort.Strings(sortedDays)
+
+	// 最长连续发布
+	var streak int
+	var streakStart, prev time.Time
+	for _, day := range sortedDays {
+		date, _ := time.ParseInLocation("2006-01-02", day, today.Location())
+		if streak > 0 && prev.AddDate(0, 0, 1).Equal(date) {
+			streak++
+		} else {
+			streak = 1
+			streakStart = date
+		}
+		prev = date
+
+		if streak > stats.LongestStreak {
+			stats.LongestStreak = streak
+			stats.LongestStreakStart = streakStart.Format("2006-01-02")
+			stats.LongestStreakEnd = date.Format("2006-01-02")
+		}
+	}
+
+	// 当前连续发布（今天还没发布时，从昨天开始计算）
+	day := today
+	if _, ok := days[day.Format("2006-01-02")]; !ok {
+		day = day.AddDate(0, 0, -1)
+	}
+	for {
+		if _, ok := days[day.Format("2006-01-02")]; !ok {
+			break
+		}
+		stats.CurrentStreak++
+		day = day.AddDate(0, 0, -1)
+	}
+}
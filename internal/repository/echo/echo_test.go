@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lin-snow/ech0/internal/cache"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+// newTestEchoRepository 使用内存数据库创建 Echo 仓库
+func newTestEchoRepository(t *testing.T) (*EchoRepository, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.Echo{}, &model.Image{}))
+
+	pageCache, err := cache.NewCache[string, commonModel.PageQueryResult[[]model.Echo]]()
+	require.NoError(t, err)
+	archiveCache, err := cache.NewCache[string, []model.ArchiveMonth]()
+	require.NoError(t, err)
+	statsCache, err := cache.NewCache[string, model.EchoStats]()
+	require.NoError(t, err)
+
+	return &EchoRepository{db: db, cache: pageCache, archiveCache: archiveCache, statsCache: statsCache}, db
+}
+
+// seedEchos 写入随机时间的 Echo，时间分别以 UTC、服务器时区和其他偏移量保存
+func seedEchos(t *testing.T, db *gorm.DB, count int) []model.Echo {
+	t.Helper()
+
+	zones := []*time.Location{time.UTC, time.Local, time.FixedZone("", -7*3600), time.FixedZone("", 9*3600+1800)}
+	rng := rand.New(rand.NewSource(1))
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	echos := make([]model.Echo, 0, count)
+	for i := 0; i < count; i++ {
+		createdAt := start.Add(time.Duration(rng.Int63n(int64(2 * 365 * 24 * time.Hour))))
+		echo := model.Echo{
+			Content:   "hello #go",
+			UserID:    1,
+			Private:   i%5 == 0,
+			CreatedAt: createdAt.In(zones[i%len(zones)]),
+		}
+		require.NoError(t, db.Create(&echo).Error)
+		echos = append(echos, echo)
+	}
+
+	return echos
+}
+
+// 在数据库中分组的归档结果与逐条按时区换算的结果一致（包括夏令时切换前后）
+func TestGetArchive_GroupsByLocalMonth(t *testing.T) {
+	repository, db := newTestEchoRepository(t)
+	echos := seedEchos(t, db, 400)
+
+	// 夏令时切换前后各一条
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	for _, createdAt := range []time.Time{
+		time.Date(2023, 10, 31, 23, 30, 0, 0, loc),
+		time.Date(2023, 11, 1, 0, 30, 0, 0, loc),
+		time.Date(2024, 3, 31, 23, 59, 59, 0, loc),
+	} {
+		echo := model.Echo{Content: "edge", UserID: 1, CreatedAt: createdAt.UTC()}
+		require.NoError(t, db.Create(&echo).Error)
+		echos = append(echos, echo)
+	}
+
+	for _, showPrivate := range []bool{true, false} {
+		expected := make(map[[2]int]int)
+		for _, echo := range echos {
+			if echo.Private && !showPrivate {
+				continue
+			}
+			localTime := echo.CreatedAt.In(loc)
+			expected[[2]int{localTime.Year(), int(localTime.Month())}]++
+		}
+
+		archive, err := repository.GetArchive(showPrivate, loc)
+		require.NoError(t, err)
+
+		actual := make(map[[2]int]int)
+		for i, month := range archive {
+			actual[[2]int{month.Year, month.Month}] = month.Count
+			if i > 0 {
+				assert.True(t, archive[i-1].Year*12+archive[i-1].Month > month.Year*12+month.Month)
+			}
+		}
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestGetArchive_Empty(t *testing.T) {
+	repository, _ := newTestEchoRepository(t)
+
+	archive, err := repository.GetArchive(true, time.UTC)
+	require.NoError(t, err)
+	assert.Empty(t, archive)
+}
+
+// 在数据库中分桶的发布统计与逐条按时区换算的结果一致
+func TestGetEchoStats_BucketsByLocalTime(t *testing.T) {
+	repository, db := newTestEchoRepository(t)
+	echos := seedEchos(t, db, 300)
+	for i, echo := range echos {
+		if i%3 == 0 {
+			require.NoError(t, db.Model(&model.Echo{}).Where("id = ?", echo.ID).Update("content", "no tags here").Error)
+		}
+	}
+
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	now := time.Date(2024, 12, 31, 12, 0, 0, 0, loc)
+
+	var expectedHours [24]int
+	var expectedWeekdays [7]int
+	expectedMonthly := make(map[string]int)
+	expectedTags := 0
+	for i, echo := range echos {
+		if echo.Private {
+			continue
+		}
+		localTime := echo.CreatedAt.In(loc)
+		expectedHours[localTime.Hour()]++
+		expectedWeekdays[localTime.Weekday()]++
+		expectedMonthly[localTime.Format("2006-01")]++
+		if i%3 != 0 {
+			expectedTags++
+		}
+	}
+
+	stats, err := repository.GetEchoStats(false, loc, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedHours, stats.HourDistribution)
+	assert.Equal(t, expectedWeekdays, stats.WeekdayDistribution)
+	require.Len(t, stats.Monthly, model.StatsRecentMonths)
+	for _, month := range stats.Monthly {
+		assert.Equal(t, expectedMonthly[month.Period], month.Count, month.Period)
+	}
+	require.Len(t, stats.TopTags, 1)
+	assert.Equal(t, model.TagCount{Tag: "go", Count: expectedTags}, stats.TopTags[0])
+}
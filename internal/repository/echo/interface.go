@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	model "github.com/lin-snow/ech0/internal/model/echo"
 )
 
@@ -19,11 +21,26 @@ type EchoRepositoryInterface interface {
 	DeleteEchoById(ctx context.Context, id uint) error
 
 	// GetTodayEchos 获取今天的 Echo 列表
-	GetTodayEchos(showPrivate bool) []model.Echo
+	GetTodayEchos(showPrivate bool, loc *time.Location) []model.Echo
 
 	// UpdateEcho 更新 Echo
 	UpdateEcho(ctx context.Context, echo *model.Echo) error
 
 	// LikeEcho 点赞 Echo
 	LikeEcho(ctx context.Context, id uint) error
+
+	// GetArchive 获取按年月分组的 Echo 数量
+	GetArchive(showPrivate bool, loc *time.Location) ([]model.ArchiveMonth, error)
+
+	// GetEchosByTimeRange 获取指定时间范围内的 Echo 列表，支持分页
+	GetEchosByTimeRange(start, end time.Time, page, pageSize int, showPrivate bool) ([]model.Echo, int64)
+
+	// GetEchosByUser 获取指定用户发布的 Echo 列表，支持分页
+	GetEchosByUser(userID uint, page, pageSize int, search string, showPrivate bool) ([]model.Echo, int64)
+
+	// GetEchosByTimeRanges 获取落在任一时间范围内的 Echo 列表
+	GetEchosByTimeRanges(ranges [][2]time.Time, showPrivate bool) []model.Echo
+
+	// GetEchoStats 获取发布统计数据
+	GetEchoStats(showPrivate bool, loc *time.Location, now time.Time) (model.EchoStats, error)
 }
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	"github.com/lin-snow/ech0/internal/transaction"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FediverseRepository struct {
+	db *gorm.DB
+}
+
+func NewFediverseRepository(db *gorm.DB) FediverseRepositoryInterface {
+	return &FediverseRepository{
+		db: db,
+	}
+}
+
+// getDB 从上下文中获取事务
+func (fediverseRepository *FediverseRepository) getDB(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(transaction.TxKey).(*gorm.DB); ok {
+		return tx
+	}
+	return fediverseRepository.db
+}
+
+// GetKeyPair 获取实例 Actor 的签名密钥对
+func (fediverseRepository *FediverseRepository) GetKeyPair() (model.KeyPair, error) {
+	var keyPair model.KeyPair
+	var kv commonModel.KeyValue
+	if err := fediverseRepository.db.Where("key = ?", model.KeyPairKey).First(&kv).Error; err != nil {
+		return keyPair, err
+	}
+
+	if err := jsonUtil.JSONUnmarshal([]byte(kv.Value), &keyPair); err != nil {
+		return keyPair, err
+	}
+
+	return keyPair, nil
+}
+
+// SaveKeyPair 保存实例 Actor 的签名密钥对
+func (fediverseRepository *FediverseRepository) SaveKeyPair(ctx context.Context, keyPair model.KeyPair) error {
+	keyPairToJSON, err := jsonUtil.JSONMarshal(keyPair)
+	if err != nil {
+		return err
+	}
+
+	return fediverseRepository.getDB(ctx).Create(&commonModel.KeyValue{
+		Key:   model.KeyPairKey,
+		Value: string(keyPairToJSON),
+	}).Error
+}
+
+// GetAllFollowers 获取所有关注者
+func (fediverseRepository *FediverseRepository) GetAllFollowers() ([]model.Follower, error) {
+	var followers []model.Follower
+	if err := fediverseRepository.db.Order("created_at ASC").Find(&followers).Error; err != nil {
+		return nil, err
+	}
+	return followers, nil
+}
+
+// CountFollowers 获取关注者数量
+func (fediverseRepository *FediverseRepository) CountFollowers() (int64, error) {
+	var count int64
+	if err := fediverseRepository.db.Model(&model.Follower{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetFollowerByActorID 根据 Actor 地址获取关注者
+func (fediverseRepository *FediverseRepository) GetFollowerByActorID(actorID string) (model.Follower, error) {
+	var follower model.Follower
+	err := fediverseRepository.db.Where("actor_id = ?", actorID).First(&follower).Error
+	return follower, err
+}
+
+// SaveFollower 保存关注者（已存在时更新收件箱地址和关注活动的 ID）
+func (fediverseRepository *FediverseRepository) SaveFollower(ctx context.Context, follower *model.Follower) error {
+	return fediverseRepository.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "actor_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"inbox", "shared_inbox", "follow_id"}),
+	}).Create(follower).Error
+}
+
+// DeleteFollowerByActorID 根据 Actor 地址删除关注者
+func (fediverseRepository *FediverseRepository) DeleteFollowerByActorID(ctx context.Context, actorID string) error {
+	return fediverseRepository.getDB(ctx).Where("actor_id = ?", actorID).Delete(&model.Follower{}).Error
+}
+
+// CreateDeliveries 将活动加入投递队列
+func (fediverseRepository *FediverseRepository) CreateDeliveries(ctx context.Context, deliveries []model.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return fediverseRepository.getDB(ctx).Create(&deliveries).Error
+}
+
+// GetDueDeliveries 获取到期需要投递的活动，按到期时间先后排列
+func (fediverseRepository *FediverseRepository) GetDueDeliveries(now time.Time, limit int) ([]model.Delivery, error) {
+	var deliveries []model.Delivery
+	if err := fediverseRepository.db.
+		Where("next_attempt_at <= ?", now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery 更新投递状态（用于重试）
+func (fediverseRepository *FediverseRepository) UpdateDelivery(ctx context.Context, delivery *model.Delivery) error {
+	return fediverseRepository.getDB(ctx).Model(delivery).Updates(map[string]any{
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"last_error":      delivery.LastError,
+	}).Error
+}
+
+// DeleteDelivery 从投递队列中删除活动
+func (fediverseRepository *FediverseRepository) DeleteDelivery(ctx context.Context, id uint) error {
+	return fediverseRepository.getDB(ctx).Delete(&model.Delivery{}, id).Error
+}
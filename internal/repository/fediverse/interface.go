@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+)
+
+type FediverseRepositoryInterface interface {
+	// GetKeyPair 获取实例 Actor 的签名密钥对
+	GetKeyPair() (model.KeyPair, error)
+
+	// SaveKeyPair 保存实例 Actor 的签名密钥对
+	SaveKeyPair(ctx context.Context, keyPair model.KeyPair) error
+
+	// GetAllFollowers 获取所有关注者
+	GetAllFollowers() ([]model.Follower, error)
+
+	// CountFollowers 获取关注者数量
+	CountFollowers() (int64, error)
+
+	// GetFollowerByActorID 根据 Actor 地址获取关注者
+	GetFollowerByActorID(actorID string) (model.Follower, error)
+
+	// SaveFollower 保存关注者（已存在时更新收件箱地址和关注活动的 ID）
+	SaveFollower(ctx context.Context, follower *model.Follower) error
+
+	// DeleteFollowerByActorID 根据 Actor 地址删除关注者
+	DeleteFollowerByActorID(ctx context.Context, actorID string) error
+
+	// CreateDeliveries 将活动加入投递队列
+	CreateDeliveries(ctx context.Context, deliveries []model.Delivery) error
+
+	// GetDueDeliveries 获取到期需要投递的活动
+	GetDueDeliveries(now time.Time, limit int) ([]model.Delivery, error)
+
+	// UpdateDelivery 更新投递状态（用于重试）
+	UpdateDelivery(ctx context.Context, delivery *model.Delivery) error
+
+	// DeleteDelivery 从投递队列中删除活动
+	DeleteDelivery(ctx context.Context, id uint) error
+}
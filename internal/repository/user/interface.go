@@ -26,4 +26,7 @@ type UserRepositoryInterface interface {
 
 	// DeleteUser 删除用户
 	DeleteUser(ctx context.Context, id uint) error
+
+	// GetProfileStats 统计用户发布的内容
+	GetProfileStats(userID uint, showPrivate bool) (model.ProfileStats, error)
 }
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"github.com/lin-snow/ech0/internal/cache"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
 	model "github.com/lin-snow/ech0/internal/model/user"
 	"github.com/lin-snow/ech0/internal/transaction"
 	"gorm.io/gorm"
@@ -99,7 +100,7 @@ func (userRepository *UserRepository) GetSysAdmin() (model.User, error) {
 
 	// 获取系统管理员（首个注册的用户）
 	user := model.User{}
-	err := userRepository.db.Where("is_admin = ?", true).First(&user).Error
+	err := userRepository.db.Where("role = ?", model.RoleOwner).First(&user).Error
 	if err != nil {
 		return model.User{}, err
 	}
@@ -147,3 +148,35 @@ func (userRepository *UserRepository) DeleteUser(ctx context.Context, id uint) e
 
 	return nil
 }
+
+// GetProfileStats 统计用户发布的 Echo、图片和收到的点赞数，showPrivate 为 false 时只统计公开 Echo
+func (userRepository *UserRepository) GetProfileStats(userID uint, showPrivate bool) (model.ProfileStats, error) {
+	var stats model.ProfileStats
+
+	echoQuery := userRepository.db.Model(&echoModel.Echo{}).Where("user_id = ?", userID)
+	if !showPrivate {
+		echoQuery = echoQuery.Where("private = ?", false)
+	}
+
+	var echoStats struct {
+		EchoCount int64
+		LikeCount int64
+	}
+	if err := echoQuery.Select("COUNT(*) AS echo_count, COALESCE(SUM(fav_count), 0) AS like_count").Scan(&echoStats).Error; err != nil {
+		return stats, err
+	}
+	stats.EchoCount = echoStats.EchoCount
+	stats.LikeCount = echoStats.LikeCount
+
+	imageQuery := userRepository.db.Model(&echoModel.Image{}).
+		Joins("JOIN echos ON echos.id = images.message_id").
+		Where("echos.user_id = ?", userID)
+	if !showPrivate {
+		imageQuery = imageQuery.Where("echos.private = ?", false)
+	}
+	if err := imageQuery.Count(&stats.ImageCount).Error; err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
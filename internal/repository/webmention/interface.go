@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	model "github.com/lin-snow/ech0/internal/model/webmention"
+)
+
+type WebmentionRepositoryInterface interface {
+	// GetWebmentionsByPage 分页获取 Webmention，status 为空时返回全部
+	GetWebmentionsByPage(page, pageSize int, status model.Status) ([]model.Webmention, int64, error)
+
+	// GetWebmentionsByEchoID 获取指定 Echo 的 Webmention
+	GetWebmentionsByEchoID(echoID uint, status model.Status) ([]model.Webmention, error)
+
+	// GetWebmentionByID 根据 ID 获取 Webmention
+	GetWebmentionByID(id uint) (*model.Webmention, error)
+
+	// SaveWebmention 保存 Webmention（同一 source 与 target 已存在时更新内容，内容未变化时保留审核状态，变化时重新待审核）
+	SaveWebmention(ctx context.Context, webmention *model.Webmention) error
+
+	// UpdateStatus 更新审核状态
+	UpdateStatus(ctx context.Context, id uint, status model.Status) error
+
+	// DeleteWebmentionByID 根据 ID 删除 Webmention
+	DeleteWebmentionByID(ctx context.Context, id uint) error
+
+	// DeleteWebmention 根据 source 与 target 删除 Webmention
+	DeleteWebmention(ctx context.Context, source, target string) error
+
+	// DeleteWebmentionsByEchoID 删除指定 Echo 的所有 Webmention
+	DeleteWebmentionsByEchoID(ctx context.Context, echoID uint) error
+}
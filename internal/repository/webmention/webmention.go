@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	model "github.com/lin-snow/ech0/internal/model/webmention"
+	"github.com/lin-snow/ech0/internal/transaction"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebmentionRepository struct {
+	db *gorm.DB
+}
+
+func NewWebmentionRepository(db *gorm.DB) WebmentionRepositoryInterface {
+	return &WebmentionRepository{
+		db: db,
+	}
+}
+
+// getDB 从上下文中获取事务
+func (webmentionRepository *WebmentionRepository) getDB(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(transaction.TxKey).(*gorm.DB); ok {
+		return tx
+	}
+	return webmentionRepository.db
+}
+
+// GetWebmentionsByPage 分页获取 Webmention，status 为空时返回全部
+func (webmentionRepository *WebmentionRepository) GetWebmentionsByPage(page, pageSize int, status model.Status) ([]model.Webmention, int64, error) {
+	var webmentions []model.Webmention
+	var total int64
+
+	query := webmentionRepository.db.Model(&model.Webmention{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).
+		Order("created_at DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&webmentions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return webmentions, total, nil
+}
+
+// GetWebmentionsByEchoID 获取指定 Echo 的 Webmention
+func (webmentionRepository *WebmentionRepository) GetWebmentionsByEchoID(echoID uint, status model.Status) ([]model.Webmention, error) {
+	var webmentions []model.Webmention
+	if err := webmentionRepository.db.
+		Where("echo_id = ? AND status = ?", echoID, status).
+		Order("created_at ASC").
+		Find(&webmentions).Error; err != nil {
+		return nil, err
+	}
+	return webmentions, nil
+}
+
+// GetWebmentionByID 根据 ID 获取 Webmention
+func (webmentionRepository *WebmentionRepository) GetWebmentionByID(id uint) (*model.Webmention, error) {
+	var webmention model.Webmention
+	if err := webmentionRepository.db.First(&webmention, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &webmention, nil
+}
+
+// SaveWebmention 保存 Webmention（同一 source 与 target 已存在时更新内容，内容未变化时保留审核状态，变化时重新待审核）
+func (webmentionRepository *WebmentionRepository) SaveWebmention(ctx context.Context, webmention *model.Webmention) error {
+	updates := clause.AssignmentColumns([]string{"echo_id", "title", "excerpt", "author_name", "verified_at", "updated_at"})
+	// source 内容变化后需要重新审核，避免已通过的 Webmention 被替换为未审核的内容
+	updates = append(updates, clause.Assignment{
+		Column: clause.Column{Name: "status"},
+		Value: gorm.Expr(
+			"CASE WHEN title = excluded.title AND excerpt = excluded.excerpt AND author_name = excluded.author_name THEN status ELSE ? END",
+			model.StatusPending,
+		),
+	})
+
+	return webmentionRepository.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}, {Name: "target"}},
+		DoUpdates: updates,
+	}).Create(webmention).Error
+}
+
+// UpdateStatus 更新审核状态
+func (webmentionRepository *WebmentionRepository) UpdateStatus(ctx context.Context, id uint, status model.Status) error {
+	result := webmentionRepository.getDB(ctx).
+		Model(&model.Webmention{}).
+		Where("id = ?", id).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteWebmentionByID 根据 ID 删除 Webmention
+func (webmentionRepository *WebmentionRepository) DeleteWebmentionByID(ctx context.Context, id uint) error {
+	result := webmentionRepository.getDB(ctx).Delete(&model.Webmention{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteWebmention 根据 source 与 target 删除 Webmention
+func (webmentionRepository *WebmentionRepository) DeleteWebmention(ctx context.Context, source, target string) error {
+	return webmentionRepository.getDB(ctx).
+		Where("source = ? AND target = ?", source, target).
+		Delete(&model.Webmention{}).Error
+}
+
+// DeleteWebmentionsByEchoID 删除指定 Echo 的所有 Webmention
+func (webmentionRepository *WebmentionRepository) DeleteWebmentionsByEchoID(ctx context.Context, echoID uint) error {
+	return webmentionRepository.getDB(ctx).
+		Where("echo_id = ?", echoID).
+		Delete(&model.Webmention{}).Error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/websub"
+)
+
+type WebSubRepositoryInterface interface {
+	// GetActiveSubscriptions 获取未过期的订阅
+	GetActiveSubscriptions(now time.Time) ([]model.Subscription, error)
+
+	// SaveSubscription 保存订阅（同一 topic 与 callback 已存在时续期）
+	SaveSubscription(ctx context.Context, subscription *model.Subscription) error
+
+	// DeleteSubscription 删除订阅
+	DeleteSubscription(ctx context.Context, topic, callback string) error
+
+	// DeleteExpiredSubscriptions 删除已过期的订阅
+	DeleteExpiredSubscriptions(ctx context.Context, now time.Time) error
+
+	// UpdateContentHash 更新最近一次推送的内容摘要
+	UpdateContentHash(ctx context.Context, id uint, contentHash string) error
+}
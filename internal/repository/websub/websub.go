@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/websub"
+	"github.com/lin-snow/ech0/internal/transaction"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebSubRepository struct {
+	db *gorm.DB
+}
+
+func NewWebSubRepository(db *gorm.DB) WebSubRepositoryInterface {
+	return &WebSubRepository{
+		db: db,
+	}
+}
+
+// getDB 从上下文中获取事务
+func (webSubRepository *WebSubRepository) getDB(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(transaction.TxKey).(*gorm.DB); ok {
+		return tx
+	}
+	return webSubRepository.db
+}
+
+// GetActiveSubscriptions 获取未过期的订阅
+func (webSubRepository *WebSubRepository) GetActiveSubscriptions(now time.Time) ([]model.Subscription, error) {
+	var subscriptions []model.Subscription
+	if err := webSubRepository.db.
+		Where("expires_at > ?", now).
+		Order("id ASC").
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// SaveSubscription 保存订阅（同一 topic 与 callback 已存在时续期）
+func (webSubRepository *WebSubRepository) SaveSubscription(ctx context.Context, subscription *model.Subscription) error {
+	return webSubRepository.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "topic"}, {Name: "callback"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "lease_seconds", "expires_at", "content_hash", "updated_at"}),
+	}).Create(subscription).Error
+}
+
+// DeleteSubscription 删除订阅
+func (webSubRepository *WebSubRepository) DeleteSubscription(ctx context.Context, topic, callback string) error {
+	return webSubRepository.getDB(ctx).
+		Where("topic = ? AND callback = ?", topic, callback).
+		Delete(&model.Subscription{}).Error
+}
+
+// DeleteExpiredSubscriptions 删除已过期的订阅
+func (webSubRepository *WebSubRepository) DeleteExpiredSubscriptions(ctx context.Context, now time.Time) error {
+	return webSubRepository.getDB(ctx).
+		Where("expires_at <= ?", now).
+		Delete(&model.Subscription{}).Error
+}
+
+// UpdateContentHash 更新最近一次推送的内容摘要
+func (webSubRepository *WebSubRepository) UpdateContentHash(ctx context.Context, id uint, contentHash string) error {
+	return webSubRepository.getDB(ctx).
+		Model(&model.Subscription{}).
+		Where("id = ?", id).
+		Update("content_hash", contentHash).Error
+}
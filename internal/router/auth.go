@@ -0,0 +1,43 @@
+package router
+
+import (
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/di"
+	"github.com/lin-snow/ech0/internal/middleware"
+)
+
+// setupAuthRoutes 设置认证与会话路由
+func setupAuthRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
+	// Resource
+	appRouterGroup.ResourceGroup.GET("/.well-known/jwks.json", h.AuthHandler.GetJWKS())
+
+	// Public
+	appRouterGroup.PublicRouterGroup.POST("/register", h.AuthHandler.Register())
+	appRouterGroup.PublicRouterGroup.POST("/login", h.AuthHandler.LegacyLogin())
+	appRouterGroup.PublicRouterGroup.POST("/auth/login", h.AuthHandler.Login())
+	appRouterGroup.PublicRouterGroup.POST("/auth/refresh", h.AuthHandler.Refresh())
+	appRouterGroup.PublicRouterGroup.POST("/auth/2fa/verify", h.AuthHandler.VerifyTwoFactor())
+	appRouterGroup.PublicRouterGroup.GET("/auth/oidc/providers", h.AuthHandler.GetOIDCProviders())
+	appRouterGroup.PublicRouterGroup.GET("/auth/oidc/:provider/login", h.AuthHandler.OIDCLogin())
+	appRouterGroup.PublicRouterGroup.GET("/auth/oidc/:provider/callback", h.AuthHandler.OIDCCallback())
+
+	// Auth
+	appRouterGroup.AuthRouterGroup.POST("/auth/logout", h.AuthHandler.Logout())
+	appRouterGroup.AuthRouterGroup.GET("/auth/sessions", h.AuthHandler.GetSessions())
+	appRouterGroup.AuthRouterGroup.DELETE("/auth/sessions/:id", h.AuthHandler.RevokeSession())
+	appRouterGroup.AuthRouterGroup.DELETE("/auth/sessions", h.AuthHandler.RevokeAllSessions())
+	appRouterGroup.AuthRouterGroup.GET("/auth/tokens", h.AuthHandler.GetAccessTokens())
+	appRouterGroup.AuthRouterGroup.POST("/auth/tokens", h.AuthHandler.CreateAccessToken())
+	appRouterGroup.AuthRouterGroup.DELETE("/auth/tokens/:id", h.AuthHandler.DeleteAccessToken())
+	appRouterGroup.AuthRouterGroup.GET("/auth/2fa", h.AuthHandler.GetTwoFactorStatus())
+	appRouterGroup.AuthRouterGroup.POST("/auth/2fa/setup", h.AuthHandler.SetupTwoFactor())
+	appRouterGroup.AuthRouterGroup.POST("/auth/2fa/enable", h.AuthHandler.EnableTwoFactor())
+	appRouterGroup.AuthRouterGroup.POST("/auth/2fa/disable", h.AuthHandler.DisableTwoFactor())
+	appRouterGroup.AuthRouterGroup.POST("/auth/2fa/recovery-codes", h.AuthHandler.RegenerateRecoveryCodes())
+	appRouterGroup.AuthRouterGroup.POST("/auth/oidc/:provider/link", h.AuthHandler.LinkOIDC())
+	appRouterGroup.AuthRouterGroup.GET("/auth/oidc/identities", h.AuthHandler.GetOIDCIdentities())
+	appRouterGroup.AuthRouterGroup.DELETE("/auth/oidc/identities/:id", h.AuthHandler.DeleteOIDCIdentity())
+	appRouterGroup.AuthRouterGroup.GET("/auth/lockouts", middleware.RequirePermission(authz.PermUsersManage), h.AuthHandler.GetLockouts())
+	appRouterGroup.AuthRouterGroup.DELETE("/auth/lockouts/:id", middleware.RequirePermission(authz.PermUsersManage), h.AuthHandler.ClearLockout())
+	appRouterGroup.AuthRouterGroup.DELETE("/auth/lockouts", middleware.RequirePermission(authz.PermUsersManage), h.AuthHandler.ClearAllLockouts())
+}
@@ -1,21 +1,27 @@
 package router
 
-import "github.com/lin-snow/ech0/internal/di"
+import (
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/di"
+	"github.com/lin-snow/ech0/internal/middleware"
+)
 
 // setupCommonRoutes 设置普通路由
 func setupCommonRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	// Public
 	appRouterGroup.PublicRouterGroup.GET("/status", h.CommonHandler.GetStatus())
-	appRouterGroup.PublicRouterGroup.GET("/heatmap", h.CommonHandler.GetHeatMap())
 	appRouterGroup.PublicRouterGroup.GET("/getmusic", h.CommonHandler.GetPlayMusic())
 	appRouterGroup.PublicRouterGroup.GET("/playmusic", h.CommonHandler.PlayMusic)
 	appRouterGroup.PublicRouterGroup.GET("/hello", h.CommonHandler.HelloEch0())
 
+	// Optional Auth
+	appRouterGroup.OptionalAuthRouterGroup.GET("/heatmap", h.CommonHandler.GetHeatMap())
+
 	// Auth
-	appRouterGroup.AuthRouterGroup.POST("/images/upload", h.CommonHandler.UploadImage())
-	appRouterGroup.AuthRouterGroup.DELETE("/images/delete", h.CommonHandler.DeleteImage())
-	appRouterGroup.AuthRouterGroup.POST("/audios/upload", h.CommonHandler.UploadAudio())
-	appRouterGroup.AuthRouterGroup.DELETE("/audios/delete", h.CommonHandler.DeleteAudio())
-	appRouterGroup.AuthRouterGroup.GET("/backup", h.BackupHandler.Backup())
-	appRouterGroup.AuthRouterGroup.GET("/backup/export", h.BackupHandler.ExportBackup())
+	appRouterGroup.AuthRouterGroup.POST("/images/upload", middleware.RequirePermission(authz.PermMediaUpload), h.CommonHandler.UploadImage())
+	appRouterGroup.AuthRouterGroup.DELETE("/images/delete", middleware.RequirePermission(authz.PermMediaDelete), h.CommonHandler.DeleteImage())
+	appRouterGroup.AuthRouterGroup.POST("/audios/upload", middleware.RequirePermission(authz.PermSettingsManage), h.CommonHandler.UploadAudio())
+	appRouterGroup.AuthRouterGroup.DELETE("/audios/delete", middleware.RequirePermission(authz.PermSettingsManage), h.CommonHandler.DeleteAudio())
+	appRouterGroup.AuthRouterGroup.GET("/backup", middleware.RequirePermission(authz.PermBackup), h.BackupHandler.Backup())
+	appRouterGroup.AuthRouterGroup.GET("/backup/export", middleware.RequirePermission(authz.PermBackup), h.BackupHandler.ExportBackup())
 }
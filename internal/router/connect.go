@@ -1,6 +1,10 @@
 package router
 
-import "github.com/lin-snow/ech0/internal/di"
+import (
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/di"
+	"github.com/lin-snow/ech0/internal/middleware"
+)
 
 // setupConnectRoutes 设置连接路由
 func setupConnectRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
@@ -8,8 +12,22 @@ func setupConnectRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	appRouterGroup.PublicRouterGroup.GET("/connect", h.ConnectHandler.GetConnect())
 	appRouterGroup.PublicRouterGroup.GET("/connect/list", h.ConnectHandler.GetConnects())
 	appRouterGroup.PublicRouterGroup.GET("/connects/info", h.ConnectHandler.GetConnectsInfo())
+	appRouterGroup.PublicRouterGroup.GET("/connects/timeline", h.ConnectHandler.GetTimeline())
+	appRouterGroup.PublicRouterGroup.POST("/connect/handshake", h.ConnectHandler.Handshake())
+	appRouterGroup.PublicRouterGroup.GET("/connect/peers", h.ConnectHandler.GetPeers())
 
 	// Auth
-	appRouterGroup.AuthRouterGroup.POST("/addConnect", h.ConnectHandler.AddConnect())
-	appRouterGroup.AuthRouterGroup.DELETE("/delConnect/:id", h.ConnectHandler.DeleteConnect())
+	appRouterGroup.AuthRouterGroup.POST("/addConnect", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.AddConnect())
+	appRouterGroup.AuthRouterGroup.DELETE("/delConnect/:id", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.DeleteConnect())
+	appRouterGroup.AuthRouterGroup.GET("/connects", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.GetAllConnects())
+	appRouterGroup.AuthRouterGroup.PUT("/connects/:id/accept", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.AcceptConnect())
+	appRouterGroup.AuthRouterGroup.PUT("/connects/:id/reject", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.RejectConnect())
+	appRouterGroup.AuthRouterGroup.GET("/connects/health", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.GetConnectsHealth())
+	appRouterGroup.AuthRouterGroup.POST("/connects/health/refresh", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.RefreshConnectsHealth())
+	appRouterGroup.AuthRouterGroup.GET("/connects/suggestions", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.GetSuggestions())
+	appRouterGroup.AuthRouterGroup.PUT("/connects/suggestions/:id/accept", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.AcceptSuggestion())
+	appRouterGroup.AuthRouterGroup.PUT("/connects/suggestions/:id/dismiss", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.DismissSuggestion())
+	appRouterGroup.AuthRouterGroup.POST("/connects/discover", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.DiscoverPeers())
+	appRouterGroup.AuthRouterGroup.GET("/connects/opml", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.ExportOPML())
+	appRouterGroup.AuthRouterGroup.POST("/connects/opml", middleware.RequirePermission(authz.PermConnectManage), h.ConnectHandler.ImportOPML())
 }
@@ -1,18 +1,29 @@
 package router
 
-import "github.com/lin-snow/ech0/internal/di"
+import (
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/di"
+	"github.com/lin-snow/ech0/internal/middleware"
+)
 
 // setupEchoRoutes 设置Echo路由
 func setupEchoRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	// Public
 	appRouterGroup.PublicRouterGroup.PUT("/echo/like/:id", h.EchoHandler.LikeEcho())
 
+	// Optional Auth
+	appRouterGroup.OptionalAuthRouterGroup.GET("/echo/page", h.EchoHandler.GetEchosByPage())
+	appRouterGroup.OptionalAuthRouterGroup.POST("/echo/page", h.EchoHandler.GetEchosByPage())
+	appRouterGroup.OptionalAuthRouterGroup.GET("/echo/today", h.EchoHandler.GetTodayEchos())
+	appRouterGroup.OptionalAuthRouterGroup.GET("/echo/:id", h.EchoHandler.GetEchoById())
+	appRouterGroup.OptionalAuthRouterGroup.GET("/echo/archive", h.EchoHandler.GetArchive())
+	appRouterGroup.OptionalAuthRouterGroup.GET("/echo/archive/:year/:month", h.EchoHandler.GetEchosByMonth())
+	appRouterGroup.OptionalAuthRouterGroup.GET("/echo/onthisday", h.EchoHandler.GetOnThisDayEchos())
+	appRouterGroup.OptionalAuthRouterGroup.GET("/echo/digest", h.EchoHandler.GetDailyDigest())
+	appRouterGroup.OptionalAuthRouterGroup.GET("/stats", h.EchoHandler.GetEchoStats())
+
 	// Auth
-	appRouterGroup.AuthRouterGroup.POST("/echo", h.EchoHandler.PostEcho())
-	appRouterGroup.AuthRouterGroup.GET("/echo/page", h.EchoHandler.GetEchosByPage())
-	appRouterGroup.AuthRouterGroup.POST("/echo/page", h.EchoHandler.GetEchosByPage())
+	appRouterGroup.AuthRouterGroup.POST("/echo", middleware.RequirePermission(authz.PermEchoCreate), h.EchoHandler.PostEcho())
 	appRouterGroup.AuthRouterGroup.DELETE("/echo/:id", h.EchoHandler.DeleteEcho())
-	appRouterGroup.AuthRouterGroup.GET("/echo/today", h.EchoHandler.GetTodayEchos())
 	appRouterGroup.AuthRouterGroup.PUT("/echo", h.EchoHandler.UpdateEcho())
-	appRouterGroup.AuthRouterGroup.GET("/echo/:id", h.EchoHandler.GetEchoById())
 }
@@ -0,0 +1,13 @@
+package router
+
+import "github.com/lin-snow/ech0/internal/di"
+
+// setupFediverseRoutes 设置联邦宇宙（ActivityPub）路由
+func setupFediverseRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
+	appRouterGroup.ResourceGroup.GET("/.well-known/webfinger", h.FediverseHandler.GetWebFinger)
+	appRouterGroup.ResourceGroup.GET("/ap/users/:username", h.FediverseHandler.GetActor)
+	appRouterGroup.ResourceGroup.GET("/ap/users/:username/outbox", h.FediverseHandler.GetOutbox)
+	appRouterGroup.ResourceGroup.GET("/ap/users/:username/followers", h.FediverseHandler.GetFollowers)
+	appRouterGroup.ResourceGroup.POST("/ap/users/:username/inbox", h.FediverseHandler.PostInbox)
+	appRouterGroup.ResourceGroup.GET("/ap/echos/:id", h.FediverseHandler.GetNote)
+}
@@ -0,0 +1,14 @@
+package router
+
+import (
+	"github.com/lin-snow/ech0/internal/di"
+	"github.com/lin-snow/ech0/internal/middleware"
+)
+
+// setupMicropubRoutes 设置 Micropub 路由
+func setupMicropubRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
+	micropubGroup := appRouterGroup.ResourceGroup.Group("/micropub", middleware.MicropubAuthMiddleware(h.AuthHandler))
+	micropubGroup.GET("", h.MicropubHandler.Query)
+	micropubGroup.POST("", h.MicropubHandler.Post)
+	micropubGroup.POST("/media", h.MicropubHandler.UploadMedia)
+}
@@ -13,4 +13,6 @@ func setupResourceRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	appRouterGroup.ResourceGroup.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	appRouterGroup.ResourceGroup.GET("/rss", h.CommonHandler.GetRss)
+	appRouterGroup.ResourceGroup.GET("/feed/:format", h.CommonHandler.GetFeed)
+	appRouterGroup.ResourceGroup.GET("/users/:username/feed/:format", h.CommonHandler.GetUserFeed)
 }
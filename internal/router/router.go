@@ -7,9 +7,10 @@ import (
 )
 
 type AppRouterGroup struct {
-	ResourceGroup     *gin.RouterGroup
-	PublicRouterGroup *gin.RouterGroup
-	AuthRouterGroup   *gin.RouterGroup
+	ResourceGroup           *gin.RouterGroup
+	PublicRouterGroup       *gin.RouterGroup
+	OptionalAuthRouterGroup *gin.RouterGroup // 未登录也可访问，登录后按当前用户的权限返回数据
+	AuthRouterGroup         *gin.RouterGroup
 }
 
 // SetupRouter 配置路由
@@ -34,11 +35,14 @@ func SetupRouter(r *gin.Engine, h *di.Handlers) {
 
 	// ===  路由组与各模块路由  ===
 	// Setup Router Groups
-	appRouterGroup := setupRouterGroup(r)
+	appRouterGroup := setupRouterGroup(r, h)
 
 	// Setup Resource Routes
 	setupResourceRoutes(appRouterGroup, h)
 
+	// Setup Auth Routes
+	setupAuthRoutes(appRouterGroup, h)
+
 	// Setup User Routes
 	setupUserRoutes(appRouterGroup, h)
 
@@ -56,17 +60,32 @@ func SetupRouter(r *gin.Engine, h *di.Handlers) {
 
 	// Setup Connect Routes
 	setupConnectRoutes(appRouterGroup, h)
+
+	// Setup Fediverse Routes
+	setupFediverseRoutes(appRouterGroup, h)
+
+	// Setup WebSub Routes
+	setupWebSubRoutes(appRouterGroup, h)
+
+	// Setup Webmention Routes
+	setupWebmentionRoutes(appRouterGroup, h)
+
+	// Setup Micropub Routes
+	setupMicropubRoutes(appRouterGroup, h)
 }
 
 // setupRouterGroup 初始化路由组
-func setupRouterGroup(r *gin.Engine) *AppRouterGroup {
+func setupRouterGroup(r *gin.Engine, h *di.Handlers) *AppRouterGroup {
 	resource := r.Group("/")
 	public := r.Group("/api")
+	optionalAuth := r.Group("/api")
+	optionalAuth.Use(middleware.OptionalAuthMiddleware(h.AuthHandler))
 	auth := r.Group("/api")
-	auth.Use(middleware.JWTAuthMiddleware())
+	auth.Use(middleware.JWTAuthMiddleware(h.AuthHandler))
 	return &AppRouterGroup{
-		ResourceGroup:     resource,
-		PublicRouterGroup: public,
-		AuthRouterGroup:   auth,
+		ResourceGroup:           resource,
+		PublicRouterGroup:       public,
+		OptionalAuthRouterGroup: optionalAuth,
+		AuthRouterGroup:         auth,
 	}
 }
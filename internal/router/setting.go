@@ -1,6 +1,10 @@
 package router
 
-import "github.com/lin-snow/ech0/internal/di"
+import (
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/di"
+	"github.com/lin-snow/ech0/internal/middleware"
+)
 
 // setupSettingRoutes 设置设置路由
 func setupSettingRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
@@ -9,6 +13,6 @@ func setupSettingRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	appRouterGroup.PublicRouterGroup.GET("/comment/settings", h.SettingHandler.GetCommentSettings())
 
 	// Auth
-	appRouterGroup.AuthRouterGroup.PUT("/settings", h.SettingHandler.UpdateSettings())
-	appRouterGroup.AuthRouterGroup.PUT("/comment/settings", h.SettingHandler.UpdateCommentSettings())
+	appRouterGroup.AuthRouterGroup.PUT("/settings", middleware.RequirePermission(authz.PermSettingsManage), h.SettingHandler.UpdateSettings())
+	appRouterGroup.AuthRouterGroup.PUT("/comment/settings", middleware.RequirePermission(authz.PermSettingsManage), h.SettingHandler.UpdateCommentSettings())
 }
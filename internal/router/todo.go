@@ -1,7 +1,9 @@
 package router
 
 import (
+	"github.com/lin-snow/ech0/internal/authz"
 	"github.com/lin-snow/ech0/internal/di"
+	"github.com/lin-snow/ech0/internal/middleware"
 )
 
 // setupTodoRoutes 设置待办事项路由
@@ -9,8 +11,8 @@ func setupTodoRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	// Public
 
 	// Auth
-	appRouterGroup.AuthRouterGroup.GET("/todo", h.TodoHandler.GetTodoList())
-	appRouterGroup.AuthRouterGroup.POST("/todo", h.TodoHandler.AddTodo())
-	appRouterGroup.AuthRouterGroup.PUT("/todo/:id", h.TodoHandler.UpdateTodo())
-	appRouterGroup.AuthRouterGroup.DELETE("/todo/:id", h.TodoHandler.DeleteTodo())
+	appRouterGroup.AuthRouterGroup.GET("/todo", middleware.RequirePermission(authz.PermTodoManage), h.TodoHandler.GetTodoList())
+	appRouterGroup.AuthRouterGroup.POST("/todo", middleware.RequirePermission(authz.PermTodoManage), h.TodoHandler.AddTodo())
+	appRouterGroup.AuthRouterGroup.PUT("/todo/:id", middleware.RequirePermission(authz.PermTodoManage), h.TodoHandler.UpdateTodo())
+	appRouterGroup.AuthRouterGroup.DELETE("/todo/:id", middleware.RequirePermission(authz.PermTodoManage), h.TodoHandler.DeleteTodo())
 }
@@ -1,17 +1,25 @@
 package router
 
-import "github.com/lin-snow/ech0/internal/di"
+import (
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/di"
+	"github.com/lin-snow/ech0/internal/middleware"
+)
 
 // setupUserRoutes 设置用户路由
 func setupUserRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	// Public
-	appRouterGroup.PublicRouterGroup.POST("/login", h.UserHandler.Login())
-	appRouterGroup.PublicRouterGroup.POST("/register", h.UserHandler.Register())
 	appRouterGroup.PublicRouterGroup.GET("/allusers", h.UserHandler.GetAllUsers())
 
+	// Optional Auth
+	appRouterGroup.OptionalAuthRouterGroup.GET("/users/:username", h.UserHandler.GetUserProfile())
+	appRouterGroup.OptionalAuthRouterGroup.GET("/users/:username/echos", h.EchoHandler.GetUserEchos())
+	appRouterGroup.OptionalAuthRouterGroup.GET("/users/:username/heatmap", h.CommonHandler.GetUserHeatMap())
+
 	// Auth
 	appRouterGroup.AuthRouterGroup.GET("/user", h.UserHandler.GetUserInfo())
-	appRouterGroup.AuthRouterGroup.PUT("/user", h.UserHandler.UpdateUser())
-	appRouterGroup.AuthRouterGroup.DELETE("/user/:id", h.UserHandler.DeleteUser())
-	appRouterGroup.AuthRouterGroup.PUT("/user/admin/:id", h.UserHandler.UpdateUserAdmin())
+	appRouterGroup.AuthRouterGroup.PUT("/user", middleware.RequirePermission(authz.PermProfileUpdate), h.UserHandler.UpdateUser())
+	appRouterGroup.AuthRouterGroup.DELETE("/user/:id", middleware.RequirePermission(authz.PermUsersManage), h.UserHandler.DeleteUser())
+	appRouterGroup.AuthRouterGroup.PUT("/user/admin/:id", middleware.RequirePermission(authz.PermUsersManage), h.UserHandler.UpdateUserAdmin())
+	appRouterGroup.AuthRouterGroup.PUT("/user/:id/role", middleware.RequirePermission(authz.PermUsersManage), h.UserHandler.UpdateUserRole())
 }
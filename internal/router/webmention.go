@@ -0,0 +1,19 @@
+package router
+
+import (
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/di"
+	"github.com/lin-snow/ech0/internal/middleware"
+)
+
+// setupWebmentionRoutes 设置 Webmention 路由
+func setupWebmentionRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
+	// Resource
+	appRouterGroup.ResourceGroup.POST("/webmention", h.WebmentionHandler.ReceiveWebmention)
+	appRouterGroup.ResourceGroup.GET("/webmention/echos/:id", h.WebmentionHandler.GetEchoSource)
+
+	// Auth
+	appRouterGroup.AuthRouterGroup.GET("/webmentions", middleware.RequirePermission(authz.PermWebmentionModerate), h.WebmentionHandler.GetWebmentions())
+	appRouterGroup.AuthRouterGroup.PUT("/webmentions/:id", middleware.RequirePermission(authz.PermWebmentionModerate), h.WebmentionHandler.UpdateWebmentionStatus())
+	appRouterGroup.AuthRouterGroup.DELETE("/webmentions/:id", middleware.RequirePermission(authz.PermWebmentionModerate), h.WebmentionHandler.DeleteWebmention())
+}
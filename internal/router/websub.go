@@ -0,0 +1,8 @@
+package router
+
+import "github.com/lin-snow/ech0/internal/di"
+
+// setupWebSubRoutes 设置 WebSub 路由
+func setupWebSubRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
+	appRouterGroup.ResourceGroup.POST("/websub/hub", h.WebSubHandler.Hub)
+}
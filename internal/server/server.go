@@ -43,7 +43,14 @@ func (s *Server) Init() {
 	}
 
 	// Gin Engine
-	s.GinEngine = gin.New()
+	engine, err := newEngine()
+	if err != nil {
+		errUtil.HandlePanicError(&commonModel.ServerError{
+			Msg: commonModel.TRUSTED_PROXIES_PANIC,
+			Err: err,
+		})
+	}
+	s.GinEngine = engine
 
 	// Database
 	database.InitDatabase()
@@ -67,6 +74,16 @@ func (s *Server) Init() {
 	router.SetupRouter(s.GinEngine, handlers)
 }
 
+// newEngine 创建 Gin 引擎，只信任配置中的反向代理，
+// 避免客户端伪造 X-Forwarded-For 绕过按 IP 的锁定和限流
+func newEngine() (*gin.Engine, error) {
+	engine := gin.New()
+	if err := engine.SetTrustedProxies(config.Config.Server.TrustedProxies); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
 // Start 异步启动服务器
 func (s *Server) Start() {
 	port := config.Config.Server.Port
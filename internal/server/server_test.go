@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lin-snow/ech0/internal/config"
+)
+
+// newCountingEngine 创建按客户端 IP 统计请求次数的引擎，与登录、注册锁定使用相同的 IP 来源
+func newCountingEngine(t *testing.T, trustedProxies []string) (*gin.Engine, map[string]int) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	original := config.Config.Server.TrustedProxies
+	config.Config.Server.TrustedProxies = trustedProxies
+	t.Cleanup(func() { config.Config.Server.TrustedProxies = original })
+
+	engine, err := newEngine()
+	require.NoError(t, err)
+
+	counter := map[string]int{}
+	engine.POST("/login", func(ctx *gin.Context) {
+		counter[ctx.ClientIP()]++
+		ctx.Status(http.StatusOK)
+	})
+	return engine, counter
+}
+
+func sendLogin(engine *gin.Engine, remoteAddr, forwardedFor string) {
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.Header.Set("X-Real-IP", forwardedFor)
+	}
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+// 未配置可信代理时，伪造的 X-Forwarded-For 不能换出新的 IP 来重置失败次数
+func TestNewEngine_IgnoresSpoofedForwardedFor(t *testing.T) {
+	engine, counter := newCountingEngine(t, nil)
+
+	for _, spoofed := range []string{"", "1.1.1.1", "2.2.2.2", "3.3.3.3, 4.4.4.4"} {
+		sendLogin(engine, "203.0.113.7:51234", spoofed)
+	}
+
+	assert.Equal(t, map[string]int{"203.0.113.7": 4}, counter)
+}
+
+// 来自可信代理的请求使用代理转发的客户端 IP，其他来源仍然忽略转发头部
+func TestNewEngine_TrustsConfiguredProxies(t *testing.T) {
+	engine, counter := newCountingEngine(t, []string{"10.0.0.0/8"})
+
+	sendLogin(engine, "10.0.0.2:40000", "198.51.100.9")
+	sendLogin(engine, "203.0.113.7:51234", "198.51.100.9")
+
+	assert.Equal(t, map[string]int{"198.51.100.9": 1, "203.0.113.7": 1}, counter)
+}
+
+func TestNewEngine_RejectsInvalidProxy(t *testing.T) {
+	config.Config.Server.TrustedProxies = []string{"not-an-ip"}
+	t.Cleanup(func() { config.Config.Server.TrustedProxies = nil })
+
+	_, err := newEngine()
+	assert.Error(t, err)
+}
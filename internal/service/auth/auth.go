@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"github.com/lin-snow/ech0/internal/config"
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	repository "github.com/lin-snow/ech0/internal/repository/auth"
+	settingService "github.com/lin-snow/ech0/internal/service/setting"
+	userService "github.com/lin-snow/ech0/internal/service/user"
+	"github.com/lin-snow/ech0/internal/transaction"
+	jwtUtil "github.com/lin-snow/ech0/internal/util/jwt"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+type AuthService struct {
+	txManager      transaction.TransactionManager         // 事务管理器
+	authRepository repository.AuthRepositoryInterface     // 会话数据层接口
+	userService    userService.UserServiceInterface       // 用户服务接口
+	settingService settingService.SettingServiceInterface // 系统设置服务接口
+
+	keyMu        sync.RWMutex                // 保护签名密钥缓存
+	keys         map[string]model.SigningKey // 按 kid 缓存的签名密钥
+	activeKey    model.SigningKey            // 当前用于签发的密钥
+	keysLoadedAt time.Time                   // 上次从数据库加载密钥的时间
+
+	oidcMu        sync.Mutex              // 保护身份提供者缓存
+	oidcProviders map[string]oidcProvider // 按标识缓存的身份提供者元数据和公钥
+	oidcFetch     singleflight.Group      // 合并同一身份提供者的并发请求
+}
+
+func NewAuthService(
+	tm transaction.TransactionManager,
+	authRepository repository.AuthRepositoryInterface,
+	userService userService.UserServiceInterface,
+	settingService settingService.SettingServiceInterface,
+) AuthServiceInterface {
+	return &AuthService{
+		txManager:      tm,
+		authRepository: authRepository,
+		userService:    userService,
+		settingService: settingService,
+	}
+}
+
+// Login 校验用户名和密码，创建会话并签发访问令牌和刷新令牌
+// 开启两步验证且没有附带验证码时，返回用于提交验证码的凭据
+func (authService *AuthService) Login(loginDto *model.LoginDto, client model.ClientInfo) (model.LoginResult, error) {
+	user, passed, err := authService.authenticate(loginDto, client)
+	if err != nil {
+		return model.LoginResult{}, err
+	}
+	if !passed {
+		challenge, err := authService.createChallenge(user)
+		if err != nil {
+			return model.LoginResult{}, err
+		}
+		return model.LoginResult{TwoFactorRequired: true, Challenge: challenge}, nil
+	}
+
+	tokens, err := authService.issueSession(user, client)
+	if err != nil {
+		return model.LoginResult{}, err
+	}
+
+	return model.LoginResult{TokenPair: &tokens}, nil
+}
+
+// LegacyLogin 兼容旧版登录接口，创建会话并只签发一个长期有效的访问令牌
+// 开启两步验证时需要在请求中附带验证码
+func (authService *AuthService) LegacyLogin(loginDto *model.LoginDto, client model.ClientInfo) (string, error) {
+	user, passed, err := authService.authenticate(loginDto, client)
+	if err != nil {
+		return "", err
+	}
+	if !passed {
+		return "", errors.New(commonModel.TWO_FACTOR_REQUIRED)
+	}
+
+	expires := time.Duration(config.Config.Auth.Jwt.Expires) * time.Second
+	session, err := authService.createSession(user, client, "", expires)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := authService.signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	return jwtUtil.GenerateToken(jwtUtil.CreateClaims(user, session.ID, expires), key)
+}
+
+// Refresh 使用刷新令牌换取新的令牌，旧的刷新令牌随即失效
+// 已经轮换过的刷新令牌再次被使用时，说明令牌可能已经泄露，撤销整个会话
+func (authService *AuthService) Refresh(refreshToken string, client model.ClientInfo) (model.TokenPair, error) {
+	hash := hashToken(refreshToken)
+
+	session, err := authService.authRepository.GetSessionByRefreshHash(hash)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.TokenPair{}, err
+		}
+
+		// 检查是否为已经轮换过的刷新令牌
+		reused, err := authService.authRepository.GetSessionByPreviousHash(hash)
+		if err == nil && reused.RevokedAt == nil {
+			logUtil.GetLogger().Warn("[刷新令牌被重复使用，撤销会话]",
+				zap.String("会话", reused.ID),
+				zap.Uint("用户", reused.UserID),
+				zap.String("IP", client.IP))
+			now := time.Now()
+			reused.RevokedAt = &now
+			if err := authService.txManager.Run(func(ctx context.Context) error {
+				return authService.authRepository.UpdateSession(ctx, &reused)
+			}); err != nil {
+				return model.TokenPair{}, err
+			}
+		}
+		return model.TokenPair{}, errors.New(commonModel.REFRESH_TOKEN_INVALID)
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return model.TokenPair{}, errors.New(commonModel.REFRESH_TOKEN_INVALID)
+	}
+
+	user, err := authService.userService.GetUserByID(int(session.UserID))
+	if err != nil {
+		return model.TokenPair{}, errors.New(commonModel.REFRESH_TOKEN_INVALID)
+	}
+
+	newRefreshToken, err := generateRefreshToken()
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	// 轮换刷新令牌，并顺延会话的有效期
+	now := time.Now()
+	session.PreviousTokenHash = hash
+	session.RefreshTokenHash = hashToken(newRefreshToken)
+	session.UserAgent = client.UserAgent
+	session.IP = client.IP
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(refreshExpires())
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		return authService.authRepository.UpdateSession(ctx, &session)
+	}); err != nil {
+		return model.TokenPair{}, err
+	}
+
+	return authService.issueTokenPair(user, session, newRefreshToken)
+}
+
+// Logout 撤销当前会话
+func (authService *AuthService) Logout(userid uint, sessionID string) error {
+	return authService.RevokeSession(userid, sessionID)
+}
+
+// GetSessions 获取用户的有效会话，并标记发起请求的会话
+func (authService *AuthService) GetSessions(userid uint, currentSessionID string) ([]model.Session, error) {
+	sessions, err := authService.authRepository.GetActiveSessions(userid)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return []model.Session{}, nil
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession 撤销用户的指定会话
+func (authService *AuthService) RevokeSession(userid uint, id string) error {
+	session, err := authService.authRepository.GetSessionByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(commonModel.SESSION_NOT_FOUND)
+		}
+		return err
+	}
+
+	// 只能撤销自己的会话
+	if session.UserID != userid {
+		return errors.New(commonModel.SESSION_NOT_FOUND)
+	}
+	if session.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+	return authService.txManager.Run(func(ctx context.Context) error {
+		return authService.authRepository.UpdateSession(ctx, &session)
+	})
+}
+
+// RevokeAllSessions 撤销用户的所有会话（包括当前会话）
+func (authService *AuthService) RevokeAllSessions(userid uint) error {
+	return authService.txManager.Run(func(ctx context.Context) error {
+		return authService.authRepository.RevokeUserSessions(ctx, userid)
+	})
+}
+
+// ValidateAccessToken 检查访问令牌所属的会话是否仍然有效
+func (authService *AuthService) ValidateAccessToken(claims *model.MyClaims) error {
+	// 没有绑定会话的令牌无法撤销，不再接受
+	if claims.SessionID == "" {
+		return errors.New(commonModel.TOKEN_NOT_VALID)
+	}
+
+	session, err := authService.authRepository.GetSessionByID(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(commonModel.SESSION_EXPIRED)
+		}
+		return err
+	}
+	if session.UserID != claims.Userid {
+		return errors.New(commonModel.TOKEN_NOT_VALID)
+	}
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return errors.New(commonModel.SESSION_EXPIRED)
+	}
+
+	// 定期记录会话最近使用的时间
+	now := time.Now()
+	if now.Sub(session.LastUsedAt) >= model.SessionTouchInterval {
+		if err := authService.txManager.Run(func(ctx context.Context) error {
+			return authService.authRepository.TouchSession(ctx, session.ID, now)
+		}); err != nil {
+			logUtil.GetLogger().Warn("[更新会话使用时间失败]", zap.String("会话", session.ID), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// LoadUser 加载令牌所属的用户，供鉴权中间件在每个请求中加载一次
+// 用户被删除后令牌立即失效
+func (authService *AuthService) LoadUser(userid uint) (userModel.User, error) {
+	user, err := authService.userService.GetUserByID(int(userid))
+	if err != nil {
+		return userModel.User{}, errors.New(commonModel.SESSION_EXPIRED)
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+// issueSession 为用户创建会话并签发访问令牌和刷新令牌
+func (authService *AuthService) issueSession(user userModel.User, client model.ClientInfo) (model.TokenPair, error) {
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	session, err := authService.createSession(user, client, hashToken(refreshToken), refreshExpires())
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	return authService.issueTokenPair(user, session, refreshToken)
+}
+
+// createSession 为用户创建会话，并顺带清理已过期或已撤销的会话
+func (authService *AuthService) createSession(user userModel.User, client model.ClientInfo, refreshHash string, expires time.Duration) (model.Session, error) {
+	now := time.Now()
+	session := model.Session{
+		ID:               uuid.NewString(),
+		UserID:           user.ID,
+		RefreshTokenHash: refreshHash,
+		UserAgent:        client.UserAgent,
+		IP:               client.IP,
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(expires),
+	}
+
+	err := authService.txManager.Run(func(ctx context.Context) error {
+		if err := authService.authRepository.DeleteStaleSessions(ctx); err != nil {
+			return err
+		}
+		return authService.authRepository.CreateSession(ctx, &session)
+	})
+
+	return session, err
+}
+
+// issueTokenPair 为会话签发短期访问令牌
+func (authService *AuthService) issueTokenPair(user userModel.User, session model.Session, refreshToken string) (model.TokenPair, error) {
+	key, err := authService.signingKey()
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	expires := accessExpires()
+	accessToken, err := jwtUtil.GenerateToken(jwtUtil.CreateClaims(user, session.ID, expires), key)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	return model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    model.TokenTypeBearer,
+		ExpiresIn:    int(expires.Seconds()),
+	}, nil
+}
+
+// accessExpires 访问令牌的有效期
+func accessExpires() time.Duration {
+	return time.Duration(config.Config.Auth.Jwt.AccessExpires) * time.Second
+}
+
+// refreshExpires 刷新令牌的有效期
+func refreshExpires() time.Duration {
+	return time.Duration(config.Config.Auth.Jwt.RefreshExpires) * time.Second
+}
+
+// generateRefreshToken 生成随机的刷新令牌
+func generateRefreshToken() (string, error) {
+	buf := make([]byte, model.RefreshTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken 计算刷新令牌的哈希，数据库中只保存哈希
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/config"
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	repository "github.com/lin-snow/ech0/internal/repository/auth"
+	userService "github.com/lin-snow/ech0/internal/service/user"
+	"github.com/lin-snow/ech0/internal/transaction"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+// fakeUserService 在内存中保存用户，只实现注册和身份提供者登录用到的方法
+type fakeUserService struct {
+	userService.UserServiceInterface
+	users map[uint]userModel.User
+}
+
+func (s *fakeUserService) GetUserByID(id int) (userModel.User, error) {
+	user, ok := s.users[uint(id)]
+	if !ok {
+		return userModel.User{}, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (s *fakeUserService) Register(registerDto *model.RegisterDto) error {
+	for _, user := range s.users {
+		if user.Username == registerDto.Username {
+			return errors.New(commonModel.USERNAME_HAS_EXISTS)
+		}
+	}
+	_, err := s.ProvisionUser(registerDto.Username, false)
+	return err
+}
+
+func (s *fakeUserService) ProvisionUser(username string, isAdmin bool) (userModel.User, error) {
+	user := userModel.User{ID: uint(len(s.users) + 1), Username: username}
+	if isAdmin {
+		user.SetRole(userModel.RoleAdmin)
+	} else {
+		user.SetRole(userModel.RoleReader)
+	}
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *fakeUserService) SetUserAdmin(id uint, isAdmin bool) error {
+	user, ok := s.users[id]
+	if !ok {
+		return errors.New(commonModel.USER_NOTFOUND)
+	}
+	if isAdmin {
+		user.SetRole(userModel.RoleAdmin)
+	} else {
+		user.SetRole(userModel.RoleReader)
+	}
+	s.users[id] = user
+	return nil
+}
+
+// newTestAuthService 使用临时数据库创建认证服务，测试结束后恢复认证配置
+func newTestAuthService(t *testing.T) (*AuthService, repository.AuthRepositoryInterface, *fakeUserService) {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "auth.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&model.Session{},
+		&model.SigningKey{},
+		&model.TwoFactor{},
+		&model.RecoveryCode{},
+		&model.LoginChallenge{},
+		&model.OIDCIdentity{},
+		&model.OIDCState{},
+		&model.Lockout{},
+	))
+
+	original := config.Config.Auth
+	config.Config.Auth.Jwt.AccessExpires = 900
+	config.Config.Auth.Jwt.RefreshExpires = 3600
+	t.Cleanup(func() { config.Config.Auth = original })
+
+	repo := repository.NewAuthRepository(db)
+	users := &fakeUserService{users: map[uint]userModel.User{}}
+	service := NewAuthService(transaction.NewTransactionManager(db), repo, users, nil).(*AuthService)
+
+	return service, repo, users
+}
+
+// 刷新令牌每次使用后轮换，旧的刷新令牌再次被使用时撤销整个会话
+func TestRefresh_ReuseRevokesSession(t *testing.T) {
+	service, repo, users := newTestAuthService(t)
+	user := userModel.User{ID: 1, Username: "alice"}
+	users.users[user.ID] = user
+	client := model.ClientInfo{IP: "203.0.113.7", UserAgent: "test"}
+
+	first, err := service.issueSession(user, client)
+	require.NoError(t, err)
+	second, err := service.Refresh(first.RefreshToken, client)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	claims, err := service.ParseAccessToken(second.AccessToken)
+	require.NoError(t, err)
+	require.NoError(t, service.ValidateAccessToken(claims))
+
+	// 重复使用已轮换的刷新令牌
+	_, err = service.Refresh(first.RefreshToken, client)
+	assert.EqualError(t, err, commonModel.REFRESH_TOKEN_INVALID)
+
+	session, err := repo.GetSessionByID(claims.SessionID)
+	require.NoError(t, err)
+	assert.NotNil(t, session.RevokedAt)
+
+	// 会话被撤销后，最新的刷新令牌和访问令牌同样失效
+	_, err = service.Refresh(second.RefreshToken, client)
+	assert.EqualError(t, err, commonModel.REFRESH_TOKEN_INVALID)
+	assert.EqualError(t, service.ValidateAccessToken(claims), commonModel.SESSION_EXPIRED)
+}
+
+// 未知的刷新令牌不会影响任何会话
+func TestRefresh_UnknownToken(t *testing.T) {
+	service, _, users := newTestAuthService(t)
+	user := userModel.User{ID: 1, Username: "alice"}
+	users.users[user.ID] = user
+
+	pair, err := service.issueSession(user, model.ClientInfo{})
+	require.NoError(t, err)
+
+	_, err = service.Refresh("unknown", model.ClientInfo{})
+	assert.EqualError(t, err, commonModel.REFRESH_TOKEN_INVALID)
+
+	_, err = service.Refresh(pair.RefreshToken, model.ClientInfo{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+type AuthServiceInterface interface {
+	// Login 校验用户名和密码，创建会话并签发访问令牌和刷新令牌，开启两步验证时返回验证凭据
+	Login(loginDto *model.LoginDto, client model.ClientInfo) (model.LoginResult, error)
+
+	// LegacyLogin 兼容旧版登录接口，创建会话并只签发一个长期有效的访问令牌
+	LegacyLogin(loginDto *model.LoginDto, client model.ClientInfo) (string, error)
+
+	// Refresh 使用刷新令牌换取新的令牌，旧的刷新令牌随即失效
+	Refresh(refreshToken string, client model.ClientInfo) (model.TokenPair, error)
+
+	// Logout 撤销当前会话
+	Logout(userid uint, sessionID string) error
+
+	// GetSessions 获取用户的有效会话，并标记发起请求的会话
+	GetSessions(userid uint, currentSessionID string) ([]model.Session, error)
+
+	// RevokeSession 撤销用户的指定会话
+	RevokeSession(userid uint, id string) error
+
+	// RevokeAllSessions 撤销用户的所有会话（包括当前会话）
+	RevokeAllSessions(userid uint) error
+
+	// ParseAccessToken 解析访问令牌，根据 kid 选择校验密钥
+	ParseAccessToken(token string) (*model.MyClaims, error)
+
+	// ValidateAccessToken 检查访问令牌所属的会话是否仍然有效
+	ValidateAccessToken(claims *model.MyClaims) error
+
+	// LoadUser 加载令牌所属的用户（用户被删除后令牌立即失效）
+	LoadUser(userid uint) (userModel.User, error)
+
+	// RotateKeys 生成新的签名密钥并开始使用，旧密钥在宽限期内仍可用于校验
+	RotateKeys(algorithm string) (model.SigningKey, error)
+
+	// GetJWKS 获取仍在使用的非对称密钥的公钥
+	GetJWKS() (model.JWKS, error)
+
+	// CreateAccessToken 创建个人访问令牌，令牌明文只在创建时返回一次
+	CreateAccessToken(userid uint, dto *model.CreateAccessTokenDto) (model.CreatedAccessToken, error)
+
+	// GetAccessTokens 获取用户的个人访问令牌
+	GetAccessTokens(userid uint) ([]model.AccessToken, error)
+
+	// DeleteAccessToken 删除用户的个人访问令牌
+	DeleteAccessToken(userid, id uint) error
+
+	// ValidatePersonalToken 校验个人访问令牌，并记录最近使用的时间和 IP
+	ValidatePersonalToken(token string, ip string) (model.AccessToken, error)
+
+	// GetTwoFactorStatus 获取用户的两步验证状态
+	GetTwoFactorStatus(userid uint) (model.TwoFactorStatus, error)
+
+	// SetupTwoFactor 开始绑定两步验证，生成新的密钥
+	SetupTwoFactor(userid uint) (model.TwoFactorSetup, error)
+
+	// EnableTwoFactor 校验验证码后启用两步验证，返回一次性的恢复码
+	EnableTwoFactor(userid uint, code string) ([]string, error)
+
+	// DisableTwoFactor 校验验证码或恢复码后关闭两步验证
+	DisableTwoFactor(userid uint, code string) error
+
+	// RegenerateRecoveryCodes 校验验证码或恢复码后重新生成恢复码
+	RegenerateRecoveryCodes(userid uint, code string) ([]string, error)
+
+	// VerifyTwoFactor 使用登录时返回的凭据和验证码完成登录
+	VerifyTwoFactor(dto *model.TwoFactorVerifyDto, client model.ClientInfo) (model.TokenPair, error)
+
+	// ResetTwoFactor 关闭指定用户的两步验证（供管理员在命令行中使用）
+	ResetTwoFactor(username string) error
+
+	// GetOIDCProviders 获取已配置的身份提供者
+	GetOIDCProviders() []model.OIDCProviderInfo
+
+	// AuthorizeOIDC 发起授权请求，userid 不为 0 时表示为该用户关联身份
+	AuthorizeOIDC(providerID string, userid uint) (model.OIDCAuthorization, error)
+
+	// HandleOIDCCallback 处理身份提供者的回调，登录或关联身份
+	HandleOIDCCallback(providerID, state, cookieState, code string, client model.ClientInfo) (model.OIDCResult, error)
+
+	// GetOIDCIdentities 获取用户关联的身份
+	GetOIDCIdentities(userid uint) ([]model.OIDCIdentity, error)
+
+	// DeleteOIDCIdentity 取消关联用户的身份
+	DeleteOIDCIdentity(userid, id uint) error
+
+	// Register 注册新用户，同一 IP 的注册请求过多时暂时锁定
+	Register(registerDto *model.RegisterDto, client model.ClientInfo) error
+
+	// GetLockouts 获取按 IP 和用户名统计的失败记录（仅管理员）
+	GetLockouts(user userModel.User) ([]model.Lockout, error)
+
+	// ClearLockout 解除锁定并清空失败次数（仅管理员）
+	ClearLockout(user userModel.User, id uint) error
+
+	// ClearAllLockouts 解除所有锁定（仅管理员）
+	ClearAllLockouts(user userModel.User) error
+}
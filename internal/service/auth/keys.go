@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lin-snow/ech0/internal/config"
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	jwtUtil "github.com/lin-snow/ech0/internal/util/jwt"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+// errUnknownKey 令牌使用了未知或已失效的密钥
+var errUnknownKey = errors.New("unknown signing key")
+
+// ParseAccessToken 解析访问令牌，根据 kid 选择校验密钥
+func (authService *AuthService) ParseAccessToken(token string) (*model.MyClaims, error) {
+	return jwtUtil.ParseToken(token, authService.lookupKey)
+}
+
+// RotateKeys 生成新的签名密钥并开始使用，旧密钥在宽限期内仍可用于校验，超出宽限期的密钥会被删除
+func (authService *AuthService) RotateKeys(algorithm string) (model.SigningKey, error) {
+	if algorithm == "" {
+		algorithm = defaultAlgorithm()
+	}
+
+	key, err := jwtUtil.GenerateKey(algorithm)
+	if err != nil {
+		return model.SigningKey{}, err
+	}
+	key.Active = true
+
+	now := time.Now()
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		if err := authService.authRepository.RetireSigningKeys(ctx, now); err != nil {
+			return err
+		}
+		if err := authService.authRepository.DeleteRetiredSigningKeys(ctx, now.Add(-gracePeriod())); err != nil {
+			return err
+		}
+		return authService.authRepository.CreateSigningKey(ctx, &key)
+	}); err != nil {
+		return model.SigningKey{}, err
+	}
+
+	if len(config.JWT_SECRET) > 0 {
+		logUtil.GetLogger().Warn("[已设置环境变量 JWT_SECRET，轮换的密钥不会用于签发]")
+	}
+
+	return key, authService.loadKeys(true)
+}
+
+// GetJWKS 获取仍在使用的非对称密钥的公钥
+func (authService *AuthService) GetJWKS() (model.JWKS, error) {
+	jwks := model.JWKS{Keys: []model.JWK{}}
+	if err := authService.loadKeys(false); err != nil {
+		return jwks, err
+	}
+
+	authService.keyMu.RLock()
+	defer authService.keyMu.RUnlock()
+	for _, key := range authService.keys {
+		if key.Algorithm != model.AlgorithmEdDSA || keyExpired(key) {
+			continue
+		}
+		publicKey, err := jwtUtil.VerificationKey(key)
+		if err != nil {
+			continue
+		}
+		x := base64.RawURLEncoding.EncodeToString(publicKey.(ed25519.PublicKey))
+		jwks.Keys = append(jwks.Keys, model.JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   x,
+			Kid: key.Kid,
+			Alg: model.AlgorithmEdDSA,
+			Use: "sig",
+		})
+	}
+
+	return jwks, nil
+}
+
+// signingKey 获取当前用于签发的密钥，设置了环境变量 JWT_SECRET 时优先使用
+func (authService *AuthService) signingKey() (model.SigningKey, error) {
+	if len(config.JWT_SECRET) > 0 {
+		return jwtUtil.SecretKey(config.JWT_SECRET), nil
+	}
+
+	if err := authService.loadKeys(false); err != nil {
+		return model.SigningKey{}, err
+	}
+
+	authService.keyMu.RLock()
+	defer authService.keyMu.RUnlock()
+	return authService.activeKey, nil
+}
+
+// lookupKey 根据 kid 获取校验密钥，遇到未知的 kid 时重新加载（其它进程可能刚刚轮换了密钥）
+func (authService *AuthService) lookupKey(kid string) (model.SigningKey, error) {
+	if kid == model.EnvKeyID {
+		if len(config.JWT_SECRET) == 0 {
+			return model.SigningKey{}, errUnknownKey
+		}
+		return jwtUtil.SecretKey(config.JWT_SECRET), nil
+	}
+
+	if err := authService.loadKeys(false); err != nil {
+		return model.SigningKey{}, err
+	}
+
+	authService.keyMu.RLock()
+	key, ok := authService.keys[kid]
+	loadedAt := authService.keysLoadedAt
+	authService.keyMu.RUnlock()
+
+	if !ok && time.Since(loadedAt) >= model.KeyMissReloadInterval {
+		if err := authService.loadKeys(true); err != nil {
+			return model.SigningKey{}, err
+		}
+		authService.keyMu.RLock()
+		key, ok = authService.keys[kid]
+		authService.keyMu.RUnlock()
+	}
+
+	if !ok || keyExpired(key) {
+		return model.SigningKey{}, errUnknownKey
+	}
+	return key, nil
+}
+
+// loadKeys 从数据库加载签名密钥（距离上次加载超过间隔或 force 时），没有可用于签发的密钥时生成一个
+func (authService *AuthService) loadKeys(force bool) error {
+	authService.keyMu.RLock()
+	fresh := !authService.keysLoadedAt.IsZero() && time.Since(authService.keysLoadedAt) < model.KeyReloadInterval
+	authService.keyMu.RUnlock()
+	if fresh && !force {
+		return nil
+	}
+
+	authService.keyMu.Lock()
+	defer authService.keyMu.Unlock()
+
+	keys, err := authService.authRepository.GetSigningKeys()
+	if err != nil {
+		return err
+	}
+
+	// 按创建时间倒序排列，多个进程同时生成密钥时使用最新的一个签发
+	var active *model.SigningKey
+	for i := range keys {
+		if keys[i].Active {
+			active = &keys[i]
+			break
+		}
+	}
+	if active == nil {
+		key, err := jwtUtil.GenerateKey(defaultAlgorithm())
+		if err != nil {
+			return err
+		}
+		key.Active = true
+		if err := authService.txManager.Run(func(ctx context.Context) error {
+			return authService.authRepository.CreateSigningKey(ctx, &key)
+		}); err != nil {
+			return err
+		}
+		logUtil.GetLogger().Info("[已生成新的签名密钥]", zap.String("kid", key.Kid), zap.String("算法", key.Algorithm))
+		keys = append(keys, key)
+		active = &keys[len(keys)-1]
+	}
+
+	authService.keys = make(map[string]model.SigningKey, len(keys))
+	for _, key := range keys {
+		authService.keys[key.Kid] = key
+	}
+	authService.activeKey = *active
+	authService.keysLoadedAt = time.Now()
+
+	return nil
+}
+
+// keyExpired 检查被轮换的密钥是否已超出宽限期
+func keyExpired(key model.SigningKey) bool {
+	return key.RetiredAt != nil && time.Since(*key.RetiredAt) > gracePeriod()
+}
+
+// defaultAlgorithm 生成新密钥时使用的签名算法
+func defaultAlgorithm() string {
+	if config.Config.Auth.Jwt.Algorithm == "" {
+		return model.AlgorithmHS256
+	}
+	return config.Config.Auth.Jwt.Algorithm
+}
+
+// gracePeriod 密钥被轮换后仍可用于校验的时间
+func gracePeriod() time.Duration {
+	return time.Duration(config.Config.Auth.Jwt.GracePeriod) * time.Second
+}
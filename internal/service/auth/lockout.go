@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/config"
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+// lockoutTarget 需要统计失败次数的 IP 或用户名
+type lockoutTarget struct {
+	scope  model.LockoutScope
+	target string
+}
+
+// Register 注册新用户，同一 IP 注册失败的次数过多时暂时锁定
+func (authService *AuthService) Register(registerDto *model.RegisterDto, client model.ClientInfo) error {
+	targets := []lockoutTarget{{model.LockoutScopeRegisterIP, client.IP}}
+	if err := authService.checkLockout(targets...); err != nil {
+		return err
+	}
+
+	// 只统计失败的注册请求（例如用户名已存在），避免探测用户名，成功注册不计入失败次数
+	if err := authService.userService.Register(registerDto); err != nil {
+		logUtil.GetLogger().Warn("[注册失败]",
+			zap.String("用户名", registerDto.Username),
+			zap.String("IP", client.IP),
+			zap.Error(err),
+		)
+		if recordErr := authService.recordFailure(targets...); recordErr != nil {
+			logUtil.GetLogger().Error("[记录注册失败次数失败]", zap.Error(recordErr))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetLockouts 获取按 IP 和用户名统计的失败记录（仅管理员）
+func (authService *AuthService) GetLockouts(user userModel.User) ([]model.Lockout, error) {
+	if err := authz.Authorize(user, authz.PermUsersManage); err != nil {
+		return nil, err
+	}
+
+	lockouts, err := authService.authRepository.GetLockouts()
+	if err != nil {
+		return nil, err
+	}
+	if len(lockouts) == 0 {
+		return []model.Lockout{}, nil
+	}
+
+	now := time.Now()
+	for i := range lockouts {
+		lockouts[i].Locked = now.Before(lockouts[i].LockedUntil)
+	}
+
+	return lockouts, nil
+}
+
+// ClearLockout 解除锁定并清空失败次数（仅管理员）
+func (authService *AuthService) ClearLockout(user userModel.User, id uint) error {
+	if err := authz.Authorize(user, authz.PermUsersManage); err != nil {
+		return err
+	}
+
+	return authService.txManager.Run(func(ctx context.Context) error {
+		deleted, err := authService.authRepository.DeleteLockout(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return errors.New(commonModel.LOCKOUT_NOT_FOUND)
+		}
+		return nil
+	})
+}
+
+// ClearAllLockouts 解除所有锁定（仅管理员）
+func (authService *AuthService) ClearAllLockouts(user userModel.User) error {
+	if err := authz.Authorize(user, authz.PermUsersManage); err != nil {
+		return err
+	}
+
+	return authService.txManager.Run(func(ctx context.Context) error {
+		return authService.authRepository.DeleteAllLockouts(ctx)
+	})
+}
+
+// authenticate 校验用户名、密码和附带的两步验证码，并按 IP 和用户名统计失败次数
+// 返回的 bool 表示是否已通过两步验证（未开启两步验证时总是 true）
+func (authService *AuthService) authenticate(loginDto *model.LoginDto, client model.ClientInfo) (userModel.User, bool, error) {
+	targets := loginTargets(loginDto.Username, client.IP)
+	if err := authService.checkLockout(targets...); err != nil {
+		return userModel.User{}, false, err
+	}
+
+	user, err := authService.userService.Authenticate(loginDto)
+	if err != nil {
+		if msg := err.Error(); msg == commonModel.USER_NOTFOUND || msg == commonModel.PASSWORD_INCORRECT {
+			authService.recordLoginFailure(loginDto.Username, client.IP, msg, targets...)
+		}
+		return userModel.User{}, false, err
+	}
+
+	passed, err := authService.passTwoFactor(user, loginDto.Code)
+	if err != nil {
+		if err.Error() == commonModel.TWO_FACTOR_CODE_INVALID {
+			authService.recordLoginFailure(loginDto.Username, client.IP, err.Error(), targets...)
+		}
+		return userModel.User{}, false, err
+	}
+
+	if passed {
+		authService.clearLoginFailures(user.Username)
+	}
+	return user, passed, nil
+}
+
+// checkLockout 检查 IP 或用户名是否处于锁定状态
+func (authService *AuthService) checkLockout(targets ...lockoutTarget) error {
+	if config.Config.Auth.Lockout.MaxAttempts <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for _, target := range targets {
+		lockout, err := authService.authRepository.GetLockout(target.scope, target.target)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+		if now.Before(lockout.LockedUntil) {
+			return errors.New(commonModel.TOO_MANY_ATTEMPTS)
+		}
+	}
+
+	return nil
+}
+
+// recordLoginFailure 记录登录失败的日志和次数，记录失败不影响本次请求的结果
+func (authService *AuthService) recordLoginFailure(username, ip, reason string, targets ...lockoutTarget) {
+	logUtil.GetLogger().Warn("[登录失败]",
+		zap.String("用户名", username),
+		zap.String("IP", ip),
+		zap.String("原因", reason),
+	)
+
+	if err := authService.recordFailure(targets...); err != nil {
+		logUtil.GetLogger().Error("[记录登录失败次数失败]", zap.Error(err))
+	}
+}
+
+// clearLoginFailures 登录成功后清空用户名的失败次数（IP 的失败次数保留，避免攻击者用自己的账号重置计数）
+func (authService *AuthService) clearLoginFailures(username string) {
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		return authService.authRepository.DeleteLockoutByTarget(ctx, model.LockoutScopeUsername, username)
+	}); err != nil {
+		logUtil.GetLogger().Error("[清空登录失败次数失败]", zap.Error(err))
+	}
+}
+
+// recordFailure 增加失败次数，时间窗口内达到阈值时锁定，锁定时长随锁定次数指数增长
+func (authService *AuthService) recordFailure(targets ...lockoutTarget) error {
+	cfg := config.Config.Auth.Lockout
+	if cfg.MaxAttempts <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	window := time.Duration(cfg.Window) * time.Second
+
+	return authService.txManager.Run(func(ctx context.Context) error {
+		if err := authService.authRepository.DeleteStaleLockouts(ctx, now.Add(-model.LockoutRetention)); err != nil {
+			return err
+		}
+
+		for _, target := range targets {
+			if target.target == "" {
+				continue
+			}
+
+			lockout, err := authService.authRepository.GetLockout(target.scope, target.target)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			lockout.Scope = target.scope
+			lockout.Target = target.target
+
+			// 上次失败已超出时间窗口时重新计数
+			if now.Sub(lockout.LastFailureAt) > window {
+				lockout.Failures = 0
+			}
+			lockout.Failures++
+			lockout.LastFailureAt = now
+
+			if lockout.Failures >= cfg.MaxAttempts {
+				lockout.Level++
+				lockout.Failures = 0
+				delay := lockoutDelay(lockout.Level)
+				lockout.LockedUntil = now.Add(delay)
+				logUtil.GetLogger().Warn("[登录已锁定]",
+					zap.String("范围", string(lockout.Scope)),
+					zap.String("目标", lockout.Target),
+					zap.Int("锁定次数", lockout.Level),
+					zap.Duration("锁定时长", delay),
+				)
+			}
+
+			if err := authService.authRepository.SaveLockout(ctx, &lockout); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// loginTargets 登录时需要统计失败次数的 IP 和用户名
+func loginTargets(username, ip string) []lockoutTarget {
+	return []lockoutTarget{
+		{model.LockoutScopeLoginIP, ip},
+		{model.LockoutScopeUsername, username},
+	}
+}
+
+// lockoutDelay 计算第 level 次锁定的时长：首次为 basedelay，之后每次翻倍，不超过 maxdelay（为 0 时不限制）
+func lockoutDelay(level int) time.Duration {
+	cfg := config.Config.Auth.Lockout
+	delay := time.Duration(cfg.BaseDelay) * time.Second
+	maxDelay := time.Duration(cfg.MaxDelay) * time.Second
+
+	// 最多翻倍 30 次，避免溢出
+	for i := 1; i < level && i <= 30 && (maxDelay <= 0 || delay < maxDelay); i++ {
+		delay *= 2
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay
+}
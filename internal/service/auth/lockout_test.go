@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lin-snow/ech0/internal/config"
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+)
+
+// setLockoutConfig 设置锁定策略，测试结束时随认证配置一起恢复
+func setLockoutConfig(maxAttempts, window, baseDelay, maxDelay int) {
+	config.Config.Auth.Lockout.MaxAttempts = maxAttempts
+	config.Config.Auth.Lockout.Window = window
+	config.Config.Auth.Lockout.BaseDelay = baseDelay
+	config.Config.Auth.Lockout.MaxDelay = maxDelay
+}
+
+// 锁定时长从 basedelay 开始每次翻倍，不超过 maxdelay，maxdelay 为 0 时不限制
+func TestLockoutDelay(t *testing.T) {
+	newTestAuthService(t)
+
+	setLockoutConfig(3, 60, 60, 600)
+	for level, expected := range map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		3:  4 * time.Minute,
+		4:  8 * time.Minute,
+		5:  10 * time.Minute,
+		40: 10 * time.Minute,
+	} {
+		assert.Equal(t, expected, lockoutDelay(level), "level %d", level)
+	}
+
+	setLockoutConfig(3, 60, 60, 0)
+	assert.Equal(t, 16*time.Minute, lockoutDelay(5))
+	assert.Positive(t, lockoutDelay(100))
+}
+
+// 时间窗口内失败次数达到阈值时锁定，再次达到阈值时锁定时长翻倍
+func TestRecordFailure_LocksAtThreshold(t *testing.T) {
+	service, repo, _ := newTestAuthService(t)
+	setLockoutConfig(3, 60, 60, 600)
+	target := lockoutTarget{model.LockoutScopeLoginIP, "203.0.113.7"}
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, service.recordFailure(target))
+		require.NoError(t, service.checkLockout(target))
+	}
+	require.NoError(t, service.recordFailure(target))
+	assert.EqualError(t, service.checkLockout(target), commonModel.TOO_MANY_ATTEMPTS)
+
+	lockout, err := repo.GetLockout(target.scope, target.target)
+	require.NoError(t, err)
+	assert.Equal(t, 1, lockout.Level)
+	assert.Zero(t, lockout.Failures)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), lockout.LockedUntil, 5*time.Second)
+
+	// 其它 IP 和用户名不受影响
+	assert.NoError(t, service.checkLockout(lockoutTarget{model.LockoutScopeLoginIP, "198.51.100.9"}))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, service.recordFailure(target))
+	}
+	lockout, err = repo.GetLockout(target.scope, target.target)
+	require.NoError(t, err)
+	assert.Equal(t, 2, lockout.Level)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), lockout.LockedUntil, 5*time.Second)
+}
+
+// 上次失败超出时间窗口后重新计数
+func TestRecordFailure_ResetsAfterWindow(t *testing.T) {
+	service, repo, _ := newTestAuthService(t)
+	setLockoutConfig(3, 60, 60, 600)
+	target := lockoutTarget{model.LockoutScopeUsername, "alice"}
+
+	require.NoError(t, service.recordFailure(target))
+	require.NoError(t, service.recordFailure(target))
+
+	lockout, err := repo.GetLockout(target.scope, target.target)
+	require.NoError(t, err)
+	lockout.LastFailureAt = time.Now().Add(-2 * time.Minute)
+	require.NoError(t, repo.SaveLockout(context.Background(), &lockout))
+
+	require.NoError(t, service.recordFailure(target))
+	require.NoError(t, service.checkLockout(target))
+	lockout, err = repo.GetLockout(target.scope, target.target)
+	require.NoError(t, err)
+	assert.Equal(t, 1, lockout.Failures)
+	assert.Zero(t, lockout.Level)
+}
+
+// 成功注册不计入失败次数，同一 IP 注册失败的次数过多时锁定
+func TestRegister_CountsOnlyFailures(t *testing.T) {
+	service, _, users := newTestAuthService(t)
+	setLockoutConfig(2, 60, 60, 600)
+	client := model.ClientInfo{IP: "203.0.113.7"}
+
+	for _, username := range []string{"alice", "bob", "carol"} {
+		require.NoError(t, service.Register(&model.RegisterDto{Username: username, Password: "password"}, client))
+	}
+	assert.Len(t, users.users, 3)
+
+	for i := 0; i < 2; i++ {
+		err := service.Register(&model.RegisterDto{Username: "alice", Password: "password"}, client)
+		assert.EqualError(t, err, commonModel.USERNAME_HAS_EXISTS)
+	}
+	err := service.Register(&model.RegisterDto{Username: "dave", Password: "password"}, client)
+	assert.EqualError(t, err, commonModel.TOO_MANY_ATTEMPTS)
+	assert.Len(t, users.users, 3)
+
+	// 其它 IP 仍然可以注册
+	assert.NoError(t, service.Register(&model.RegisterDto{Username: "dave", Password: "password"}, model.ClientInfo{IP: "198.51.100.9"}))
+}
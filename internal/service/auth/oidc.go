@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/lin-snow/ech0/internal/config"
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	oidcUtil "github.com/lin-snow/ech0/internal/util/oidc"
+)
+
+// oidcProvider 缓存的身份提供者元数据和公钥
+type oidcProvider struct {
+	discovery     oidcUtil.Discovery
+	keys          oidcUtil.KeySet
+	fetchedAt     time.Time // 获取元数据的时间
+	keysFetchedAt time.Time // 获取公钥的时间
+}
+
+// GetOIDCProviders 获取已配置的身份提供者
+func (authService *AuthService) GetOIDCProviders() []model.OIDCProviderInfo {
+	providers := []model.OIDCProviderInfo{}
+	for _, provider := range config.Config.Auth.OIDC.Providers {
+		name := provider.Name
+		if name == "" {
+			name = provider.ID
+		}
+		providers = append(providers, model.OIDCProviderInfo{ID: provider.ID, Name: name})
+	}
+
+	return providers
+}
+
+// AuthorizeOIDC 发起授权码（PKCE）请求，userid 不为 0 时表示为该用户关联身份
+func (authService *AuthService) AuthorizeOIDC(providerID string, userid uint) (model.OIDCAuthorization, error) {
+	cfg, err := getOIDCConfig(providerID)
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+
+	provider, err := authService.getOIDCProvider(cfg, false)
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+
+	redirectURL, err := authService.oidcRedirectURL(cfg)
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+
+	state, err := oidcUtil.RandomString(model.OIDCStateBytes)
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+	nonce, err := oidcUtil.RandomString(model.OIDCStateBytes)
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+	verifier, err := oidcUtil.RandomString(model.OIDCVerifierBytes)
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+
+	authURL, err := oidcUtil.AuthorizationURL(
+		provider.discovery.AuthorizationEndpoint, cfg.ClientID, redirectURL, oidcScopes(cfg), state, nonce, verifier,
+	)
+	if err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+
+	now := time.Now()
+	oidcState := model.OIDCState{
+		State:        state,
+		Provider:     cfg.ID,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		UserID:       userid,
+		ExpiresAt:    now.Add(model.OIDCStateExpiry),
+		CreatedAt:    now,
+	}
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		if err := authService.authRepository.DeleteExpiredOIDCStates(ctx); err != nil {
+			return err
+		}
+		return authService.authRepository.CreateOIDCState(ctx, &oidcState)
+	}); err != nil {
+		return model.OIDCAuthorization{}, err
+	}
+
+	return model.OIDCAuthorization{URL: authURL, State: state}, nil
+}
+
+// HandleOIDCCallback 处理身份提供者的回调：校验 state（需与浏览器 Cookie 中的一致）和 ID Token，然后登录或关联身份
+// 多因素认证由身份提供者负责，通过身份提供者登录时不再要求本地的两步验证
+func (authService *AuthService) HandleOIDCCallback(providerID, state, cookieState, code string, client model.ClientInfo) (model.OIDCResult, error) {
+	var result model.OIDCResult
+
+	oidcState, err := authService.consumeOIDCState(providerID, state, cookieState)
+	if err != nil {
+		return result, err
+	}
+
+	cfg, err := getOIDCConfig(providerID)
+	if err != nil {
+		return result, err
+	}
+	claims, err := authService.exchangeOIDCCode(cfg, oidcState, code)
+	if err != nil {
+		logUtil.GetLogger().Warn("[OIDC 登录失败]", zap.String("提供者", cfg.ID), zap.Error(err))
+		return result, errors.New(commonModel.OIDC_LOGIN_FAILED)
+	}
+	subject, _ := claims.GetSubject()
+
+	identity, err := authService.authRepository.GetOIDCIdentity(cfg.ID, subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return result, err
+	}
+	identity.Provider = cfg.ID
+	identity.Subject = subject
+	identity.Email = oidcUtil.StringClaim(claims, "email")
+	identity.LastLoginAt = time.Now()
+
+	// 关联身份
+	if oidcState.UserID != 0 {
+		if identity.ID != 0 && identity.UserID != oidcState.UserID {
+			if _, err := authService.userService.GetUserByID(int(identity.UserID)); err == nil {
+				return result, errors.New(commonModel.OIDC_IDENTITY_LINKED)
+			}
+		}
+		identity.UserID = oidcState.UserID
+		result.Linked = true
+		return result, authService.txManager.Run(func(ctx context.Context) error {
+			return authService.authRepository.SaveOIDCIdentity(ctx, &identity)
+		})
+	}
+
+	// 登录：已关联的用户被删除时视为尚未关联
+	var user userModel.User
+	if identity.ID != 0 {
+		user, err = authService.userService.GetUserByID(int(identity.UserID))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return result, err
+		}
+	}
+
+	// 配置了管理员组时，根据身份提供者的用户组同步管理员权限
+	isAdmin := false
+	if cfg.AdminGroup != "" {
+		groupsClaim := cfg.GroupsClaim
+		if groupsClaim == "" {
+			groupsClaim = model.OIDCDefaultGroupsClaim
+		}
+		isAdmin = slices.Contains(oidcUtil.StringsClaim(claims, groupsClaim), cfg.AdminGroup)
+	}
+
+	if user.ID == 0 {
+		if !cfg.AutoProvision {
+			return result, errors.New(commonModel.OIDC_USER_NOT_LINKED)
+		}
+		if user, err = authService.userService.ProvisionUser(oidcUsername(cfg, claims), isAdmin); err != nil {
+			return result, err
+		}
+	} else if cfg.AdminGroup != "" && user.Role.IsAdmin() != isAdmin {
+		if err := authService.userService.SetUserAdmin(user.ID, isAdmin); err != nil {
+			return result, err
+		}
+	}
+	identity.UserID = user.ID
+
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		return authService.authRepository.SaveOIDCIdentity(ctx, &identity)
+	}); err != nil {
+		return result, err
+	}
+
+	result.Tokens, err = authService.issueSession(user, client)
+	return result, err
+}
+
+// GetOIDCIdentities 获取用户关联的身份
+func (authService *AuthService) GetOIDCIdentities(userid uint) ([]model.OIDCIdentity, error) {
+	identities, err := authService.authRepository.GetOIDCIdentitiesByUserID(userid)
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return []model.OIDCIdentity{}, nil
+	}
+
+	return identities, nil
+}
+
+// DeleteOIDCIdentity 取消关联用户的身份
+func (authService *AuthService) DeleteOIDCIdentity(userid, id uint) error {
+	return authService.txManager.Run(func(ctx context.Context) error {
+		deleted, err := authService.authRepository.DeleteOIDCIdentity(ctx, userid, id)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return errors.New(commonModel.OIDC_IDENTITY_NOT_FOUND)
+		}
+		return nil
+	})
+}
+
+// consumeOIDCState 校验并删除授权请求，每个授权请求只能使用一次
+func (authService *AuthService) consumeOIDCState(providerID, state, cookieState string) (model.OIDCState, error) {
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		return model.OIDCState{}, errors.New(commonModel.OIDC_STATE_INVALID)
+	}
+
+	oidcState, err := authService.authRepository.GetOIDCState(state)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return oidcState, errors.New(commonModel.OIDC_STATE_INVALID)
+		}
+		return oidcState, err
+	}
+
+	var deleted bool
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		deleted, err = authService.authRepository.DeleteOIDCState(ctx, state)
+		return err
+	}); err != nil {
+		return oidcState, err
+	}
+	if !deleted || oidcState.Provider != providerID || time.Now().After(oidcState.ExpiresAt) {
+		return oidcState, errors.New(commonModel.OIDC_STATE_INVALID)
+	}
+
+	return oidcState, nil
+}
+
+// exchangeOIDCCode 使用授权码换取 ID Token 并校验，签名密钥未知时重新获取一次公钥
+func (authService *AuthService) exchangeOIDCCode(cfg config.OIDCProvider, oidcState model.OIDCState, code string) (jwt.MapClaims, error) {
+	if code == "" {
+		return nil, errors.New("missing authorization code")
+	}
+
+	provider, err := authService.getOIDCProvider(cfg, false)
+	if err != nil {
+		return nil, err
+	}
+	redirectURL, err := authService.oidcRedirectURL(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := oidcUtil.ExchangeCode(
+		provider.discovery, cfg.ClientID, config.GetOIDCClientSecret(cfg), code, redirectURL, oidcState.CodeVerifier,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := oidcUtil.VerifyIDToken(tokens.IDToken, provider.keys, provider.discovery.Issuer, cfg.ClientID, oidcState.Nonce)
+	if oidcUtil.IsUnknownKey(err) {
+		if provider, err = authService.getOIDCProvider(cfg, true); err != nil {
+			return nil, err
+		}
+		claims, err = oidcUtil.VerifyIDToken(tokens.IDToken, provider.keys, provider.discovery.Issuer, cfg.ClientID, oidcState.Nonce)
+	}
+
+	return claims, err
+}
+
+// getOIDCProvider 获取身份提供者的元数据和公钥，缓存过期或 refreshKeys 为 true 时重新获取
+// 请求身份提供者时不持有锁，同一身份提供者的并发请求只发送一次
+func (authService *AuthService) getOIDCProvider(cfg config.OIDCProvider, refreshKeys bool) (oidcProvider, error) {
+	if provider, ok := authService.cachedOIDCProvider(cfg.ID, refreshKeys); ok {
+		return provider, nil
+	}
+
+	result, err, _ := authService.oidcFetch.Do(cfg.ID, func() (any, error) {
+		// 等待期间其他请求可能已经更新了缓存
+		if provider, ok := authService.cachedOIDCProvider(cfg.ID, refreshKeys); ok {
+			return provider, nil
+		}
+
+		authService.oidcMu.Lock()
+		provider, ok := authService.oidcProviders[cfg.ID]
+		authService.oidcMu.Unlock()
+		now := time.Now()
+
+		if !ok || now.Sub(provider.fetchedAt) > model.OIDCMetadataTTL {
+			discovery, err := oidcUtil.Discover(cfg.Issuer)
+			if err != nil {
+				return nil, fmt.Errorf("oidc discovery: %w", err)
+			}
+			keys, err := oidcUtil.FetchKeySet(discovery.JWKSURI)
+			if err != nil {
+				return nil, fmt.Errorf("oidc jwks: %w", err)
+			}
+			provider = oidcProvider{discovery: discovery, keys: keys, fetchedAt: now, keysFetchedAt: now}
+		} else {
+			keys, err := oidcUtil.FetchKeySet(provider.discovery.JWKSURI)
+			if err != nil {
+				return nil, fmt.Errorf("oidc jwks: %w", err)
+			}
+			provider.keys = keys
+			provider.keysFetchedAt = now
+		}
+
+		authService.oidcMu.Lock()
+		if authService.oidcProviders == nil {
+			authService.oidcProviders = make(map[string]oidcProvider)
+		}
+		authService.oidcProviders[cfg.ID] = provider
+		authService.oidcMu.Unlock()
+
+		return provider, nil
+	})
+	if err != nil {
+		return oidcProvider{}, err
+	}
+
+	return result.(oidcProvider), nil
+}
+
+// cachedOIDCProvider 获取缓存的身份提供者，元数据过期或需要刷新公钥时返回 false
+func (authService *AuthService) cachedOIDCProvider(providerID string, refreshKeys bool) (oidcProvider, bool) {
+	authService.oidcMu.Lock()
+	defer authService.oidcMu.Unlock()
+
+	provider, ok := authService.oidcProviders[providerID]
+	now := time.Now()
+	if !ok || now.Sub(provider.fetchedAt) > model.OIDCMetadataTTL {
+		return provider, false
+	}
+	if refreshKeys && now.Sub(provider.keysFetchedAt) > model.OIDCKeyRefreshInterval {
+		return provider, false
+	}
+
+	return provider, true
+}
+
+// oidcRedirectURL 获取回调地址，未配置时根据实例的访问地址生成
+func (authService *AuthService) oidcRedirectURL(cfg config.OIDCProvider) (string, error) {
+	if cfg.RedirectURL != "" {
+		return cfg.RedirectURL, nil
+	}
+
+	serverURL, err := authService.settingService.GetServerURL()
+	if err != nil {
+		return "", err
+	}
+	return serverURL + fmt.Sprintf(model.OIDCCallbackPath, cfg.ID), nil
+}
+
+// getOIDCConfig 根据标识获取身份提供者的配置
+func getOIDCConfig(providerID string) (config.OIDCProvider, error) {
+	for _, provider := range config.Config.Auth.OIDC.Providers {
+		if provider.ID == providerID && provider.Issuer != "" && provider.ClientID != "" {
+			return provider, nil
+		}
+	}
+
+	return config.OIDCProvider{}, errors.New(commonModel.OIDC_PROVIDER_NOT_FOUND)
+}
+
+// oidcScopes 获取请求的范围，总是包含 openid
+func oidcScopes(cfg config.OIDCProvider) []string {
+	if len(cfg.Scopes) == 0 {
+		return []string{"openid", "profile", "email"}
+	}
+	if slices.Contains(cfg.Scopes, "openid") {
+		return cfg.Scopes
+	}
+
+	return append([]string{"openid"}, cfg.Scopes...)
+}
+
+// oidcUsername 获取自动创建用户时使用的用户名，依次尝试配置的声明、邮箱的本地部分和 sub
+func oidcUsername(cfg config.OIDCProvider, claims jwt.MapClaims) string {
+	usernameClaim := cfg.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = model.OIDCDefaultUsernameClaim
+	}
+
+	if username := strings.TrimSpace(oidcUtil.StringClaim(claims, usernameClaim)); username != "" {
+		return username
+	}
+	if email := oidcUtil.StringClaim(claims, "email"); email != "" {
+		local, _, _ := strings.Cut(email, "@")
+		if local != "" {
+			return local
+		}
+	}
+
+	subject, _ := claims.GetSubject()
+	return subject
+}
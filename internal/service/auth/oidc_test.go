@@ -0,0 +1,376 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lin-snow/ech0/internal/config"
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	repository "github.com/lin-snow/ech0/internal/repository/auth"
+	oidcUtil "github.com/lin-snow/ech0/internal/util/oidc"
+)
+
+const (
+	testProviderID  = "idp"
+	testClientID    = "ech0"
+	testRedirectURL = "https://ech0.example/api/auth/oidc/idp/callback"
+	testKeyID       = "key-1"
+)
+
+// fakeIssuer 模拟身份提供者：提供元数据、公钥和令牌端点，令牌端点校验 PKCE 并返回测试指定的 ID Token
+type fakeIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu            sync.Mutex
+	challenge     string                             // 授权请求中的 PKCE 挑战码
+	idToken       func(nonce string) (string, error) // 生成令牌端点返回的 ID Token
+	nonce         string                             // 授权请求中的 nonce
+	tokenRequests []url.Values                       // 令牌端点收到的请求
+	discoveries   int                                // 元数据被请求的次数
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	issuer := &fakeIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcUtil.DiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		issuer.mu.Lock()
+		issuer.discoveries++
+		issuer.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"jwks_uri":               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testKeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+			return
+		}
+
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+		issuer.tokenRequests = append(issuer.tokenRequests, r.PostForm)
+
+		if r.PostForm.Get("client_id") != testClientID || r.PostForm.Get("redirect_uri") != testRedirectURL ||
+			oidcUtil.CodeChallenge(r.PostForm.Get("code_verifier")) != issuer.challenge {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		idToken, err := issuer.idToken(issuer.nonce)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"access_token": "at", "token_type": "Bearer", "id_token": idToken})
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// claims 返回一组有效的 ID Token 声明
+func (issuer *fakeIssuer) claims(subject, nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   issuer.server.URL,
+		"aud":   testClientID,
+		"sub":   subject,
+		"nonce": nonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+	}
+}
+
+// sign 使用身份提供者的私钥签名 ID Token
+func (issuer *fakeIssuer) sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	return token.SignedString(issuer.key)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// oidcTestEnv 连接到模拟身份提供者的认证服务
+type oidcTestEnv struct {
+	service *AuthService
+	users   *fakeUserService
+	repo    repository.AuthRepositoryInterface
+	issuer  *fakeIssuer
+}
+
+// newOIDCTestEnv 使用临时数据库和模拟身份提供者创建认证服务，cfg 中的发行者、客户端和回调地址会被覆盖
+func newOIDCTestEnv(t *testing.T, cfg config.OIDCProvider) *oidcTestEnv {
+	t.Helper()
+
+	service, repo, users := newTestAuthService(t)
+	issuer := newFakeIssuer(t)
+	cfg.ID = testProviderID
+	cfg.Issuer = issuer.server.URL
+	cfg.ClientID = testClientID
+	cfg.RedirectURL = testRedirectURL
+
+	config.Config.Auth.OIDC.Providers = []config.OIDCProvider{cfg}
+
+	return &oidcTestEnv{service: service, users: users, repo: repo, issuer: issuer}
+}
+
+// login 发起授权请求并使用模拟身份提供者签发的 ID Token 完成回调
+func (env *oidcTestEnv) login(t *testing.T, userid uint, idToken func(nonce string) (string, error)) (model.OIDCResult, error) {
+	t.Helper()
+
+	authorization, err := env.service.AuthorizeOIDC(testProviderID, userid)
+	require.NoError(t, err)
+	authURL, err := url.Parse(authorization.URL)
+	require.NoError(t, err)
+	query := authURL.Query()
+
+	env.issuer.mu.Lock()
+	env.issuer.challenge = query.Get("code_challenge")
+	env.issuer.nonce = query.Get("nonce")
+	env.issuer.idToken = idToken
+	env.issuer.mu.Unlock()
+
+	return env.service.HandleOIDCCallback(testProviderID, authorization.State, authorization.State, "code", model.ClientInfo{})
+}
+
+// validToken 签发有效的 ID Token，extra 中的声明会覆盖默认值
+func (env *oidcTestEnv) validToken(subject string, extra jwt.MapClaims) func(nonce string) (string, error) {
+	return func(nonce string) (string, error) {
+		claims := env.issuer.claims(subject, nonce)
+		for name, value := range extra {
+			claims[name] = value
+		}
+		return env.issuer.sign(claims)
+	}
+}
+
+// 授权请求使用元数据中的授权端点和 PKCE S256，换取令牌时提交与挑战码匹配的校验码
+func TestOIDC_DiscoveryAndPKCE(t *testing.T) {
+	env := newOIDCTestEnv(t, config.OIDCProvider{AutoProvision: true})
+
+	authorization, err := env.service.AuthorizeOIDC(testProviderID, 0)
+	require.NoError(t, err)
+	authURL, err := url.Parse(authorization.URL)
+	require.NoError(t, err)
+	query := authURL.Query()
+
+	assert.Equal(t, env.issuer.server.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, testClientID, query.Get("client_id"))
+	assert.Equal(t, testRedirectURL, query.Get("redirect_uri"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, authorization.State, query.Get("state"))
+	assert.NotEmpty(t, query.Get("nonce"))
+	assert.NotEmpty(t, query.Get("code_challenge"))
+
+	result, err := env.login(t, 0, env.validToken("alice", jwt.MapClaims{"preferred_username": "alice"}))
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Tokens.AccessToken)
+	assert.NotEmpty(t, result.Tokens.RefreshToken)
+
+	env.issuer.mu.Lock()
+	defer env.issuer.mu.Unlock()
+	require.Len(t, env.issuer.tokenRequests, 1)
+	form := env.issuer.tokenRequests[0]
+	assert.Equal(t, "authorization_code", form.Get("grant_type"))
+	assert.Equal(t, env.issuer.challenge, oidcUtil.CodeChallenge(form.Get("code_verifier")))
+	// 元数据被缓存，两次授权请求只获取一次
+	assert.Equal(t, 1, env.issuer.discoveries)
+}
+
+// state 只能使用一次，且必须与浏览器 Cookie 中的一致
+func TestOIDC_RejectsInvalidState(t *testing.T) {
+	env := newOIDCTestEnv(t, config.OIDCProvider{AutoProvision: true})
+
+	authorization, err := env.service.AuthorizeOIDC(testProviderID, 0)
+	require.NoError(t, err)
+
+	_, err = env.service.HandleOIDCCallback(testProviderID, authorization.State, "other", "code", model.ClientInfo{})
+	assert.EqualError(t, err, commonModel.OIDC_STATE_INVALID)
+
+	// 第一次回调消耗 state（换取令牌失败也不会恢复），之后的回调都会被拒绝
+	_, _ = env.service.HandleOIDCCallback(testProviderID, authorization.State, authorization.State, "code", model.ClientInfo{})
+	_, err = env.service.HandleOIDCCallback(testProviderID, authorization.State, authorization.State, "code", model.ClientInfo{})
+	assert.EqualError(t, err, commonModel.OIDC_STATE_INVALID)
+}
+
+// 发行者、受众、授权方、nonce 或签名算法不正确的 ID Token 都会被拒绝，不会创建用户
+func TestOIDC_RejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		idToken func(env *oidcTestEnv) func(nonce string) (string, error)
+	}{
+		{"nonce mismatch", func(env *oidcTestEnv) func(string) (string, error) {
+			return func(string) (string, error) { return env.issuer.sign(env.issuer.claims("alice", "replayed")) }
+		}},
+		{"wrong issuer", func(env *oidcTestEnv) func(string) (string, error) {
+			return env.validToken("alice", jwt.MapClaims{"iss": "https://evil.example"})
+		}},
+		{"wrong audience", func(env *oidcTestEnv) func(string) (string, error) {
+			return env.validToken("alice", jwt.MapClaims{"aud": "other-client"})
+		}},
+		{"multiple audiences without azp", func(env *oidcTestEnv) func(string) (string, error) {
+			return env.validToken("alice", jwt.MapClaims{"aud": []string{testClientID, "other-client"}})
+		}},
+		{"wrong azp", func(env *oidcTestEnv) func(string) (string, error) {
+			return env.validToken("alice", jwt.MapClaims{"aud": []string{testClientID, "other-client"}, "azp": "other-client"})
+		}},
+		{"expired", func(env *oidcTestEnv) func(string) (string, error) {
+			return env.validToken("alice", jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})
+		}},
+		{"alg none", func(env *oidcTestEnv) func(string) (string, error) {
+			return func(nonce string) (string, error) {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, env.issuer.claims("alice", nonce))
+				token.Header["kid"] = testKeyID
+				return token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+			}
+		}},
+		{"HS256 with public key", func(env *oidcTestEnv) func(string) (string, error) {
+			return func(nonce string) (string, error) {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, env.issuer.claims("alice", nonce))
+				token.Header["kid"] = testKeyID
+				return token.SignedString(env.issuer.key.N.Bytes())
+			}
+		}},
+		{"unknown key", func(env *oidcTestEnv) func(string) (string, error) {
+			return func(nonce string) (string, error) {
+				other, err := rsa.GenerateKey(rand.Reader, 2048)
+				if err != nil {
+					return "", err
+				}
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, env.issuer.claims("alice", nonce))
+				token.Header["kid"] = "key-2"
+				return token.SignedString(other)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t, config.OIDCProvider{AutoProvision: true})
+
+			_, err := env.login(t, 0, tt.idToken(env))
+			assert.EqualError(t, err, commonModel.OIDC_LOGIN_FAILED)
+			assert.Empty(t, env.users.users)
+		})
+	}
+}
+
+// 已登录的用户关联身份后可以通过身份提供者登录，同一身份不能再关联到其他用户
+func TestOIDC_LinksIdentity(t *testing.T) {
+	env := newOIDCTestEnv(t, config.OIDCProvider{})
+	env.users.users[1] = userModel.User{ID: 1, Username: "owner", Role: userModel.RoleOwner}
+	env.users.users[2] = userModel.User{ID: 2, Username: "bob", Role: userModel.RoleReader}
+
+	// 未关联且未开启自动创建时拒绝登录
+	_, err := env.login(t, 0, env.validToken("alice", nil))
+	assert.EqualError(t, err, commonModel.OIDC_USER_NOT_LINKED)
+
+	result, err := env.login(t, 1, env.validToken("alice", jwt.MapClaims{"email": "alice@example.com"}))
+	require.NoError(t, err)
+	assert.True(t, result.Linked)
+	assert.Empty(t, result.Tokens.AccessToken)
+
+	identity, err := env.repo.GetOIDCIdentity(testProviderID, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), identity.UserID)
+	assert.Equal(t, "alice@example.com", identity.Email)
+
+	result, err = env.login(t, 0, env.validToken("alice", nil))
+	require.NoError(t, err)
+	assert.False(t, result.Linked)
+	claims, err := env.service.ParseAccessToken(result.Tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), claims.Userid)
+
+	_, err = env.login(t, 2, env.validToken("alice", nil))
+	assert.EqualError(t, err, commonModel.OIDC_IDENTITY_LINKED)
+}
+
+// 自动创建的用户根据管理员组获得管理员权限，之后每次登录同步
+func TestOIDC_AutoProvisionWithAdminGroup(t *testing.T) {
+	env := newOIDCTestEnv(t, config.OIDCProvider{AutoProvision: true, AdminGroup: "ech0-admins", GroupsClaim: "roles"})
+
+	_, err := env.login(t, 0, env.validToken("alice", jwt.MapClaims{
+		"preferred_username": "alice",
+		"roles":              []string{"staff", "ech0-admins"},
+	}))
+	require.NoError(t, err)
+	require.Len(t, env.users.users, 1)
+	user := env.users.users[1]
+	assert.Equal(t, "alice", user.Username)
+	assert.Equal(t, userModel.RoleAdmin, user.Role)
+
+	// 离开管理员组后再次登录取消管理员权限，不会重复创建用户
+	_, err = env.login(t, 0, env.validToken("alice", jwt.MapClaims{"roles": []string{"staff"}}))
+	require.NoError(t, err)
+	require.Len(t, env.users.users, 1)
+	assert.Equal(t, userModel.RoleReader, env.users.users[1].Role)
+
+	// 没有配置用户名声明时使用邮箱的本地部分
+	_, err = env.login(t, 0, env.validToken("bob", jwt.MapClaims{"email": "bob@example.com"}))
+	require.NoError(t, err)
+	require.Len(t, env.users.users, 2)
+	assert.Equal(t, "bob", env.users.users[2].Username)
+	assert.Equal(t, userModel.RoleReader, env.users.users[2].Role)
+}
+
+// 并发获取身份提供者时只请求一次元数据，且请求期间不阻塞其他身份提供者
+func TestGetOIDCProvider_CoalescesConcurrentFetches(t *testing.T) {
+	env := newOIDCTestEnv(t, config.OIDCProvider{})
+	cfg, err := getOIDCConfig(testProviderID)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := env.service.getOIDCProvider(cfg, false)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	env.issuer.mu.Lock()
+	defer env.issuer.mu.Unlock()
+	assert.Equal(t, 1, env.issuer.discoveries)
+}
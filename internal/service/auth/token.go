@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+// maxTokenNameLength 个人访问令牌名称的最大长度
+const maxTokenNameLength = 64
+
+// CreateAccessToken 创建个人访问令牌，令牌明文只在创建时返回一次
+func (authService *AuthService) CreateAccessToken(userid uint, dto *model.CreateAccessTokenDto) (model.CreatedAccessToken, error) {
+	name := strings.TrimSpace(dto.Name)
+	if name == "" {
+		return model.CreatedAccessToken{}, errors.New(commonModel.INVALID_REQUEST_BODY)
+	}
+	if utf8.RuneCountInString(name) > maxTokenNameLength {
+		return model.CreatedAccessToken{}, errors.New(commonModel.ACCESS_TOKEN_NAME_TOO_LONG)
+	}
+	if dto.ExpiresIn < 0 {
+		return model.CreatedAccessToken{}, errors.New(commonModel.INVALID_EXPIRES)
+	}
+
+	// 校验并去重权限范围
+	if len(dto.Scopes) == 0 {
+		return model.CreatedAccessToken{}, errors.New(commonModel.INVALID_SCOPE)
+	}
+	scopes := make([]model.Scope, 0, len(dto.Scopes))
+	for _, scope := range dto.Scopes {
+		if !slices.Contains(model.AllScopes, scope) {
+			return model.CreatedAccessToken{}, errors.New(commonModel.INVALID_SCOPE)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	tokens, err := authService.authRepository.GetAccessTokens(userid)
+	if err != nil {
+		return model.CreatedAccessToken{}, err
+	}
+	if len(tokens) >= model.MaxAccessTokens {
+		return model.CreatedAccessToken{}, errors.New(commonModel.ACCESS_TOKEN_COUNT_EXCEED_LIMIT)
+	}
+
+	buf := make([]byte, model.PersonalTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return model.CreatedAccessToken{}, err
+	}
+	plain := model.PersonalTokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	token := model.AccessToken{
+		UserID:    userid,
+		Name:      name,
+		Prefix:    plain[:model.PersonalTokenDisplayLength],
+		TokenHash: hashToken(plain),
+		Scopes:    scopes,
+	}
+	if dto.ExpiresIn > 0 {
+		expiresAt := time.Now().AddDate(0, 0, dto.ExpiresIn)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		return authService.authRepository.CreateAccessToken(ctx, &token)
+	}); err != nil {
+		return model.CreatedAccessToken{}, err
+	}
+
+	return model.CreatedAccessToken{AccessToken: token, Token: plain}, nil
+}
+
+// GetAccessTokens 获取用户的个人访问令牌
+func (authService *AuthService) GetAccessTokens(userid uint) ([]model.AccessToken, error) {
+	tokens, err := authService.authRepository.GetAccessTokens(userid)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return []model.AccessToken{}, nil
+	}
+
+	return tokens, nil
+}
+
+// DeleteAccessToken 删除用户的个人访问令牌，令牌立即失效
+func (authService *AuthService) DeleteAccessToken(userid, id uint) error {
+	token, err := authService.authRepository.GetAccessTokenByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(commonModel.ACCESS_TOKEN_NOT_FOUND)
+		}
+		return err
+	}
+
+	// 只能删除自己的令牌
+	if token.UserID != userid {
+		return errors.New(commonModel.ACCESS_TOKEN_NOT_FOUND)
+	}
+
+	return authService.txManager.Run(func(ctx context.Context) error {
+		return authService.authRepository.DeleteAccessToken(ctx, token.ID)
+	})
+}
+
+// ValidatePersonalToken 校验个人访问令牌，并记录最近使用的时间和 IP
+func (authService *AuthService) ValidatePersonalToken(plain string, ip string) (model.AccessToken, error) {
+	token, err := authService.authRepository.GetAccessTokenByHash(hashToken(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.AccessToken{}, errors.New(commonModel.TOKEN_NOT_VALID)
+		}
+		return model.AccessToken{}, err
+	}
+
+	now := time.Now()
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return model.AccessToken{}, errors.New(commonModel.ACCESS_TOKEN_EXPIRED)
+	}
+
+	// 定期记录令牌最近使用的时间和 IP
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= model.SessionTouchInterval || token.LastUsedIP != ip {
+		if err := authService.txManager.Run(func(ctx context.Context) error {
+			return authService.authRepository.TouchAccessToken(ctx, token.ID, now, ip)
+		}); err != nil {
+			logUtil.GetLogger().Warn("[更新访问令牌使用时间失败]", zap.Uint("令牌", token.ID), zap.Error(err))
+		}
+	}
+
+	return token, nil
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	totpUtil "github.com/lin-snow/ech0/internal/util/totp"
+)
+
+// recoveryEncoding 恢复码使用小写的 Base32 编码，方便抄写
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// GetTwoFactorStatus 获取用户的两步验证状态
+func (authService *AuthService) GetTwoFactorStatus(userid uint) (model.TwoFactorStatus, error) {
+	var status model.TwoFactorStatus
+
+	twoFactor, err := authService.getTwoFactor(userid)
+	if err != nil {
+		return status, err
+	}
+	if !twoFactor.Enabled {
+		return status, nil
+	}
+
+	count, err := authService.authRepository.CountRecoveryCodes(userid)
+	if err != nil {
+		return status, err
+	}
+	status.Enabled = true
+	status.RecoveryCodesRemaining = int(count)
+
+	return status, nil
+}
+
+// SetupTwoFactor 开始绑定两步验证，生成新的密钥，验证通过后才会启用
+func (authService *AuthService) SetupTwoFactor(userid uint) (model.TwoFactorSetup, error) {
+	twoFactor, err := authService.getTwoFactor(userid)
+	if err != nil {
+		return model.TwoFactorSetup{}, err
+	}
+	if twoFactor.Enabled {
+		return model.TwoFactorSetup{}, errors.New(commonModel.TWO_FACTOR_ALREADY_ENABLED)
+	}
+
+	user, err := authService.userService.GetUserByID(int(userid))
+	if err != nil {
+		return model.TwoFactorSetup{}, err
+	}
+
+	secret, err := totpUtil.GenerateSecret()
+	if err != nil {
+		return model.TwoFactorSetup{}, err
+	}
+
+	twoFactor.UserID = userid
+	twoFactor.Secret = secret
+	twoFactor.LastCounter = 0
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		return authService.authRepository.SaveTwoFactor(ctx, &twoFactor)
+	}); err != nil {
+		return model.TwoFactorSetup{}, err
+	}
+
+	return model.TwoFactorSetup{
+		Secret: secret,
+		URI:    totpUtil.URI(model.TOTPIssuer, user.Username, secret),
+	}, nil
+}
+
+// EnableTwoFactor 校验身份验证器生成的验证码后启用两步验证，返回一次性的恢复码
+func (authService *AuthService) EnableTwoFactor(userid uint, code string) ([]string, error) {
+	twoFactor, err := authService.getTwoFactor(userid)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Enabled {
+		return nil, errors.New(commonModel.TWO_FACTOR_ALREADY_ENABLED)
+	}
+	if twoFactor.Secret == "" {
+		return nil, errors.New(commonModel.TWO_FACTOR_NOT_SETUP)
+	}
+
+	// 启用时只接受验证码，确认身份验证器已正确绑定
+	counter, ok := totpUtil.Validate(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return nil, errors.New(commonModel.TWO_FACTOR_CODE_INVALID)
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor.Enabled = true
+	twoFactor.LastCounter = counter
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		if err := authService.authRepository.SaveTwoFactor(ctx, &twoFactor); err != nil {
+			return err
+		}
+		return authService.authRepository.ReplaceRecoveryCodes(ctx, userid, hashes)
+	}); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor 校验验证码或恢复码后关闭两步验证
+func (authService *AuthService) DisableTwoFactor(userid uint, code string) error {
+	twoFactor, err := authService.getEnabledTwoFactor(userid)
+	if err != nil {
+		return err
+	}
+
+	return authService.txManager.Run(func(ctx context.Context) error {
+		if err := authService.verifyTwoFactorCode(ctx, twoFactor, code); err != nil {
+			return err
+		}
+		return authService.authRepository.DeleteTwoFactor(ctx, userid)
+	})
+}
+
+// RegenerateRecoveryCodes 校验验证码或恢复码后重新生成恢复码，旧的恢复码全部失效
+func (authService *AuthService) RegenerateRecoveryCodes(userid uint, code string) ([]string, error) {
+	twoFactor, err := authService.getEnabledTwoFactor(userid)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		if err := authService.verifyTwoFactorCode(ctx, twoFactor, code); err != nil {
+			return err
+		}
+		return authService.authRepository.ReplaceRecoveryCodes(ctx, userid, hashes)
+	}); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// VerifyTwoFactor 使用登录时返回的凭据和验证码完成登录
+func (authService *AuthService) VerifyTwoFactor(dto *model.TwoFactorVerifyDto, client model.ClientInfo) (model.TokenPair, error) {
+	challenge, err := authService.authRepository.GetChallenge(dto.Challenge)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.TokenPair{}, errors.New(commonModel.TWO_FACTOR_CHALLENGE_INVALID)
+		}
+		return model.TokenPair{}, err
+	}
+
+	// 凭据过期或尝试次数过多时作废，需要重新输入密码
+	if time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= model.MaxChallengeAttempts {
+		if err := authService.txManager.Run(func(ctx context.Context) error {
+			return authService.authRepository.DeleteChallenge(ctx, challenge.ID)
+		}); err != nil {
+			return model.TokenPair{}, err
+		}
+		return model.TokenPair{}, errors.New(commonModel.TWO_FACTOR_CHALLENGE_INVALID)
+	}
+
+	user, err := authService.userService.GetUserByID(int(challenge.UserID))
+	if err != nil {
+		return model.TokenPair{}, errors.New(commonModel.TWO_FACTOR_CHALLENGE_INVALID)
+	}
+	twoFactor, err := authService.getEnabledTwoFactor(challenge.UserID)
+	if err != nil {
+		return model.TokenPair{}, errors.New(commonModel.TWO_FACTOR_CHALLENGE_INVALID)
+	}
+
+	// 每个凭据的尝试次数有限，但可以重新登录获取新的凭据，因此同样按 IP 和用户名统计失败次数
+	targets := loginTargets(user.Username, client.IP)
+	if err := authService.checkLockout(targets...); err != nil {
+		return model.TokenPair{}, err
+	}
+
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		return authService.verifyTwoFactorCode(ctx, twoFactor, dto.Code)
+	}); err != nil {
+		if err.Error() == commonModel.TWO_FACTOR_CODE_INVALID {
+			authService.recordLoginFailure(user.Username, client.IP, err.Error(), targets...)
+			if err := authService.txManager.Run(func(ctx context.Context) error {
+				return authService.authRepository.IncrementChallengeAttempts(ctx, challenge.ID)
+			}); err != nil {
+				return model.TokenPair{}, err
+			}
+		}
+		return model.TokenPair{}, err
+	}
+
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		return authService.authRepository.DeleteChallenge(ctx, challenge.ID)
+	}); err != nil {
+		return model.TokenPair{}, err
+	}
+	authService.clearLoginFailures(user.Username)
+
+	return authService.issueSession(user, client)
+}
+
+// ResetTwoFactor 关闭指定用户的两步验证（供管理员在用户丢失身份验证器和恢复码时使用）
+func (authService *AuthService) ResetTwoFactor(username string) error {
+	user, err := authService.userService.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	if _, err := authService.getEnabledTwoFactor(user.ID); err != nil {
+		return err
+	}
+
+	return authService.txManager.Run(func(ctx context.Context) error {
+		return authService.authRepository.DeleteTwoFactor(ctx, user.ID)
+	})
+}
+
+// passTwoFactor 检查登录是否满足两步验证：未开启时直接通过，附带验证码时校验验证码，否则返回 false
+func (authService *AuthService) passTwoFactor(user userModel.User, code string) (bool, error) {
+	twoFactor, err := authService.getTwoFactor(user.ID)
+	if err != nil {
+		return false, err
+	}
+	if !twoFactor.Enabled {
+		return true, nil
+	}
+	if strings.TrimSpace(code) == "" {
+		return false, nil
+	}
+
+	if err := authService.txManager.Run(func(ctx context.Context) error {
+		return authService.verifyTwoFactorCode(ctx, twoFactor, code)
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// createChallenge 创建等待两步验证的登录请求，并顺带清理已过期的请求
+func (authService *AuthService) createChallenge(user userModel.User) (string, error) {
+	now := time.Now()
+	challenge := model.LoginChallenge{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(model.ChallengeExpiry),
+		CreatedAt: now,
+	}
+
+	err := authService.txManager.Run(func(ctx context.Context) error {
+		if err := authService.authRepository.DeleteExpiredChallenges(ctx); err != nil {
+			return err
+		}
+		return authService.authRepository.CreateChallenge(ctx, &challenge)
+	})
+
+	return challenge.ID, err
+}
+
+// verifyTwoFactorCode 校验验证码（同一时间步的验证码只能使用一次）或恢复码（使用后失效）
+func (authService *AuthService) verifyTwoFactorCode(ctx context.Context, twoFactor model.TwoFactor, code string) error {
+	if counter, ok := totpUtil.Validate(twoFactor.Secret, code, time.Now()); ok {
+		advanced, err := authService.authRepository.AdvanceTOTPCounter(ctx, twoFactor.UserID, counter)
+		if err != nil {
+			return err
+		}
+		if !advanced {
+			return errors.New(commonModel.TWO_FACTOR_CODE_INVALID)
+		}
+		return nil
+	}
+
+	used, err := authService.authRepository.UseRecoveryCode(ctx, twoFactor.UserID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.New(commonModel.TWO_FACTOR_CODE_INVALID)
+	}
+	return nil
+}
+
+// getTwoFactor 获取用户的两步验证，不存在时返回未开启的空记录
+func (authService *AuthService) getTwoFactor(userid uint) (model.TwoFactor, error) {
+	twoFactor, err := authService.authRepository.GetTwoFactor(userid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.TwoFactor{UserID: userid}, nil
+		}
+		return model.TwoFactor{}, err
+	}
+	return twoFactor, nil
+}
+
+// getEnabledTwoFactor 获取用户已开启的两步验证
+func (authService *AuthService) getEnabledTwoFactor(userid uint) (model.TwoFactor, error) {
+	twoFactor, err := authService.getTwoFactor(userid)
+	if err != nil {
+		return model.TwoFactor{}, err
+	}
+	if !twoFactor.Enabled {
+		return model.TwoFactor{}, errors.New(commonModel.TWO_FACTOR_NOT_ENABLED)
+	}
+	return twoFactor, nil
+}
+
+// generateRecoveryCodes 生成恢复码，返回明文（格式为 xxxx-xxxx）和对应的哈希
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, model.RecoveryCodeCount)
+	hashes := make([]string, 0, model.RecoveryCodeCount)
+	for range model.RecoveryCodeCount {
+		buf := make([]byte, model.RecoveryCodeBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := recoveryEncoding.EncodeToString(buf)
+		codes = append(codes, raw[:4]+"-"+raw[4:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode 去掉恢复码中的分隔符和空白并转为小写
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	totpUtil "github.com/lin-snow/ech0/internal/util/totp"
+)
+
+// enableTestTwoFactor 为用户绑定并启用两步验证，返回密钥、启用时使用的时间步和恢复码
+func enableTestTwoFactor(t *testing.T, service *AuthService, user userModel.User) (string, int64, []string) {
+	t.Helper()
+
+	setup, err := service.SetupTwoFactor(user.ID)
+	require.NoError(t, err)
+
+	counter := totpUtil.Counter(time.Now())
+	code, err := totpUtil.Code(setup.Secret, counter)
+	require.NoError(t, err)
+	recoveryCodes, err := service.EnableTwoFactor(user.ID, code)
+	require.NoError(t, err)
+
+	return setup.Secret, counter, recoveryCodes
+}
+
+// 同一时间步的验证码只能使用一次，时间步只能前进
+func TestPassTwoFactor_RejectsReplayedCode(t *testing.T) {
+	service, _, users := newTestAuthService(t)
+	user := userModel.User{ID: 1, Username: "alice"}
+	users.users[user.ID] = user
+	secret, counter, _ := enableTestTwoFactor(t, service, user)
+
+	// 启用时使用的验证码不能再用于登录
+	enableCode, err := totpUtil.Code(secret, counter)
+	require.NoError(t, err)
+	_, err = service.passTwoFactor(user, enableCode)
+	assert.EqualError(t, err, commonModel.TWO_FACTOR_CODE_INVALID)
+
+	nextCode, err := totpUtil.Code(secret, counter+1)
+	require.NoError(t, err)
+	passed, err := service.passTwoFactor(user, nextCode)
+	require.NoError(t, err)
+	assert.True(t, passed)
+
+	// 重放刚刚使用的验证码，或使用更早时间步的验证码
+	_, err = service.passTwoFactor(user, nextCode)
+	assert.EqualError(t, err, commonModel.TWO_FACTOR_CODE_INVALID)
+	_, err = service.passTwoFactor(user, enableCode)
+	assert.EqualError(t, err, commonModel.TWO_FACTOR_CODE_INVALID)
+}
+
+// 恢复码使用后失效，没有附带验证码时要求进行两步验证
+func TestPassTwoFactor_RecoveryCodeIsSingleUse(t *testing.T) {
+	service, _, users := newTestAuthService(t)
+	user := userModel.User{ID: 1, Username: "alice"}
+	users.users[user.ID] = user
+	_, _, recoveryCodes := enableTestTwoFactor(t, service, user)
+	require.NotEmpty(t, recoveryCodes)
+
+	passed, err := service.passTwoFactor(user, "")
+	require.NoError(t, err)
+	assert.False(t, passed)
+
+	passed, err = service.passTwoFactor(user, recoveryCodes[0])
+	require.NoError(t, err)
+	assert.True(t, passed)
+
+	_, err = service.passTwoFactor(user, recoveryCodes[0])
+	assert.EqualError(t, err, commonModel.TWO_FACTOR_CODE_INVALID)
+
+	status, err := service.GetTwoFactorStatus(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, len(recoveryCodes)-1, status.RecoveryCodesRemaining)
+}
@@ -1,13 +1,13 @@
 package service
 
 import (
-	"errors"
 	"fmt"
 	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lin-snow/ech0/internal/authz"
 	backup "github.com/lin-snow/ech0/internal/backup"
-	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
 )
 
@@ -22,16 +22,11 @@ func NewBackupService(commonService commonService.CommonServiceInterface) Backup
 }
 
 // Backup 执行备份
-func (backupService *BackupService) Backup(userid uint) error {
-	user, err := backupService.commonService.CommonGetUserByUserId(userid)
-	if err != nil {
+func (backupService *BackupService) Backup(user userModel.User) error {
+	if err := authz.Authorize(user, authz.PermBackup); err != nil {
 		return err
 	}
 
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
-	}
-
 	// 执行备份
 	if _, _, err := backup.ExecuteBackup(); err != nil {
 		return err
@@ -41,20 +36,14 @@ func (backupService *BackupService) Backup(userid uint) error {
 }
 
 // ExportBackup 导出备份
-func (backupService *BackupService) ExportBackup(userid uint, ctx *gin.Context) error {
-	user, err := backupService.commonService.CommonGetUserByUserId(userid)
-	if err != nil {
+func (backupService *BackupService) ExportBackup(user userModel.User, ctx *gin.Context) error {
+	if err := authz.Authorize(user, authz.PermBackup); err != nil {
 		return err
 	}
 
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
-	}
-
 	// 导出备份
 	// 1. 先备份
-	var backupFilePath string // 备份文件路径
-	backupFilePath, _, err = backup.ExecuteBackup()
+	backupFilePath, _, err := backup.ExecuteBackup() // 备份文件路径
 	if err != nil {
 		return err
 	}
@@ -1,13 +1,16 @@
 package service
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
 
 type BackupServiceInterface interface {
 	// 执行备份
-	Backup(userid uint) error
+	Backup(user userModel.User) error
 
 	// 导出备份
-	ExportBackup(userid uint, ctx *gin.Context) error
+	ExportBackup(user userModel.User, ctx *gin.Context) error
 
 	// 恢复备份
 	// ImportBackup(userid uint) error
@@ -7,22 +7,25 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/feeds"
+	"github.com/lin-snow/ech0/internal/authz"
 	"github.com/lin-snow/ech0/internal/config"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	echoModel "github.com/lin-snow/ech0/internal/model/echo"
 	userModel "github.com/lin-snow/ech0/internal/model/user"
 	repository "github.com/lin-snow/ech0/internal/repository/common"
-	mdUtil "github.com/lin-snow/ech0/internal/util/md"
 	storageUtil "github.com/lin-snow/ech0/internal/util/storage"
+	timeUtil "github.com/lin-snow/ech0/internal/util/time"
 )
 
 type CommonService struct {
 	txManager        transaction.TransactionManager
 	commonRepository repository.CommonRepositoryInterface
+	location         atomic.Pointer[time.Location] // 缓存的站点时区，修改设置后清除
 }
 
 func NewCommonService(
@@ -39,13 +42,18 @@ func (commonService *CommonService) CommonGetUserByUserId(userId uint) (userMode
 	return commonService.commonRepository.GetUserByUserId(userId)
 }
 
-func (commonService *CommonService) UploadImage(userId uint, file *multipart.FileHeader) (string, error) {
-	user, err := commonService.commonRepository.GetUserByUserId(userId)
+// CommonGetUserByUsername 根据用户名获取用户信息，用户不存在时返回 USER_NOTFOUND
+func (commonService *CommonService) CommonGetUserByUsername(username string) (userModel.User, error) {
+	user, err := commonService.commonRepository.GetUserByUsername(username)
 	if err != nil {
-		return "", err
+		return userModel.User{}, errors.New(commonModel.USER_NOTFOUND)
 	}
-	if !user.IsAdmin {
-		return "", errors.New(commonModel.NO_PERMISSION_DENIED)
+	return user, nil
+}
+
+func (commonService *CommonService) UploadImage(user userModel.User, file *multipart.FileHeader) (string, error) {
+	if err := authz.Authorize(user, authz.PermMediaUpload); err != nil {
+		return "", err
 	}
 
 	// 检查文件类型是否合法
@@ -67,14 +75,10 @@ func (commonService *CommonService) UploadImage(userId uint, file *multipart.Fil
 	return imageUrl, nil
 }
 
-func (commonService *CommonService) DeleteImage(userid uint, url, source string) error {
-	user, err := commonService.commonRepository.GetUserByUserId(userid)
-	if err != nil {
+func (commonService *CommonService) DeleteImage(user userModel.User, url, source string) error {
+	if err := authz.Authorize(user, authz.PermMediaDelete); err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
-	}
 
 	// 检查图片是否存在
 	if url == "" {
@@ -170,6 +174,7 @@ func (commonService *CommonService) GetStatus() (commonModel.Status, error) {
 		users = append(users, commonModel.UserStatus{
 			UserID:   user.ID,
 			UserName: user.Username,
+			Role:     user.Role,
 			IsAdmin:  user.IsAdmin,
 		})
 	}
@@ -190,123 +195,154 @@ func (commonService *CommonService) GetStatus() (commonModel.Status, error) {
 	return status, nil
 }
 
-func (commonService *CommonService) GetHeatMap() ([]commonModel.Heatmap, error) {
-	// 获取当前日期
-	today := time.Now()
+// GetLocation 获取站点设置的时区，未设置或无效时使用配置文件中的时区，最后回退到服务器本地时区
+// 解析结果会被缓存，直到 InvalidateLocation 被调用
+func (commonService *CommonService) GetLocation() *time.Location {
+	if loc := commonService.location.Load(); loc != nil {
+		return loc
+	}
+
+	timezone := config.Config.Setting.Timezone
+	setting, settingErr := commonService.commonRepository.GetSystemSetting()
+	if settingErr == nil && setting.Timezone != "" {
+		timezone = setting.Timezone
+	}
 
-	// 获取一个月前的日期
-	oneMonthAgo := today.AddDate(0, -1, 0)
+	loc, err := timeUtil.LoadLocation(timezone)
+	if err != nil {
+		loc = time.Local
+	}
 
-	// 格式化为YYYY-MM-DD
-	startDate := oneMonthAgo.Format("2006-01-02") // 一个月前的日期
-	endDate := today.Format("2006-01-02")         // 当前日期
+	// 读取设置失败时不缓存，下次重新读取
+	if settingErr == nil {
+		commonService.location.Store(loc)
+	}
 
-	// 数据库查询 （只返回某天count >= 1的item）
-	heatmapData, err := commonService.commonRepository.GetHeatMap(startDate, endDate)
+	return loc
+}
+
+// InvalidateLocation 清除缓存的站点时区
+func (commonService *CommonService) InvalidateLocation() {
+	commonService.location.Store(nil)
+}
+
+// ResolveLocation 解析 IANA 时区名称，名称为空时使用站点设置的时区
+func (commonService *CommonService) ResolveLocation(name string) (*time.Location, error) {
+	if strings.TrimSpace(name) == "" {
+		return commonService.GetLocation(), nil
+	}
+
+	loc, err := timeUtil.LoadLocation(name)
 	if err != nil {
-		return nil, err
+		return nil, errors.New(commonModel.INVALID_TIMEZONE)
 	}
 
-	// 如果不足30天，补齐数据（date为缺的日期，count为0）
-	// Create a map for quick lookup of existing heatmap data
-	heatmapMap := make(map[string]commonModel.Heatmap)
-	for _, item := range heatmapData {
-		heatmapMap[item.Date] = item
+	return loc, nil
+}
+
+// GetHeatMap 获取热力图数据，支持自定义天数、年份视图、日期范围、时区和用户过滤
+func (commonService *CommonService) GetHeatMap(user userModel.User, heatmapQueryDto commonModel.HeatmapQueryDto) ([]commonModel.Heatmap, error) {
+	loc, err := commonService.ResolveLocation(heatmapQueryDto.Timezone)
+	if err != nil {
+		return nil, err
 	}
 
-	var results [30]commonModel.Heatmap
-	for i := 0; i < 30; i++ {
-		// 计算日期 (from today back to 29 days ago)
-		date := today.AddDate(0, 0, -i).Format("2006-01-02")
-		resultIndex := 29 - i
+	// 计算查询范围 [start, end)，均为 loc 时区下某天的零点
+	start, end, err := resolveHeatmapRange(heatmapQueryDto, loc)
+	if err != nil {
+		return nil, err
+	}
 
-		if item, ok := heatmapMap[date]; ok {
-			// 找到数据，填充结果
-			results[resultIndex] = item
+	// 只有拥有查看私密 Echo 权限的用户可以统计私密Echo，统计单个用户时本人也可以统计自己的私密Echo
+	showPrivate := false
+	if heatmapQueryDto.Private {
+		if heatmapQueryDto.UserID != 0 {
+			showPrivate = authz.CanViewPrivate(user, heatmapQueryDto.UserID)
 		} else {
-			// 未找到数据，填充默认值
-			results[resultIndex] = commonModel.Heatmap{
-				Date:  date,
-				Count: 0,
-			}
+			showPrivate = authz.Can(user, authz.PermEchoReadPrivate)
 		}
 	}
 
-	return results[:], nil
+	// 数据库查询 （只返回某天count >= 1的item）
+	heatmapData, err := commonService.commonRepository.GetHeatMap(start, end, loc, heatmapQueryDto.UserID, showPrivate)
+	if err != nil {
+		return nil, err
+	}
+
+	// 补齐数据（date为缺的日期，count为0）
+	heatmapMap := make(map[string]int, len(heatmapData))
+	for _, item := range heatmapData {
+		heatmapMap[item.Date] = item.Count
+	}
+
+	var results []commonModel.Heatmap
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		results = append(results, commonModel.Heatmap{
+			Date:  date,
+			Count: heatmapMap[date],
+		})
+	}
+
+	return results, nil
 }
 
-func (commonService *CommonService) GenerateRSS(ctx *gin.Context) (string, error) {
-	// 获取所有Echo
-	echos, err := commonService.commonRepository.GetAllEchos(false)
+// GetUserHeatMap 获取指定用户的热力图数据
+func (commonService *CommonService) GetUserHeatMap(user userModel.User, username string, heatmapQueryDto commonModel.HeatmapQueryDto) ([]commonModel.Heatmap, error) {
+	target, err := commonService.CommonGetUserByUsername(username)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// 生成 RSS 订阅链接
-	schema := "http"
-	if ctx.Request.TLS != nil {
-		schema = "https"
-	}
-	host := ctx.Request.Host
-	feed := &feeds.Feed{
-		Title: "Ech0s~",
-		Link: &feeds.Link{
-			Href: fmt.Sprintf("%s://%s/", schema, host),
-		},
-		Image: &feeds.Image{
-			Url: fmt.Sprintf("%s://%s/favicon.ico", schema, host),
-		},
-		Description: "Ech0s~",
-		Author: &feeds.Author{
-			Name: "Ech0s~",
-		},
-		Updated: time.Now(),
-	}
-
-	for _, msg := range echos {
-		renderedContent := mdUtil.MdToHTML([]byte(msg.Content))
-
-		title := msg.Username + " - " + msg.CreatedAt.Format("2006-01-02")
-
-		// 添加图片链接到正文前(scheme://host/api/ImageURL)
-		if len(msg.Images) > 0 {
-			for _, image := range msg.Images {
-				// 根据图片来源生成链接
-				if image.ImageSource == echoModel.ImageSourceLocal {
-					imageURL := fmt.Sprintf("%s://%s/api%s", schema, host, image.ImageURL)
-					renderedContent = append([]byte(fmt.Sprintf("<img src=\"%s\" alt=\"Image\" style=\"max-width:100%%;height:auto;\" />", imageURL)), renderedContent...)
-				}
-			}
-		}
+	heatmapQueryDto.UserID = target.ID
+	return commonService.GetHeatMap(user, heatmapQueryDto)
+}
 
-		item := &feeds.Item{
-			Title:       title,
-			Link:        &feeds.Link{Href: fmt.Sprintf("%s://%s/echo/%d", schema, host, msg.ID)},
-			Description: string(renderedContent),
-			Author: &feeds.Author{
-				Name: msg.Username,
-			},
-			Created: msg.CreatedAt,
+// resolveHeatmapRange 根据查询参数计算热力图的时间范围，优先级为 start/end > year > days
+func resolveHeatmapRange(heatmapQueryDto commonModel.HeatmapQueryDto, loc *time.Location) (time.Time, time.Time, error) {
+	// 指定日期范围
+	if heatmapQueryDto.Start != "" || heatmapQueryDto.End != "" {
+		start, err := time.ParseInLocation("2006-01-02", heatmapQueryDto.Start, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New(commonModel.INVALID_HEATMAP_RANGE)
+		}
+		end, err := time.ParseInLocation("2006-01-02", heatmapQueryDto.End, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New(commonModel.INVALID_HEATMAP_RANGE)
+		}
+		// 结束日期包含在内
+		end = end.AddDate(0, 0, 1)
+		if !start.Before(end) || start.AddDate(0, 0, commonModel.MaxHeatmapDays).Before(end) {
+			return time.Time{}, time.Time{}, errors.New(commonModel.INVALID_HEATMAP_RANGE)
 		}
-		feed.Items = append(feed.Items, item)
+		return start, end, nil
 	}
 
-	atom, err := feed.ToAtom()
-	if err != nil {
-		return "", err
+	// 年份视图
+	if heatmapQueryDto.Year != 0 {
+		if heatmapQueryDto.Year < 1 || heatmapQueryDto.Year > 9999 {
+			return time.Time{}, time.Time{}, errors.New(commonModel.INVALID_HEATMAP_RANGE)
+		}
+		start := time.Date(heatmapQueryDto.Year, time.January, 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(1, 0, 0), nil
 	}
 
-	return atom, nil
+	// 最近 N 天（含今天）
+	days := heatmapQueryDto.Days
+	if days == 0 {
+		days = commonModel.DefaultHeatmapDays
+	}
+	if days < 1 || days > commonModel.MaxHeatmapDays {
+		return time.Time{}, time.Time{}, errors.New(commonModel.INVALID_HEATMAP_RANGE)
+	}
+	end := timeUtil.StartOfDay(time.Now(), loc).AddDate(0, 0, 1)
+	return end.AddDate(0, 0, -days), end, nil
 }
 
-func (commonService *CommonService) UploadMusic(userId uint, file *multipart.FileHeader) (string, error) {
-	user, err := commonService.commonRepository.GetUserByUserId(userId)
-	if err != nil {
+func (commonService *CommonService) UploadMusic(user userModel.User, file *multipart.FileHeader) (string, error) {
+	if err := authz.Authorize(user, authz.PermSettingsManage); err != nil {
 		return "", err
 	}
-	if !user.IsAdmin {
-		return "", errors.New(commonModel.NO_PERMISSION_DENIED)
-	}
 
 	// 检查文件类型是否合法
 	if !storageUtil.IsAllowedType(file.Header.Get("Content-Type"), config.Config.Upload.AllowedTypes) {
@@ -327,14 +363,10 @@ func (commonService *CommonService) UploadMusic(userId uint, file *multipart.Fil
 	return audioUrl, nil
 }
 
-func (commonService *CommonService) DeleteMusic(userid uint) error {
-	user, err := commonService.commonRepository.GetUserByUserId(userid)
-	if err != nil {
+func (commonService *CommonService) DeleteMusic(user userModel.User) error {
+	if err := authz.Authorize(user, authz.PermSettingsManage); err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
-	}
 
 	// 支持的音频格式
 	audioFiles := []string{"music.flac", "music.m4a", "music.mp3"}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	repository "github.com/lin-snow/ech0/internal/repository/common"
+)
+
+// fakeCommonRepository 模拟公共仓库，只实现读取系统设置
+type fakeCommonRepository struct {
+	repository.CommonRepositoryInterface
+	setting settingModel.SystemSetting
+	reads   int
+}
+
+func (f *fakeCommonRepository) GetSystemSetting() (settingModel.SystemSetting, error) {
+	f.reads++
+	return f.setting, nil
+}
+
+// 时区只在首次使用和清除缓存后读取设置
+func TestGetLocation_CachedUntilInvalidated(t *testing.T) {
+	repo := &fakeCommonRepository{setting: settingModel.SystemSetting{Timezone: "Asia/Shanghai"}}
+	commonService := &CommonService{commonRepository: repo}
+
+	assert.Equal(t, "Asia/Shanghai", commonService.GetLocation().String())
+	assert.Equal(t, "Asia/Shanghai", commonService.GetLocation().String())
+	assert.Equal(t, 1, repo.reads)
+
+	repo.setting.Timezone = "Europe/Berlin"
+	assert.Equal(t, "Asia/Shanghai", commonService.GetLocation().String())
+
+	commonService.InvalidateLocation()
+	assert.Equal(t, "Europe/Berlin", commonService.GetLocation().String())
+	assert.Equal(t, 2, repo.reads)
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/feeds"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	mdUtil "github.com/lin-snow/ech0/internal/util/md"
+	tagUtil "github.com/lin-snow/ech0/internal/util/tag"
+)
+
+// GenerateFeed 生成指定格式（Atom、RSS 2.0、JSON Feed 1.1）的订阅源，支持按标签和用户过滤
+func (commonService *CommonService) GenerateFeed(ctx *gin.Context, format commonModel.FeedFormat, feedQueryDto commonModel.FeedQueryDto) (commonModel.Feed, error) {
+	// 根据请求生成订阅链接
+	schema := "http"
+	if ctx.Request.TLS != nil {
+		schema = "https"
+	}
+	baseURL := fmt.Sprintf("%s://%s", schema, ctx.Request.Host)
+
+	return commonService.buildFeed(baseURL, baseURL+ctx.Request.URL.RequestURI(), format, feedQueryDto)
+}
+
+// GenerateFeedByURL 根据订阅源地址（/rss、/feed/{format} 或 /users/{username}/feed/{format}）生成订阅源，用于 WebSub 推送
+func (commonService *CommonService) GenerateFeedByURL(feedURL string) (commonModel.Feed, error) {
+	parsed, err := url.Parse(feedURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return commonModel.Feed{}, errors.New(commonModel.INVALID_FEED_FORMAT)
+	}
+
+	query := parsed.Query()
+	feedQueryDto := commonModel.FeedQueryDto{
+		Tag:      query.Get("tag"),
+		Username: query.Get("user"),
+	}
+
+	var format commonModel.FeedFormat
+	switch {
+	case parsed.Path == "/rss":
+		format = commonModel.FeedFormatAtom
+	case strings.HasPrefix(parsed.Path, "/feed/"):
+		format = commonModel.FeedFormat(strings.TrimPrefix(parsed.Path, "/feed/"))
+	case strings.HasPrefix(parsed.Path, "/users/"):
+		// 用户的订阅源：/users/{username}/feed/{format}
+		parts := strings.Split(strings.TrimPrefix(parsed.Path, "/users/"), "/")
+		if len(parts) != 3 || parts[0] == "" || parts[1] != "feed" {
+			return commonModel.Feed{}, errors.New(commonModel.INVALID_FEED_FORMAT)
+		}
+		feedQueryDto.Username = parts[0]
+		format = commonModel.FeedFormat(parts[2])
+	default:
+		return commonModel.Feed{}, errors.New(commonModel.INVALID_FEED_FORMAT)
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if feedQueryDto.Limit, err = strconv.Atoi(limit); err != nil {
+			return commonModel.Feed{}, errors.New(commonModel.INVALID_QUERY_PARAMS)
+		}
+	}
+
+	return commonService.buildFeed(parsed.Scheme+"://"+parsed.Host, feedURL, format, feedQueryDto)
+}
+
+// buildFeed 生成订阅源，baseURL 为站点地址，selfURL 为订阅源自身的地址
+func (commonService *CommonService) buildFeed(baseURL, selfURL string, format commonModel.FeedFormat, feedQueryDto commonModel.FeedQueryDto) (commonModel.Feed, error) {
+	if format != commonModel.FeedFormatAtom && format != commonModel.FeedFormatRSS && format != commonModel.FeedFormatJSON {
+		return commonModel.Feed{}, errors.New(commonModel.INVALID_FEED_FORMAT)
+	}
+
+	// 条目数量
+	limit := feedQueryDto.Limit
+	if limit <= 0 {
+		limit = commonModel.DefaultFeedLimit
+	}
+	if limit > commonModel.MaxFeedLimit {
+		limit = commonModel.MaxFeedLimit
+	}
+
+	// 只包含指定用户发布的 Echo
+	var userID uint
+	username := strings.TrimSpace(feedQueryDto.Username)
+	if username != "" {
+		user, err := commonService.CommonGetUserByUsername(username)
+		if err != nil {
+			return commonModel.Feed{}, err
+		}
+		userID = user.ID
+	}
+
+	tag := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(feedQueryDto.Tag), "#"))
+	echos, err := commonService.commonRepository.GetFeedEchos(limit, tag, userID)
+	if err != nil {
+		return commonModel.Feed{}, err
+	}
+
+	// 站点标题与描述取自系统设置
+	title := "Ech0s~"
+	description := "Ech0s~"
+	if setting, err := commonService.commonRepository.GetSystemSetting(); err == nil {
+		if setting.SiteTitle != "" {
+			title = setting.SiteTitle
+		}
+		if setting.ServerName != "" {
+			description = setting.ServerName
+		}
+	}
+	if tag != "" {
+		title = fmt.Sprintf("%s #%s", title, tag)
+	}
+	if username != "" {
+		title = fmt.Sprintf("%s @%s", title, username)
+	}
+
+	loc := commonService.GetLocation()
+
+	feed := &feeds.Feed{
+		Id:    baseURL + "/",
+		Title: title,
+		Link: &feeds.Link{
+			Href: baseURL + "/",
+		},
+		Image: &feeds.Image{
+			Url: baseURL + "/favicon.ico",
+		},
+		Description: description,
+		Author: &feeds.Author{
+			Name: description,
+		},
+	}
+
+	// 订阅源的更新时间取最新条目的发布时间，保证内容不变时输出稳定（便于 ETag 缓存）
+	result := commonModel.Feed{
+		SelfURL: selfURL,
+		HubURL:  baseURL + commonModel.WebSubHubPath,
+	}
+	if len(echos) > 0 {
+		result.LastModified = echos[0].CreatedAt
+		feed.Updated = echos[0].CreatedAt.In(loc)
+	}
+
+	imageURLs := make([][]string, 0, len(echos))
+	for _, msg := range echos {
+		renderedContent := mdUtil.MdToHTML([]byte(msg.Content))
+
+		// 按顺序添加图片到正文前
+		var urls []string
+		var imagesHTML strings.Builder
+		for _, image := range msg.Images {
+			imageURL := feedImageURL(baseURL, image)
+			urls = append(urls, imageURL)
+			imagesHTML.WriteString(fmt.Sprintf("<img src=\"%s\" alt=\"Image\" style=\"max-width:100%%;height:auto;\" />", imageURL))
+		}
+		imageURLs = append(imageURLs, urls)
+
+		link := fmt.Sprintf("%s/echo/%d", baseURL, msg.ID)
+		item := &feeds.Item{
+			Id:      link,
+			Title:   msg.Username + " - " + msg.CreatedAt.In(loc).Format("2006-01-02"),
+			Link:    &feeds.Link{Href: link},
+			Content: imagesHTML.String() + string(renderedContent),
+			Author: &feeds.Author{
+				Name: msg.Username,
+			},
+			Created: msg.CreatedAt.In(loc),
+		}
+
+		// 第一张图片作为附件（RSS 2.0 每个条目只支持一个 enclosure）
+		if len(urls) > 0 {
+			item.Enclosure = feedEnclosure(urls[0])
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	var content string
+	switch format {
+	case commonModel.FeedFormatAtom:
+		atomFeed := (&feeds.Atom{Feed: feed}).AtomFeed()
+		// Atom 支持多个 enclosure，补充其余图片
+		for i, entry := range atomFeed.Entries {
+			for _, imageURL := range imageURLs[i][min(1, len(imageURLs[i])):] {
+				enclosure := feedEnclosure(imageURL)
+				entry.Links = append(entry.Links, feeds.AtomLink{Href: enclosure.Url, Rel: "enclosure", Type: enclosure.Type})
+			}
+		}
+		content, err = feeds.ToXML(&atomHubFeed{
+			AtomFeed: atomFeed,
+			HubLinks: []feeds.AtomLink{{Href: result.HubURL, Rel: "hub"}, {Href: selfURL, Rel: "self"}},
+		})
+		result.ContentType = "application/atom+xml; charset=utf-8"
+	case commonModel.FeedFormatRSS:
+		content, err = feeds.ToXML(&rssHubFeed{
+			RssFeed:  (&feeds.Rss{Feed: feed}).RssFeed(),
+			HubLinks: []rssAtomLink{{Href: result.HubURL, Rel: "hub"}, {Href: selfURL, Rel: "self", Type: "application/rss+xml"}},
+		})
+		result.ContentType = "application/rss+xml; charset=utf-8"
+	case commonModel.FeedFormatJSON:
+		jsonFeed := (&feeds.JSON{Feed: feed}).JSONFeed()
+		jsonFeed.FeedUrl = selfURL
+		jsonFeed.Favicon = baseURL + "/favicon.ico"
+		jsonFeed.Hubs = []*feeds.JSONHub{{Type: "WebSub", Url: result.HubURL}}
+		// JSON Feed 支持多个附件和标签
+		for i, item := range jsonFeed.Items {
+			for _, imageURL := range imageURLs[i] {
+				item.Attachments = append(item.Attachments, feeds.JSONAttachment{
+					Url:      imageURL,
+					MIMEType: feedEnclosure(imageURL).Type,
+				})
+			}
+			item.Tags = tagUtil.ExtractTags(echos[i].Content)
+		}
+		content, err = jsonFeed.ToJSON()
+		result.ContentType = "application/feed+json; charset=utf-8"
+	}
+	if err != nil {
+		return commonModel.Feed{}, err
+	}
+
+	result.Content = []byte(content)
+	return result, nil
+}
+
+// atomHubFeed 在 Atom 订阅源中追加 WebSub 的 hub/self 链接
+type atomHubFeed struct {
+	*feeds.AtomFeed
+	HubLinks []feeds.AtomLink
+}
+
+// FeedXml 实现 feeds.XmlFeed 接口
+func (a *atomHubFeed) FeedXml() interface{} {
+	return a
+}
+
+// rssHubFeed 在 RSS 2.0 订阅源中追加 WebSub 的 hub/self 链接（atom:link）
+type rssHubFeed struct {
+	*feeds.RssFeed
+	HubLinks []rssAtomLink
+}
+
+// rssAtomLink 定义 RSS 2.0 中的 atom:link 元素
+type rssAtomLink struct {
+	XMLName xml.Name `xml:"atom:link"`
+	Href    string   `xml:"href,attr"`
+	Rel     string   `xml:"rel,attr"`
+	Type    string   `xml:"type,attr,omitempty"`
+}
+
+// rssHubFeedXml 定义 RSS 2.0 的根元素（声明 atom 命名空间）
+type rssHubFeedXml struct {
+	XMLName          xml.Name `xml:"rss"`
+	Version          string   `xml:"version,attr"`
+	ContentNamespace string   `xml:"xmlns:content,attr"`
+	AtomNamespace    string   `xml:"xmlns:atom,attr"`
+	Channel          *rssHubFeed
+}
+
+// FeedXml 实现 feeds.XmlFeed 接口
+func (r *rssHubFeed) FeedXml() interface{} {
+	return &rssHubFeedXml{
+		Version:          "2.0",
+		ContentNamespace: "http://purl.org/rss/1.0/modules/content/",
+		AtomNamespace:    "http://www.w3.org/2005/Atom",
+		Channel:          r,
+	}
+}
+
+// feedImageURL 根据图片来源生成图片的完整链接
+func feedImageURL(baseURL string, image echoModel.Image) string {
+	if image.ImageSource == echoModel.ImageSourceLocal {
+		return fmt.Sprintf("%s/api%s", baseURL, image.ImageURL)
+	}
+	return image.ImageURL
+}
+
+// feedEnclosure 根据图片链接生成附件信息（无法得知文件大小时长度为 0）
+func feedEnclosure(imageURL string) *feeds.Enclosure {
+	mimeType := mime.TypeByExtension(strings.ToLower(path.Ext(strings.SplitN(imageURL, "?", 2)[0])))
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = "image/jpeg"
+	}
+
+	return &feeds.Enclosure{
+		Url:    imageURL,
+		Length: "0",
+		Type:   mimeType,
+	}
+}
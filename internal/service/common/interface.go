@@ -2,6 +2,7 @@ package service
 
 import (
 	"mime/multipart"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	model "github.com/lin-snow/ech0/internal/model/common"
@@ -12,11 +13,14 @@ type CommonServiceInterface interface {
 	// CommonGetUserByUserId 根据用户ID获取用户信息
 	CommonGetUserByUserId(userId uint) (userModel.User, error)
 
+	// CommonGetUserByUsername 根据用户名获取用户信息
+	CommonGetUserByUsername(username string) (userModel.User, error)
+
 	// UploadImage 上传图片
-	UploadImage(userid uint, file *multipart.FileHeader) (string, error)
+	UploadImage(user userModel.User, file *multipart.FileHeader) (string, error)
 
 	// DeleteImage 删除图片
-	DeleteImage(userid uint, url, source string) error
+	DeleteImage(user userModel.User, url, source string) error
 
 	// DirectDeleteImage 直接根据URL和来源删除图片
 	DirectDeleteImage(url, source string) error
@@ -27,17 +31,32 @@ type CommonServiceInterface interface {
 	// GetStatus 获取系统状态
 	GetStatus() (model.Status, error)
 
+	// GetLocation 获取站点设置的时区
+	GetLocation() *time.Location
+
+	// InvalidateLocation 清除缓存的站点时区，站点设置修改后调用
+	InvalidateLocation()
+
+	// ResolveLocation 解析时区名称，为空时使用站点设置的时区
+	ResolveLocation(name string) (*time.Location, error)
+
 	// GetHeatMap 获取热力图数据
-	GetHeatMap() ([]model.Heatmap, error)
+	GetHeatMap(user userModel.User, heatmapQueryDto model.HeatmapQueryDto) ([]model.Heatmap, error)
+
+	// GetUserHeatMap 获取指定用户的热力图数据
+	GetUserHeatMap(user userModel.User, username string, heatmapQueryDto model.HeatmapQueryDto) ([]model.Heatmap, error)
+
+	// GenerateFeed 生成指定格式的订阅源
+	GenerateFeed(ctx *gin.Context, format model.FeedFormat, feedQueryDto model.FeedQueryDto) (model.Feed, error)
 
-	// GenerateRSS 生成RSS订阅链接
-	GenerateRSS(ctx *gin.Context) (string, error)
+	// GenerateFeedByURL 根据订阅源地址生成订阅源（用于 WebSub 推送）
+	GenerateFeedByURL(feedURL string) (model.Feed, error)
 
 	// UploadMusic 上传音乐文件
-	UploadMusic(userId uint, file *multipart.FileHeader) (string, error)
+	UploadMusic(user userModel.User, file *multipart.FileHeader) (string, error)
 
 	// DeleteMusic 删除音乐文件
-	DeleteMusic(userid uint) error
+	DeleteMusic(user userModel.User) error
 
 	// GetPlayMusicUrl 获取可播放的音乐URL
 	GetPlayMusicUrl() string
@@ -2,23 +2,23 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/lin-snow/ech0/internal/transaction"
 	"sync"
-	"time"
 
 	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 
+	"github.com/lin-snow/ech0/internal/authz"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/connect"
 	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	repository "github.com/lin-snow/ech0/internal/repository/connect"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
 	settingService "github.com/lin-snow/ech0/internal/service/setting"
-	httpUtil "github.com/lin-snow/ech0/internal/util/http"
 	logUtil "github.com/lin-snow/ech0/internal/util/log"
 )
 
@@ -28,6 +28,11 @@ type ConnectService struct {
 	echoRepository    echoRepository.EchoRepositoryInterface
 	commonService     commonService.CommonServiceInterface
 	settingService    settingService.SettingServiceInterface
+
+	keyPairMu sync.Mutex
+	keyPair   *model.KeyPair // 缓存的握手密钥对
+
+	nonces nonceCache // 最近收到的握手消息 nonce
 }
 
 func NewConnectService(
@@ -37,77 +42,222 @@ func NewConnectService(
 	commonService commonService.CommonServiceInterface,
 	settingService settingService.SettingServiceInterface,
 ) ConnectServiceInterface {
-	return &ConnectService{
+	connectService := &ConnectService{
 		txManager:         tm,
 		connectRepository: connectRepository,
 		echoRepository:    echoRepository,
 		commonService:     commonService,
 		settingService:    settingService,
 	}
+
+	// 启动健康检查、时间线同步和发现实例协程
+	healthWorkerOnce.Do(func() {
+		go connectService.runHealthWorker()
+	})
+	timelineWorkerOnce.Do(func() {
+		go connectService.runTimelineWorker()
+	})
+	discoveryWorkerOnce.Do(func() {
+		go connectService.runDiscoveryWorker()
+	})
+
+	return connectService
 }
 
-// AddConnect 添加连接
-func (connectService *ConnectService) AddConnect(userid uint, connected model.Connected) error {
-	return connectService.txManager.Run(func(ctx context.Context) error {
-		user, err := connectService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
-			return err
-		}
+// AddConnect 添加连接，向对方实例发起握手，对方确认后连接才会生效
+func (connectService *ConnectService) AddConnect(user userModel.User, connected model.Connected) error {
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return err
+	}
 
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
-		}
+	// 检查连接地址是否为空
+	if connected.ConnectURL == "" {
+		return errors.New(commonModel.INVALID_CONNECTION_URL)
+	}
 
-		// 检查连接地址是否为空
-		if connected.ConnectURL == "" {
-			return errors.New(commonModel.INVALID_CONNECTION_URL)
-		}
+	return connectService.addConnect(connected.ConnectURL)
+}
 
-		// 去除连接地址前后的空格和斜杠
-		connected.ConnectURL = httpUtil.TrimURL(connected.ConnectURL)
+// addConnect 校验对方实例的连接信息并发起握手
+func (connectService *ConnectService) addConnect(connectURL string) error {
+	serverURL, err := connectService.settingService.GetServerURL()
+	if err != nil {
+		return err
+	}
 
-		// 检查连接地址是否已存在
-		connectedList, err := connectService.connectRepository.GetAllConnects()
-		if err != nil {
-			return err
-		}
+	// 获取对方实例的信息，以对方公布的地址作为连接地址
+	peerURL := normalizeURL(connectURL)
+	info, err := fetchPeerInfo(peerURL)
+	if err != nil {
+		logUtil.GetLogger().Warn("[连接信息获取失败]", zap.String("地址", peerURL), zap.Error(err))
+		return errors.New(commonModel.CONNECT_UNREACHABLE)
+	}
+	if info.ServerURL != "" {
+		peerURL = normalizeURL(info.ServerURL)
+	}
+	if peerURL == serverURL {
+		return errors.New(commonModel.CONNECT_TO_SELF)
+	}
+	if info.PublicKey == "" {
+		// 对方实例不支持连接握手
+		return errors.New(commonModel.CONNECT_HANDSHAKE_FAIL)
+	}
 
-		// 检查连接地址是否已存在
-		for _, conn := range connectedList {
-			if conn.ConnectURL == connected.ConnectURL {
-				return errors.New(commonModel.CONNECT_HAS_EXISTS)
-			}
+	// 检查连接地址是否已存在
+	existing, err := connectService.connectRepository.GetConnectByURL(peerURL)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing.ID != 0 {
+		switch {
+		case existing.State == model.StateAccepted:
+			return errors.New(commonModel.CONNECT_HAS_EXISTS)
+		case existing.State == model.StatePending && existing.Direction == model.DirectionOutgoing:
+			return errors.New(commonModel.CONNECT_HAS_EXISTS)
+		case existing.State == model.StatePending && existing.Direction == model.DirectionIncoming:
+			// 对方已经发起了连接请求，直接接受
+			return connectService.acceptConnect(existing)
 		}
+	}
 
-		// 添加连接地址
-		if err := connectService.connectRepository.CreateConnect(ctx, &connected); err != nil {
-			return err
+	message, err := connectService.newHandshakeMessage(model.HandshakeRequest, peerURL)
+	if err != nil {
+		return err
+	}
+	result, err := connectService.sendHandshake(message)
+	if err != nil {
+		logUtil.GetLogger().Warn("[连接握手失败]", zap.String("地址", peerURL), zap.Error(err))
+		return errors.New(commonModel.CONNECT_HANDSHAKE_FAIL)
+	}
+
+	existing.ConnectURL = peerURL
+	existing.Direction = model.DirectionOutgoing
+	existing.State = model.StatePending
+	if result.State == model.StateAccepted {
+		existing.State = model.StateAccepted
+	}
+	existing.ServerName = info.ServerName
+	existing.PublicKey = info.PublicKey
+
+	if err := connectService.txManager.Run(func(ctx context.Context) error {
+		if existing.ID == 0 {
+			return connectService.connectRepository.CreateConnect(ctx, &existing)
 		}
+		return connectService.connectRepository.UpdateConnect(ctx, &existing)
+	}); err != nil {
+		return err
+	}
 
-		return nil
-	})
+	connectService.notifyConnectsChanged()
+	return nil
+}
+
+// AcceptConnect 接受其它实例发起的连接请求
+func (connectService *ConnectService) AcceptConnect(user userModel.User, id uint) error {
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return err
+	}
+
+	connected, err := connectService.getConnectByID(id)
+	if err != nil {
+		return err
+	}
+	if connected.Direction != model.DirectionIncoming || connected.State != model.StatePending {
+		return errors.New(commonModel.INVALID_CONNECT_STATE)
+	}
+
+	return connectService.acceptConnect(connected)
+}
+
+// acceptConnect 通知对方并将连接标记为已接受
+func (connectService *ConnectService) acceptConnect(connected model.Connected) error {
+	message, err := connectService.newHandshakeMessage(model.HandshakeAccept, connected.ConnectURL)
+	if err != nil {
+		return err
+	}
+	if _, err := connectService.sendHandshake(message); err != nil {
+		logUtil.GetLogger().Warn("[连接握手失败]", zap.String("地址", connected.ConnectURL), zap.Error(err))
+		return errors.New(commonModel.CONNECT_HANDSHAKE_FAIL)
+	}
+
+	connected.State = model.StateAccepted
+	if err := connectService.txManager.Run(func(ctx context.Context) error {
+		return connectService.connectRepository.UpdateConnect(ctx, &connected)
+	}); err != nil {
+		return err
+	}
 
+	connectService.notifyConnectsChanged()
+	return nil
 }
 
-// DeleteConnect 删除连接
-func (connectService *ConnectService) DeleteConnect(userid, id uint) error {
+// RejectConnect 拒绝其它实例发起的连接请求
+func (connectService *ConnectService) RejectConnect(user userModel.User, id uint) error {
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return err
+	}
+
+	connected, err := connectService.getConnectByID(id)
+	if err != nil {
+		return err
+	}
+	if connected.Direction != model.DirectionIncoming || connected.State != model.StatePending {
+		return errors.New(commonModel.INVALID_CONNECT_STATE)
+	}
+
+	connectService.notifyPeer(model.HandshakeReject, connected)
+
+	connected.State = model.StateRejected
 	return connectService.txManager.Run(func(ctx context.Context) error {
-		user, err := connectService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
-			return err
-		}
+		return connectService.connectRepository.UpdateConnect(ctx, &connected)
+	})
+}
 
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
-		}
+// DeleteConnect 删除连接，已建立或等待确认的连接会通知对方撤销
+func (connectService *ConnectService) DeleteConnect(user userModel.User, id uint) error {
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return err
+	}
 
+	connected, err := connectService.getConnectByID(id)
+	if err != nil {
+		return err
+	}
+	if connected.State == model.StateAccepted || connected.State == model.StatePending {
+		connectService.notifyPeer(model.HandshakeRevoke, connected)
+	}
+
+	if err := connectService.txManager.Run(func(ctx context.Context) error {
 		// 删除连接地址
-		if err := connectService.connectRepository.DeleteConnect(ctx, id); err != nil {
-			return err
+		return connectService.connectRepository.DeleteConnect(ctx, id)
+	}); err != nil {
+		return err
+	}
+
+	connectService.notifyConnectsChanged()
+	return nil
+}
+
+// GetAllConnects 获取所有连接（包括等待确认、已拒绝和已撤销的连接）
+func (connectService *ConnectService) GetAllConnects(user userModel.User) ([]model.Connected, error) {
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return nil, err
+	}
+
+	return connectService.connectRepository.GetAllConnects()
+}
+
+// getConnectByID 根据 ID 获取连接
+func (connectService *ConnectService) getConnectByID(id uint) (model.Connected, error) {
+	connected, err := connectService.connectRepository.GetConnectByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return connected, errors.New(commonModel.CONNECT_NOT_FOUND)
 		}
+		return connected, err
+	}
 
-		return nil
-	})
+	return connected, nil
 }
 
 // GetConnect 提供当前实例的连接信息
@@ -127,7 +277,7 @@ func (connectService *ConnectService) GetConnect() (model.Connect, error) {
 	}
 
 	// 统计当天发布的数量
-	todayEchos := connectService.echoRepository.GetTodayEchos(true)
+	todayEchos := connectService.echoRepository.GetTodayEchos(true, connectService.commonService.GetLocation())
 
 	// 设置 Connect 信息
 	connect.ServerName = setting.ServerName
@@ -136,6 +286,13 @@ func (connectService *ConnectService) GetConnect() (model.Connect, error) {
 	connect.TodayEchos = len(todayEchos)
 	connect.SysUsername = status.Username
 
+	// 公布握手使用的公钥
+	keyPair, err := connectService.getKeyPair()
+	if err != nil {
+		return connect, err
+	}
+	connect.PublicKey = keyPair.PublicKey
+
 	// 处理 Logo URL，避免出现重复的斜杠
 	trimmedServerURL := setting.ServerURL
 	if len(trimmedServerURL) > 0 && trimmedServerURL[len(trimmedServerURL)-1] == '/' {
@@ -156,200 +313,48 @@ func (connectService *ConnectService) GetConnect() (model.Connect, error) {
 	return connect, nil
 }
 
-// GetConnectsInfo 获取实例获取到的其它实例的连接信息
+// GetConnectsInfo 获取其它实例的连接信息（读取后台健康检查缓存的结果，离线的实例不会返回）
 func (connectService *ConnectService) GetConnectsInfo() ([]model.Connect, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second) // 增加到15秒总超时
-	defer cancel()
-
-	// 获取所有连接地址
-	connects, err := connectService.connectRepository.GetAllConnects()
+	connects, err := connectService.connectRepository.GetConnectsByState(model.StateAccepted)
 	if err != nil {
 		return nil, err
 	}
-
-	if len(connects) == 0 {
-		return []model.Connect{}, nil
+	accepted := make(map[uint]struct{}, len(connects))
+	for _, conn := range connects {
+		accepted[conn.ID] = struct{}{}
 	}
 
-	var connectList []model.Connect
-	connectList = make([]model.Connect, 0, len(connects))
-
-	var wg sync.WaitGroup
-	connectChan := make(chan model.Connect, len(connects))
+	healths, err := connectService.connectRepository.GetConnectHealths()
+	if err != nil {
+		return nil, err
+	}
 
+	connectList := make([]model.Connect, 0, len(healths))
 	seenURLs := make(map[string]struct{})
-	var seenMutex sync.Mutex
-
-	// 改进重试配置
-	const maxRetries = 3
-	const baseDelay = 1 * time.Second
-	const requestTimeout = 4 * time.Second // 单个请求超时时间
+	for _, health := range healths {
+		if _, ok := accepted[health.ConnectID]; !ok {
+			continue
+		}
+		if health.LastSeenAt == nil || health.FailureStreak >= model.OfflineFailureStreak || health.Info.ServerURL == "" {
+			continue
+		}
 
-	for _, conn := range connects {
-		wg.Add(1)
-		go func(conn model.Connected) {
-			defer wg.Done()
-			url := httpUtil.TrimURL(conn.ConnectURL) + "/api/connect"
-
-			var lastErr error
-			for attempt := 0; attempt < maxRetries; attempt++ {
-				select {
-				case <-ctx.Done():
-					logUtil.GetLogger().Info("[连接信息获取取消]", zap.String("地址", conn.ConnectURL), zap.Error(ctx.Err()))
-					return // 总体超时直接退出
-				default:
-				}
-
-				// 计算当前重试的延迟时间（指数退避）
-				if attempt > 0 {
-					delay := baseDelay * time.Duration(1<<(attempt-1)) // 1s, 2s, 4s...
-					select {
-					case <-time.After(delay):
-					case <-ctx.Done():
-						return
-					}
-				}
-
-				resp, err := httpUtil.SendRequest(url, "GET", struct {
-					Header  string
-					Content string
-				}{
-					Header:  "Ech0_URL",
-					Content: conn.ConnectURL,
-				}, requestTimeout) // 传入自定义超时时间
-
-				if err != nil {
-					lastErr = err
-					logUtil.GetLogger().Error("[连接信息获取失败]",
-						zap.String("地址", conn.ConnectURL),
-						zap.Int("尝试次数", attempt+1),
-						zap.Error(err),
-					)
-
-					// 如果是最后一次重试，记录最终失败
-					if attempt == maxRetries-1 {
-						logUtil.GetLogger().Error("[连接信息最终失败]",
-							zap.String("地址", conn.ConnectURL),
-							zap.Int("已重试次数", maxRetries),
-							zap.Error(lastErr),
-						)
-					}
-					continue
-				}
-
-				var connectInfo commonModel.Result[model.Connect]
-				if err := json.Unmarshal(resp, &connectInfo); err != nil {
-					lastErr = fmt.Errorf("JSON解析失败: %w", err)
-					logUtil.GetLogger().Error("[连接信息解析失败]",
-						zap.String("地址", conn.ConnectURL),
-						zap.Int("尝试次数", attempt+1),
-						zap.Error(lastErr),
-					)
-
-					if attempt == maxRetries-1 {
-						logUtil.GetLogger().Error("[连接信息最终失败]",
-							zap.String("地址", conn.ConnectURL),
-							zap.Int("已重试次数", maxRetries),
-							zap.Error(lastErr),
-						)
-					}
-					continue
-				}
-
-				// 验证响应数据
-				if connectInfo.Code != 1 {
-					lastErr = fmt.Errorf("响应码无效: %d, 消息: %s", connectInfo.Code, connectInfo.Message)
-					logUtil.GetLogger().Error("[连接信息校验失败]",
-						zap.String("地址", conn.ConnectURL),
-						zap.Int("尝试次数", attempt+1),
-						zap.Error(lastErr),
-					)
-
-					if attempt == maxRetries-1 {
-						logUtil.GetLogger().Error("[连接信息最终失败]",
-							zap.String("地址", conn.ConnectURL),
-							zap.Int("已重试次数", maxRetries),
-							zap.Error(lastErr),
-						)
-					}
-					continue
-				}
-
-				if connectInfo.Data.ServerURL == "" {
-					lastErr = fmt.Errorf("服务器URL为空")
-					logUtil.GetLogger().Error("[连接信息校验失败]",
-						zap.String("地址", conn.ConnectURL),
-						zap.Int("尝试次数", attempt+1),
-						zap.Error(lastErr),
-					)
-
-					if attempt == maxRetries-1 {
-						logUtil.GetLogger().Error("[连接信息最终失败]",
-							zap.String("地址", conn.ConnectURL),
-							zap.Int("已重试次数", maxRetries),
-							zap.Error(lastErr),
-						)
-					}
-					continue
-				}
-
-				// 成功获取有效数据，检查重复并发送
-				seenMutex.Lock()
-				if _, exists := seenURLs[connectInfo.Data.ServerURL]; exists {
-					seenMutex.Unlock()
-					logUtil.GetLogger().Info("[连接信息重复]",
-						zap.String("地址", conn.ConnectURL),
-						zap.String("ServerURL", connectInfo.Data.ServerURL),
-					)
-					return // 重复数据，直接返回
-				}
-				seenURLs[connectInfo.Data.ServerURL] = struct{}{}
-				seenMutex.Unlock()
-
-				logUtil.GetLogger().Info("[连接信息获取成功]",
-					zap.String("地址", conn.ConnectURL),
-					zap.String("服务器", connectInfo.Data.ServerName),
-				)
-				connectChan <- connectInfo.Data
-				return // 成功处理，退出重试循环
-			}
-		}(conn)
-	}
-
-	// 使用带缓冲的通道来避免goroutine泄漏
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(connectChan)
-		close(done)
-	}()
-
-	// 收集结果，支持超时和正常完成
-	select {
-	case <-done:
-		// 正常收集完毕
-		logUtil.GetLogger().Info("[连接信息收集完成] 开始处理收集到的连接")
-	case <-ctx.Done():
-		// 超时，但仍然处理已收集到的数据
-		logUtil.GetLogger().Info("[连接信息收集超时] 处理已收集到的部分连接")
-	}
-
-	// 收集所有有效的连接信息
-	for connect := range connectChan {
-		if connect.ServerURL == "" {
+		// 同一实例可能以不同地址被添加多次
+		if _, exists := seenURLs[health.Info.ServerURL]; exists {
 			continue
 		}
-		connectList = append(connectList, connect)
+		seenURLs[health.Info.ServerURL] = struct{}{}
+
+		connectList = append(connectList, health.Info)
 	}
 
-	logUtil.GetLogger().Info("[连接信息汇总]", zap.Int("有效连接数", len(connectList)))
 	return connectList, nil
 }
 
-// GetConnects 获取当前实例添加的所有连接
+// GetConnects 获取当前实例已建立的所有连接
 func (connectService *ConnectService) GetConnects() ([]model.Connected, error) {
-	// 获取所有连接地址
-	connects, err := connectService.connectRepository.GetAllConnects()
+	// 获取所有已建立的连接
+	connects, err := connectService.connectRepository.GetConnectsByState(model.StateAccepted)
 	if err != nil {
 		return nil, err
 	}
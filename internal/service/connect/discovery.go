@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/connect"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+var (
+	// discoveryWorkerOnce 保证整个进程只启动一个发现实例的协程
+	discoveryWorkerOnce sync.Once
+	// discoveryCh 管理员手动触发时唤醒发现实例的协程（多次通知会合并）
+	discoveryCh = make(chan struct{}, 1)
+	// discoveryMu 保证同一时间只进行一次发现
+	discoveryMu sync.Mutex
+)
+
+// GetPeers 提供当前实例机器可读的连接列表，关闭发现时不公开连接
+func (connectService *ConnectService) GetPeers() (model.PeerList, error) {
+	var peerList model.PeerList
+
+	var setting settingModel.SystemSetting
+	if err := connectService.settingService.GetSetting(&setting); err != nil {
+		return peerList, err
+	}
+
+	info, err := connectService.GetConnect()
+	if err != nil {
+		return peerList, err
+	}
+	peerList.ServerName = info.ServerName
+	peerList.ServerURL = info.ServerURL
+	peerList.Logo = info.Logo
+	peerList.Discoverable = !setting.DisableDiscovery
+	peerList.Peers = []model.Peer{}
+
+	if !peerList.Discoverable {
+		return peerList, nil
+	}
+
+	connects, err := connectService.connectRepository.GetConnectsByState(model.StateAccepted)
+	if err != nil {
+		return peerList, err
+	}
+	for _, conn := range connects {
+		peerList.Peers = append(peerList.Peers, model.Peer{
+			ServerName: conn.ServerName,
+			ServerURL:  httpUtil.TrimURL(conn.ConnectURL),
+		})
+	}
+
+	return peerList, nil
+}
+
+// GetSuggestions 获取推荐实例，status 为空时只获取等待处理的推荐实例
+func (connectService *ConnectService) GetSuggestions(user userModel.User, status model.SuggestionStatus) ([]model.PeerSuggestion, error) {
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return nil, err
+	}
+
+	if status == "" {
+		status = model.SuggestionNew
+	}
+	suggestions, err := connectService.connectRepository.GetSuggestions(status)
+	if err != nil {
+		return nil, err
+	}
+	if len(suggestions) == 0 {
+		return []model.PeerSuggestion{}, nil
+	}
+
+	return suggestions, nil
+}
+
+// AcceptSuggestion 向推荐实例发起连接，成功后移除该推荐
+func (connectService *ConnectService) AcceptSuggestion(user userModel.User, id uint) error {
+	suggestion, err := connectService.getSuggestionByID(user, id)
+	if err != nil {
+		return err
+	}
+
+	if err := connectService.AddConnect(user, model.Connected{ConnectURL: suggestion.ServerURL}); err != nil {
+		return err
+	}
+
+	return connectService.txManager.Run(func(ctx context.Context) error {
+		return connectService.connectRepository.DeleteSuggestion(ctx, suggestion.ID)
+	})
+}
+
+// DismissSuggestion 忽略推荐实例，之后再次发现时不会重新推荐
+func (connectService *ConnectService) DismissSuggestion(user userModel.User, id uint) error {
+	suggestion, err := connectService.getSuggestionByID(user, id)
+	if err != nil {
+		return err
+	}
+
+	suggestion.Status = model.SuggestionDismissed
+	return connectService.txManager.Run(func(ctx context.Context) error {
+		return connectService.connectRepository.SaveSuggestion(ctx, &suggestion)
+	})
+}
+
+// DiscoverPeers 通知后台协程立即发现实例
+func (connectService *ConnectService) DiscoverPeers(user userModel.User) error {
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return err
+	}
+
+	select {
+	case discoveryCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// getSuggestionByID 检查管理员权限并根据 ID 获取推荐实例
+func (connectService *ConnectService) getSuggestionByID(user userModel.User, id uint) (model.PeerSuggestion, error) {
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return model.PeerSuggestion{}, err
+	}
+
+	suggestion, err := connectService.connectRepository.GetSuggestionByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return suggestion, errors.New(commonModel.SUGGESTION_NOT_FOUND)
+		}
+		return suggestion, err
+	}
+
+	return suggestion, nil
+}
+
+// runDiscoveryWorker 定时以及收到通知时发现实例
+func (connectService *ConnectService) runDiscoveryWorker() {
+	ticker := time.NewTicker(model.DiscoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-discoveryCh:
+		}
+
+		connectService.discover()
+	}
+}
+
+// discoveryTarget 等待访问的实例
+type discoveryTarget struct {
+	url   string
+	via   string
+	depth int
+}
+
+// discover 从已建立的连接出发逐层爬取连接的连接，将尚未建立连接的实例保存为推荐实例
+func (connectService *ConnectService) discover() {
+	discoveryMu.Lock()
+	defer discoveryMu.Unlock()
+
+	serverURL, _ := connectService.settingService.GetServerURL()
+
+	connects, err := connectService.connectRepository.GetAllConnects()
+	if err != nil {
+		logUtil.GetLogger().Error("[发现实例获取连接失败]", zap.Error(err))
+		return
+	}
+	suggestions, err := connectService.connectRepository.GetSuggestions("")
+	if err != nil {
+		logUtil.GetLogger().Error("[发现实例获取推荐失败]", zap.Error(err))
+		return
+	}
+
+	// 已有记录（任意状态）的实例不再推荐
+	known := make(map[string]struct{}, len(connects)+1)
+	if serverURL != "" {
+		known[serverURL] = struct{}{}
+	}
+	connectedURLs := make([]string, 0, len(connects))
+	queue := make([]discoveryTarget, 0, len(connects))
+	for _, conn := range connects {
+		connectURL := normalizeURL(conn.ConnectURL)
+		known[connectURL] = struct{}{}
+		connectedURLs = append(connectedURLs, connectURL)
+		if conn.State == model.StateAccepted {
+			queue = append(queue, discoveryTarget{url: connectURL, depth: 1})
+		}
+	}
+	existing := make(map[string]model.PeerSuggestion, len(suggestions))
+	for _, suggestion := range suggestions {
+		existing[suggestion.ServerURL] = suggestion
+	}
+
+	visited := make(map[string]struct{})
+	found := make(map[string]model.PeerSuggestion)
+	now := time.Now()
+
+	// 按广度优先顺序逐个访问，相邻请求之间保持间隔
+	for len(queue) > 0 && len(visited) < model.DiscoveryMaxInstances {
+		target := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[target.url]; ok {
+			continue
+		}
+		if len(visited) > 0 {
+			time.Sleep(model.DiscoveryRequestInterval)
+		}
+		visited[target.url] = struct{}{}
+
+		peerList, err := fetchPeerList(target.url)
+		if err != nil {
+			logUtil.GetLogger().Debug("[发现实例请求失败]", zap.String("地址", target.url), zap.Error(err))
+			continue
+		}
+		// 对方关闭了发现：既不推荐，也不继续访问它的连接
+		if !peerList.Discoverable {
+			continue
+		}
+
+		if _, ok := known[target.url]; !ok {
+			if _, ok := found[target.url]; !ok {
+				suggestion := existing[target.url]
+				suggestion.ServerURL = target.url
+				suggestion.ServerName = peerList.ServerName
+				suggestion.Logo = peerList.Logo
+				suggestion.Via = target.via
+				suggestion.Depth = target.depth
+				suggestion.LastSeenAt = now
+				if suggestion.Status == "" {
+					suggestion.Status = model.SuggestionNew
+				}
+				found[target.url] = suggestion
+			}
+		}
+
+		if target.depth >= model.DiscoveryMaxDepth {
+			continue
+		}
+		for i, peer := range peerList.Peers {
+			if i >= model.DiscoveryMaxPeers {
+				break
+			}
+			peerURL := normalizeURL(peer.ServerURL)
+			if peerURL == "" || peerURL == serverURL {
+				continue
+			}
+			// 其它实例公布的地址不可信，解析到内网的地址既不访问也不推荐
+			if err := checkPeerURL(peerURL); err != nil {
+				continue
+			}
+			queue = append(queue, discoveryTarget{url: peerURL, via: target.url, depth: target.depth + 1})
+		}
+	}
+
+	if err := connectService.txManager.Run(func(ctx context.Context) error {
+		for _, suggestion := range found {
+			if err := connectService.connectRepository.SaveSuggestion(ctx, &suggestion); err != nil {
+				return err
+			}
+		}
+		return connectService.connectRepository.DeleteStaleSuggestions(ctx, now.Add(-model.SuggestionExpiry), connectedURLs)
+	}); err != nil {
+		logUtil.GetLogger().Error("[发现实例保存失败]", zap.Error(err))
+		return
+	}
+
+	logUtil.GetLogger().Info("[发现实例完成]", zap.Int("访问实例数", len(visited)), zap.Int("推荐实例数", len(found)))
+}
+
+// fetchPeerList 获取对方实例的连接列表
+func fetchPeerList(peerURL string) (model.PeerList, error) {
+	if err := checkPeerURL(peerURL); err != nil {
+		return model.PeerList{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, peerURL+"/api/connect/peers", nil)
+	if err != nil {
+		return model.PeerList{}, err
+	}
+	req.Header.Set("User-Agent", model.DiscoveryUserAgent+"/"+commonModel.Version)
+
+	resp, err := peerClient.Do(req)
+	if err != nil {
+		return model.PeerList{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, model.MaxPeerResponseSize))
+	if err != nil {
+		return model.PeerList{}, err
+	}
+
+	var result commonModel.Result[model.PeerList]
+	if err := jsonUtil.JSONUnmarshal(body, &result); err != nil {
+		return model.PeerList{}, err
+	}
+	if result.Code != 1 {
+		return model.PeerList{}, errors.New(result.Message)
+	}
+
+	return result.Data, nil
+}
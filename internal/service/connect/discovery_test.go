@@ -0,0 +1,47 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	model "github.com/lin-snow/ech0/internal/model/connect"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+)
+
+// 连接列表中解析到内网的地址既不会被访问，也不会出现在推荐实例中
+func TestDiscover_SkipsPrivatePeers(t *testing.T) {
+	service, repo := newTestConnectService()
+	connected := newTestPeer(t)
+	public := newTestPeer(t)
+	public.peerList = model.PeerList{ServerName: "public", Discoverable: true}
+	connected.peerList = model.PeerList{
+		ServerName:   "connected",
+		Discoverable: true,
+		Peers: []model.Peer{
+			{ServerName: "internal", ServerURL: "http://10.0.0.1:8080"},
+			{ServerName: "public", ServerURL: public.url},
+		},
+	}
+	repo.connects[connected.url] = model.Connected{ID: 1, ConnectURL: connected.url, State: model.StateAccepted}
+
+	// 测试实例都在本机，只把 10.0.0.0/8 视为内网地址
+	var checked []string
+	checkPeerURL = func(rawURL string) error {
+		checked = append(checked, rawURL)
+		if strings.HasPrefix(rawURL, "http://10.") {
+			return httpUtil.ErrPrivateAddress
+		}
+		return nil
+	}
+
+	service.discover()
+
+	require.Contains(t, repo.suggestions, public.url)
+	assert.Equal(t, connected.url, repo.suggestions[public.url].Via)
+	assert.Len(t, repo.suggestions, 1)
+	assert.Contains(t, checked, "http://10.0.0.1:8080")
+	assert.Equal(t, int32(1), public.requests.Load())
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/connect"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+var (
+	// handshakeClient 发送握手消息使用的 HTTP 客户端（拒绝内网地址）
+	handshakeClient = httpUtil.NewSafeClient(model.HandshakeTimeout)
+	// peerClient 请求其它实例接口使用的 HTTP 客户端（拒绝内网地址）
+	peerClient = httpUtil.NewSafeClient(model.PeerRequestTimeout)
+	// checkPeerURL 请求对方实例前检查地址是否解析到公网
+	checkPeerURL = httpUtil.CheckPublicURL
+)
+
+// HandleHandshake 处理其它实例发来的握手消息
+func (connectService *ConnectService) HandleHandshake(body []byte, signature string) (model.HandshakeResult, error) {
+	var message model.HandshakeMessage
+	if err := jsonUtil.JSONUnmarshal(body, &message); err != nil {
+		return model.HandshakeResult{}, errors.New(commonModel.INVALID_HANDSHAKE)
+	}
+
+	// 校验消息时间和接收方，防止重放以及转发给其它实例
+	sentAt := time.Unix(message.Timestamp, 0)
+	if time.Since(sentAt).Abs() > model.HandshakeMaxSkew {
+		return model.HandshakeResult{}, errors.New(commonModel.INVALID_HANDSHAKE)
+	}
+	serverURL, err := connectService.settingService.GetServerURL()
+	if err != nil {
+		return model.HandshakeResult{}, err
+	}
+	origin := normalizeURL(message.ServerURL)
+	if origin == "" || normalizeURL(message.Target) != serverURL {
+		return model.HandshakeResult{}, errors.New(commonModel.INVALID_HANDSHAKE)
+	}
+	if message.Nonce == "" || len(message.Nonce) > model.MaxHandshakeNonceLength {
+		return model.HandshakeResult{}, errors.New(commonModel.INVALID_HANDSHAKE)
+	}
+
+	if message.Type == model.HandshakeRequest {
+		return connectService.handleRequest(origin, message, body, signature)
+	}
+
+	connected, err := connectService.connectRepository.GetConnectByURL(origin)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.HandshakeResult{}, errors.New(commonModel.CONNECT_NOT_FOUND)
+		}
+		return model.HandshakeResult{}, err
+	}
+	if !verifySignature(body, signature, connected.PublicKey) {
+		return model.HandshakeResult{}, errors.New(commonModel.INVALID_CONNECT_SIGN)
+	}
+	if !connectService.nonces.add(origin+" "+message.Nonce, sentAt.Add(model.HandshakeMaxSkew)) {
+		return model.HandshakeResult{}, errors.New(commonModel.INVALID_HANDSHAKE)
+	}
+
+	switch message.Type {
+	case model.HandshakeAccept:
+		if connected.Direction != model.DirectionOutgoing || connected.State != model.StatePending {
+			return model.HandshakeResult{}, errors.New(commonModel.INVALID_CONNECT_STATE)
+		}
+		connected.State = model.StateAccepted
+	case model.HandshakeReject:
+		if connected.State != model.StatePending {
+			return model.HandshakeResult{}, errors.New(commonModel.INVALID_CONNECT_STATE)
+		}
+		connected.State = model.StateRejected
+	case model.HandshakeRevoke:
+		connected.State = model.StateRevoked
+	default:
+		return model.HandshakeResult{}, errors.New(commonModel.INVALID_HANDSHAKE)
+	}
+
+	if err := connectService.txManager.Run(func(ctx context.Context) error {
+		return connectService.connectRepository.UpdateConnect(ctx, &connected)
+	}); err != nil {
+		return model.HandshakeResult{}, err
+	}
+
+	connectService.notifyConnectsChanged()
+	return model.HandshakeResult{State: connected.State}, nil
+}
+
+// handleRequest 处理建立连接的请求，对方的公钥需要与其 /api/connect 公布的公钥一致
+func (connectService *ConnectService) handleRequest(origin string, message model.HandshakeMessage, body []byte, signature string) (model.HandshakeResult, error) {
+	if !verifySignature(body, signature, message.PublicKey) {
+		return model.HandshakeResult{}, errors.New(commonModel.INVALID_CONNECT_SIGN)
+	}
+	// 重放的请求可能把已撤销或已拒绝的连接重新变为等待确认
+	if !connectService.nonces.add(origin+" "+message.Nonce, time.Unix(message.Timestamp, 0).Add(model.HandshakeMaxSkew)) {
+		return model.HandshakeResult{}, errors.New(commonModel.INVALID_HANDSHAKE)
+	}
+	// 对方声明的地址由请求方控制，不能让本实例去请求内网地址
+	if err := checkPeerURL(origin); err != nil {
+		return model.HandshakeResult{}, errors.New(commonModel.INVALID_HANDSHAKE)
+	}
+	info, err := fetchPeerInfo(origin)
+	if err != nil || info.PublicKey != message.PublicKey {
+		return model.HandshakeResult{}, errors.New(commonModel.INVALID_CONNECT_SIGN)
+	}
+
+	connected, err := connectService.connectRepository.GetConnectByURL(origin)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.HandshakeResult{}, err
+	}
+
+	connected.ConnectURL = origin
+	connected.ServerName = info.ServerName
+	connected.PublicKey = message.PublicKey
+	switch {
+	case connected.ID == 0:
+		connected.Direction = model.DirectionIncoming
+		connected.State = model.StatePending
+	case connected.State == model.StateAccepted:
+		// 已经建立的连接保持不变（对方可能重新发起了请求）
+	case connected.State == model.StatePending && connected.Direction == model.DirectionOutgoing:
+		// 双方互相发起了连接请求，直接建立连接
+		connected.State = model.StateAccepted
+	default:
+		connected.Direction = model.DirectionIncoming
+		connected.State = model.StatePending
+	}
+
+	if err := connectService.txManager.Run(func(ctx context.Context) error {
+		if connected.ID == 0 {
+			return connectService.connectRepository.CreateConnect(ctx, &connected)
+		}
+		return connectService.connectRepository.UpdateConnect(ctx, &connected)
+	}); err != nil {
+		return model.HandshakeResult{}, err
+	}
+
+	if connected.State == model.StateAccepted {
+		connectService.notifyConnectsChanged()
+	}
+	return model.HandshakeResult{State: connected.State}, nil
+}
+
+// newHandshakeMessage 创建发往 target 的握手消息
+func (connectService *ConnectService) newHandshakeMessage(handshakeType model.HandshakeType, target string) (model.HandshakeMessage, error) {
+	serverURL, err := connectService.settingService.GetServerURL()
+	if err != nil {
+		return model.HandshakeMessage{}, err
+	}
+
+	var setting settingModel.SystemSetting
+	if err := connectService.settingService.GetSetting(&setting); err != nil {
+		return model.HandshakeMessage{}, err
+	}
+
+	nonce := make([]byte, model.HandshakeNonceBytes)
+	if _, err := rand.Read(nonce); err != nil {
+		return model.HandshakeMessage{}, err
+	}
+
+	message := model.HandshakeMessage{
+		Type:       handshakeType,
+		ServerURL:  serverURL,
+		Target:     target,
+		ServerName: setting.ServerName,
+		Timestamp:  time.Now().Unix(),
+		Nonce:      base64.RawURLEncoding.EncodeToString(nonce),
+	}
+	if handshakeType == model.HandshakeRequest {
+		keyPair, err := connectService.getKeyPair()
+		if err != nil {
+			return model.HandshakeMessage{}, err
+		}
+		message.PublicKey = keyPair.PublicKey
+	}
+
+	return message, nil
+}
+
+// sendHandshake 签名并发送握手消息
+func (connectService *ConnectService) sendHandshake(message model.HandshakeMessage) (model.HandshakeResult, error) {
+	if err := checkPeerURL(message.Target); err != nil {
+		return model.HandshakeResult{}, err
+	}
+
+	body, err := jsonUtil.JSONMarshal(message)
+	if err != nil {
+		return model.HandshakeResult{}, err
+	}
+
+	keyPair, err := connectService.getKeyPair()
+	if err != nil {
+		return model.HandshakeResult{}, err
+	}
+	privateKey, err := base64.StdEncoding.DecodeString(keyPair.PrivateKey)
+	if err != nil || len(privateKey) != ed25519.PrivateKeySize {
+		return model.HandshakeResult{}, fmt.Errorf("无效的连接私钥")
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(ed25519.PrivateKey(privateKey), body))
+
+	req, err := http.NewRequest(http.MethodPost, message.Target+"/api/connect/handshake", bytes.NewReader(body))
+	if err != nil {
+		return model.HandshakeResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(model.SignatureHeader, signature)
+
+	resp, err := handshakeClient.Do(req)
+	if err != nil {
+		return model.HandshakeResult{}, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, model.MaxHandshakeBodySize))
+	if err != nil {
+		return model.HandshakeResult{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return model.HandshakeResult{}, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var result commonModel.Result[model.HandshakeResult]
+	if err := jsonUtil.JSONUnmarshal(respBody, &result); err != nil {
+		return model.HandshakeResult{}, err
+	}
+	if result.Code != 1 {
+		return model.HandshakeResult{}, errors.New(result.Message)
+	}
+
+	return result.Data, nil
+}
+
+// notifyPeer 尽力通知对方实例连接状态的变化（失败时只记录日志）
+func (connectService *ConnectService) notifyPeer(handshakeType model.HandshakeType, connected model.Connected) {
+	if connected.PublicKey == "" {
+		// 旧版本添加的连接无法进行握手
+		return
+	}
+
+	message, err := connectService.newHandshakeMessage(handshakeType, connected.ConnectURL)
+	if err == nil {
+		_, err = connectService.sendHandshake(message)
+	}
+	if err != nil {
+		logUtil.GetLogger().Warn("[连接握手通知失败]",
+			zap.String("地址", connected.ConnectURL),
+			zap.String("类型", string(handshakeType)),
+			zap.Error(err),
+		)
+	}
+}
+
+// getKeyPair 获取连接握手使用的密钥对，不存在时自动生成
+func (connectService *ConnectService) getKeyPair() (model.KeyPair, error) {
+	connectService.keyPairMu.Lock()
+	defer connectService.keyPairMu.Unlock()
+
+	if connectService.keyPair != nil {
+		return *connectService.keyPair, nil
+	}
+
+	keyPair, err := connectService.connectRepository.GetKeyPair()
+	if err != nil {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return model.KeyPair{}, err
+		}
+		keyPair = model.KeyPair{
+			PrivateKey: base64.StdEncoding.EncodeToString(privateKey),
+			PublicKey:  base64.StdEncoding.EncodeToString(publicKey),
+		}
+		if err := connectService.txManager.Run(func(ctx context.Context) error {
+			return connectService.connectRepository.SaveKeyPair(ctx, keyPair)
+		}); err != nil {
+			return model.KeyPair{}, err
+		}
+	}
+
+	connectService.keyPair = &keyPair
+	return keyPair, nil
+}
+
+// fetchPeerInfo 获取对方实例的连接信息
+func fetchPeerInfo(peerURL string) (model.Connect, error) {
+	var info commonModel.Result[model.Connect]
+	if err := fetchResult(peerURL+"/api/connect", &info); err != nil {
+		return model.Connect{}, err
+	}
+	return info.Data, nil
+}
+
+// verifySignature 使用 Base64 编码的 Ed25519 公钥校验签名
+func verifySignature(body []byte, signature, publicKey string) bool {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(key), body, sig)
+}
+
+// normalizeURL 规范化实例地址（去除首尾空格和斜杠，缺少协议时补全 https://）
+func normalizeURL(rawURL string) string {
+	rawURL = httpUtil.TrimURL(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		rawURL = "https://" + rawURL
+	}
+	return rawURL
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/connect"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	repository "github.com/lin-snow/ech0/internal/repository/connect"
+	settingService "github.com/lin-snow/ech0/internal/service/setting"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+)
+
+const testServerURL = "https://ech0.example"
+
+type fakeTxManager struct{}
+
+func (fakeTxManager) Run(fn func(ctx context.Context) error) error {
+	return fn(context.Background())
+}
+
+type fakeSettingService struct {
+	settingService.SettingServiceInterface
+}
+
+func (fakeSettingService) GetServerURL() (string, error) {
+	return testServerURL, nil
+}
+
+func (fakeSettingService) GetSetting(setting *settingModel.SystemSetting) error {
+	setting.ServerName = "Ech0"
+	return nil
+}
+
+// fakeConnectRepository 在内存中保存连接和推荐实例，只实现测试用到的方法
+type fakeConnectRepository struct {
+	repository.ConnectRepositoryInterface
+	connects    map[string]model.Connected
+	suggestions map[string]model.PeerSuggestion
+}
+
+func (r *fakeConnectRepository) GetAllConnects() ([]model.Connected, error) {
+	connects := make([]model.Connected, 0, len(r.connects))
+	for _, connected := range r.connects {
+		connects = append(connects, connected)
+	}
+	return connects, nil
+}
+
+func (r *fakeConnectRepository) GetSuggestions(model.SuggestionStatus) ([]model.PeerSuggestion, error) {
+	suggestions := make([]model.PeerSuggestion, 0, len(r.suggestions))
+	for _, suggestion := range r.suggestions {
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+func (r *fakeConnectRepository) SaveSuggestion(_ context.Context, suggestion *model.PeerSuggestion) error {
+	r.suggestions[suggestion.ServerURL] = *suggestion
+	return nil
+}
+
+func (r *fakeConnectRepository) DeleteStaleSuggestions(context.Context, time.Time, []string) error {
+	return nil
+}
+
+func (r *fakeConnectRepository) GetConnectByURL(connectURL string) (model.Connected, error) {
+	connected, ok := r.connects[connectURL]
+	if !ok {
+		return model.Connected{}, gorm.ErrRecordNotFound
+	}
+	return connected, nil
+}
+
+func (r *fakeConnectRepository) CreateConnect(_ context.Context, connected *model.Connected) error {
+	connected.ID = uint(len(r.connects) + 1)
+	r.connects[connected.ConnectURL] = *connected
+	return nil
+}
+
+func (r *fakeConnectRepository) UpdateConnect(_ context.Context, connected *model.Connected) error {
+	r.connects[connected.ConnectURL] = *connected
+	return nil
+}
+
+// allowLoopbackPeers 允许请求本机上的测试实例，测试结束后恢复内网地址检查
+func allowLoopbackPeers(t *testing.T) {
+	t.Helper()
+
+	originalHandshake, originalPeer, originalCheck := handshakeClient, peerClient, checkPeerURL
+	handshakeClient = &http.Client{Timeout: model.HandshakeTimeout}
+	peerClient = &http.Client{Timeout: model.PeerRequestTimeout}
+	checkPeerURL = func(string) error { return nil }
+	t.Cleanup(func() {
+		handshakeClient, peerClient, checkPeerURL = originalHandshake, originalPeer, originalCheck
+	})
+}
+
+// testPeer 模拟对方实例：公布公钥并签名握手消息
+type testPeer struct {
+	url        string
+	publicKey  string
+	privateKey ed25519.PrivateKey
+	peerList   model.PeerList   // /api/connect/peers 返回的连接列表
+	echos      []echoModel.Echo // /api/echo/page 返回的 Echo
+	down       atomic.Bool      // 为 true 时所有请求返回 503
+	requests   atomic.Int32
+}
+
+func newTestPeer(t *testing.T) *testPeer {
+	t.Helper()
+	allowLoopbackPeers(t)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	peer := &testPeer{publicKey: base64.StdEncoding.EncodeToString(publicKey), privateKey: privateKey}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer.requests.Add(1)
+		if peer.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var body []byte
+		switch r.URL.Path {
+		case "/api/connect/peers":
+			body, _ = jsonUtil.JSONMarshal(commonModel.Result[model.PeerList]{Code: 1, Data: peer.peerList})
+		case "/api/echo/page":
+			body, _ = jsonUtil.JSONMarshal(commonModel.Result[commonModel.PageQueryResult[[]echoModel.Echo]]{
+				Code: 1,
+				Data: commonModel.PageQueryResult[[]echoModel.Echo]{Items: peer.echos, Total: int64(len(peer.echos))},
+			})
+		default:
+			body, _ = jsonUtil.JSONMarshal(commonModel.Result[model.Connect]{
+				Code: 1,
+				Data: model.Connect{ServerName: "peer", ServerURL: peer.url, PublicKey: peer.publicKey},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	peer.url = server.URL
+
+	return peer
+}
+
+// sign 生成发往测试实例的握手消息和签名
+func (peer *testPeer) sign(t *testing.T, handshakeType model.HandshakeType, nonce string) ([]byte, string) {
+	t.Helper()
+
+	message := model.HandshakeMessage{
+		Type:      handshakeType,
+		ServerURL: peer.url,
+		Target:    testServerURL,
+		Timestamp: time.Now().Unix(),
+		Nonce:     nonce,
+	}
+	if handshakeType == model.HandshakeRequest {
+		message.PublicKey = peer.publicKey
+	}
+	body, err := jsonUtil.JSONMarshal(message)
+	require.NoError(t, err)
+	return body, base64.StdEncoding.EncodeToString(ed25519.Sign(peer.privateKey, body))
+}
+
+func newTestConnectService() (*ConnectService, *fakeConnectRepository) {
+	repo := &fakeConnectRepository{connects: map[string]model.Connected{}, suggestions: map[string]model.PeerSuggestion{}}
+	return &ConnectService{
+		txManager:         fakeTxManager{},
+		connectRepository: repo,
+		settingService:    fakeSettingService{},
+	}, repo
+}
+
+// 重放的连接请求不能把已撤销的连接重新变为等待确认，对方重新发起的请求仍然有效
+func TestHandleHandshake_RejectsReplayedRequest(t *testing.T) {
+	service, repo := newTestConnectService()
+	peer := newTestPeer(t)
+
+	body, signature := peer.sign(t, model.HandshakeRequest, "nonce-1")
+	result, err := service.HandleHandshake(body, signature)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatePending, result.State)
+
+	// 站长撤销连接后，攻击者重放之前的请求
+	connected := repo.connects[peer.url]
+	connected.State = model.StateRevoked
+	repo.connects[peer.url] = connected
+
+	_, err = service.HandleHandshake(body, signature)
+	assert.EqualError(t, err, commonModel.INVALID_HANDSHAKE)
+	assert.Equal(t, model.StateRevoked, repo.connects[peer.url].State)
+
+	body, signature = peer.sign(t, model.HandshakeRequest, "nonce-2")
+	result, err = service.HandleHandshake(body, signature)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatePending, result.State)
+	assert.Equal(t, model.DirectionIncoming, repo.connects[peer.url].Direction)
+}
+
+// 状态变更消息同样只能使用一次
+func TestHandleHandshake_RejectsReplayedStateChange(t *testing.T) {
+	service, repo := newTestConnectService()
+	peer := newTestPeer(t)
+	repo.connects[peer.url] = model.Connected{
+		ID:         1,
+		ConnectURL: peer.url,
+		Direction:  model.DirectionOutgoing,
+		State:      model.StatePending,
+		PublicKey:  peer.publicKey,
+	}
+
+	body, signature := peer.sign(t, model.HandshakeReject, "nonce-1")
+	result, err := service.HandleHandshake(body, signature)
+	require.NoError(t, err)
+	assert.Equal(t, model.StateRejected, result.State)
+
+	connected := repo.connects[peer.url]
+	connected.State = model.StatePending
+	repo.connects[peer.url] = connected
+
+	_, err = service.HandleHandshake(body, signature)
+	assert.EqualError(t, err, commonModel.INVALID_HANDSHAKE)
+	assert.Equal(t, model.StatePending, repo.connects[peer.url].State)
+}
+
+// 没有 nonce 的消息被拒绝
+func TestHandleHandshake_RequiresNonce(t *testing.T) {
+	service, repo := newTestConnectService()
+	peer := newTestPeer(t)
+
+	body, signature := peer.sign(t, model.HandshakeRequest, "")
+	_, err := service.HandleHandshake(body, signature)
+	assert.EqualError(t, err, commonModel.INVALID_HANDSHAKE)
+	assert.Empty(t, repo.connects)
+}
+
+// 对方声明的地址解析到内网时，在请求对方之前拒绝连接请求
+func TestHandleHandshake_RejectsPrivateOrigin(t *testing.T) {
+	service, repo := newTestConnectService()
+	peer := newTestPeer(t)
+	checkPeerURL = httpUtil.CheckPublicURL
+	peerClient = httpUtil.NewSafeClient(model.PeerRequestTimeout)
+
+	body, signature := peer.sign(t, model.HandshakeRequest, "nonce-1")
+	_, err := service.HandleHandshake(body, signature)
+	assert.EqualError(t, err, commonModel.INVALID_HANDSHAKE)
+	assert.Zero(t, peer.requests.Load())
+	assert.Empty(t, repo.connects)
+}
+
+// 握手消息不会发往内网地址
+func TestSendHandshake_RejectsPrivateTarget(t *testing.T) {
+	service, _ := newTestConnectService()
+	peer := newTestPeer(t)
+	checkPeerURL = httpUtil.CheckPublicURL
+	handshakeClient = httpUtil.NewSafeClient(model.HandshakeTimeout)
+
+	_, err := service.sendHandshake(model.HandshakeMessage{Type: model.HandshakeRevoke, Target: peer.url})
+	assert.ErrorIs(t, err, httpUtil.ErrPrivateAddress)
+	assert.Zero(t, peer.requests.Load())
+}
+
+// 过期的 nonce 被清理，记录已满时拒绝新的消息
+func TestNonceCache(t *testing.T) {
+	var cache nonceCache
+	now := time.Now()
+
+	assert.True(t, cache.add("a", now.Add(time.Minute)))
+	assert.False(t, cache.add("a", now.Add(time.Minute)))
+	assert.True(t, cache.add("expired", now.Add(-time.Second)))
+	assert.True(t, cache.add("expired", now.Add(time.Minute)))
+
+	for i := len(cache.entries); i < model.MaxSeenHandshakeNonces; i++ {
+		require.True(t, cache.add(base64.StdEncoding.EncodeToString([]byte{byte(i), byte(i >> 8)}), now.Add(time.Minute)))
+	}
+	assert.False(t, cache.add("full", now.Add(time.Minute)))
+
+	cache.entries["a"] = now.Add(-time.Second)
+	assert.True(t, cache.add("full", now.Add(time.Minute)))
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	model "github.com/lin-snow/ech0/internal/model/connect"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+var (
+	// healthWorkerOnce 保证整个进程只启动一个健康检查协程
+	healthWorkerOnce sync.Once
+	// healthCheckCh 连接发生变化时唤醒健康检查协程（多次通知会合并）
+	healthCheckCh = make(chan struct{}, 1)
+	// healthCheckMu 保证同一时间只进行一次健康检查
+	healthCheckMu sync.Mutex
+)
+
+// GetConnectsHealth 获取所有连接的健康状态
+func (connectService *ConnectService) GetConnectsHealth(user userModel.User) ([]model.ConnectHealth, error) {
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return nil, err
+	}
+
+	return connectService.connectRepository.GetConnectHealths()
+}
+
+// RefreshConnectsHealth 立即检查所有连接并返回最新的健康状态
+func (connectService *ConnectService) RefreshConnectsHealth(user userModel.User) ([]model.ConnectHealth, error) {
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return nil, err
+	}
+
+	connectService.checkHealth()
+	return connectService.connectRepository.GetConnectHealths()
+}
+
+// notifyConnectsChanged 连接发生变化时通知后台协程重新检查连接和同步时间线
+func (connectService *ConnectService) notifyConnectsChanged() {
+	select {
+	case healthCheckCh <- struct{}{}:
+	default:
+	}
+	connectService.SyncTimeline()
+}
+
+// runHealthWorker 启动时以及定时、收到通知时检查连接
+func (connectService *ConnectService) runHealthWorker() {
+	ticker := time.NewTicker(model.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		connectService.checkHealth()
+
+		select {
+		case <-ticker.C:
+		case <-healthCheckCh:
+		}
+	}
+}
+
+// checkHealth 并发探测所有已建立的连接并保存结果
+func (connectService *ConnectService) checkHealth() {
+	healthCheckMu.Lock()
+	defer healthCheckMu.Unlock()
+
+	connects, err := connectService.connectRepository.GetConnectsByState(model.StateAccepted)
+	if err != nil {
+		logUtil.GetLogger().Error("[连接健康检查获取连接失败]", zap.Error(err))
+		return
+	}
+
+	healths, err := connectService.connectRepository.GetConnectHealths()
+	if err != nil {
+		logUtil.GetLogger().Error("[连接健康检查获取状态失败]", zap.Error(err))
+		return
+	}
+	previous := make(map[uint]model.ConnectHealth, len(healths))
+	for _, health := range healths {
+		previous[health.ConnectID] = health
+	}
+
+	results := make([]model.ConnectHealth, len(connects))
+	var wg sync.WaitGroup
+	for i, conn := range connects {
+		wg.Add(1)
+		go func(i int, conn model.Connected) {
+			defer wg.Done()
+			results[i] = probeConnect(conn, previous[conn.ID])
+		}(i, conn)
+	}
+	wg.Wait()
+
+	connectIDs := make([]uint, 0, len(results))
+	if err := connectService.txManager.Run(func(ctx context.Context) error {
+		for i := range results {
+			connectIDs = append(connectIDs, results[i].ConnectID)
+			if err := connectService.connectRepository.SaveConnectHealth(ctx, &results[i]); err != nil {
+				return err
+			}
+		}
+
+		// 清理已删除或不再建立的连接
+		return connectService.connectRepository.DeleteConnectHealthsExcept(ctx, connectIDs)
+	}); err != nil {
+		logUtil.GetLogger().Error("[连接健康检查保存失败]", zap.Error(err))
+	}
+}
+
+// probeConnect 获取对方实例的连接信息，记录耗时和失败次数（失败时保留上次获取到的信息）
+func probeConnect(conn model.Connected, health model.ConnectHealth) model.ConnectHealth {
+	connectURL := httpUtil.TrimURL(conn.ConnectURL)
+	health.ConnectID = conn.ID
+	health.ConnectURL = connectURL
+
+	start := time.Now()
+	info, err := fetchPeerInfo(connectURL)
+	health.LastCheckedAt = &start
+
+	if err != nil {
+		health.LastError = err.Error()
+		health.FailureStreak++
+		logUtil.GetLogger().Warn("[连接健康检查失败]",
+			zap.String("地址", connectURL),
+			zap.Int("连续失败次数", health.FailureStreak),
+			zap.Error(err),
+		)
+		return health
+	}
+
+	info.ServerURL = httpUtil.TrimURL(info.ServerURL)
+	health.Info = info
+	health.LastSeenAt = &start
+	health.LatencyMs = time.Since(start).Milliseconds()
+	health.LastError = ""
+	health.FailureStreak = 0
+	return health
+}
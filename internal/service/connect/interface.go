@@ -1,20 +1,69 @@
 package service
 
-import model "github.com/lin-snow/ech0/internal/model/connect"
+import (
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/connect"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
 
 type ConnectServiceInterface interface {
-	// AddConnect 添加连接
-	AddConnect(userid uint, connected model.Connected) error
+	// AddConnect 添加连接，向对方实例发起握手
+	AddConnect(user userModel.User, connected model.Connected) error
+
+	// AcceptConnect 接受其它实例发起的连接请求
+	AcceptConnect(user userModel.User, id uint) error
+
+	// RejectConnect 拒绝其它实例发起的连接请求
+	RejectConnect(user userModel.User, id uint) error
 
 	// DeleteConnect 删除连接
-	DeleteConnect(userid, id uint) error
+	DeleteConnect(user userModel.User, id uint) error
+
+	// GetAllConnects 获取所有连接（包括等待确认、已拒绝和已撤销的连接）
+	GetAllConnects(user userModel.User) ([]model.Connected, error)
+
+	// HandleHandshake 处理其它实例发来的握手消息
+	HandleHandshake(body []byte, signature string) (model.HandshakeResult, error)
 
 	// GetConnect 提供当前实例的连接信息
 	GetConnect() (model.Connect, error)
 
-	// GetConnectsInfo 获取实例获取到的其它实例的连接信息
+	// GetConnectsInfo 获取其它实例的连接信息（读取后台健康检查缓存的结果）
 	GetConnectsInfo() ([]model.Connect, error)
 
-	// GetConnects 获取实例添加的所有连接
+	// GetConnects 获取实例已建立的所有连接
 	GetConnects() ([]model.Connected, error)
+
+	// GetConnectsHealth 获取所有连接的健康状态
+	GetConnectsHealth(user userModel.User) ([]model.ConnectHealth, error)
+
+	// RefreshConnectsHealth 立即检查所有连接并返回最新的健康状态
+	RefreshConnectsHealth(user userModel.User) ([]model.ConnectHealth, error)
+
+	// GetPeers 提供当前实例机器可读的连接列表，供其它实例发现
+	GetPeers() (model.PeerList, error)
+
+	// GetSuggestions 获取通过发现得到的推荐实例
+	GetSuggestions(user userModel.User, status model.SuggestionStatus) ([]model.PeerSuggestion, error)
+
+	// AcceptSuggestion 向推荐实例发起连接
+	AcceptSuggestion(user userModel.User, id uint) error
+
+	// DismissSuggestion 忽略推荐实例
+	DismissSuggestion(user userModel.User, id uint) error
+
+	// DiscoverPeers 通知后台协程立即发现实例
+	DiscoverPeers(user userModel.User) error
+
+	// ExportOPML 将连接导出为 OPML
+	ExportOPML(user userModel.User) ([]byte, error)
+
+	// ImportOPML 从 OPML 导入连接，返回已创建、重复和失败的实例
+	ImportOPML(user userModel.User, data []byte) (model.ImportResult, error)
+
+	// GetTimeline 分页获取聚合时间线
+	GetTimeline(timelineQueryDto model.TimelineQueryDto) (commonModel.PageQueryResult[[]model.TimelineEcho], error)
+
+	// SyncTimeline 通知后台协程立即同步时间线
+	SyncTimeline()
 }
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/connect"
+)
+
+// nonceCache 记录握手消息的 nonce，直到消息因为时间超出允许的偏差而失效
+type nonceCache struct {
+	mu      sync.Mutex
+	entries map[string]time.Time // nonce 到失效时间
+}
+
+// add 记录 nonce，nonce 已经出现过（消息被重放）或记录已满时返回 false
+func (cache *nonceCache) add(nonce string, expiresAt time.Time) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	now := time.Now()
+	if cache.entries == nil {
+		cache.entries = make(map[string]time.Time)
+	}
+	if expires, ok := cache.entries[nonce]; ok && now.Before(expires) {
+		return false
+	}
+	if len(cache.entries) >= model.MaxSeenHandshakeNonces {
+		for key, expires := range cache.entries {
+			if !now.Before(expires) {
+				delete(cache.entries, key)
+			}
+		}
+		if len(cache.entries) >= model.MaxSeenHandshakeNonces {
+			return false
+		}
+	}
+
+	cache.entries[nonce] = expiresAt
+	return true
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/connect"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+)
+
+// feedPaths 导入时可以识别的 Ech0 订阅源路径
+var feedPaths = []string{model.FeedPath, "/feed/atom", "/feed/rss", "/feed/json"}
+
+// ExportOPML 将已建立和等待确认的连接导出为 OPML
+func (connectService *ConnectService) ExportOPML(user userModel.User) ([]byte, error) {
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return nil, err
+	}
+
+	var setting settingModel.SystemSetting
+	if err := connectService.settingService.GetSetting(&setting); err != nil {
+		return nil, err
+	}
+
+	connects, err := connectService.connectRepository.GetAllConnects()
+	if err != nil {
+		return nil, err
+	}
+
+	opml := model.OPML{
+		Version: model.OPMLVersion,
+		Head: model.OPMLHead{
+			Title:       strings.TrimSpace(setting.ServerName + " Connects"),
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+		Body: model.OPMLBody{Outlines: []model.OPMLOutline{}},
+	}
+	for _, conn := range connects {
+		// 已拒绝和已撤销的连接不导出
+		if conn.State != model.StateAccepted && conn.State != model.StatePending {
+			continue
+		}
+
+		connectURL := httpUtil.TrimURL(conn.ConnectURL)
+		name := conn.ServerName
+		if name == "" {
+			name = connectURL
+		}
+		opml.Body.Outlines = append(opml.Body.Outlines, model.OPMLOutline{
+			Text:    name,
+			Title:   name,
+			Type:    "rss",
+			XMLURL:  connectURL + model.FeedPath,
+			HTMLURL: connectURL,
+		})
+	}
+
+	body, err := xml.MarshalIndent(opml, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+// ImportOPML 从 OPML 导入连接，逐个校验实例的连接信息后发起握手
+func (connectService *ConnectService) ImportOPML(user userModel.User, data []byte) (model.ImportResult, error) {
+	result := model.ImportResult{
+		Created:    []string{},
+		Duplicates: []string{},
+		Failed:     []model.ImportFailure{},
+	}
+
+	if err := authz.Authorize(user, authz.PermConnectManage); err != nil {
+		return result, err
+	}
+
+	var opml model.OPML
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// 兼容声明了非 UTF-8 编码的文件（按原样读取）
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	if err := decoder.Decode(&opml); err != nil {
+		return result, errors.New(commonModel.INVALID_OPML)
+	}
+
+	seen := make(map[string]struct{})
+	for _, outline := range flattenOutlines(opml.Body.Outlines) {
+		connectURL := normalizeURL(outlineURL(outline))
+		if connectURL == "" {
+			continue
+		}
+
+		// 同一文件中重复的条目
+		if _, ok := seen[connectURL]; ok {
+			result.Duplicates = append(result.Duplicates, connectURL)
+			continue
+		}
+		seen[connectURL] = struct{}{}
+
+		if err := connectService.addConnect(connectURL); err != nil {
+			if err.Error() == commonModel.CONNECT_HAS_EXISTS {
+				result.Duplicates = append(result.Duplicates, connectURL)
+				continue
+			}
+			result.Failed = append(result.Failed, model.ImportFailure{URL: connectURL, Reason: err.Error()})
+			continue
+		}
+		result.Created = append(result.Created, connectURL)
+	}
+
+	return result, nil
+}
+
+// flattenOutlines 展开按分类嵌套的条目，只保留带有地址的条目
+func flattenOutlines(outlines []model.OPMLOutline) []model.OPMLOutline {
+	var result []model.OPMLOutline
+	for _, outline := range outlines {
+		if outline.HTMLURL != "" || outline.XMLURL != "" {
+			result = append(result, outline)
+		}
+		result = append(result, flattenOutlines(outline.Outlines)...)
+	}
+	return result
+}
+
+// outlineURL 获取条目对应的实例地址，没有 htmlUrl 时根据订阅源地址推断
+func outlineURL(outline model.OPMLOutline) string {
+	if outline.HTMLURL != "" {
+		return outline.HTMLURL
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(outline.XMLURL))
+	if err != nil || parsed.Host == "" {
+		return outline.XMLURL
+	}
+
+	// 去掉 Ech0 订阅源的路径，无法识别时使用站点根地址
+	path := strings.TrimSuffix(parsed.Path, "/")
+	sitePath := ""
+	for _, feedPath := range feedPaths {
+		if trimmed, ok := strings.CutSuffix(path, feedPath); ok {
+			sitePath = trimmed
+			break
+		}
+	}
+	return parsed.Scheme + "://" + parsed.Host + sitePath
+}
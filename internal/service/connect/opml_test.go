@@ -0,0 +1,110 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/connect"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+var testOwner = userModel.User{ID: 1, Role: userModel.RoleOwner}
+
+// newImportingConnectService 创建可以发起握手的连接服务（预先设置握手密钥对）
+func newImportingConnectService(t *testing.T) (*ConnectService, *fakeConnectRepository) {
+	t.Helper()
+
+	service, repo := newTestConnectService()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	service.keyPair = &model.KeyPair{
+		PublicKey:  base64.StdEncoding.EncodeToString(publicKey),
+		PrivateKey: base64.StdEncoding.EncodeToString(privateKey),
+	}
+	return service, repo
+}
+
+// 导出的 OPML 导入到另一个实例后，对每个连接发起握手，再次导入时全部视为已存在
+func TestOPML_RoundTrip(t *testing.T) {
+	alice, bob, carol := newTestPeer(t), newTestPeer(t), newTestPeer(t)
+
+	exporter, exporterRepo := newTestConnectService()
+	exporterRepo.connects[alice.url] = model.Connected{ID: 1, ConnectURL: alice.url, ServerName: "Alice", State: model.StateAccepted}
+	exporterRepo.connects[bob.url] = model.Connected{ID: 2, ConnectURL: bob.url + "/", State: model.StatePending, Direction: model.DirectionOutgoing}
+	exporterRepo.connects[carol.url] = model.Connected{ID: 3, ConnectURL: carol.url, ServerName: "Carol", State: model.StateRevoked}
+
+	data, err := exporter.ExportOPML(testOwner)
+	require.NoError(t, err)
+
+	var opml model.OPML
+	require.NoError(t, xml.Unmarshal(data, &opml))
+	assert.Equal(t, model.OPMLVersion, opml.Version)
+	assert.Equal(t, "Ech0 Connects", opml.Head.Title)
+	assert.ElementsMatch(t, []model.OPMLOutline{
+		{Text: "Alice", Title: "Alice", Type: "rss", XMLURL: alice.url + model.FeedPath, HTMLURL: alice.url},
+		{Text: bob.url, Title: bob.url, Type: "rss", XMLURL: bob.url + model.FeedPath, HTMLURL: bob.url},
+	}, opml.Body.Outlines)
+
+	importer, importerRepo := newImportingConnectService(t)
+	result, err := importer.ImportOPML(testOwner, data)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{alice.url, bob.url}, result.Created)
+	assert.Empty(t, result.Duplicates)
+	assert.Empty(t, result.Failed)
+	for _, peer := range []*testPeer{alice, bob} {
+		connected := importerRepo.connects[peer.url]
+		assert.Equal(t, model.DirectionOutgoing, connected.Direction)
+		assert.Equal(t, model.StatePending, connected.State)
+		assert.Equal(t, peer.publicKey, connected.PublicKey)
+	}
+
+	result, err = importer.ImportOPML(testOwner, data)
+	require.NoError(t, err)
+	assert.Empty(t, result.Created)
+	assert.ElementsMatch(t, []string{alice.url, bob.url}, result.Duplicates)
+}
+
+// 阅读器导出的文件：按分类嵌套、只有订阅源地址、重复条目和无法访问的实例
+func TestImportOPML_ReaderExport(t *testing.T) {
+	alice := newTestPeer(t)
+	importer, _ := newImportingConnectService(t)
+
+	data := []byte(`<?xml version="1.0" encoding="ISO-8859-1"?>
+<opml version="1.0">
+  <head><title>Reader</title></head>
+  <body>
+    <outline text="Ech0">
+      <outline text="Alice" type="rss" xmlUrl="` + alice.url + `/feed/atom"/>
+      <outline text="Alice again" htmlUrl="` + alice.url + `/"/>
+    </outline>
+    <outline text="Offline" xmlUrl="http://127.0.0.1:1/rss"/>
+    <outline text="Folder without feeds"/>
+  </body>
+</opml>`)
+
+	result, err := importer.ImportOPML(testOwner, data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{alice.url}, result.Created)
+	assert.Equal(t, []string{alice.url}, result.Duplicates)
+	assert.Equal(t, []model.ImportFailure{{URL: "http://127.0.0.1:1", Reason: commonModel.CONNECT_UNREACHABLE}}, result.Failed)
+}
+
+func TestImportOPML_RejectsInvalidRequests(t *testing.T) {
+	importer, _ := newImportingConnectService(t)
+
+	_, err := importer.ImportOPML(testOwner, []byte("not xml"))
+	assert.EqualError(t, err, commonModel.INVALID_OPML)
+
+	_, err = importer.ImportOPML(userModel.User{ID: 2, Role: userModel.RoleAuthor}, []byte("<opml/>"))
+	assert.EqualError(t, err, commonModel.NO_PERMISSION_DENIED)
+
+	_, err = importer.ExportOPML(userModel.User{ID: 2, Role: userModel.RoleAuthor})
+	assert.EqualError(t, err, commonModel.NO_PERMISSION_DENIED)
+}
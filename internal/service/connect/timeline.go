@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/connect"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+var (
+	// timelineWorkerOnce 保证整个进程只启动一个时间线同步协程
+	timelineWorkerOnce sync.Once
+	// timelineSyncCh 连接发生变化时唤醒同步协程（多次通知会合并）
+	timelineSyncCh = make(chan struct{}, 1)
+)
+
+// timelineFetchResult 单个连接的拉取结果
+type timelineFetchResult struct {
+	sourceURL string
+	info      model.Connect
+	echos     []echoModel.Echo
+	err       error
+}
+
+// GetTimeline 分页获取聚合时间线
+func (connectService *ConnectService) GetTimeline(timelineQueryDto model.TimelineQueryDto) (commonModel.PageQueryResult[[]model.TimelineEcho], error) {
+	if timelineQueryDto.Page < 1 {
+		timelineQueryDto.Page = 1
+	}
+	if timelineQueryDto.PageSize < 1 || timelineQueryDto.PageSize > 100 {
+		timelineQueryDto.PageSize = 10
+	}
+
+	echos, total, err := connectService.connectRepository.GetTimelineEchosByPage(
+		timelineQueryDto.Page,
+		timelineQueryDto.PageSize,
+		httpUtil.TrimURL(timelineQueryDto.Source),
+	)
+	if err != nil {
+		return commonModel.PageQueryResult[[]model.TimelineEcho]{}, err
+	}
+
+	return commonModel.PageQueryResult[[]model.TimelineEcho]{
+		Items: echos,
+		Total: total,
+	}, nil
+}
+
+// SyncTimeline 通知后台协程立即同步时间线
+func (connectService *ConnectService) SyncTimeline() {
+	select {
+	case timelineSyncCh <- struct{}{}:
+	default:
+	}
+}
+
+// runTimelineWorker 启动时以及定时、收到通知时同步时间线
+func (connectService *ConnectService) runTimelineWorker() {
+	ticker := time.NewTicker(model.TimelineSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		connectService.syncTimeline()
+
+		select {
+		case <-ticker.C:
+		case <-timelineSyncCh:
+		}
+	}
+}
+
+// syncTimeline 从所有连接拉取最新的公开 Echo 并替换本地缓存（拉取失败时保留旧缓存）
+func (connectService *ConnectService) syncTimeline() {
+	connects, err := connectService.connectRepository.GetConnectsByState(model.StateAccepted)
+	if err != nil {
+		logUtil.GetLogger().Error("[时间线获取连接失败]", zap.Error(err))
+		return
+	}
+
+	results := make([]timelineFetchResult, len(connects))
+	var wg sync.WaitGroup
+	for i, conn := range connects {
+		wg.Add(1)
+		go func(i int, sourceURL string) {
+			defer wg.Done()
+			info, echos, err := fetchTimeline(sourceURL)
+			results[i] = timelineFetchResult{sourceURL: sourceURL, info: info, echos: echos, err: err}
+		}(i, httpUtil.TrimURL(conn.ConnectURL))
+	}
+	wg.Wait()
+
+	// 同一实例可能以不同地址被添加多次，也可能连接到了自己，按实例地址去重
+	seen := make(map[string]struct{})
+	if serverURL, err := connectService.settingService.GetServerURL(); err == nil {
+		seen[serverURL] = struct{}{}
+	}
+
+	keep := make([]string, 0, len(results))
+	now := time.Now()
+	for _, result := range results {
+		if result.err != nil {
+			logUtil.GetLogger().Warn("[时间线拉取失败]", zap.String("地址", result.sourceURL), zap.Error(result.err))
+			keep = append(keep, result.sourceURL)
+			continue
+		}
+
+		serverURL := httpUtil.TrimURL(result.info.ServerURL)
+		if serverURL == "" {
+			serverURL = result.sourceURL
+		}
+		if _, ok := seen[serverURL]; ok {
+			continue
+		}
+		seen[serverURL] = struct{}{}
+		keep = append(keep, result.sourceURL)
+
+		echos := toTimelineEchos(result.sourceURL, result.info, result.echos, now)
+		if err := connectService.txManager.Run(func(ctx context.Context) error {
+			return connectService.connectRepository.ReplaceTimelineEchos(ctx, result.sourceURL, echos)
+		}); err != nil {
+			logUtil.GetLogger().Error("[时间线保存失败]", zap.String("地址", result.sourceURL), zap.Error(err))
+		}
+	}
+
+	if err := connectService.txManager.Run(func(ctx context.Context) error {
+		return connectService.connectRepository.DeleteTimelineEchosExcept(ctx, keep)
+	}); err != nil {
+		logUtil.GetLogger().Error("[时间线清理失败]", zap.Error(err))
+	}
+}
+
+// fetchTimeline 获取实例的连接信息和最新的公开 Echo
+func fetchTimeline(sourceURL string) (model.Connect, []echoModel.Echo, error) {
+	var info commonModel.Result[model.Connect]
+	if err := fetchResult(sourceURL+"/api/connect", &info); err != nil {
+		return model.Connect{}, nil, err
+	}
+
+	var page commonModel.Result[commonModel.PageQueryResult[[]echoModel.Echo]]
+	if err := fetchResult(fmt.Sprintf("%s/api/echo/page?page=1&pageSize=%d", sourceURL, model.TimelineFetchSize), &page); err != nil {
+		return model.Connect{}, nil, err
+	}
+
+	return info.Data, page.Data.Items, nil
+}
+
+// fetchResult 请求 Ech0 实例的接口并解析统一响应
+// 连接地址可能由其它实例提供，请求前拒绝解析到内网的地址
+func fetchResult[T any](url string, result *commonModel.Result[T]) error {
+	if err := checkPeerURL(url); err != nil {
+		return err
+	}
+
+	resp, err := peerClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, model.MaxPeerResponseSize))
+	if err != nil {
+		return err
+	}
+	if err := jsonUtil.JSONUnmarshal(body, result); err != nil {
+		return fmt.Errorf("JSON解析失败: %w", err)
+	}
+	if result.Code != 1 {
+		return errors.New(result.Message)
+	}
+
+	return nil
+}
+
+// toTimelineEchos 将拉取到的 Echo 转换为时间线缓存（跳过私密和重复的 Echo）
+func toTimelineEchos(sourceURL string, info model.Connect, echos []echoModel.Echo, fetchedAt time.Time) []model.TimelineEcho {
+	result := make([]model.TimelineEcho, 0, len(echos))
+	seen := make(map[uint]struct{}, len(echos))
+	for _, echo := range echos {
+		if echo.Private || echo.ID == 0 {
+			continue
+		}
+		if _, ok := seen[echo.ID]; ok {
+			continue
+		}
+		seen[echo.ID] = struct{}{}
+
+		images := make([]echoModel.Image, 0, len(echo.Images))
+		for _, image := range echo.Images {
+			if image.ImageURL == "" {
+				continue
+			}
+			if image.ImageSource == echoModel.ImageSourceLocal && !strings.HasPrefix(image.ImageURL, "http") {
+				image.ImageURL = sourceURL + "/api/" + strings.TrimPrefix(image.ImageURL, "/")
+				image.ImageSource = echoModel.ImageSourceURL
+			}
+			images = append(images, echoModel.Image{ImageURL: image.ImageURL, ImageSource: image.ImageSource})
+		}
+
+		result = append(result, model.TimelineEcho{
+			SourceURL:     sourceURL,
+			RemoteID:      echo.ID,
+			URL:           fmt.Sprintf("%s/echo/%d", sourceURL, echo.ID),
+			ServerName:    info.ServerName,
+			Logo:          info.Logo,
+			Username:      echo.Username,
+			Content:       echo.Content,
+			Images:        images,
+			Extension:     echo.Extension,
+			ExtensionType: echo.ExtensionType,
+			FavCount:      echo.FavCount,
+			CreatedAt:     echo.CreatedAt,
+			FetchedAt:     fetchedAt,
+		})
+	}
+
+	return result
+}
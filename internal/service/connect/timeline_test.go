@@ -0,0 +1,130 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	model "github.com/lin-snow/ech0/internal/model/connect"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	repository "github.com/lin-snow/ech0/internal/repository/connect"
+	"github.com/lin-snow/ech0/internal/transaction"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+// newTimelineTestService 创建使用临时数据库保存连接和时间线缓存的连接服务
+func newTimelineTestService(t *testing.T) (*ConnectService, *gorm.DB) {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "connect.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.Connected{}, &model.TimelineEcho{}))
+
+	return &ConnectService{
+		txManager:         transaction.NewTransactionManager(db),
+		connectRepository: repository.NewConnectRepository(db),
+		settingService:    fakeSettingService{},
+	}, db
+}
+
+// timelineURLs 获取时间线中 Echo 的地址
+func timelineURLs(t *testing.T, service *ConnectService, query model.TimelineQueryDto) []string {
+	t.Helper()
+
+	result, err := service.GetTimeline(query)
+	require.NoError(t, err)
+	urls := make([]string, 0, len(result.Items))
+	for _, echo := range result.Items {
+		urls = append(urls, echo.URL)
+	}
+	return urls
+}
+
+// 多个实例的公开 Echo 合并后按发布时间倒序排列，私密和重复的 Echo 被跳过
+func TestSyncTimeline_MergesByCreatedAt(t *testing.T) {
+	service, db := newTimelineTestService(t)
+	alice, bob := newTestPeer(t), newTestPeer(t)
+	base := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	alice.echos = []echoModel.Echo{
+		{ID: 3, Content: "a3", CreatedAt: base.Add(3 * time.Hour)},
+		{ID: 2, Content: "private", Private: true, CreatedAt: base.Add(5 * time.Hour)},
+		{ID: 1, Content: "a1", CreatedAt: base.Add(1 * time.Hour)},
+		{ID: 1, Content: "a1 duplicate", CreatedAt: base.Add(1 * time.Hour)},
+	}
+	bob.echos = []echoModel.Echo{
+		{ID: 9, Content: "b4", CreatedAt: base.Add(4 * time.Hour), Images: []echoModel.Image{
+			{ImageURL: "/images/b.png", ImageSource: echoModel.ImageSourceLocal},
+		}},
+		{ID: 8, Content: "b2", CreatedAt: base.Add(2 * time.Hour)},
+	}
+	require.NoError(t, db.Create(&[]model.Connected{
+		{ConnectURL: alice.url, State: model.StateAccepted},
+		{ConnectURL: bob.url + "/", State: model.StateAccepted},
+	}).Error)
+
+	service.syncTimeline()
+
+	assert.Equal(t, []string{bob.url + "/echo/9", alice.url + "/echo/3", bob.url + "/echo/8", alice.url + "/echo/1"},
+		timelineURLs(t, service, model.TimelineQueryDto{Page: 1, PageSize: 10}))
+	assert.Equal(t, []string{bob.url + "/echo/8", alice.url + "/echo/1"},
+		timelineURLs(t, service, model.TimelineQueryDto{Page: 2, PageSize: 2}))
+	assert.Equal(t, []string{alice.url + "/echo/3", alice.url + "/echo/1"},
+		timelineURLs(t, service, model.TimelineQueryDto{Page: 1, PageSize: 10, Source: alice.url + "/"}))
+
+	result, err := service.GetTimeline(model.TimelineQueryDto{Page: 1, PageSize: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), result.Total)
+	assert.Equal(t, "peer", result.Items[0].ServerName)
+	assert.Equal(t, []echoModel.Image{{ImageURL: bob.url + "/api/images/b.png", ImageSource: echoModel.ImageSourceURL}}, result.Items[0].Images)
+}
+
+// 拉取失败时保留该实例的旧缓存，不再连接的实例的缓存被删除
+func TestSyncTimeline_KeepsCacheOnFailure(t *testing.T) {
+	service, db := newTimelineTestService(t)
+	alice, bob := newTestPeer(t), newTestPeer(t)
+	base := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	alice.echos = []echoModel.Echo{{ID: 1, Content: "a1", CreatedAt: base}}
+	bob.echos = []echoModel.Echo{{ID: 1, Content: "b1", CreatedAt: base.Add(time.Hour)}}
+	connects := []model.Connected{
+		{ConnectURL: alice.url, State: model.StateAccepted},
+		{ConnectURL: bob.url, State: model.StateAccepted},
+	}
+	require.NoError(t, db.Create(&connects).Error)
+	service.syncTimeline()
+	require.Len(t, timelineURLs(t, service, model.TimelineQueryDto{}), 2)
+
+	// bob 暂时无法访问，alice 发布了新的 Echo
+	bob.down.Store(true)
+	alice.echos = append([]echoModel.Echo{{ID: 2, Content: "a2", CreatedAt: base.Add(2 * time.Hour)}}, alice.echos...)
+	service.syncTimeline()
+	assert.Equal(t, []string{alice.url + "/echo/2", bob.url + "/echo/1", alice.url + "/echo/1"},
+		timelineURLs(t, service, model.TimelineQueryDto{}))
+
+	// 撤销与 bob 的连接后删除其缓存
+	require.NoError(t, db.Model(&connects[1]).Update("state", model.StateRevoked).Error)
+	service.syncTimeline()
+	assert.Equal(t, []string{alice.url + "/echo/2", alice.url + "/echo/1"},
+		timelineURLs(t, service, model.TimelineQueryDto{}))
+}
+
+// 连接地址解析到内网时，不会请求该地址
+func TestFetchTimeline_RejectsPrivateAddress(t *testing.T) {
+	peer := newTestPeer(t)
+	checkPeerURL = httpUtil.CheckPublicURL
+	peerClient = httpUtil.NewSafeClient(model.PeerRequestTimeout)
+
+	_, _, err := fetchTimeline(peer.url)
+	assert.ErrorIs(t, err, httpUtil.ErrPrivateAddress)
+	assert.Zero(t, peer.requests.Load())
+}
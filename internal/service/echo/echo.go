@@ -3,48 +3,62 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/authz"
 	"github.com/lin-snow/ech0/internal/transaction"
 
-	authModel "github.com/lin-snow/ech0/internal/model/auth"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/echo"
+	fediverseModel "github.com/lin-snow/ech0/internal/model/fediverse"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	repository "github.com/lin-snow/ech0/internal/repository/echo"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
+	fediverseService "github.com/lin-snow/ech0/internal/service/fediverse"
+	webmentionService "github.com/lin-snow/ech0/internal/service/webmention"
+	webSubService "github.com/lin-snow/ech0/internal/service/websub"
 	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	timeUtil "github.com/lin-snow/ech0/internal/util/time"
 )
 
 type EchoService struct {
-	txManager      transaction.TransactionManager
-	commonService  commonService.CommonServiceInterface
-	echoRepository repository.EchoRepositoryInterface
+	txManager        transaction.TransactionManager
+	commonService    commonService.CommonServiceInterface
+	echoRepository   repository.EchoRepositoryInterface
+	fediverseService fediverseService.FediverseServiceInterface
+	webSubService    webSubService.WebSubServiceInterface
+
+	webmentionService webmentionService.WebmentionServiceInterface
 }
 
 func NewEchoService(
 	tm transaction.TransactionManager,
 	commonService commonService.CommonServiceInterface,
 	echoRepository repository.EchoRepositoryInterface,
+	fediverseService fediverseService.FediverseServiceInterface,
+	webSubService webSubService.WebSubServiceInterface,
+	webmentionService webmentionService.WebmentionServiceInterface,
 ) EchoServiceInterface {
 	return &EchoService{
-		txManager:      tm,
-		commonService:  commonService,
-		echoRepository: echoRepository,
+		txManager:        tm,
+		commonService:    commonService,
+		echoRepository:   echoRepository,
+		fediverseService: fediverseService,
+		webSubService:    webSubService,
+
+		webmentionService: webmentionService,
 	}
 }
 
 // PostEcho 创建新的Echo
-func (echoService *EchoService) PostEcho(userid uint, newEcho *model.Echo) error {
-	return echoService.txManager.Run(func(ctx context.Context) error {
-		newEcho.UserID = userid
+func (echoService *EchoService) PostEcho(user userModel.User, newEcho *model.Echo) error {
+	if err := echoService.txManager.Run(func(ctx context.Context) error {
+		newEcho.UserID = user.ID
 
-		user, err := echoService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
+		if err := authz.Authorize(user, authz.PermEchoCreate); err != nil {
 			return err
 		}
 
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
-		}
-
 		// 检查Extension内容
 		if newEcho.Extension != "" && newEcho.ExtensionType != "" {
 			switch newEcho.ExtensionType {
@@ -76,12 +90,26 @@ func (echoService *EchoService) PostEcho(userid uint, newEcho *model.Echo) error
 		}
 
 		return echoService.echoRepository.CreateEcho(ctx, newEcho)
-	})
+	}); err != nil {
+		return err
+	}
+
+	// 通知 WebSub 订阅者订阅源可能已更新
+	echoService.webSubService.NotifySubscribers()
+
+	// 公开的 Echo 推送到联邦宇宙
+	if !newEcho.Private {
+		echoService.fediverseService.PublishEcho(newEcho, fediverseModel.ActivityCreate)
+	}
 
+	// 向 Echo 中链接的页面发送 Webmention
+	echoService.webmentionService.SendWebmentions(nil, newEcho)
+
+	return nil
 }
 
 // GetEchosByPage 获取Echo列表，支持分页
-func (echoService *EchoService) GetEchosByPage(userid uint, pageQueryDto commonModel.PageQueryDto) (commonModel.PageQueryResult[[]model.Echo], error) {
+func (echoService *EchoService) GetEchosByPage(user userModel.User, pageQueryDto commonModel.PageQueryDto) (commonModel.PageQueryResult[[]model.Echo], error) {
 	// 参数校验
 	if pageQueryDto.Page < 1 {
 		pageQueryDto.Page = 1
@@ -90,21 +118,8 @@ func (echoService *EchoService) GetEchosByPage(userid uint, pageQueryDto commonM
 		pageQueryDto.PageSize = 10
 	}
 
-	//管理员登陆则支持查看隐私数据，否则不允许
-	showPrivate := false
-	if userid == authModel.NO_USER_LOGINED {
-		showPrivate = false
-	} else {
-		user, err := echoService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
-			return commonModel.PageQueryResult[[]model.Echo]{}, err
-		}
-		if user.IsAdmin {
-			showPrivate = true
-		} else {
-			showPrivate = false
-		}
-	}
+	// 拥有查看私密 Echo 权限的用户登陆则支持查看隐私数据，否则不允许
+	showPrivate := authz.Can(user, authz.PermEchoReadPrivate)
 
 	echosByPage, total := echoService.echoRepository.GetEchosByPage(pageQueryDto.Page, pageQueryDto.PageSize, pageQueryDto.Search, showPrivate)
 	result := commonModel.PageQueryResult[[]model.Echo]{
@@ -115,17 +130,34 @@ func (echoService *EchoService) GetEchosByPage(userid uint, pageQueryDto commonM
 	return result, nil
 }
 
-// DeleteEchoById 删除指定ID的Echo
-func (echoService *EchoService) DeleteEchoById(userid, id uint) error {
-	return echoService.txManager.Run(func(ctx context.Context) error {
-		user, err := echoService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
-			return err
-		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
-		}
+// GetUserEchos 获取指定用户发布的Echo列表，支持分页，本人和拥有查看私密 Echo 权限的用户可以看到私密Echo
+func (echoService *EchoService) GetUserEchos(user userModel.User, username string, pageQueryDto commonModel.PageQueryDto) (commonModel.PageQueryResult[[]model.Echo], error) {
+	target, err := echoService.commonService.CommonGetUserByUsername(username)
+	if err != nil {
+		return commonModel.PageQueryResult[[]model.Echo]{}, err
+	}
 
+	// 参数校验
+	if pageQueryDto.Page < 1 {
+		pageQueryDto.Page = 1
+	}
+	if pageQueryDto.PageSize < 1 || pageQueryDto.PageSize > 100 {
+		pageQueryDto.PageSize = 10
+	}
+
+	showPrivate := authz.CanViewPrivate(user, target.ID)
+
+	echos, total := echoService.echoRepository.GetEchosByUser(target.ID, pageQueryDto.Page, pageQueryDto.PageSize, pageQueryDto.Search, showPrivate)
+	return commonModel.PageQueryResult[[]model.Echo]{
+		Items: echos,
+		Total: total,
+	}, nil
+}
+
+// DeleteEchoById 删除指定ID的Echo
+func (echoService *EchoService) DeleteEchoById(user userModel.User, id uint) error {
+	var deletedEcho *model.Echo
+	if err := echoService.txManager.Run(func(ctx context.Context) error {
 		// 检查该Echo是否存在图片
 		echo, err := echoService.echoRepository.GetEchosById(id)
 		if err != nil {
@@ -135,6 +167,11 @@ func (echoService *EchoService) DeleteEchoById(userid, id uint) error {
 			return errors.New(commonModel.ECHO_NOT_FOUND)
 		}
 
+		// 作者只能删除自己的 Echo
+		if err := authz.AuthorizeOwned(user, echo.UserID, authz.PermEchoEditOwn, authz.PermEchoEditAny); err != nil {
+			return err
+		}
+
 		// 删除Echo中的图片
 		if len(echo.Images) > 0 {
 			for _, img := range echo.Images {
@@ -144,44 +181,54 @@ func (echoService *EchoService) DeleteEchoById(userid, id uint) error {
 			}
 		}
 
+		deletedEcho = echo
 		return echoService.echoRepository.DeleteEchoById(ctx, id)
-	})
+	}); err != nil {
+		return err
+	}
+
+	echoService.webSubService.NotifySubscribers()
+
+	// 已推送过的公开 Echo 需要通知关注者删除
+	if !deletedEcho.Private {
+		echoService.fediverseService.PublishEcho(deletedEcho, fediverseModel.ActivityDelete)
+	}
+
+	// 删除收到的 Webmention，并通知曾经提及的页面（source 已返回 410）
+	echoService.webmentionService.DeleteEchoWebmentions(deletedEcho.ID)
+	echoService.webmentionService.SendWebmentions(deletedEcho, nil)
 
+	return nil
 }
 
 // GetTodayEchos 获取今天的Echo列表
-func (echoService *EchoService) GetTodayEchos(userid uint) ([]model.Echo, error) {
-	//管理员登陆则支持查看隐私数据，否则不允许
-	showPrivate := false
-	if userid == authModel.NO_USER_LOGINED {
-		showPrivate = false
-	} else {
-		user, err := echoService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
-			return nil, err
-		}
-		if user.IsAdmin {
-			showPrivate = true
-		} else {
-			showPrivate = false
-		}
-	}
+func (echoService *EchoService) GetTodayEchos(user userModel.User) ([]model.Echo, error) {
+	// 拥有查看私密 Echo 权限的用户登陆则支持查看隐私数据，否则不允许
+	showPrivate := authz.Can(user, authz.PermEchoReadPrivate)
 
 	// 获取当日发布的Echos
-	todayEchos := echoService.echoRepository.GetTodayEchos(showPrivate)
+	todayEchos := echoService.echoRepository.GetTodayEchos(showPrivate, echoService.commonService.GetLocation())
 
 	return todayEchos, nil
 }
 
 // UpdateEcho 更新指定ID的Echo
-func (echoService *EchoService) UpdateEcho(userid uint, echo *model.Echo) error {
-	return echoService.txManager.Run(func(ctx context.Context) error {
-		user, err := echoService.commonService.CommonGetUserByUserId(userid)
+func (echoService *EchoService) UpdateEcho(user userModel.User, echo *model.Echo) error {
+	var oldEcho *model.Echo
+	if err := echoService.txManager.Run(func(ctx context.Context) error {
+		// 记录更新前的可见性，用于决定推送到联邦宇宙的活动
+		var err error
+		oldEcho, err = echoService.echoRepository.GetEchosById(echo.ID)
 		if err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
+		if oldEcho == nil {
+			return errors.New(commonModel.ECHO_NOT_FOUND)
+		}
+
+		// 作者只能编辑自己的 Echo
+		if err := authz.AuthorizeOwned(user, oldEcho.UserID, authz.PermEchoEditOwn, authz.PermEchoEditAny); err != nil {
+			return err
 		}
 
 		// 检查Extension内容
@@ -217,8 +264,34 @@ func (echoService *EchoService) UpdateEcho(userid uint, echo *model.Echo) error
 		}
 
 		return echoService.echoRepository.UpdateEcho(ctx, echo)
-	})
+	}); err != nil {
+		return err
+	}
+
+	echoService.webSubService.NotifySubscribers()
+	echoService.publishEchoUpdate(oldEcho, echo.ID)
 
+	return nil
+}
+
+// publishEchoUpdate 根据更新前后的可见性向联邦宇宙推送 Create/Update/Delete 活动
+func (echoService *EchoService) publishEchoUpdate(oldEcho *model.Echo, id uint) {
+	newEcho, err := echoService.echoRepository.GetEchosById(id)
+	if err != nil || newEcho == nil {
+		return
+	}
+
+	switch {
+	case oldEcho.Private && !newEcho.Private:
+		echoService.fediverseService.PublishEcho(newEcho, fediverseModel.ActivityCreate)
+	case !oldEcho.Private && newEcho.Private:
+		echoService.fediverseService.PublishEcho(newEcho, fediverseModel.ActivityDelete)
+	case !oldEcho.Private && !newEcho.Private:
+		echoService.fediverseService.PublishEcho(newEcho, fediverseModel.ActivityUpdate)
+	}
+
+	// 新旧链接都需要发送 Webmention，以便被移除链接的页面也能感知更新
+	echoService.webmentionService.SendWebmentions(oldEcho, newEcho)
 }
 
 // LikeEcho 点赞指定ID的Echo
@@ -230,7 +303,7 @@ func (echoService *EchoService) LikeEcho(id uint) error {
 }
 
 // GetEchoById 获取指定 ID 的 Echo
-func (echoService *EchoService) GetEchoById(userId, id uint) (*model.Echo, error) {
+func (echoService *EchoService) GetEchoById(user userModel.User, id uint) (*model.Echo, error) {
 	var echo *model.Echo
 
 	echo, err := echoService.echoRepository.GetEchosById(id)
@@ -243,28 +316,128 @@ func (echoService *EchoService) GetEchoById(userId, id uint) (*model.Echo, error
 		return nil, errors.New(commonModel.ECHO_NOT_FOUND)
 	}
 
-	// 如果没有登录用户，则不允许获取私密Echo
-	if userId == authModel.NO_USER_LOGINED {
-		// 如果Echo是私密的，则不允许获取
-		if echo.Private {
-			// 不允许通过ID获取私密Echo
+	// 私密Echo只有作者本人和拥有查看私密 Echo 权限的用户可以获取
+	if echo.Private {
+		if !authz.CanViewPrivate(user, echo.UserID) {
 			return nil, errors.New(commonModel.NO_PERMISSION_DENIED)
 		}
-	} else {
-		// 如果用户已经登录,获取当前登录用户
-		user, err := echoService.commonService.CommonGetUserByUserId(userId)
+	}
+
+	// 附带已审核通过的 Webmention
+	echo.Webmentions = echoService.webmentionService.GetEchoWebmentions(echo.ID)
+
+	return echo, nil
+}
+
+// GetArchive 获取按年月分组的Echo归档统计
+func (echoService *EchoService) GetArchive(user userModel.User) ([]model.ArchiveMonth, error) {
+	showPrivate := authz.Can(user, authz.PermEchoReadPrivate)
+
+	return echoService.echoRepository.GetArchive(showPrivate, echoService.commonService.GetLocation())
+}
+
+// GetEchosByMonth 获取指定年月的Echo列表，支持分页
+func (echoService *EchoService) GetEchosByMonth(user userModel.User, year, month int, pageQueryDto commonModel.PageQueryDto) (commonModel.PageQueryResult[[]model.Echo], error) {
+	// 参数校验
+	if year < 1 || month < 1 || month > 12 {
+		return commonModel.PageQueryResult[[]model.Echo]{}, errors.New(commonModel.INVALID_ARCHIVE_DATE)
+	}
+	if pageQueryDto.Page < 1 {
+		pageQueryDto.Page = 1
+	}
+	if pageQueryDto.PageSize < 1 || pageQueryDto.PageSize > 100 {
+		pageQueryDto.PageSize = 10
+	}
+
+	showPrivate := authz.Can(user, authz.PermEchoReadPrivate)
+
+	// 计算站点时区下该月的开始和结束时间
+	startOfMonth := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, echoService.commonService.GetLocation())
+	endOfMonth := startOfMonth.AddDate(0, 1, 0)
+
+	echos, total := echoService.echoRepository.GetEchosByTimeRange(startOfMonth, endOfMonth, pageQueryDto.Page, pageQueryDto.PageSize, showPrivate)
+
+	return commonModel.PageQueryResult[[]model.Echo]{
+		Items: echos,
+		Total: total,
+	}, nil
+}
+
+// GetOnThisDayEchos 获取往年同一天发布的Echo列表
+func (echoService *EchoService) GetOnThisDayEchos(user userModel.User, timezone string) ([]model.Echo, error) {
+	loc, err := echoService.commonService.ResolveLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	showPrivate := authz.Can(user, authz.PermEchoReadPrivate)
+
+	return echoService.getOnThisDayEchos(timeUtil.StartOfDay(time.Now(), loc), showPrivate)
+}
+
+// GetDailyDigest 获取每日摘要，可选包含那年今日
+func (echoService *EchoService) GetDailyDigest(user userModel.User, digestQueryDto model.DigestQueryDto) (model.DailyDigest, error) {
+	loc, err := echoService.commonService.ResolveLocation(digestQueryDto.Timezone)
+	if err != nil {
+		return model.DailyDigest{}, err
+	}
+
+	showPrivate := authz.Can(user, authz.PermEchoReadPrivate)
+
+	// 获取指定时区下今天的开始和结束时间
+	startOfDay := timeUtil.StartOfDay(time.Now(), loc)
+	endOfDay := startOfDay.AddDate(0, 0, 1)
+
+	digest := model.DailyDigest{
+		Date:  startOfDay.Format("2006-01-02"),
+		Today: echoService.echoRepository.GetEchosByTimeRanges([][2]time.Time{{startOfDay, endOfDay}}, showPrivate),
+	}
+
+	if digestQueryDto.Memories {
+		digest.OnThisDay, err = echoService.getOnThisDayEchos(startOfDay, showPrivate)
 		if err != nil {
-			return nil, err
+			return model.DailyDigest{}, err
 		}
+	}
 
-		if echo.Private {
-			if !user.IsAdmin {
-				return nil, errors.New(commonModel.NO_PERMISSION_DENIED)
-			}
+	return digest, nil
+}
+
+// GetEchoStats 获取发布统计数据，未登录或非管理员时不统计私密Echo
+func (echoService *EchoService) GetEchoStats(user userModel.User, statsQueryDto model.StatsQueryDto) (model.EchoStats, error) {
+	loc, err := echoService.commonService.ResolveLocation(statsQueryDto.Timezone)
+	if err != nil {
+		return model.EchoStats{}, err
+	}
+
+	showPrivate := authz.Can(user, authz.PermEchoReadPrivate)
+
+	return echoService.echoRepository.GetEchoStats(showPrivate, loc, time.Now())
+}
+
+// getOnThisDayEchos 获取 startOfDay 所在日期在往年同一天发布的Echo列表
+func (echoService *EchoService) getOnThisDayEchos(startOfDay time.Time, showPrivate bool) ([]model.Echo, error) {
+	loc := startOfDay.Location()
 
-			return echo, nil
+	// 借助归档统计，只查询在同一月份有发布记录的往年
+	archive, err := echoService.echoRepository.GetArchive(showPrivate, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges [][2]time.Time
+	for _, item := range archive {
+		if item.Year >= startOfDay.Year() || item.Month != int(startOfDay.Month()) {
+			continue
 		}
+
+		start := time.Date(item.Year, startOfDay.Month(), startOfDay.Day(), 0, 0, 0, 0, loc)
+		// 跳过不存在该日期的年份（如非闰年的 2 月 29 日）
+		if start.Month() != startOfDay.Month() {
+			continue
+		}
+		ranges = append(ranges, [2]time.Time{start, start.AddDate(0, 0, 1)})
 	}
 
-	return echo, nil
+	return echoService.echoRepository.GetEchosByTimeRanges(ranges, showPrivate), nil
 }
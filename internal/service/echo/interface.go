@@ -3,27 +3,46 @@ package service
 import (
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/echo"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 )
 
 type EchoServiceInterface interface {
 	// PostEcho 创建新的Echo
-	PostEcho(userid uint, newEcho *model.Echo) error
+	PostEcho(user userModel.User, newEcho *model.Echo) error
 
 	// GetEchosByPage 获取Echo列表，支持分页
-	GetEchosByPage(userid uint, pageQueryDto commonModel.PageQueryDto) (commonModel.PageQueryResult[[]model.Echo], error)
+	GetEchosByPage(user userModel.User, pageQueryDto commonModel.PageQueryDto) (commonModel.PageQueryResult[[]model.Echo], error)
+
+	// GetUserEchos 获取指定用户发布的Echo列表，支持分页
+	GetUserEchos(user userModel.User, username string, pageQueryDto commonModel.PageQueryDto) (commonModel.PageQueryResult[[]model.Echo], error)
 
 	// DeleteEchoById 删除指定ID的Echo
-	DeleteEchoById(userid, id uint) error
+	DeleteEchoById(user userModel.User, id uint) error
 
 	// GetTodayEchos 获取今天的Echo列表
-	GetTodayEchos(userid uint) ([]model.Echo, error)
+	GetTodayEchos(user userModel.User) ([]model.Echo, error)
 
 	// UpdateEcho 更新指定ID的Echo
-	UpdateEcho(userid uint, echo *model.Echo) error
+	UpdateEcho(user userModel.User, echo *model.Echo) error
 
 	// LikeEcho 点赞指定ID的Echo
 	LikeEcho(id uint) error
 
 	// GetEchoById 获取指定 ID 的 Echo
-	GetEchoById(userId, id uint) (*model.Echo, error)
+	GetEchoById(user userModel.User, id uint) (*model.Echo, error)
+
+	// GetArchive 获取按年月分组的Echo归档统计
+	GetArchive(user userModel.User) ([]model.ArchiveMonth, error)
+
+	// GetEchosByMonth 获取指定年月的Echo列表，支持分页
+	GetEchosByMonth(user userModel.User, year, month int, pageQueryDto commonModel.PageQueryDto) (commonModel.PageQueryResult[[]model.Echo], error)
+
+	// GetOnThisDayEchos 获取往年同一天发布的Echo列表
+	GetOnThisDayEchos(user userModel.User, timezone string) ([]model.Echo, error)
+
+	// GetDailyDigest 获取每日摘要，可选包含那年今日
+	GetDailyDigest(user userModel.User, digestQueryDto model.DigestQueryDto) (model.DailyDigest, error)
+
+	// GetEchoStats 获取发布统计数据
+	GetEchoStats(user userModel.User, statsQueryDto model.StatsQueryDto) (model.EchoStats, error)
 }
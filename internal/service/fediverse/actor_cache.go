@@ -0,0 +1,55 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+)
+
+// remoteActorCache 缓存远程 Actor，超过有效期后重新获取
+type remoteActorCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedRemoteActor
+}
+
+type cachedRemoteActor struct {
+	actor     model.RemoteActor
+	fetchedAt time.Time
+}
+
+// get 获取未过期的 Actor 和获取时间
+func (cache *remoteActorCache) get(id string) (model.RemoteActor, time.Time, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[id]
+	if !ok || time.Since(entry.fetchedAt) > model.RemoteActorCacheTTL {
+		return model.RemoteActor{}, time.Time{}, false
+	}
+	return entry.actor, entry.fetchedAt, true
+}
+
+// set 保存 Actor，数量达到上限时先清理过期的，仍然已满时随机淘汰一个
+func (cache *remoteActorCache) set(actor model.RemoteActor) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.entries == nil {
+		cache.entries = make(map[string]cachedRemoteActor)
+	}
+	if _, ok := cache.entries[actor.ID]; !ok && len(cache.entries) >= model.MaxCachedRemoteActors {
+		for id, entry := range cache.entries {
+			if time.Since(entry.fetchedAt) > model.RemoteActorCacheTTL {
+				delete(cache.entries, id)
+			}
+		}
+		for id := range cache.entries {
+			if len(cache.entries) < model.MaxCachedRemoteActors {
+				break
+			}
+			delete(cache.entries, id)
+		}
+	}
+	cache.entries[actor.ID] = cachedRemoteActor{actor: actor, fetchedAt: time.Now()}
+}
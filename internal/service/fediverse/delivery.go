@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// enqueue 将活动加入投递队列并唤醒投递协程
+func (fediverseService *FediverseService) enqueue(activity model.Activity, inboxes []string) error {
+	payload, err := jsonUtil.JSONMarshal(activity)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]model.Delivery, 0, len(inboxes))
+	for _, inbox := range inboxes {
+		deliveries = append(deliveries, model.Delivery{
+			Inbox:         inbox,
+			Payload:       string(payload),
+			NextAttemptAt: now,
+		})
+	}
+
+	if err := fediverseService.txManager.Run(func(ctx context.Context) error {
+		return fediverseService.fediverseRepository.CreateDeliveries(ctx, deliveries)
+	}); err != nil {
+		return err
+	}
+
+	select {
+	case deliveryNotify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// runDeliveryWorker 定时或在有新活动时处理投递队列
+func (fediverseService *FediverseService) runDeliveryWorker() {
+	ticker := time.NewTicker(model.DeliveryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-deliveryNotify:
+		}
+		fediverseService.processDeliveries()
+	}
+}
+
+// processDeliveries 投递所有到期的活动，失败的按指数退避重试
+func (fediverseService *FediverseService) processDeliveries() {
+	for {
+		deliveries, err := fediverseService.fediverseRepository.GetDueDeliveries(time.Now(), model.DeliveryBatchSize)
+		if err != nil {
+			logUtil.GetLogger().Error("[ActivityPub 获取投递队列失败]", zap.Error(err))
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		for i := range deliveries {
+			fediverseService.processDelivery(&deliveries[i])
+		}
+
+		// 不足一批说明队列中已没有到期的活动
+		if len(deliveries) < model.DeliveryBatchSize {
+			return
+		}
+	}
+}
+
+// processDelivery 投递单个活动
+func (fediverseService *FediverseService) processDelivery(delivery *model.Delivery) {
+	err := fediverseService.deliver(delivery.Inbox, []byte(delivery.Payload))
+	if err == nil {
+		if err := fediverseService.txManager.Run(func(ctx context.Context) error {
+			return fediverseService.fediverseRepository.DeleteDelivery(ctx, delivery.ID)
+		}); err != nil {
+			logUtil.GetLogger().Error("[ActivityPub 删除投递记录失败]", zap.Uint("id", delivery.ID), zap.Error(err))
+		}
+		return
+	}
+
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+
+	// 超过最大投递次数，放弃投递
+	if delivery.Attempts >= model.MaxDeliveryAttempts {
+		logUtil.GetLogger().Warn("[ActivityPub 投递失败，已放弃]",
+			zap.String("inbox", delivery.Inbox),
+			zap.Int("attempts", delivery.Attempts),
+			zap.Error(err))
+		if err := fediverseService.txManager.Run(func(ctx context.Context) error {
+			return fediverseService.fediverseRepository.DeleteDelivery(ctx, delivery.ID)
+		}); err != nil {
+			logUtil.GetLogger().Error("[ActivityPub 删除投递记录失败]", zap.Uint("id", delivery.ID), zap.Error(err))
+		}
+		return
+	}
+
+	delivery.NextAttemptAt = time.Now().Add(retryDelay(delivery.Attempts))
+	if err := fediverseService.txManager.Run(func(ctx context.Context) error {
+		return fediverseService.fediverseRepository.UpdateDelivery(ctx, delivery)
+	}); err != nil {
+		logUtil.GetLogger().Error("[ActivityPub 更新投递记录失败]", zap.Uint("id", delivery.ID), zap.Error(err))
+	}
+}
+
+// deliver 向目标收件箱发送签名的 POST 请求
+func (fediverseService *FediverseService) deliver(inbox string, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, inbox, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", model.ActivityContentType)
+	req.Header.Set("Accept", model.ActivityContentType)
+	if err := fediverseService.signRequest(req, payload); err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, model.MaxInboxBodySize))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("deliver failed: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// retryDelay 计算第 attempts 次失败后的重试等待时间
+func retryDelay(attempts int) time.Duration {
+	delay := model.DeliveryRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= model.DeliveryRetryMaxDelay {
+			return model.DeliveryRetryMaxDelay
+		}
+	}
+	return delay
+}
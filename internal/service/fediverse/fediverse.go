@@ -0,0 +1,655 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	repository "github.com/lin-snow/ech0/internal/repository/fediverse"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	settingService "github.com/lin-snow/ech0/internal/service/setting"
+	"github.com/lin-snow/ech0/internal/transaction"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	httpsigUtil "github.com/lin-snow/ech0/internal/util/httpsig"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	mdUtil "github.com/lin-snow/ech0/internal/util/md"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type FediverseService struct {
+	txManager           transaction.TransactionManager
+	fediverseRepository repository.FediverseRepositoryInterface
+	echoRepository      echoRepository.EchoRepositoryInterface
+	commonService       commonService.CommonServiceInterface
+	settingService      settingService.SettingServiceInterface
+
+	keyPairMu sync.Mutex
+	keyPair   *model.KeyPair
+}
+
+var (
+	// deliveryWorkerOnce 保证整个进程只启动一个投递协程
+	deliveryWorkerOnce sync.Once
+	// deliveryNotify 有新的活动入队时唤醒投递协程
+	deliveryNotify = make(chan struct{}, 1)
+	// httpClient 与其它实例通信使用的 HTTP 客户端（拒绝内网地址）
+	httpClient = httpUtil.NewSafeClient(model.RequestTimeout)
+	// remoteActors 缓存远程 Actor 的公钥，避免每次收到活动都重新获取
+	remoteActors = &remoteActorCache{}
+)
+
+func NewFediverseService(
+	tm transaction.TransactionManager,
+	fediverseRepository repository.FediverseRepositoryInterface,
+	echoRepository echoRepository.EchoRepositoryInterface,
+	commonService commonService.CommonServiceInterface,
+	settingService settingService.SettingServiceInterface,
+) FediverseServiceInterface {
+	fediverseService := &FediverseService{
+		txManager:           tm,
+		fediverseRepository: fediverseRepository,
+		echoRepository:      echoRepository,
+		commonService:       commonService,
+		settingService:      settingService,
+	}
+
+	// 投递队列保存在数据库中，启动后继续投递上次未完成的活动
+	deliveryWorkerOnce.Do(func() {
+		go fediverseService.runDeliveryWorker()
+	})
+
+	return fediverseService
+}
+
+// GetWebFinger 根据 acct 资源获取 WebFinger 信息（只支持实例管理员）
+func (fediverseService *FediverseService) GetWebFinger(resource string) (model.WebFinger, error) {
+	baseURL, err := fediverseService.getBaseURL()
+	if err != nil {
+		return model.WebFinger{}, err
+	}
+
+	// 解析 acct:username@host
+	account := strings.TrimPrefix(strings.TrimSpace(resource), "acct:")
+	username, host, ok := strings.Cut(account, "@")
+	if !ok || username == "" || !strings.EqualFold(host, hostOf(baseURL)) {
+		return model.WebFinger{}, errors.New(commonModel.INVALID_WEBFINGER_RESOURCE)
+	}
+
+	actorID, err := fediverseService.getActorID(baseURL, username)
+	if err != nil {
+		return model.WebFinger{}, err
+	}
+
+	return model.WebFinger{
+		Subject: "acct:" + username + "@" + hostOf(baseURL),
+		Aliases: []string{actorID},
+		Links: []model.WebFingerLink{
+			{Rel: "self", Type: model.ActivityContentType, Href: actorID},
+			{Rel: "http://webfinger.net/rel/profile-page", Type: "text/html", Href: baseURL + "/"},
+		},
+	}, nil
+}
+
+// GetActor 获取 Actor 文档（只有实例管理员拥有 Actor）
+func (fediverseService *FediverseService) GetActor(username string) (model.Actor, error) {
+	baseURL, err := fediverseService.getBaseURL()
+	if err != nil {
+		return model.Actor{}, err
+	}
+	var setting settingModel.SystemSetting
+	if err := fediverseService.settingService.GetSetting(&setting); err != nil {
+		return model.Actor{}, err
+	}
+
+	sysadmin, err := fediverseService.commonService.GetSysAdmin()
+	if err != nil || sysadmin.Username != username {
+		return model.Actor{}, errors.New(commonModel.USER_NOTFOUND)
+	}
+
+	keyPair, err := fediverseService.getKeyPair()
+	if err != nil {
+		return model.Actor{}, err
+	}
+
+	actorID := actorURL(baseURL, username)
+	actor := model.Actor{
+		Context:           []string{model.ActivityStreamsContext, model.SecurityContext},
+		ID:                actorID,
+		Type:              "Person",
+		PreferredUsername: username,
+		Name:              username,
+		Summary:           setting.SiteTitle,
+		URL:               baseURL + "/",
+		Inbox:             actorID + "/inbox",
+		Outbox:            actorID + "/outbox",
+		Followers:         actorID + "/followers",
+		PublicKey: model.PublicKey{
+			ID:           actorID + "#main-key",
+			Owner:        actorID,
+			PublicKeyPem: keyPair.PublicKey,
+		},
+		Discoverable: true,
+	}
+	if sysadmin.Avatar != "" {
+		actor.Icon = &model.Image{Type: "Image", URL: absoluteURL(baseURL, "/api/"+strings.TrimPrefix(sysadmin.Avatar, "/"))}
+	}
+
+	return actor, nil
+}
+
+// GetOutbox 获取发件箱，包含实例中所有用户公开的 Echo
+func (fediverseService *FediverseService) GetOutbox(username string, page int) (any, error) {
+	baseURL, err := fediverseService.getBaseURL()
+	if err != nil {
+		return nil, err
+	}
+	actorID, err := fediverseService.getActorID(baseURL, username)
+	if err != nil {
+		return nil, err
+	}
+	outboxID := actorID + "/outbox"
+
+	echos, total := fediverseService.echoRepository.GetEchosByPage(max(page, 1), model.OutboxPageSize, "", false)
+
+	// 集合信息
+	if page <= 0 {
+		lastPage := max((total+model.OutboxPageSize-1)/model.OutboxPageSize, 1)
+		return model.OrderedCollection{
+			Context:    model.ActivityStreamsContext,
+			ID:         outboxID,
+			Type:       "OrderedCollection",
+			TotalItems: total,
+			First:      fmt.Sprintf("%s?page=1", outboxID),
+			Last:       fmt.Sprintf("%s?page=%d", outboxID, lastPage),
+		}, nil
+	}
+
+	// 分页
+	collectionPage := model.OrderedCollectionPage{
+		Context:      model.ActivityStreamsContext,
+		ID:           fmt.Sprintf("%s?page=%d", outboxID, page),
+		Type:         "OrderedCollectionPage",
+		PartOf:       outboxID,
+		TotalItems:   total,
+		OrderedItems: []model.Activity{},
+	}
+	if int64(page*model.OutboxPageSize) < total {
+		collectionPage.Next = fmt.Sprintf("%s?page=%d", outboxID, page+1)
+	}
+	if page > 1 {
+		collectionPage.Prev = fmt.Sprintf("%s?page=%d", outboxID, page-1)
+	}
+	for i := range echos {
+		note := fediverseService.buildNote(baseURL, actorID, &echos[i])
+		collectionPage.OrderedItems = append(collectionPage.OrderedItems, model.Activity{
+			ID:        note.ID + "/activity",
+			Type:      string(model.ActivityCreate),
+			Actor:     actorID,
+			Published: note.Published,
+			To:        note.To,
+			Cc:        note.Cc,
+			Object:    note,
+		})
+	}
+
+	return collectionPage, nil
+}
+
+// GetFollowers 获取关注者集合（只公开数量）
+func (fediverseService *FediverseService) GetFollowers(username string) (model.OrderedCollection, error) {
+	baseURL, err := fediverseService.getBaseURL()
+	if err != nil {
+		return model.OrderedCollection{}, err
+	}
+	actorID, err := fediverseService.getActorID(baseURL, username)
+	if err != nil {
+		return model.OrderedCollection{}, err
+	}
+
+	total, err := fediverseService.fediverseRepository.CountFollowers()
+	if err != nil {
+		return model.OrderedCollection{}, err
+	}
+
+	return model.OrderedCollection{
+		Context:    model.ActivityStreamsContext,
+		ID:         actorID + "/followers",
+		Type:       "OrderedCollection",
+		TotalItems: total,
+	}, nil
+}
+
+// GetNote 获取公开 Echo 对应的 Note 对象
+func (fediverseService *FediverseService) GetNote(id uint) (model.Note, error) {
+	baseURL, err := fediverseService.getBaseURL()
+	if err != nil {
+		return model.Note{}, err
+	}
+
+	echo, err := fediverseService.echoRepository.GetEchosById(id)
+	if err != nil {
+		return model.Note{}, err
+	}
+	if echo == nil || echo.Private {
+		return model.Note{}, errors.New(commonModel.ECHO_NOT_FOUND)
+	}
+
+	actorID, err := fediverseService.getInstanceActorID(baseURL)
+	if err != nil {
+		return model.Note{}, err
+	}
+
+	note := fediverseService.buildNote(baseURL, actorID, echo)
+	note.Context = model.ActivityStreamsContext
+	return note, nil
+}
+
+// HandleInbox 处理收件箱收到的活动，目前支持 Follow 与 Undo(Follow)
+func (fediverseService *FediverseService) HandleInbox(username string, req *http.Request, body []byte) error {
+	baseURL, err := fediverseService.getBaseURL()
+	if err != nil {
+		return err
+	}
+	actorID, err := fediverseService.getActorID(baseURL, username)
+	if err != nil {
+		return err
+	}
+
+	var activity model.IncomingActivity
+	if err := jsonUtil.JSONUnmarshal(body, &activity); err != nil || activity.Actor == "" || activity.Type == "" {
+		return errors.New(commonModel.INVALID_ACTIVITY)
+	}
+
+	// 获取发送方的 Actor 并校验 HTTP 签名
+	remoteActor, err := fediverseService.verifySignature(req, body, activity.Actor)
+	if err != nil {
+		logUtil.GetLogger().Warn("[ActivityPub 签名校验失败]", zap.String("actor", activity.Actor), zap.Error(err))
+		return errors.New(commonModel.INVALID_HTTP_SIGNATURE)
+	}
+
+	switch model.ActivityType(activity.Type) {
+	case model.ActivityFollow:
+		if objectID(activity.Object) != actorID {
+			return errors.New(commonModel.INVALID_ACTIVITY)
+		}
+
+		follower := &model.Follower{
+			ActorID:     remoteActor.ID,
+			Inbox:       remoteActor.Inbox,
+			SharedInbox: remoteActor.Endpoints.SharedInbox,
+			FollowID:    activity.ID,
+		}
+		if err := fediverseService.txManager.Run(func(ctx context.Context) error {
+			return fediverseService.fediverseRepository.SaveFollower(ctx, follower)
+		}); err != nil {
+			return err
+		}
+
+		// 自动接受关注
+		accept := model.Activity{
+			Context: model.ActivityStreamsContext,
+			ID:      fmt.Sprintf("%s#accepts/follows/%d", actorID, time.Now().UnixNano()),
+			Type:    string(model.ActivityAccept),
+			Actor:   actorID,
+			Object:  activity,
+		}
+		return fediverseService.enqueue(accept, []string{remoteActor.Inbox})
+
+	case model.ActivityUndo:
+		// 只处理取消关注，其它撤销活动忽略
+		undoFollow, err := fediverseService.isUndoFollow(activity.Object, remoteActor.ID, actorID)
+		if err != nil || !undoFollow {
+			return err
+		}
+		return fediverseService.txManager.Run(func(ctx context.Context) error {
+			return fediverseService.fediverseRepository.DeleteFollowerByActorID(ctx, remoteActor.ID)
+		})
+	}
+
+	// 其它活动暂不处理
+	return nil
+}
+
+// isUndoFollow 判断撤销的对象是否为签名方发出的、关注本实例 Actor 的 Follow 活动
+// 对象可以是内联的 Follow 活动，也可以是 Follow 活动的 ID（与关注时保存的 ID 比较）
+func (fediverseService *FediverseService) isUndoFollow(object any, signerID, actorID string) (bool, error) {
+	switch inner := object.(type) {
+	case map[string]any:
+		if inner["type"] != string(model.ActivityFollow) {
+			return false, nil
+		}
+		if actor, _ := inner["actor"].(string); actor != signerID {
+			return false, errors.New(commonModel.INVALID_ACTIVITY)
+		}
+		return objectID(inner["object"]) == actorID, nil
+	case string:
+		follower, err := fediverseService.fediverseRepository.GetFollowerByActorID(signerID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+		return inner != "" && follower.FollowID == inner, nil
+	}
+	return false, nil
+}
+
+// PublishEcho 向关注者投递 Echo 的 Create/Update/Delete 活动（失败只记录日志，不影响 Echo 的操作结果）
+func (fediverseService *FediverseService) PublishEcho(echo *echoModel.Echo, activityType model.ActivityType) {
+	if err := fediverseService.publishEcho(echo, activityType); err != nil {
+		logUtil.GetLogger().Error("[ActivityPub 活动入队失败]",
+			zap.Uint("echo_id", echo.ID),
+			zap.String("type", string(activityType)),
+			zap.Error(err))
+	}
+}
+
+func (fediverseService *FediverseService) publishEcho(echo *echoModel.Echo, activityType model.ActivityType) error {
+	inboxes, err := fediverseService.getFollowerInboxes()
+	if err != nil || len(inboxes) == 0 {
+		return err
+	}
+
+	baseURL, err := fediverseService.getBaseURL()
+	if err != nil {
+		return err
+	}
+	actorID, err := fediverseService.getInstanceActorID(baseURL)
+	if err != nil {
+		return err
+	}
+	note := fediverseService.buildNote(baseURL, actorID, echo)
+
+	activity := model.Activity{
+		Context: model.ActivityStreamsContext,
+		Type:    string(activityType),
+		Actor:   actorID,
+		To:      note.To,
+		Cc:      note.Cc,
+	}
+	switch activityType {
+	case model.ActivityCreate:
+		activity.ID = note.ID + "/activity"
+		activity.Published = note.Published
+		activity.Object = note
+	case model.ActivityUpdate:
+		note.Updated = time.Now().UTC().Format(time.RFC3339)
+		activity.ID = fmt.Sprintf("%s#updates/%d", note.ID, time.Now().Unix())
+		activity.Object = note
+	case model.ActivityDelete:
+		activity.ID = note.ID + "#delete"
+		activity.Object = model.Tombstone{ID: note.ID, Type: "Tombstone"}
+	default:
+		return errors.New(commonModel.INVALID_ACTIVITY)
+	}
+
+	return fediverseService.enqueue(activity, inboxes)
+}
+
+// buildNote 将 Echo 转换为 Note 对象
+func (fediverseService *FediverseService) buildNote(baseURL, actorID string, echo *echoModel.Echo) model.Note {
+	note := model.Note{
+		ID:           fmt.Sprintf("%s/ap/echos/%d", baseURL, echo.ID),
+		Type:         "Note",
+		AttributedTo: actorID,
+		Content:      string(mdUtil.MdToHTML([]byte(echo.Content))),
+		Published:    echo.CreatedAt.UTC().Format(time.RFC3339),
+		URL:          fmt.Sprintf("%s/echo/%d", baseURL, echo.ID),
+		To:           []string{model.PublicAddress},
+		Cc:           []string{actorID + "/followers"},
+	}
+
+	for _, image := range echo.Images {
+		imageURL := image.ImageURL
+		if image.ImageSource == echoModel.ImageSourceLocal {
+			imageURL = absoluteURL(baseURL, "/api"+image.ImageURL)
+		}
+		mediaType := mime.TypeByExtension(strings.ToLower(path.Ext(strings.SplitN(imageURL, "?", 2)[0])))
+		if !strings.HasPrefix(mediaType, "image/") {
+			mediaType = "image/jpeg"
+		}
+		note.Attachment = append(note.Attachment, model.Image{
+			Type:      "Document",
+			MediaType: mediaType,
+			URL:       imageURL,
+		})
+	}
+
+	return note
+}
+
+// getBaseURL 获取实例的访问地址（联邦通信需要稳定的绝对地址）
+func (fediverseService *FediverseService) getBaseURL() (string, error) {
+	return fediverseService.settingService.GetServerURL()
+}
+
+// getActorID 校验用户名是否为实例管理员，并返回其 Actor 地址
+func (fediverseService *FediverseService) getActorID(baseURL, username string) (string, error) {
+	sysadmin, err := fediverseService.commonService.GetSysAdmin()
+	if err != nil || sysadmin.Username != username {
+		return "", errors.New(commonModel.USER_NOTFOUND)
+	}
+	return actorURL(baseURL, username), nil
+}
+
+// getInstanceActorID 获取实例的 Actor 地址
+// 只有站长拥有 Actor 和签名密钥，所有用户的 Echo 都以站长的 Actor 发布，与发件箱和签名保持一致
+func (fediverseService *FediverseService) getInstanceActorID(baseURL string) (string, error) {
+	sysadmin, err := fediverseService.commonService.GetSysAdmin()
+	if err != nil {
+		return "", err
+	}
+	return actorURL(baseURL, sysadmin.Username), nil
+}
+
+// getKeyPair 获取签名密钥对，不存在时生成并保存
+func (fediverseService *FediverseService) getKeyPair() (model.KeyPair, error) {
+	fediverseService.keyPairMu.Lock()
+	defer fediverseService.keyPairMu.Unlock()
+
+	if fediverseService.keyPair != nil {
+		return *fediverseService.keyPair, nil
+	}
+
+	keyPair, err := fediverseService.fediverseRepository.GetKeyPair()
+	if err != nil {
+		privateKey, publicKey, err := httpsigUtil.GenerateKeyPair()
+		if err != nil {
+			return model.KeyPair{}, err
+		}
+		keyPair = model.KeyPair{PrivateKey: privateKey, PublicKey: publicKey}
+		if err := fediverseService.txManager.Run(func(ctx context.Context) error {
+			return fediverseService.fediverseRepository.SaveKeyPair(ctx, keyPair)
+		}); err != nil {
+			return model.KeyPair{}, err
+		}
+	}
+
+	fediverseService.keyPair = &keyPair
+	return keyPair, nil
+}
+
+// getFollowerInboxes 获取所有关注者的收件箱（同一实例优先使用共享收件箱并去重）
+func (fediverseService *FediverseService) getFollowerInboxes() ([]string, error) {
+	followers, err := fediverseService.fediverseRepository.GetAllFollowers()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var inboxes []string
+	for _, follower := range followers {
+		inbox := follower.Inbox
+		if follower.SharedInbox != "" {
+			inbox = follower.SharedInbox
+		}
+		if _, ok := seen[inbox]; ok {
+			continue
+		}
+		seen[inbox] = struct{}{}
+		inboxes = append(inboxes, inbox)
+	}
+
+	return inboxes, nil
+}
+
+// verifySignature 获取发送方的 Actor 并校验请求的 HTTP 签名
+func (fediverseService *FediverseService) verifySignature(req *http.Request, body []byte, actor string) (model.RemoteActor, error) {
+	sig, err := httpsigUtil.ParseSignature(req)
+	if err != nil {
+		return model.RemoteActor{}, err
+	}
+
+	// 签名所用的密钥必须属于活动的发送方
+	keyOwner, _, _ := strings.Cut(sig.KeyID, "#")
+	if keyOwner != actor {
+		return model.RemoteActor{}, errors.New("signature key does not belong to actor")
+	}
+
+	// 不需要公钥的检查先完成，过期、摘要不符的请求不会触发对外请求
+	if err := httpsigUtil.CheckRequest(req, sig, body); err != nil {
+		return model.RemoteActor{}, err
+	}
+
+	verify := func(remoteActor model.RemoteActor) error {
+		if remoteActor.PublicKey.ID != sig.KeyID {
+			return errors.New("signature key id mismatch")
+		}
+		publicKey, err := httpsigUtil.ParsePublicKey(remoteActor.PublicKey.PublicKeyPem)
+		if err != nil {
+			return err
+		}
+		return httpsigUtil.VerifyRequest(req, sig, publicKey, body)
+	}
+
+	if cached, fetchedAt, ok := remoteActors.get(actor); ok {
+		err := verify(cached)
+		// 校验失败可能是对方轮换了密钥，缓存过一段时间后才重新获取，避免被伪造的请求反复触发
+		if err == nil || time.Since(fetchedAt) < model.RemoteActorRefreshInterval {
+			return cached, err
+		}
+	}
+
+	remoteActor, err := fediverseService.fetchRemoteActor(actor)
+	if err != nil {
+		return model.RemoteActor{}, err
+	}
+	remoteActors.set(remoteActor)
+
+	return remoteActor, verify(remoteActor)
+}
+
+// fetchRemoteActor 获取远程 Actor 文档（使用签名的 GET 请求，兼容开启了安全模式的实例）
+func (fediverseService *FediverseService) fetchRemoteActor(actor string) (model.RemoteActor, error) {
+	var remoteActor model.RemoteActor
+
+	parsed, err := url.Parse(actor)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return remoteActor, errors.New(commonModel.INVALID_ACTIVITY)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, actor, nil)
+	if err != nil {
+		return remoteActor, err
+	}
+	req.Header.Set("Accept", model.ActivityContentType+", "+model.LDJSONContentType)
+	if err := fediverseService.signRequest(req, nil); err != nil {
+		return remoteActor, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return remoteActor, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return remoteActor, fmt.Errorf("fetch actor failed: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, model.MaxInboxBodySize))
+	if err != nil {
+		return remoteActor, err
+	}
+	if err := jsonUtil.JSONUnmarshal(body, &remoteActor); err != nil {
+		return remoteActor, err
+	}
+	if remoteActor.ID != actor || remoteActor.Inbox == "" || remoteActor.PublicKey.PublicKeyPem == "" {
+		return remoteActor, errors.New("invalid remote actor")
+	}
+
+	return remoteActor, nil
+}
+
+// signRequest 使用实例管理员的密钥为请求签名
+func (fediverseService *FediverseService) signRequest(req *http.Request, body []byte) error {
+	baseURL, err := fediverseService.getBaseURL()
+	if err != nil {
+		return err
+	}
+	sysadmin, err := fediverseService.commonService.GetSysAdmin()
+	if err != nil {
+		return err
+	}
+	keyPair, err := fediverseService.getKeyPair()
+	if err != nil {
+		return err
+	}
+	privateKey, err := httpsigUtil.ParsePrivateKey(keyPair.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	return httpsigUtil.SignRequest(req, actorURL(baseURL, sysadmin.Username)+"#main-key", privateKey, body)
+}
+
+// actorURL 生成用户的 Actor 地址
+func actorURL(baseURL, username string) string {
+	return baseURL + "/ap/users/" + url.PathEscape(username)
+}
+
+// absoluteURL 将站内路径转换为绝对地址
+func absoluteURL(baseURL, p string) string {
+	if strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://") {
+		return p
+	}
+	return baseURL + "/" + strings.TrimPrefix(p, "/")
+}
+
+// hostOf 获取地址中的主机名（含端口）
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+// objectID 获取活动中 object 的 ID（object 可能是链接或内嵌对象）
+func objectID(object any) string {
+	switch o := object.(type) {
+	case string:
+		return o
+	case map[string]any:
+		if id, ok := o["id"].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	repository "github.com/lin-snow/ech0/internal/repository/fediverse"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	settingService "github.com/lin-snow/ech0/internal/service/setting"
+	httpsigUtil "github.com/lin-snow/ech0/internal/util/httpsig"
+)
+
+const (
+	testActor      = "https://remote.example/users/alice"
+	testServerURL  = "https://ech0.example"
+	testLocalActor = testServerURL + "/ap/users/admin"
+)
+
+type fakeTxManager struct{}
+
+func (fakeTxManager) Run(fn func(ctx context.Context) error) error {
+	return fn(context.Background())
+}
+
+type fakeSettingService struct {
+	settingService.SettingServiceInterface
+}
+
+func (fakeSettingService) GetServerURL() (string, error) {
+	return testServerURL, nil
+}
+
+type fakeCommonService struct {
+	commonService.CommonServiceInterface
+}
+
+func (fakeCommonService) GetSysAdmin() (userModel.User, error) {
+	return userModel.User{ID: 1, Username: "admin", Role: userModel.RoleOwner}, nil
+}
+
+// fakeFediverseRepository 在内存中保存关注者，只实现收件箱用到的方法
+type fakeFediverseRepository struct {
+	repository.FediverseRepositoryInterface
+	followers map[string]model.Follower
+}
+
+func (r *fakeFediverseRepository) GetFollowerByActorID(actorID string) (model.Follower, error) {
+	follower, ok := r.followers[actorID]
+	if !ok {
+		return model.Follower{}, gorm.ErrRecordNotFound
+	}
+	return follower, nil
+}
+
+func (r *fakeFediverseRepository) SaveFollower(_ context.Context, follower *model.Follower) error {
+	r.followers[follower.ActorID] = *follower
+	return nil
+}
+
+func (r *fakeFediverseRepository) DeleteFollowerByActorID(_ context.Context, actorID string) error {
+	delete(r.followers, actorID)
+	return nil
+}
+
+func (r *fakeFediverseRepository) CreateDeliveries(context.Context, []model.Delivery) error {
+	return nil
+}
+
+func newTestFediverseService() (*FediverseService, *fakeFediverseRepository) {
+	repo := &fakeFediverseRepository{followers: map[string]model.Follower{}}
+	return &FediverseService{
+		txManager:           fakeTxManager{},
+		fediverseRepository: repo,
+		commonService:       fakeCommonService{},
+		settingService:      fakeSettingService{},
+	}, repo
+}
+
+// newTestRemoteActor 生成远程 Actor 和对应的私钥，并放入缓存
+// 测试中的 FediverseService 没有依赖，需要获取远程 Actor 时会直接失败，因此能确认没有发出请求
+func newTestRemoteActor(t *testing.T) (model.RemoteActor, string) {
+	t.Helper()
+
+	original := remoteActors
+	remoteActors = &remoteActorCache{}
+	t.Cleanup(func() { remoteActors = original })
+
+	privatePem, publicPem, err := httpsigUtil.GenerateKeyPair()
+	require.NoError(t, err)
+	actor := model.RemoteActor{
+		ID:        testActor,
+		Inbox:     testActor + "/inbox",
+		PublicKey: model.PublicKey{ID: testActor + "#main-key", Owner: testActor, PublicKeyPem: publicPem},
+	}
+	remoteActors.set(actor)
+
+	return actor, privatePem
+}
+
+// signedInboxRequest 创建使用指定 keyId 和私钥签名的收件箱请求
+func signedInboxRequest(t *testing.T, keyID, privatePem, body string) *http.Request {
+	t.Helper()
+
+	privateKey, err := httpsigUtil.ParsePrivateKey(privatePem)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "https://ech0.example/api/inbox", strings.NewReader(body))
+	require.NoError(t, httpsigUtil.SignRequest(req, keyID, privateKey, []byte(body)))
+	return req
+}
+
+// 缓存的公钥可以直接校验签名，不需要重新获取 Actor
+func TestVerifySignature_UsesCachedActor(t *testing.T) {
+	actor, privatePem := newTestRemoteActor(t)
+	body := `{"type":"Follow"}`
+	req := signedInboxRequest(t, actor.PublicKey.ID, privatePem, body)
+
+	verified, err := (&FediverseService{}).verifySignature(req, []byte(body), testActor)
+	require.NoError(t, err)
+	assert.Equal(t, actor.ID, verified.ID)
+}
+
+// keyId 必须属于活动的发送方，并与 Actor 文档中的公钥一致
+func TestVerifySignature_RejectsWrongKeyID(t *testing.T) {
+	actor, privatePem := newTestRemoteActor(t)
+	body := `{"type":"Follow"}`
+
+	tests := []struct {
+		name  string
+		keyID string
+		err   string
+	}{
+		{"other actor", "https://other.example/users/mallory#main-key", "signature key does not belong to actor"},
+		{"other key of actor", actor.ID + "#other-key", "signature key id mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := signedInboxRequest(t, tt.keyID, privatePem, body)
+			_, err := (&FediverseService{}).verifySignature(req, []byte(body), testActor)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+// 签名或摘要无效、缺少签名的请求被拒绝，且刚获取过的 Actor 不会因此被重新获取
+func TestVerifySignature_RejectsInvalidRequests(t *testing.T) {
+	actor, privatePem := newTestRemoteActor(t)
+	otherPrivatePem, _, err := httpsigUtil.GenerateKeyPair()
+	require.NoError(t, err)
+	body := `{"type":"Follow"}`
+
+	// 使用其他私钥伪造签名
+	req := signedInboxRequest(t, actor.PublicKey.ID, otherPrivatePem, body)
+	_, err = (&FediverseService{}).verifySignature(req, []byte(body), testActor)
+	assert.Error(t, err)
+
+	// 请求体被替换
+	req = signedInboxRequest(t, actor.PublicKey.ID, privatePem, body)
+	_, err = (&FediverseService{}).verifySignature(req, []byte(`{"type":"Delete"}`), testActor)
+	assert.EqualError(t, err, "digest mismatch")
+
+	// 没有签名
+	req = httptest.NewRequest(http.MethodPost, "https://ech0.example/api/inbox", strings.NewReader(body))
+	_, err = (&FediverseService{}).verifySignature(req, []byte(body), testActor)
+	assert.Error(t, err)
+}
+
+// 只有撤销签名方自己发出的、关注本实例的 Follow 活动时才删除关注者
+func TestHandleInbox_UndoFollow(t *testing.T) {
+	actor, privatePem := newTestRemoteActor(t)
+	followID := testActor + "#follows/1"
+
+	tests := []struct {
+		name    string
+		object  string
+		removed bool
+		err     string
+	}{
+		{"inline follow", `{"id":"` + followID + `","type":"Follow","actor":"` + testActor + `","object":"` + testLocalActor + `"}`, true, ""},
+		{"follow id", `"` + followID + `"`, true, ""},
+		{"unknown id", `"` + testActor + `#follows/2"`, false, ""},
+		{"other activity id", `"` + testActor + `#likes/1"`, false, ""},
+		{"other activity", `{"type":"Like","actor":"` + testActor + `","object":"` + testLocalActor + `"}`, false, ""},
+		{"follow by other actor", `{"type":"Follow","actor":"https://remote.example/users/mallory","object":"` + testLocalActor + `"}`, false, commonModel.INVALID_ACTIVITY},
+		{"follow of other actor", `{"type":"Follow","actor":"` + testActor + `","object":"https://ech0.example/ap/users/bob"}`, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestFediverseService()
+
+			follow := `{"id":"` + followID + `","type":"Follow","actor":"` + testActor + `","object":"` + testLocalActor + `"}`
+			require.NoError(t, service.HandleInbox("admin", signedInboxRequest(t, actor.PublicKey.ID, privatePem, follow), []byte(follow)))
+			require.Equal(t, followID, repo.followers[testActor].FollowID)
+
+			undo := `{"id":"` + testActor + `#undo","type":"Undo","actor":"` + testActor + `","object":` + tt.object + `}`
+			err := service.HandleInbox("admin", signedInboxRequest(t, actor.PublicKey.ID, privatePem, undo), []byte(undo))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			_, exists := repo.followers[testActor]
+			assert.Equal(t, !tt.removed, exists)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"net/http"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+)
+
+type FediverseServiceInterface interface {
+	// GetWebFinger 根据 acct 资源获取 WebFinger 信息
+	GetWebFinger(resource string) (model.WebFinger, error)
+
+	// GetActor 获取 Actor 文档
+	GetActor(username string) (model.Actor, error)
+
+	// GetOutbox 获取发件箱，page 为 0 时返回集合信息，否则返回对应分页
+	GetOutbox(username string, page int) (any, error)
+
+	// GetFollowers 获取关注者集合
+	GetFollowers(username string) (model.OrderedCollection, error)
+
+	// GetNote 获取 Echo 对应的 Note 对象
+	GetNote(id uint) (model.Note, error)
+
+	// HandleInbox 处理收件箱收到的活动（校验 HTTP 签名）
+	HandleInbox(username string, req *http.Request, body []byte) error
+
+	// PublishEcho 向关注者投递 Echo 的 Create/Update/Delete 活动
+	PublishEcho(echo *echoModel.Echo, activityType model.ActivityType)
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"mime/multipart"
+
+	model "github.com/lin-snow/ech0/internal/model/micropub"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+type MicropubServiceInterface interface {
+	// Post 处理创建、更新、删除请求，创建成功时返回新 Echo 的地址
+	Post(user userModel.User, request model.Request, photos []*multipart.FileHeader) (string, error)
+
+	// GetConfig 获取 q=config 的配置
+	GetConfig() model.Config
+
+	// GetSource 获取 q=source 的 Echo 属性，properties 为空时返回全部属性
+	GetSource(user userModel.User, url string, properties []string) (model.Source, error)
+
+	// UploadMedia 上传媒体文件，返回文件的访问地址
+	UploadMedia(user userModel.User, file *multipart.FileHeader) (string, error)
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"strconv"
+	"strings"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/micropub"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	echoService "github.com/lin-snow/ech0/internal/service/echo"
+	settingService "github.com/lin-snow/ech0/internal/service/setting"
+)
+
+type MicropubService struct {
+	echoService    echoService.EchoServiceInterface
+	commonService  commonService.CommonServiceInterface
+	settingService settingService.SettingServiceInterface
+}
+
+func NewMicropubService(
+	echoService echoService.EchoServiceInterface,
+	commonService commonService.CommonServiceInterface,
+	settingService settingService.SettingServiceInterface,
+) MicropubServiceInterface {
+	return &MicropubService{
+		echoService:    echoService,
+		commonService:  commonService,
+		settingService: settingService,
+	}
+}
+
+// Post 处理创建、更新、删除请求，创建成功时返回新 Echo 的地址
+func (micropubService *MicropubService) Post(user userModel.User, request model.Request, photos []*multipart.FileHeader) (string, error) {
+	switch request.Action {
+	case "", model.ActionCreate:
+		return micropubService.create(user, request, photos)
+	case model.ActionUpdate:
+		return "", micropubService.update(user, request)
+	case model.ActionDelete:
+		id, err := micropubService.parseEchoURL(request.URL)
+		if err != nil {
+			return "", err
+		}
+		return "", micropubService.echoService.DeleteEchoById(user, id)
+	default:
+		// 已删除的 Echo 无法恢复，不支持 undelete
+		return "", errors.New(commonModel.INVALID_MICROPUB_ACTION)
+	}
+}
+
+// create 创建 Echo
+func (micropubService *MicropubService) create(user userModel.User, request model.Request, photos []*multipart.FileHeader) (string, error) {
+	if len(request.Type) > 0 && request.Type[0] != model.EntryType {
+		return "", errors.New(commonModel.INVALID_MICROPUB_TYPE)
+	}
+
+	properties := request.Properties
+	if properties == nil {
+		properties = make(map[string][]any)
+	}
+
+	// 表单中直接上传的图片先保存到本地，再作为 photo 属性处理
+	for _, photo := range photos {
+		imageURL, err := micropubService.commonService.UploadImage(user, photo)
+		if err != nil {
+			return "", err
+		}
+		properties["photo"] = append(properties["photo"], imageURL)
+	}
+
+	newEcho := micropubService.propertiesToEcho(properties)
+	if err := micropubService.echoService.PostEcho(user, newEcho); err != nil {
+		return "", err
+	}
+
+	return micropubService.echoURL(newEcho.ID), nil
+}
+
+// update 按 replace、add、delete 的顺序更新 Echo
+func (micropubService *MicropubService) update(user userModel.User, request model.Request) error {
+	id, err := micropubService.parseEchoURL(request.URL)
+	if err != nil {
+		return err
+	}
+
+	echo, err := micropubService.echoService.GetEchoById(user, id)
+	if err != nil {
+		return err
+	}
+
+	properties := micropubService.echoToProperties(echo)
+	if _, ok := request.Replace["category"]; ok {
+		// 替换分类时先移除内容中原有的标签
+		removeTags(properties, properties["category"])
+	}
+	for name, values := range request.Replace {
+		properties[name] = values
+	}
+	for name, values := range request.Add {
+		properties[name] = append(properties[name], values...)
+	}
+	if err := deleteProperties(properties, request.Delete); err != nil {
+		return err
+	}
+
+	updatedEcho := micropubService.propertiesToEcho(properties)
+	updatedEcho.ID = echo.ID
+	return micropubService.echoService.UpdateEcho(user, updatedEcho)
+}
+
+// GetConfig 获取 q=config 的配置
+func (micropubService *MicropubService) GetConfig() model.Config {
+	return model.Config{
+		MediaEndpoint: micropubService.getBaseURL() + model.MediaEndpointPath,
+		SyndicateTo:   []any{},
+		PostTypes: []model.PostType{
+			{Type: "note", Name: "Note"},
+			{Type: "article", Name: "Article"},
+			{Type: "photo", Name: "Photo"},
+			{Type: "bookmark", Name: "Bookmark"},
+		},
+		Q: []string{model.QueryConfig, model.QuerySource, model.QuerySyndicateTo},
+	}
+}
+
+// GetSource 获取 q=source 的 Echo 属性，properties 为空时返回全部属性
+func (micropubService *MicropubService) GetSource(user userModel.User, url string, properties []string) (model.Source, error) {
+	id, err := micropubService.parseEchoURL(url)
+	if err != nil {
+		return model.Source{}, err
+	}
+
+	echo, err := micropubService.echoService.GetEchoById(user, id)
+	if err != nil {
+		return model.Source{}, err
+	}
+
+	all := micropubService.echoToProperties(echo)
+	if len(properties) == 0 {
+		return model.Source{Type: []string{model.EntryType}, Properties: all}, nil
+	}
+
+	// 只请求部分属性时不返回 type
+	selected := make(map[string][]any, len(properties))
+	for _, name := range properties {
+		if values, ok := all[name]; ok {
+			selected[name] = values
+		}
+	}
+	return model.Source{Properties: selected}, nil
+}
+
+// UploadMedia 上传媒体文件，返回文件的访问地址
+func (micropubService *MicropubService) UploadMedia(user userModel.User, file *multipart.FileHeader) (string, error) {
+	imageURL, err := micropubService.commonService.UploadImage(user, file)
+	if err != nil {
+		return "", err
+	}
+
+	return micropubService.getBaseURL() + localImagePrefix + strings.TrimPrefix(imageURL, "/images/"), nil
+}
+
+// getBaseURL 获取站点地址，未设置时返回空字符串（此时返回相对地址）
+func (micropubService *MicropubService) getBaseURL() string {
+	baseURL, err := micropubService.settingService.GetServerURL()
+	if err != nil {
+		return ""
+	}
+	return baseURL
+}
+
+// echoURL 获取 Echo 的地址
+func (micropubService *MicropubService) echoURL(id uint) string {
+	return fmt.Sprintf("%s/echo/%d", micropubService.getBaseURL(), id)
+}
+
+// parseEchoURL 从 Echo 地址中解析出 Echo ID
+func (micropubService *MicropubService) parseEchoURL(rawURL string) (uint, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || rawURL == "" {
+		return 0, errors.New(commonModel.INVALID_MICROPUB_URL)
+	}
+
+	// 配置了站点地址时只接受本站的链接
+	if parsed.Host != "" {
+		if baseURL, err := url.Parse(micropubService.getBaseURL()); err == nil && baseURL.Host != "" && !strings.EqualFold(baseURL.Host, parsed.Host) {
+			return 0, errors.New(commonModel.INVALID_MICROPUB_URL)
+		}
+	}
+
+	idStr, ok := strings.CutPrefix(strings.TrimSuffix(parsed.Path, "/"), "/echo/")
+	if !ok {
+		return 0, errors.New(commonModel.INVALID_MICROPUB_URL)
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New(commonModel.INVALID_MICROPUB_URL)
+	}
+
+	return uint(id), nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/micropub"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	echoService "github.com/lin-snow/ech0/internal/service/echo"
+	settingService "github.com/lin-snow/ech0/internal/service/setting"
+)
+
+const testBaseURL = "https://ech0.example"
+
+// fakeSettingService 返回固定的站点地址
+type fakeSettingService struct {
+	settingService.SettingServiceInterface
+}
+
+func (fakeSettingService) GetServerURL() (string, error) {
+	return testBaseURL, nil
+}
+
+// fakeEchoService 在内存中保存 Echo
+type fakeEchoService struct {
+	echoService.EchoServiceInterface
+	echos map[uint]*echoModel.Echo
+}
+
+func (s *fakeEchoService) PostEcho(user userModel.User, newEcho *echoModel.Echo) error {
+	newEcho.ID = uint(len(s.echos) + 1)
+	s.echos[newEcho.ID] = newEcho
+	return nil
+}
+
+func (s *fakeEchoService) GetEchoById(user userModel.User, id uint) (*echoModel.Echo, error) {
+	echo, ok := s.echos[id]
+	if !ok {
+		return nil, assert.AnError
+	}
+	copied := *echo
+	return &copied, nil
+}
+
+func (s *fakeEchoService) UpdateEcho(user userModel.User, echo *echoModel.Echo) error {
+	s.echos[echo.ID] = echo
+	return nil
+}
+
+func newTestMicropubService() (*MicropubService, *fakeEchoService) {
+	echos := &fakeEchoService{echos: make(map[uint]*echoModel.Echo)}
+	return &MicropubService{echoService: echos, settingService: fakeSettingService{}}, echos
+}
+
+func TestPropertiesToEcho(t *testing.T) {
+	service, _ := newTestMicropubService()
+
+	tests := []struct {
+		name       string
+		properties map[string][]any
+		want       echoModel.Echo
+	}{
+		{
+			name:       "note",
+			properties: map[string][]any{"content": {"hello"}},
+			want:       echoModel.Echo{Content: "hello"},
+		},
+		{
+			name:       "content object",
+			properties: map[string][]any{"content": {map[string]any{"html": "<p>hi</p>"}}},
+			want:       echoModel.Echo{Content: "<p>hi</p>"},
+		},
+		{
+			name:       "article",
+			properties: map[string][]any{"name": {"Title"}, "content": {"body"}},
+			want:       echoModel.Echo{Content: "# Title\n\nbody"},
+		},
+		{
+			name:       "categories",
+			properties: map[string][]any{"content": {"hello #go"}, "category": {"go", "web dev", "#indie"}},
+			want:       echoModel.Echo{Content: "hello #go\n\n#web_dev #indie"},
+		},
+		{
+			name:       "bookmark",
+			properties: map[string][]any{"bookmark-of": {"https://blog.example/post"}, "name": {"A post"}, "content": {"worth reading"}},
+			want: echoModel.Echo{
+				Content:       "worth reading",
+				Extension:     `{"title":"A post","site":"https://blog.example/post"}`,
+				ExtensionType: echoModel.Extension_WEBSITE,
+			},
+		},
+		{
+			name: "photos",
+			properties: map[string][]any{"photo": {
+				testBaseURL + "/api/images/a.png",
+				"/images/b.png",
+				map[string]any{"value": "https://cdn.example/c.png", "alt": "c"},
+			}},
+			want: echoModel.Echo{Images: []echoModel.Image{
+				{ImageURL: "/images/a.png", ImageSource: echoModel.ImageSourceLocal},
+				{ImageURL: "/images/b.png", ImageSource: echoModel.ImageSourceLocal},
+				{ImageURL: "https://cdn.example/c.png", ImageSource: echoModel.ImageSourceURL},
+			}},
+		},
+		{
+			name:       "unlisted",
+			properties: map[string][]any{"content": {"x"}, "visibility": {"unlisted"}},
+			want:       echoModel.Echo{Content: "x", Private: true},
+		},
+		{
+			name:       "draft",
+			properties: map[string][]any{"content": {"x"}, "post-status": {"draft"}},
+			want:       echoModel.Echo{Content: "x", Private: true},
+		},
+		{
+			name:       "published",
+			properties: map[string][]any{"content": {"x"}, "published": {"2024-05-01T08:00:00Z"}},
+			want:       echoModel.Echo{Content: "x", CreatedAt: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, *service.propertiesToEcho(tt.properties))
+		})
+	}
+}
+
+// Echo 转换为属性后再转换回来内容保持不变
+func TestEchoToProperties_RoundTrip(t *testing.T) {
+	service, _ := newTestMicropubService()
+
+	echos := []echoModel.Echo{
+		{Content: "# Title\n\nbody #go", Private: true},
+		{
+			Content:       "worth reading",
+			Extension:     `{"title":"A post","site":"https://blog.example/post"}`,
+			ExtensionType: echoModel.Extension_WEBSITE,
+			Images:        []echoModel.Image{{ImageURL: "/images/a.png", ImageSource: echoModel.ImageSourceLocal}},
+		},
+	}
+
+	for _, echo := range echos {
+		echo.ID = 3
+		echo.CreatedAt = time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+		properties := service.echoToProperties(&echo)
+		assert.Equal(t, []any{testBaseURL + "/echo/3"}, properties["url"])
+
+		got := service.propertiesToEcho(properties)
+		got.ID = echo.ID
+		assert.Equal(t, echo, *got)
+	}
+}
+
+// 创建 h-entry 后按 replace、add、delete 更新，分类与内容中的标签保持一致
+func TestPost_CreateAndUpdate(t *testing.T) {
+	service, echos := newTestMicropubService()
+	user := userModel.User{ID: 1}
+
+	_, err := service.Post(user, model.Request{Type: []string{"h-event"}}, nil)
+	assert.EqualError(t, err, commonModel.INVALID_MICROPUB_TYPE)
+
+	location, err := service.Post(user, model.Request{
+		Type:       []string{model.EntryType},
+		Properties: map[string][]any{"content": {"hello"}, "category": {"go", "web"}},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, testBaseURL+"/echo/1", location)
+	assert.Equal(t, "hello\n\n#go #web", echos.echos[1].Content)
+
+	_, err = service.Post(user, model.Request{
+		Action:  model.ActionUpdate,
+		URL:     location,
+		Replace: map[string][]any{"content": {"updated"}},
+		Add:     map[string][]any{"category": {"indie"}},
+		Delete:  map[string]any{"category": []any{"web"}},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "updated\n\n#go #indie", echos.echos[1].Content)
+
+	_, err = service.Post(user, model.Request{Action: model.ActionUpdate, URL: location, Delete: []any{"category"}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", echos.echos[1].Content)
+
+	_, err = service.Post(user, model.Request{Action: model.ActionUpdate, URL: "https://other.example/echo/1"}, nil)
+	assert.EqualError(t, err, commonModel.INVALID_MICROPUB_URL)
+}
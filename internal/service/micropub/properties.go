@@ -0,0 +1,244 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/micropub"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	tagUtil "github.com/lin-snow/ech0/internal/util/tag"
+)
+
+// localImagePrefix 本地图片的访问路径前缀
+const localImagePrefix = "/api/images/"
+
+// invalidTagChars 匹配标签中不允许出现的字符
+var invalidTagChars = regexp.MustCompile(`[^\p{L}\p{N}_-]+`)
+
+// website 网站扩展的内容（bookmark-of 对应网站扩展）
+type website struct {
+	Title string `json:"title"`
+	Site  string `json:"site"`
+}
+
+// propertiesToEcho 将 Micropub 属性转换为 Echo
+func (micropubService *MicropubService) propertiesToEcho(properties map[string][]any) *echoModel.Echo {
+	echo := &echoModel.Echo{}
+
+	content := firstString(properties["content"])
+	name := firstString(properties["name"])
+	if bookmark := firstString(properties["bookmark-of"]); bookmark != "" {
+		// 收藏的网页使用网站扩展展示，name 作为网站标题
+		title := name
+		if title == "" {
+			title = bookmark
+		}
+		if extension, err := jsonUtil.JSONMarshal(website{Title: title, Site: bookmark}); err == nil {
+			echo.Extension = string(extension)
+			echo.ExtensionType = echoModel.Extension_WEBSITE
+		}
+	} else if name != "" {
+		// 文章标题作为一级标题放在内容开头
+		content = strings.TrimSpace("# " + name + "\n\n" + content)
+	}
+
+	// 分类转换为内容末尾的 #标签（内容中已有的标签不重复添加）
+	var tags []string
+	for _, value := range properties["category"] {
+		tag := sanitizeTag(stringValue(value))
+		if tag == "" || tagUtil.HasTag(content+" "+strings.Join(tags, " "), tag) {
+			continue
+		}
+		tags = append(tags, "#"+tag)
+	}
+	if len(tags) > 0 {
+		content = strings.TrimSpace(content + "\n\n" + strings.Join(tags, " "))
+	}
+	echo.Content = content
+
+	for _, value := range properties["photo"] {
+		if photo := stringValue(value); photo != "" {
+			echo.Images = append(echo.Images, micropubService.toImage(photo))
+		}
+	}
+
+	// 私密、不公开列出以及草稿都按私密 Echo 处理
+	visibility := firstString(properties["visibility"])
+	echo.Private = visibility == model.VisibilityPrivate ||
+		visibility == model.VisibilityUnlisted ||
+		firstString(properties["post-status"]) == model.PostStatusDraft
+
+	if published, err := time.Parse(time.RFC3339, firstString(properties["published"])); err == nil {
+		echo.CreatedAt = published
+	}
+
+	return echo
+}
+
+// echoToProperties 将 Echo 转换为 Micropub 属性
+func (micropubService *MicropubService) echoToProperties(echo *echoModel.Echo) map[string][]any {
+	properties := make(map[string][]any)
+
+	content := echo.Content
+	if echo.ExtensionType == echoModel.Extension_WEBSITE {
+		var site website
+		if err := jsonUtil.JSONUnmarshal([]byte(echo.Extension), &site); err == nil && site.Site != "" {
+			properties["bookmark-of"] = []any{site.Site}
+			if site.Title != "" && site.Title != site.Site {
+				properties["name"] = []any{site.Title}
+			}
+		}
+	} else if title, ok := strings.CutPrefix(content, "# "); ok {
+		title, rest, _ := strings.Cut(title, "\n")
+		properties["name"] = []any{strings.TrimSpace(title)}
+		content = strings.TrimSpace(rest)
+	}
+	if content != "" {
+		properties["content"] = []any{content}
+	}
+
+	for _, tag := range tagUtil.ExtractTags(echo.Content) {
+		properties["category"] = append(properties["category"], tag)
+	}
+
+	baseURL := micropubService.getBaseURL()
+	for _, image := range echo.Images {
+		imageURL := image.ImageURL
+		if image.ImageSource == echoModel.ImageSourceLocal {
+			imageURL = baseURL + "/api" + image.ImageURL
+		}
+		properties["photo"] = append(properties["photo"], imageURL)
+	}
+
+	visibility := model.VisibilityPublic
+	if echo.Private {
+		visibility = model.VisibilityPrivate
+	}
+	properties["visibility"] = []any{visibility}
+	properties["published"] = []any{echo.CreatedAt.Format(time.RFC3339)}
+	properties["url"] = []any{micropubService.echoURL(echo.ID)}
+
+	return properties
+}
+
+// toImage 将图片地址转换为 Echo 图片，本站上传的图片按本地图片保存
+func (micropubService *MicropubService) toImage(imageURL string) echoModel.Image {
+	local := imageURL
+	if baseURL := micropubService.getBaseURL(); baseURL != "" {
+		local = strings.TrimPrefix(local, baseURL)
+	}
+	if name, ok := strings.CutPrefix(local, localImagePrefix); ok {
+		local = "/images/" + name
+	}
+	if strings.HasPrefix(local, "/images/") {
+		return echoModel.Image{ImageURL: local, ImageSource: echoModel.ImageSourceLocal}
+	}
+
+	return echoModel.Image{ImageURL: imageURL, ImageSource: echoModel.ImageSourceURL}
+}
+
+// deleteProperties 处理更新请求中的 delete，分类同时会从内容中移除对应的标签
+func deleteProperties(properties map[string][]any, del any) error {
+	switch del := del.(type) {
+	case nil:
+	case []any:
+		for _, value := range del {
+			name, ok := value.(string)
+			if !ok {
+				return errors.New(commonModel.INVALID_REQUEST_BODY)
+			}
+			if name == "category" {
+				removeTags(properties, properties["category"])
+			}
+			delete(properties, name)
+		}
+	case map[string]any:
+		for name, value := range del {
+			values, ok := value.([]any)
+			if !ok {
+				return errors.New(commonModel.INVALID_REQUEST_BODY)
+			}
+			if name == "category" {
+				removeTags(properties, values)
+			}
+			properties[name] = withoutValues(properties[name], values)
+			if len(properties[name]) == 0 {
+				delete(properties, name)
+			}
+		}
+	default:
+		return errors.New(commonModel.INVALID_REQUEST_BODY)
+	}
+
+	return nil
+}
+
+// removeTags 从内容中移除指定的 #标签
+func removeTags(properties map[string][]any, tags []any) {
+	content := firstString(properties["content"])
+	for _, value := range tags {
+		tag := sanitizeTag(stringValue(value))
+		if tag == "" {
+			continue
+		}
+		pattern := regexp.MustCompile(`(?i)(^|\s)#` + regexp.QuoteMeta(tag) + `([^\p{L}\p{N}_-]|$)`)
+		content = pattern.ReplaceAllString(content, "$1$2")
+	}
+	if content = strings.TrimSpace(content); content != "" {
+		properties["content"] = []any{content}
+	} else {
+		delete(properties, "content")
+	}
+}
+
+// withoutValues 移除属性中的指定值
+func withoutValues(values, removed []any) []any {
+	result := make([]any, 0, len(values))
+	for _, value := range values {
+		keep := true
+		for _, r := range removed {
+			if fmt.Sprint(value) == fmt.Sprint(r) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// sanitizeTag 将分类转换为合法的标签（去掉 #，非法字符替换为下划线）
+func sanitizeTag(category string) string {
+	tag := strings.TrimPrefix(strings.TrimSpace(category), "#")
+	return strings.Trim(invalidTagChars.ReplaceAllString(tag, "_"), "_")
+}
+
+// firstString 获取属性的第一个值
+func firstString(values []any) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(stringValue(values[0]))
+}
+
+// stringValue 获取属性值的字符串形式，支持字符串以及 {value}、{text}、{html} 对象
+func stringValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case map[string]any:
+		for _, key := range []string{"value", "text", "html"} {
+			if s, ok := value[key].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
@@ -1,17 +1,23 @@
 package service
 
-import model "github.com/lin-snow/ech0/internal/model/setting"
+import (
+	model "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
 
 type SettingServiceInterface interface {
 	// GetSetting 获取设置
 	GetSetting(setting *model.SystemSetting) error
 
+	// GetServerURL 获取实例的访问地址（不带末尾斜杠，缺省协议时使用 https）
+	GetServerURL() (string, error)
+
 	// UpdateSetting 更新设置
-	UpdateSetting(userid uint, newSetting *model.SystemSettingDto) error
+	UpdateSetting(user userModel.User, newSetting *model.SystemSettingDto) error
 
 	// GetCommentSetting 获取评论设置
 	GetCommentSetting(setting *model.CommentSetting) error
 
 	// UpdateCommentSetting 更新评论设置
-	UpdateCommentSetting(userid uint, newSetting *model.CommentSettingDto) error
+	UpdateCommentSetting(user userModel.User, newSetting *model.CommentSettingDto) error
 }
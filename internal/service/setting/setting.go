@@ -4,14 +4,18 @@ import (
 	"context"
 	"errors"
 	"github.com/lin-snow/ech0/internal/transaction"
+	"strings"
 
+	"github.com/lin-snow/ech0/internal/authz"
 	"github.com/lin-snow/ech0/internal/config"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
 	httpUtil "github.com/lin-snow/ech0/internal/util/http"
 	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	timeUtil "github.com/lin-snow/ech0/internal/util/time"
 )
 
 type SettingService struct {
@@ -46,6 +50,7 @@ func (settingService *SettingService) GetSetting(setting *model.SystemSetting) e
 			setting.MetingAPI = config.Config.Setting.MetingAPI
 			setting.CustomCSS = config.Config.Setting.CustomCSS
 			setting.CustomJS = config.Config.Setting.CustomJS
+			setting.Timezone = config.Config.Setting.Timezone
 
 			// 处理 URL
 			setting.ServerURL = httpUtil.TrimURL(setting.ServerURL)
@@ -59,6 +64,8 @@ func (settingService *SettingService) GetSetting(setting *model.SystemSetting) e
 			if err := settingService.keyvalueRepository.AddKeyValue(ctx, commonModel.SystemSettingsKey, string(settingToJSON)); err != nil {
 				return err
 			}
+
+			return nil
 		}
 
 		if err := jsonUtil.JSONUnmarshal([]byte(systemSetting.(string)), setting); err != nil {
@@ -70,15 +77,34 @@ func (settingService *SettingService) GetSetting(setting *model.SystemSetting) e
 
 }
 
+// GetServerURL 获取实例的访问地址（不带末尾斜杠，缺省协议时使用 https）
+func (settingService *SettingService) GetServerURL() (string, error) {
+	var setting model.SystemSetting
+	if err := settingService.GetSetting(&setting); err != nil {
+		return "", err
+	}
+
+	serverURL := strings.TrimSuffix(strings.TrimSpace(setting.ServerURL), "/")
+	if serverURL == "" {
+		return "", errors.New(commonModel.SERVER_URL_NOT_SET)
+	}
+	if !strings.HasPrefix(serverURL, "http://") && !strings.HasPrefix(serverURL, "https://") {
+		serverURL = "https://" + serverURL
+	}
+
+	return serverURL, nil
+}
+
 // UpdateSetting 更新设置
-func (settingService *SettingService) UpdateSetting(userid uint, newSetting *model.SystemSettingDto) error {
-	return settingService.txManager.Run(func(ctx context.Context) error {
-		user, err := settingService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
+func (settingService *SettingService) UpdateSetting(user userModel.User, newSetting *model.SystemSettingDto) error {
+	if err := settingService.txManager.Run(func(ctx context.Context) error {
+		if err := authz.Authorize(user, authz.PermSettingsManage); err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
+
+		// 检查时区是否有效
+		if _, err := timeUtil.LoadLocation(newSetting.Timezone); err != nil {
+			return errors.New(commonModel.INVALID_TIMEZONE)
 		}
 
 		var setting model.SystemSetting
@@ -90,6 +116,8 @@ func (settingService *SettingService) UpdateSetting(userid uint, newSetting *mod
 		setting.MetingAPI = httpUtil.TrimURL(newSetting.MetingAPI)
 		setting.CustomCSS = newSetting.CustomCSS
 		setting.CustomJS = newSetting.CustomJS
+		setting.Timezone = strings.TrimSpace(newSetting.Timezone)
+		setting.DisableDiscovery = newSetting.DisableDiscovery
 
 		// 序列化为 JSON
 		settingToJSON, err := jsonUtil.JSONMarshal(setting)
@@ -104,8 +132,14 @@ func (settingService *SettingService) UpdateSetting(userid uint, newSetting *mod
 		}
 
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
 
+	// 时区可能已修改，清除缓存的时区
+	settingService.commonService.InvalidateLocation()
+
+	return nil
 }
 
 // GetCommentSetting 获取评论设置
@@ -129,6 +163,8 @@ func (settingService *SettingService) GetCommentSetting(setting *model.CommentSe
 			if err := settingService.keyvalueRepository.AddKeyValue(ctx, commonModel.CommentSettingKey, string(settingToJSON)); err != nil {
 				return err
 			}
+
+			return nil
 		}
 
 		if err := jsonUtil.JSONUnmarshal([]byte(commentSetting.(string)), setting); err != nil {
@@ -141,15 +177,11 @@ func (settingService *SettingService) GetCommentSetting(setting *model.CommentSe
 }
 
 // UpdateCommentSetting 更新评论设置
-func (settingService *SettingService) UpdateCommentSetting(userid uint, newSetting *model.CommentSettingDto) error {
+func (settingService *SettingService) UpdateCommentSetting(user userModel.User, newSetting *model.CommentSettingDto) error {
 	return settingService.txManager.Run(func(ctx context.Context) error {
-		user, err := settingService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
+		if err := authz.Authorize(user, authz.PermSettingsManage); err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
-		}
 
 		// 检查评论服务提供者是否有效
 		if newSetting.Provider != string(commonModel.TWIKOO) &&
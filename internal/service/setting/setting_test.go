@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	model "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+)
+
+// fakeKeyValueRepository 模拟键值对仓库，未写入的键返回错误
+type fakeKeyValueRepository struct {
+	values map[string]interface{}
+}
+
+func (f *fakeKeyValueRepository) GetKeyValue(key string) (interface{}, error) {
+	value, ok := f.values[key]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return value, nil
+}
+func (f *fakeKeyValueRepository) AddKeyValue(ctx context.Context, key string, value interface{}) error {
+	f.values[key] = value
+	return nil
+}
+func (f *fakeKeyValueRepository) DeleteKeyValue(ctx context.Context, key string) error {
+	delete(f.values, key)
+	return nil
+}
+func (f *fakeKeyValueRepository) UpdateKeyValue(ctx context.Context, key string, value interface{}) error {
+	f.values[key] = value
+	return nil
+}
+
+// fakeTxManager 模拟事务管理器，直接执行传入的函数
+type fakeTxManager struct{}
+
+func (f *fakeTxManager) Run(fn func(ctx context.Context) error) error {
+	return fn(context.Background())
+}
+
+// fakeCommonService 模拟公共服务，只记录清除时区缓存的次数
+type fakeCommonService struct {
+	commonService.CommonServiceInterface
+	invalidated int
+}
+
+func (f *fakeCommonService) InvalidateLocation() {
+	f.invalidated++
+}
+
+func newTestSettingService() (*SettingService, *fakeKeyValueRepository) {
+	repo := &fakeKeyValueRepository{values: map[string]interface{}{}}
+	return &SettingService{txManager: &fakeTxManager{}, keyvalueRepository: repo}, repo
+}
+
+// 首次运行时数据库中没有设置，应写入默认设置并返回，而不是对空值做类型断言
+func TestGetSetting_FirstRun(t *testing.T) {
+	settingService, repo := newTestSettingService()
+
+	var setting model.SystemSetting
+	assert.NotPanics(t, func() {
+		assert.NoError(t, settingService.GetSetting(&setting))
+	})
+	assert.Len(t, repo.values, 1)
+
+	// 再次获取时读取已保存的设置
+	var saved model.SystemSetting
+	assert.NoError(t, settingService.GetSetting(&saved))
+	assert.Equal(t, setting, saved)
+}
+
+func TestGetCommentSetting_FirstRun(t *testing.T) {
+	settingService, repo := newTestSettingService()
+
+	var setting model.CommentSetting
+	assert.NotPanics(t, func() {
+		assert.NoError(t, settingService.GetCommentSetting(&setting))
+	})
+	assert.Len(t, repo.values, 1)
+}
+
+// 修改设置后清除缓存的时区，修改失败时保留
+func TestUpdateSetting_InvalidatesLocation(t *testing.T) {
+	settingService, _ := newTestSettingService()
+	common := &fakeCommonService{}
+	settingService.commonService = common
+	owner := userModel.User{ID: 1, Role: userModel.RoleOwner}
+
+	assert.NoError(t, settingService.UpdateSetting(owner, &model.SystemSettingDto{Timezone: "Asia/Tokyo"}))
+	assert.Equal(t, 1, common.invalidated)
+
+	assert.Error(t, settingService.UpdateSetting(owner, &model.SystemSettingDto{Timezone: "Not/AZone"}))
+	assert.Equal(t, 1, common.invalidated)
+}
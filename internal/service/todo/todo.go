@@ -5,8 +5,10 @@ import (
 	"errors"
 	"github.com/lin-snow/ech0/internal/transaction"
 
+	"github.com/lin-snow/ech0/internal/authz"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/todo"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	repository "github.com/lin-snow/ech0/internal/repository/todo"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
 )
@@ -30,17 +32,12 @@ func NewTodoService(
 }
 
 // GetTodoList 获取当前用户的 To do列表
-func (todoService *TodoService) GetTodoList(userid uint) ([]model.Todo, error) {
-	// 检查执行操作的用户是否为管理员
-	user, err := todoService.commonService.CommonGetUserByUserId(userid)
-	if err != nil {
+func (todoService *TodoService) GetTodoList(user userModel.User) ([]model.Todo, error) {
+	if err := authz.Authorize(user, authz.PermTodoManage); err != nil {
 		return nil, err
 	}
-	if !user.IsAdmin {
-		return nil, errors.New(commonModel.NO_PERMISSION_DENIED)
-	}
 
-	todos, err := todoService.todoRepository.GetTodosByUserID(userid)
+	todos, err := todoService.todoRepository.GetTodosByUserID(user.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -55,18 +52,13 @@ func (todoService *TodoService) GetTodoList(userid uint) ([]model.Todo, error) {
 }
 
 // AddTodo 创建新的 To do
-func (todoService *TodoService) AddTodo(userid uint, todo *model.Todo) error {
+func (todoService *TodoService) AddTodo(user userModel.User, todo *model.Todo) error {
 	return todoService.txManager.Run(func(ctx context.Context) error {
-		// 检查执行操作的用户是否为管理员
-		user, err := todoService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
+		if err := authz.Authorize(user, authz.PermTodoManage); err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
-		}
 
-		todos, err := todoService.todoRepository.GetTodosByUserID(userid)
+		todos, err := todoService.todoRepository.GetTodosByUserID(user.ID)
 		if err != nil {
 			return err
 		}
@@ -81,7 +73,7 @@ func (todoService *TodoService) AddTodo(userid uint, todo *model.Todo) error {
 		}
 
 		// 设置TO DO
-		todo.UserID = userid
+		todo.UserID = user.ID
 		todo.Username = user.Username
 		todo.Status = uint(model.NotDone)
 
@@ -95,16 +87,11 @@ func (todoService *TodoService) AddTodo(userid uint, todo *model.Todo) error {
 }
 
 // UpdateTodo 更新指定ID的 To do
-func (todoService *TodoService) UpdateTodo(userid uint, id int64) error {
+func (todoService *TodoService) UpdateTodo(user userModel.User, id int64) error {
 	return todoService.txManager.Run(func(ctx context.Context) error {
-		// 检查执行操作的用户是否为管理员
-		user, err := todoService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
+		if err := authz.Authorize(user, authz.PermTodoManage); err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
-		}
 
 		// 获取 To do
 		theTodo, err := todoService.todoRepository.GetTodoByID(id)
@@ -113,7 +100,7 @@ func (todoService *TodoService) UpdateTodo(userid uint, id int64) error {
 		}
 
 		// 检查该 To do 是否属于当前用户
-		if theTodo.UserID != userid {
+		if theTodo.UserID != user.ID {
 			return errors.New(commonModel.NO_PERMISSION_DENIED)
 		}
 
@@ -134,16 +121,11 @@ func (todoService *TodoService) UpdateTodo(userid uint, id int64) error {
 }
 
 // DeleteTodo 删除指定ID的 To do
-func (todoService *TodoService) DeleteTodo(userid uint, id int64) error {
+func (todoService *TodoService) DeleteTodo(user userModel.User, id int64) error {
 	return todoService.txManager.Run(func(ctx context.Context) error {
-		// 检查执行操作的用户是否为管理员
-		user, err := todoService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
+		if err := authz.Authorize(user, authz.PermTodoManage); err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
-		}
 
 		// 获取 To do
 		theTodo, err := todoService.todoRepository.GetTodoByID(id)
@@ -152,7 +134,7 @@ func (todoService *TodoService) DeleteTodo(userid uint, id int64) error {
 		}
 
 		// 检查该 To do 是否属于当前用户
-		if theTodo.UserID != userid {
+		if theTodo.UserID != user.ID {
 			return errors.New(commonModel.NO_PERMISSION_DENIED)
 		}
 
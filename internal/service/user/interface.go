@@ -6,20 +6,32 @@ import (
 )
 
 type UserServiceInterface interface {
-	// Login 用户登录
-	Login(user *authModel.LoginDto) (string, error)
+	// Authenticate 校验用户名和密码，成功时返回对应的用户
+	Authenticate(loginDto *authModel.LoginDto) (model.User, error)
 
 	// GetUserByID 根据用户ID获取用户信息
 	GetUserByID(userId int) (model.User, error)
 
+	// GetUserByUsername 根据用户名获取用户信息
+	GetUserByUsername(username string) (model.User, error)
+
+	// ProvisionUser 为外部身份自动创建用户
+	ProvisionUser(username string, isAdmin bool) (model.User, error)
+
+	// SetUserAdmin 设置用户的管理员权限（站长的角色不会被修改）
+	SetUserAdmin(id uint, isAdmin bool) error
+
 	// Register 用户注册
 	Register(registerDto *authModel.RegisterDto) error
 
 	// UpdateUser 更新用户信息
-	UpdateUser(userid uint, userdto model.UserInfoDto) error
+	UpdateUser(user model.User, sessionID string, userdto model.UserInfoDto) error
 
-	// UpdateUserAdmin 更新用户的管理员权限
-	UpdateUserAdmin(userid uint, id uint) error
+	// UpdateUserAdmin 切换用户的管理员权限
+	UpdateUserAdmin(user model.User, id uint) error
+
+	// UpdateUserRole 修改用户的角色
+	UpdateUserRole(user model.User, id uint, role model.Role) error
 
 	// GetAllUsers 获取所有用户
 	GetAllUsers() ([]model.User, error)
@@ -28,5 +40,8 @@ type UserServiceInterface interface {
 	GetSysAdmin() (model.User, error)
 
 	// DeleteUser 删除用户
-	DeleteUser(userid, id uint) error
+	DeleteUser(user model.User, id uint) error
+
+	// GetUserProfile 获取用户的公开资料
+	GetUserProfile(user model.User, username string) (model.UserProfile, error)
 }
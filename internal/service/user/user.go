@@ -3,24 +3,35 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/config"
 	"github.com/lin-snow/ech0/internal/transaction"
+	"go.uber.org/zap"
 
 	authModel "github.com/lin-snow/ech0/internal/model/auth"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	settingModel "github.com/lin-snow/ech0/internal/model/setting"
 	model "github.com/lin-snow/ech0/internal/model/user"
+	authRepository "github.com/lin-snow/ech0/internal/repository/auth"
 	repository "github.com/lin-snow/ech0/internal/repository/user"
 	settingService "github.com/lin-snow/ech0/internal/service/setting"
 	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
-	jwtUtil "github.com/lin-snow/ech0/internal/util/jwt"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
 )
 
 // UserService 用户服务结构体，提供用户相关的业务逻辑处理
 type UserService struct {
 	txManager      transaction.TransactionManager         // 事务管理器
 	userRepository repository.UserRepositoryInterface     // 用户数据层接口
+	authRepository authRepository.AuthRepositoryInterface // 认证数据层接口，修改密码时撤销会话和令牌
 	settingService settingService.SettingServiceInterface // 系统设置数据层接口
 }
 
@@ -28,6 +39,7 @@ type UserService struct {
 //
 // 参数:
 //   - userRepository: 用户数据层接口实现
+//   - authRepository: 认证数据层接口实现
 //   - settingService: 系统设置数据层接口实现
 //
 // 返回:
@@ -35,56 +47,54 @@ type UserService struct {
 func NewUserService(
 	tm transaction.TransactionManager,
 	userRepository repository.UserRepositoryInterface,
+	authRepository authRepository.AuthRepositoryInterface,
 	settingService settingService.SettingServiceInterface,
 ) UserServiceInterface {
 	return &UserService{
 		txManager:      tm,
 		userRepository: userRepository,
+		authRepository: authRepository,
 		settingService: settingService,
 	}
 }
 
-// Login 用户登录验证
-// 验证用户名和密码，成功后生成JWT token
+// Authenticate 校验用户名和密码
+// 验证成功后返回对应的用户，令牌与会话由认证服务签发
 //
 // 参数:
 //   - loginDto: 登录数据传输对象，包含用户名和密码
 //
 // 返回:
-//   - string: 生成的JWT token
-//   - error: 登录过程中的错误信息
-func (userService *UserService) Login(loginDto *authModel.LoginDto) (string, error) {
+//   - model.User: 通过验证的用户
+//   - error: 验证过程中的错误信息
+func (userService *UserService) Authenticate(loginDto *authModel.LoginDto) (model.User, error) {
 	// 合法性校验
 	if loginDto.Username == "" || loginDto.Password == "" {
-		return "", errors.New(commonModel.USERNAME_OR_PASSWORD_NOT_BE_EMPTY)
+		return model.User{}, errors.New(commonModel.USERNAME_OR_PASSWORD_NOT_BE_EMPTY)
 	}
 
-	// 将密码进行 MD5 加密
-	loginDto.Password = cryptoUtil.MD5Encrypt(loginDto.Password)
-
 	// 检查用户是否存在
 	user, err := userService.userRepository.GetUserByUsername(loginDto.Username)
 	if err != nil {
-		return "", errors.New(commonModel.USER_NOTFOUND)
+		return model.User{}, errors.New(commonModel.USER_NOTFOUND)
 	}
 
-	// 进行密码验证,查看外界传入的密码是否与数据库一致
-	if user.Password != loginDto.Password {
-		return "", errors.New(commonModel.PASSWORD_INCORRECT)
+	// 进行密码验证,查看外界传入的密码是否与数据库中的哈希匹配
+	if !cryptoUtil.VerifyPassword(loginDto.Password, user.Password) {
+		return model.User{}, errors.New(commonModel.PASSWORD_INCORRECT)
 	}
 
-	// 生成 Token
-	token, err := jwtUtil.GenerateToken(jwtUtil.CreateClaims(user))
-	if err != nil {
-		return "", err
+	// 旧版本的 MD5 哈希或参数已过时的哈希，在登录成功后透明升级
+	if cryptoUtil.NeedsRehash(user.Password) {
+		userService.rehashPassword(user, loginDto.Password)
 	}
 
-	return token, nil
+	return user, nil
 }
 
 // Register 用户注册
 // 注册新用户，包括用户数量限制检查、注册权限检查等
-// 第一个注册的用户自动设置为系统管理员
+// 第一个注册的用户自动设置为站长（系统管理员），其余用户为配置的默认角色
 //
 // 参数:
 //   - registerDto: 注册数据传输对象，包含用户名和密码
@@ -102,14 +112,22 @@ func (userService *UserService) Register(registerDto *authModel.RegisterDto) err
 			return errors.New(commonModel.USER_COUNT_EXCEED_LIMIT)
 		}
 
-		// 将密码进行 MD5 加密
-		registerDto.Password = cryptoUtil.MD5Encrypt(registerDto.Password)
+		// 检查密码是否符合密码策略
+		if err := checkPasswordPolicy(registerDto.Password); err != nil {
+			return err
+		}
+
+		// 计算密码哈希
+		passwordHash, err := cryptoUtil.HashPassword(registerDto.Password)
+		if err != nil {
+			return err
+		}
 
 		newUser := model.User{
 			Username: registerDto.Username,
-			Password: registerDto.Password,
-			IsAdmin:  false,
+			Password: passwordHash,
 		}
+		newUser.SetRole(defaultRole())
 
 		// 检查用户是否已经存在
 		user, err := userService.userRepository.GetUserByUsername(newUser.Username)
@@ -119,8 +137,8 @@ func (userService *UserService) Register(registerDto *authModel.RegisterDto) err
 
 		// 检查是否该系统第一次注册用户
 		if len(users) == 0 {
-			// 第一个注册的用户为系统管理员
-			newUser.IsAdmin = true
+			// 第一个注册的用户为站长
+			newUser.SetRole(model.RoleOwner)
 		}
 
 		// 检查是否开放注册
@@ -141,23 +159,26 @@ func (userService *UserService) Register(registerDto *authModel.RegisterDto) err
 }
 
 // UpdateUser 更新用户信息
-// 只有管理员可以更新用户信息，支持更新用户名、密码和头像
+// 更新当前用户自己的信息，支持更新用户名、密码、头像、昵称和个人简介
+// 修改密码时需要提供当前密码，修改后撤销除当前会话以外的所有会话并删除所有个人访问令牌
 //
 // 参数:
-//   - userid: 执行更新操作的用户ID（必须为管理员）
+//   - user: 执行更新操作的用户
+//   - sessionID: 发起请求的会话ID，修改密码时保留该会话
 //   - userdto: 用户信息数据传输对象，包含要更新的用户信息
 //
 // 返回:
 //   - error: 更新过程中的错误信息
-func (userService *UserService) UpdateUser(userid uint, userdto model.UserInfoDto) error {
+func (userService *UserService) UpdateUser(user model.User, sessionID string, userdto model.UserInfoDto) error {
 	return userService.txManager.Run(func(ctx context.Context) error {
-		// 检查执行操作的用户是否为管理员
-		user, err := userService.userRepository.GetUserByID(int(userid))
-		if err != nil {
+		if err := authz.Authorize(user, authz.PermProfileUpdate); err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
+
+		// 鉴权中间件加载的用户不包含密码哈希，重新从数据库加载后再修改，避免保存时清空密码
+		user, err := userService.userRepository.GetUserByID(int(user.ID))
+		if err != nil {
+			return err
 		}
 
 		// 检查是否需要更新用户名
@@ -171,13 +192,23 @@ func (userService *UserService) UpdateUser(userid uint, userdto model.UserInfoDt
 		}
 
 		// 检查是否需要更新密码
-		if userdto.Password != "" && cryptoUtil.MD5Encrypt(userdto.Password) != user.Password {
-			// 检查密码是否为空
-			if userdto.Password == "" {
-				return errors.New(commonModel.USERNAME_OR_PASSWORD_NOT_BE_EMPTY)
+		passwordChanged := false
+		if userdto.Password != "" && !cryptoUtil.VerifyPassword(userdto.Password, user.Password) {
+			// 校验当前密码，避免被盗用的会话或令牌直接改掉密码
+			if !cryptoUtil.VerifyPassword(userdto.CurrentPassword, user.Password) {
+				return errors.New(commonModel.CURRENT_PASSWORD_INCORRECT)
+			}
+			// 检查密码是否符合密码策略
+			if err := checkPasswordPolicy(userdto.Password); err != nil {
+				return err
 			}
 			// 更新密码
-			user.Password = cryptoUtil.MD5Encrypt(userdto.Password)
+			passwordHash, err := cryptoUtil.HashPassword(userdto.Password)
+			if err != nil {
+				return err
+			}
+			user.Password = passwordHash
+			passwordChanged = true
 		}
 
 		// 检查是否需要更新头像
@@ -185,60 +216,95 @@ func (userService *UserService) UpdateUser(userid uint, userdto model.UserInfoDt
 			// 更新头像
 			user.Avatar = userdto.Avatar
 		}
+
+		// 检查是否需要更新昵称
+		if userdto.DisplayName != nil {
+			displayName := strings.TrimSpace(*userdto.DisplayName)
+			if utf8.RuneCountInString(displayName) > model.MaxDisplayNameLength {
+				return errors.New(commonModel.DISPLAY_NAME_TOO_LONG)
+			}
+			user.DisplayName = displayName
+		}
+
+		// 检查是否需要更新个人简介
+		if userdto.Bio != nil {
+			bio := strings.TrimSpace(*userdto.Bio)
+			if utf8.RuneCountInString(bio) > model.MaxBioLength {
+				return errors.New(commonModel.BIO_TOO_LONG)
+			}
+			user.Bio = bio
+		}
+
 		// 更新用户信息
 		if err := userService.userRepository.UpdateUser(ctx, &user); err != nil {
 			return err
 		}
 
+		// 修改密码后，其他设备上的会话和个人访问令牌全部失效
+		if passwordChanged {
+			if err := userService.authRepository.RevokeOtherSessions(ctx, user.ID, sessionID); err != nil {
+				return err
+			}
+			if err := userService.authRepository.DeleteUserAccessTokens(ctx, user.ID); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 }
 
-// UpdateUserAdmin 更新用户的管理员权限
-// 只有系统管理员、管理员可以修改其他用户的管理员权限，不能修改自己和系统管理员的权限
+// UpdateUserAdmin 切换用户的管理员权限
+// 普通用户设置为管理员，管理员恢复为默认角色，规则与 UpdateUserRole 相同
 //
 // 参数:
-//   - userid: 执行操作的用户ID（必须为管理员）
+//   - user: 执行操作的用户
 //   - id: 要修改权限的用户ID
 //
 // 返回:
 //   - error: 更新过程中的错误信息
-func (userService *UserService) UpdateUserAdmin(userid uint, id uint) error {
-	return userService.txManager.Run(func(ctx context.Context) error {
-		// 检查执行操作的用户是否为管理员
-		user, err := userService.userRepository.GetUserByID(int(userid))
-		if err != nil {
-			return err
-		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
-		}
+func (userService *UserService) UpdateUserAdmin(user model.User, id uint) error {
+	target, err := userService.userRepository.GetUserByID(int(id))
+	if err != nil {
+		return err
+	}
+
+	role := model.RoleAdmin
+	if target.Role.IsAdmin() {
+		role = defaultRole()
+	}
+
+	return userService.UpdateUserRole(user, id, role)
+}
 
-		// 检查要修改权限的用户是否存在
-		user, err = userService.userRepository.GetUserByID(int(id))
+// UpdateUserRole 修改用户的角色
+// 不能修改自己和站长的角色，只能修改级别比自己低的用户，授予的角色不能高于自己的角色
+//
+// 参数:
+//   - user: 执行操作的用户
+//   - id: 要修改角色的用户ID
+//   - role: 新的角色
+//
+// 返回:
+//   - error: 更新过程中的错误信息
+func (userService *UserService) UpdateUserRole(user model.User, id uint, role model.Role) error {
+	return userService.txManager.Run(func(ctx context.Context) error {
+		// 检查要修改角色的用户是否存在
+		target, err := userService.userRepository.GetUserByID(int(id))
 		if err != nil {
 			return err
 		}
 
-		// 检查系统管理员信息
-		sysadmin, err := userService.GetSysAdmin()
-		if err != nil {
+		if err := authz.AuthorizeAssignRole(user, target, role); err != nil {
 			return err
 		}
-
-		// 检查是否尝试修改自己或系统管理员的权限
-		if userid == user.ID || id == sysadmin.ID {
-			return errors.New(commonModel.INVALID_PARAMS_BODY)
+		if target.Role == role {
+			return nil
 		}
 
-		user.IsAdmin = !user.IsAdmin
-
-		// 更新用户信息
-		if err := userService.userRepository.UpdateUser(ctx, &user); err != nil {
-			return err
-		}
+		target.SetRole(role)
 
-		return nil
+		return userService.userRepository.UpdateUser(ctx, &target)
 	})
 }
 
@@ -290,40 +356,26 @@ func (userService *UserService) GetSysAdmin() (model.User, error) {
 }
 
 // DeleteUser 删除用户
-// 只有管理员可以删除用户，不能删除自己和系统管理员
+// 只能删除级别比自己低的用户，不能删除自己和站长
 //
 // 参数:
-//   - userid: 执行删除操作的用户ID（必须为管理员）
+//   - user: 执行删除操作的用户
 //   - id: 要删除的用户ID
 //
 // 返回:
 //   - error: 删除过程中的错误信息
-func (userService *UserService) DeleteUser(userid, id uint) error {
+func (userService *UserService) DeleteUser(user model.User, id uint) error {
 	return userService.txManager.Run(func(ctx context.Context) error {
-		// 检查执行操作的用户是否为管理员
-		user, err := userService.userRepository.GetUserByID(int(userid))
-		if err != nil {
-			return err
-		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
-		}
-
 		// 检查要删除的用户是否存在
-		user, err = userService.userRepository.GetUserByID(int(id))
+		target, err := userService.userRepository.GetUserByID(int(id))
 		if err != nil {
 			return err
 		}
 
-		sysadmin, err := userService.GetSysAdmin()
-		if err != nil {
+		if err := authz.AuthorizeManageUser(user, target); err != nil {
 			return err
 		}
 
-		if userid == user.ID || id == sysadmin.ID {
-			return errors.New(commonModel.INVALID_PARAMS_BODY)
-		}
-
 		if err := userService.userRepository.DeleteUser(ctx, id); err != nil {
 			return err
 		}
@@ -333,6 +385,38 @@ func (userService *UserService) DeleteUser(userid, id uint) error {
 
 }
 
+// GetUserProfile 获取用户的公开资料
+// 访客只能看到公开 Echo 的统计，本人和拥有查看私密 Echo 权限的用户可以看到全部统计
+//
+// 参数:
+//   - user: 当前用户，未登录时为零值
+//   - username: 要查看的用户名
+//
+// 返回:
+//   - model.UserProfile: 用户的公开资料
+//   - error: 获取过程中的错误信息
+func (userService *UserService) GetUserProfile(user model.User, username string) (model.UserProfile, error) {
+	target, err := userService.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return model.UserProfile{}, errors.New(commonModel.USER_NOTFOUND)
+	}
+
+	stats, err := userService.userRepository.GetProfileStats(target.ID, authz.CanViewPrivate(user, target.ID))
+	if err != nil {
+		return model.UserProfile{}, err
+	}
+
+	return model.UserProfile{
+		ID:          target.ID,
+		Username:    target.Username,
+		DisplayName: target.DisplayName,
+		Avatar:      target.Avatar,
+		Bio:         target.Bio,
+		Role:        target.Role,
+		Stats:       stats,
+	}, nil
+}
+
 // GetUserByID 根据用户ID获取用户信息
 //
 // 参数:
@@ -344,3 +428,195 @@ func (userService *UserService) DeleteUser(userid, id uint) error {
 func (userService *UserService) GetUserByID(userId int) (model.User, error) {
 	return userService.userRepository.GetUserByID(userId)
 }
+
+// GetUserByUsername 根据用户名获取用户信息
+//
+// 参数:
+//   - username: 用户名
+//
+// 返回:
+//   - model.User: 用户信息
+//   - error: 获取过程中的错误信息
+func (userService *UserService) GetUserByUsername(username string) (model.User, error) {
+	user, err := userService.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return model.User{}, errors.New(commonModel.USER_NOTFOUND)
+	}
+
+	return user, nil
+}
+
+// ProvisionUser 为外部身份（如 OpenID Connect）自动创建用户
+// 用户名已存在时追加数字后缀，密码设置为无法使用的随机值，第一个用户为站长
+//
+// 参数:
+//   - username: 期望的用户名
+//   - isAdmin: 是否为管理员
+//
+// 返回:
+//   - model.User: 创建的用户
+//   - error: 创建过程中的错误信息
+func (userService *UserService) ProvisionUser(username string, isAdmin bool) (model.User, error) {
+	var newUser model.User
+	err := userService.txManager.Run(func(ctx context.Context) error {
+		// 检查用户数量是否超过限制
+		users, err := userService.userRepository.GetAllUsers()
+		if err != nil {
+			return err
+		}
+		if len(users) > authModel.MAX_USER_COUNT {
+			return errors.New(commonModel.USER_COUNT_EXCEED_LIMIT)
+		}
+
+		// 随机密码只用于占位，外部身份的用户无法使用密码登录
+		randomPassword := make([]byte, 32)
+		if _, err := rand.Read(randomPassword); err != nil {
+			return err
+		}
+		passwordHash, err := cryptoUtil.HashPassword(hex.EncodeToString(randomPassword))
+		if err != nil {
+			return err
+		}
+
+		newUser = model.User{
+			Username: uniqueUsername(username, users),
+			Password: passwordHash,
+		}
+		switch {
+		case len(users) == 0:
+			newUser.SetRole(model.RoleOwner)
+		case isAdmin:
+			newUser.SetRole(model.RoleAdmin)
+		default:
+			newUser.SetRole(defaultRole())
+		}
+
+		return userService.userRepository.CreateUser(ctx, &newUser)
+	})
+
+	return newUser, err
+}
+
+// SetUserAdmin 设置用户的管理员权限（由身份提供者的用户组同步）
+// 取消管理员权限时恢复为默认角色，站长的角色不会被修改
+//
+// 参数:
+//   - id: 用户ID
+//   - isAdmin: 是否为管理员
+//
+// 返回:
+//   - error: 更新过程中的错误信息
+func (userService *UserService) SetUserAdmin(id uint, isAdmin bool) error {
+	return userService.txManager.Run(func(ctx context.Context) error {
+		user, err := userService.userRepository.GetUserByID(int(id))
+		if err != nil {
+			return err
+		}
+		if user.Role == model.RoleOwner || user.Role.IsAdmin() == isAdmin {
+			return nil
+		}
+
+		if isAdmin {
+			user.SetRole(model.RoleAdmin)
+		} else {
+			user.SetRole(defaultRole())
+		}
+		return userService.userRepository.UpdateUser(ctx, &user)
+	})
+}
+
+// rehashPassword 使用当前的哈希算法重新计算密码哈希并保存
+// 升级失败不影响本次登录，下次登录时会再次尝试
+//
+// 参数:
+//   - user: 登录成功的用户
+//   - password: 用户输入的明文密码
+func (userService *UserService) rehashPassword(user model.User, password string) {
+	passwordHash, err := cryptoUtil.HashPassword(password)
+	if err == nil {
+		user.Password = passwordHash
+		err = userService.txManager.Run(func(ctx context.Context) error {
+			return userService.userRepository.UpdateUser(ctx, &user)
+		})
+	}
+	if err != nil {
+		logUtil.GetLogger().Warn("[密码哈希升级失败]", zap.Uint("用户ID", user.ID), zap.Error(err))
+	}
+}
+
+// checkPasswordPolicy 检查密码是否符合配置的密码策略
+//
+// 参数:
+//   - password: 明文密码
+//
+// 返回:
+//   - error: 不符合密码策略时返回对应的错误
+func checkPasswordPolicy(password string) error {
+	policy := config.Config.Auth.Password
+
+	if password == "" {
+		return errors.New(commonModel.USERNAME_OR_PASSWORD_NOT_BE_EMPTY)
+	}
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		return errors.New(commonModel.PASSWORD_TOO_SHORT)
+	}
+	if policy.MaxLength > 0 && length > policy.MaxLength {
+		return errors.New(commonModel.PASSWORD_TOO_LONG)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	if (policy.RequireUpper && !hasUpper) || (policy.RequireLower && !hasLower) ||
+		(policy.RequireDigit && !hasDigit) || (policy.RequireSymbol && !hasSymbol) {
+		return errors.New(commonModel.PASSWORD_TOO_WEAK)
+	}
+
+	return nil
+}
+
+// defaultRole 获取配置的新用户默认角色，只能是作者或读者
+func defaultRole() model.Role {
+	if model.Role(config.Config.Auth.DefaultRole) == model.RoleAuthor {
+		return model.RoleAuthor
+	}
+	return model.RoleReader
+}
+
+// maxUsernameLength 自动生成的用户名的最大长度（保留追加后缀的空间）
+const maxUsernameLength = 64
+
+// uniqueUsername 生成不与已有用户重复的用户名，重复时追加数字后缀
+func uniqueUsername(username string, users []model.User) string {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		username = "user"
+	}
+	if utf8.RuneCountInString(username) > maxUsernameLength {
+		username = string([]rune(username)[:maxUsernameLength])
+	}
+
+	taken := make(map[string]struct{}, len(users))
+	for _, user := range users {
+		taken[user.Username] = struct{}{}
+	}
+
+	candidate := username
+	for i := 2; ; i++ {
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+		candidate = username + "-" + strconv.Itoa(i)
+	}
+}
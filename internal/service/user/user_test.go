@@ -1,17 +1,22 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
 
+	"github.com/lin-snow/ech0/internal/config"
 	authModel "github.com/lin-snow/ech0/internal/model/auth"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	settingModel "github.com/lin-snow/ech0/internal/model/setting"
 	model "github.com/lin-snow/ech0/internal/model/user"
+	authRepository "github.com/lin-snow/ech0/internal/repository/auth"
 	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
 )
 
@@ -26,14 +31,39 @@ func (m *MockUserRepository) GetUserByUsername(username string) (model.User, err
 	args := m.Called(username)
 	return args.Get(0).(model.User), args.Error(1)
 }
-func (m *MockUserRepository) CreateUser(user *model.User) error {
+func (m *MockUserRepository) CreateUser(ctx context.Context, user *model.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
-func (m *MockUserRepository) GetUserByID(id int) (model.User, error) { return model.User{}, nil }
-func (m *MockUserRepository) UpdateUser(user *model.User) error      { return nil }
-func (m *MockUserRepository) DeleteUser(id uint) error               { return nil }
-func (m *MockUserRepository) GetSysAdmin() (model.User, error)       { return model.User{}, nil }
+func (m *MockUserRepository) GetUserByID(id int) (model.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.User), args.Error(1)
+}
+func (m *MockUserRepository) UpdateUser(ctx context.Context, user *model.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+func (m *MockUserRepository) DeleteUser(ctx context.Context, id uint) error { return nil }
+func (m *MockUserRepository) GetSysAdmin() (model.User, error)              { return model.User{}, nil }
+func (m *MockUserRepository) GetProfileStats(userID uint, showPrivate bool) (model.ProfileStats, error) {
+	args := m.Called(userID, showPrivate)
+	return args.Get(0).(model.ProfileStats), args.Error(1)
+}
+
+// MockAuthRepository 模拟认证仓库接口，只实现用户服务用到的方法
+type MockAuthRepository struct {
+	authRepository.AuthRepositoryInterface
+	mock.Mock
+}
+
+func (m *MockAuthRepository) RevokeOtherSessions(ctx context.Context, userID uint, keepID string) error {
+	args := m.Called(userID, keepID)
+	return args.Error(0)
+}
+func (m *MockAuthRepository) DeleteUserAccessTokens(ctx context.Context, userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
 
 // MockSettingService 模拟设置服务接口
 type MockSettingService struct{ mock.Mock }
@@ -47,35 +77,49 @@ func (m *MockSettingService) GetSetting(setting *settingModel.SystemSetting) err
 	}
 	return args.Error(0)
 }
+func (m *MockSettingService) GetServerURL() (string, error) {
+	return "", nil
+}
 func (m *MockSettingService) GetCommentSetting(setting *settingModel.CommentSetting) error {
 	return nil
 }
-func (m *MockSettingService) UpdateCommentSetting(id uint, setting *settingModel.CommentSettingDto) error {
+func (m *MockSettingService) UpdateCommentSetting(user model.User, setting *settingModel.CommentSettingDto) error {
 	return nil
 }
-func (m *MockSettingService) UpdateSetting(id uint, setting *settingModel.SystemSettingDto) error {
+func (m *MockSettingService) UpdateSetting(user model.User, setting *settingModel.SystemSettingDto) error {
 	return nil
 }
 
+// MockTxManager 模拟事务管理器，直接执行传入的函数
+type MockTxManager struct{}
+
+func (m *MockTxManager) Run(fn func(ctx context.Context) error) error {
+	return fn(context.Background())
+}
+
 // 测试套件
 type UserServiceTestSuite struct {
 	suite.Suite
 	userService    *UserService
 	mockUserRepo   *MockUserRepository
+	mockAuthRepo   *MockAuthRepository
 	mockSettingSvc *MockSettingService
 }
 
 func (suite *UserServiceTestSuite) SetupTest() {
 	suite.mockUserRepo = new(MockUserRepository)
+	suite.mockAuthRepo = new(MockAuthRepository)
 	suite.mockSettingSvc = new(MockSettingService)
 	suite.userService = &UserService{
+		txManager:      &MockTxManager{},
 		userRepository: suite.mockUserRepo,
+		authRepository: suite.mockAuthRepo,
 		settingService: suite.mockSettingSvc,
 	}
 }
 
-// ✅ 测试首个用户注册 → 自动成为管理员
-func (suite *UserServiceTestSuite) TestRegister_FirstUser_ShouldBeAdmin() {
+// ✅ 测试首个用户注册 → 自动成为站长
+func (suite *UserServiceTestSuite) TestRegister_FirstUser_ShouldBeOwner() {
 	registerDto := &authModel.RegisterDto{Username: "admin", Password: "password123"}
 
 	// Mock: 没有现有用户
@@ -92,8 +136,9 @@ func (suite *UserServiceTestSuite) TestRegister_FirstUser_ShouldBeAdmin() {
 	// Mock: 成功创建用户
 	suite.mockUserRepo.On("CreateUser", mock.MatchedBy(func(user *model.User) bool {
 		return user.Username == "admin" &&
-			user.Password == cryptoUtil.MD5Encrypt("password123") &&
-			user.IsAdmin
+			strings.HasPrefix(user.Password, "$argon2id$") &&
+			cryptoUtil.VerifyPassword("password123", user.Password) &&
+			user.Role == model.RoleOwner && user.IsAdmin
 	})).Return(nil)
 
 	err := suite.userService.Register(registerDto)
@@ -126,6 +171,222 @@ func (suite *UserServiceTestSuite) TestRegister_RegistrationNotAllowed() {
 	suite.mockSettingSvc.AssertExpectations(suite.T())
 }
 
+// 🚫 测试密码不符合密码策略时禁止注册
+func (suite *UserServiceTestSuite) TestRegister_PasswordPolicy() {
+	policy := config.Config.Auth.Password
+	defer func() { config.Config.Auth.Password = policy }()
+	config.Config.Auth.Password.MinLength = 8
+	config.Config.Auth.Password.RequireDigit = true
+
+	// Mock: 没有现有用户
+	suite.mockUserRepo.On("GetAllUsers").Return([]model.User{}, nil)
+
+	err := suite.userService.Register(&authModel.RegisterDto{Username: "admin", Password: "short1"})
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), commonModel.PASSWORD_TOO_SHORT, err.Error())
+
+	err = suite.userService.Register(&authModel.RegisterDto{Username: "admin", Password: "password"})
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), commonModel.PASSWORD_TOO_WEAK, err.Error())
+
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "CreateUser", mock.Anything)
+}
+
+// ✅ 测试旧版本 MD5 密码登录成功后自动升级为 argon2id
+func (suite *UserServiceTestSuite) TestAuthenticate_LegacyMD5_ShouldRehash() {
+	legacyUser := model.User{ID: 1, Username: "admin", Password: cryptoUtil.MD5Encrypt("password123"), IsAdmin: true}
+	suite.mockUserRepo.On("GetUserByUsername", "admin").Return(legacyUser, nil)
+
+	// Mock: 保存升级后的密码哈希
+	suite.mockUserRepo.On("UpdateUser", mock.MatchedBy(func(user *model.User) bool {
+		return user.ID == legacyUser.ID &&
+			strings.HasPrefix(user.Password, "$argon2id$") &&
+			cryptoUtil.VerifyPassword("password123", user.Password)
+	})).Return(nil)
+
+	user, err := suite.userService.Authenticate(&authModel.LoginDto{Username: "admin", Password: "password123"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), legacyUser.ID, user.ID)
+	suite.mockUserRepo.AssertExpectations(suite.T())
+}
+
+// ✅ 测试 bcrypt 密码登录成功后自动升级为 argon2id
+func (suite *UserServiceTestSuite) TestAuthenticate_Bcrypt_ShouldRehash() {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	suite.Require().NoError(err)
+	bcryptUser := model.User{ID: 1, Username: "admin", Password: string(bcryptHash)}
+	suite.mockUserRepo.On("GetUserByUsername", "admin").Return(bcryptUser, nil)
+
+	// Mock: 保存升级后的密码哈希
+	suite.mockUserRepo.On("UpdateUser", mock.MatchedBy(func(user *model.User) bool {
+		return user.ID == bcryptUser.ID &&
+			strings.HasPrefix(user.Password, "$argon2id$") &&
+			cryptoUtil.VerifyPassword("password123", user.Password)
+	})).Return(nil)
+
+	user, err := suite.userService.Authenticate(&authModel.LoginDto{Username: "admin", Password: "password123"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), bcryptUser.ID, user.ID)
+	suite.mockUserRepo.AssertExpectations(suite.T())
+}
+
+// ✅ 测试 argon2id 密码使用当前参数时不会重新计算
+func (suite *UserServiceTestSuite) TestAuthenticate_CurrentHash_ShouldNotRehash() {
+	passwordHash, err := cryptoUtil.HashPassword("password123")
+	suite.Require().NoError(err)
+	suite.mockUserRepo.On("GetUserByUsername", "admin").Return(model.User{ID: 1, Username: "admin", Password: passwordHash}, nil)
+
+	_, err = suite.userService.Authenticate(&authModel.LoginDto{Username: "admin", Password: "password123"})
+
+	assert.NoError(suite.T(), err)
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "UpdateUser", mock.Anything)
+}
+
+// 🚫 测试密码错误时不会升级密码哈希
+func (suite *UserServiceTestSuite) TestAuthenticate_WrongPassword() {
+	legacyUser := model.User{ID: 1, Username: "admin", Password: cryptoUtil.MD5Encrypt("password123")}
+	suite.mockUserRepo.On("GetUserByUsername", "admin").Return(legacyUser, nil)
+
+	_, err := suite.userService.Authenticate(&authModel.LoginDto{Username: "admin", Password: "password124"})
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), commonModel.PASSWORD_INCORRECT, err.Error())
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "UpdateUser", mock.Anything)
+}
+
+// ✅ 测试自动创建外部身份用户 → 用户名重复时追加后缀，密码无法使用
+func (suite *UserServiceTestSuite) TestProvisionUser_DuplicateUsername() {
+	existingUsers := []model.User{{ID: 1, Username: "alice", Role: model.RoleOwner, IsAdmin: true}, {ID: 2, Username: "alice-2"}}
+	suite.mockUserRepo.On("GetAllUsers").Return(existingUsers, nil)
+	suite.mockUserRepo.On("CreateUser", mock.MatchedBy(func(user *model.User) bool {
+		return user.Username == "alice-3" &&
+			strings.HasPrefix(user.Password, "$argon2id$") &&
+			user.Role == model.RoleReader && !user.IsAdmin
+	})).Return(nil)
+
+	user, err := suite.userService.ProvisionUser(" alice ", false)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "alice-3", user.Username)
+	suite.mockUserRepo.AssertExpectations(suite.T())
+}
+
+// 🚫 测试管理员不能修改其他管理员的角色，也不能授予站长角色
+func (suite *UserServiceTestSuite) TestUpdateUserRole_AdminCannotManageAdmin() {
+	admin := model.User{ID: 2, Username: "bob", Role: model.RoleAdmin, IsAdmin: true}
+	otherAdmin := model.User{ID: 3, Username: "carol", Role: model.RoleAdmin, IsAdmin: true}
+	author := model.User{ID: 4, Username: "dave", Role: model.RoleAuthor}
+	suite.mockUserRepo.On("GetUserByID", 3).Return(otherAdmin, nil)
+	suite.mockUserRepo.On("GetUserByID", 4).Return(author, nil)
+
+	err := suite.userService.UpdateUserRole(admin, otherAdmin.ID, model.RoleReader)
+	assert.EqualError(suite.T(), err, commonModel.NO_PERMISSION_DENIED)
+
+	err = suite.userService.UpdateUserRole(admin, author.ID, model.RoleOwner)
+	assert.EqualError(suite.T(), err, commonModel.INVALID_ROLE)
+
+	// 可以修改级别比自己低的用户
+	suite.mockUserRepo.On("UpdateUser", mock.MatchedBy(func(user *model.User) bool {
+		return user.ID == author.ID && user.Role == model.RoleReader && !user.IsAdmin
+	})).Return(nil)
+
+	err = suite.userService.UpdateUserRole(admin, author.ID, model.RoleReader)
+	assert.NoError(suite.T(), err)
+	suite.mockUserRepo.AssertExpectations(suite.T())
+}
+
+// ✅ 测试更新昵称和个人简介 → 去除首尾空格，不传的字段保持不变
+func (suite *UserServiceTestSuite) TestUpdateUser_DisplayNameAndBio() {
+	user := model.User{ID: 2, Username: "bob", Role: model.RoleAuthor, DisplayName: "Bob", Bio: "old bio"}
+	displayName := "  Bobby  "
+	suite.mockUserRepo.On("GetUserByID", 2).Return(user, nil)
+	suite.mockUserRepo.On("UpdateUser", mock.MatchedBy(func(updated *model.User) bool {
+		return updated.DisplayName == "Bobby" && updated.Bio == "old bio"
+	})).Return(nil)
+
+	err := suite.userService.UpdateUser(user, "", model.UserInfoDto{DisplayName: &displayName})
+
+	assert.NoError(suite.T(), err)
+	suite.mockUserRepo.AssertExpectations(suite.T())
+}
+
+// 🚫 测试个人简介超过长度限制
+func (suite *UserServiceTestSuite) TestUpdateUser_BioTooLong() {
+	user := model.User{ID: 2, Username: "bob", Role: model.RoleAuthor}
+	bio := strings.Repeat("字", model.MaxBioLength+1)
+	suite.mockUserRepo.On("GetUserByID", 2).Return(user, nil)
+
+	err := suite.userService.UpdateUser(user, "", model.UserInfoDto{Bio: &bio})
+
+	assert.EqualError(suite.T(), err, commonModel.BIO_TOO_LONG)
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "UpdateUser", mock.Anything)
+}
+
+// 🚫 测试修改密码时当前密码错误 → 不更新密码，也不撤销会话
+func (suite *UserServiceTestSuite) TestUpdateUser_PasswordRequiresCurrentPassword() {
+	hash, err := cryptoUtil.HashPassword("old-password")
+	assert.NoError(suite.T(), err)
+	// 与鉴权中间件加载的用户一样不含密码哈希，当前密码与数据库中的哈希比较
+	user := model.User{ID: 2, Username: "bob", Role: model.RoleAuthor}
+	stored := user
+	stored.Password = hash
+	suite.mockUserRepo.On("GetUserByID", 2).Return(stored, nil)
+
+	err = suite.userService.UpdateUser(user, "session-1", model.UserInfoDto{Password: "new-password"})
+	assert.EqualError(suite.T(), err, commonModel.CURRENT_PASSWORD_INCORRECT)
+
+	err = suite.userService.UpdateUser(user, "session-1", model.UserInfoDto{Password: "new-password", CurrentPassword: "wrong"})
+	assert.EqualError(suite.T(), err, commonModel.CURRENT_PASSWORD_INCORRECT)
+
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "UpdateUser", mock.Anything)
+	suite.mockAuthRepo.AssertNotCalled(suite.T(), "RevokeOtherSessions", mock.Anything, mock.Anything)
+}
+
+// ✅ 测试修改密码 → 撤销其他会话并删除所有个人访问令牌，保留当前会话
+func (suite *UserServiceTestSuite) TestUpdateUser_PasswordChangeRevokesCredentials() {
+	hash, err := cryptoUtil.HashPassword("old-password")
+	assert.NoError(suite.T(), err)
+	user := model.User{ID: 2, Username: "bob", Role: model.RoleAuthor}
+	stored := user
+	stored.Password = hash
+
+	suite.mockUserRepo.On("GetUserByID", 2).Return(stored, nil)
+	suite.mockUserRepo.On("UpdateUser", mock.MatchedBy(func(updated *model.User) bool {
+		return cryptoUtil.VerifyPassword("new-password", updated.Password)
+	})).Return(nil)
+	suite.mockAuthRepo.On("RevokeOtherSessions", user.ID, "session-1").Return(nil)
+	suite.mockAuthRepo.On("DeleteUserAccessTokens", user.ID).Return(nil)
+
+	err = suite.userService.UpdateUser(user, "session-1", model.UserInfoDto{Password: "new-password", CurrentPassword: "old-password"})
+
+	assert.NoError(suite.T(), err)
+	suite.mockUserRepo.AssertExpectations(suite.T())
+	suite.mockAuthRepo.AssertExpectations(suite.T())
+}
+
+// ✅ 测试获取用户主页 → 访客只统计公开 Echo，本人统计全部
+func (suite *UserServiceTestSuite) TestGetUserProfile_PrivateStatsOnlyForSelf() {
+	target := model.User{ID: 3, Username: "carol", Password: "hash", Role: model.RoleAuthor, DisplayName: "Carol", Bio: "hi"}
+	suite.mockUserRepo.On("GetUserByUsername", "carol").Return(target, nil)
+	suite.mockUserRepo.On("GetProfileStats", target.ID, false).Return(model.ProfileStats{EchoCount: 1}, nil)
+	suite.mockUserRepo.On("GetProfileStats", target.ID, true).Return(model.ProfileStats{EchoCount: 2}, nil)
+
+	profile, err := suite.userService.GetUserProfile(model.User{}, "carol")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Carol", profile.DisplayName)
+	assert.Equal(suite.T(), int64(1), profile.Stats.EchoCount)
+
+	profile, err = suite.userService.GetUserProfile(target, "carol")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), profile.Stats.EchoCount)
+
+	suite.mockUserRepo.On("GetUserByUsername", "nobody").Return(model.User{}, errors.New("record not found"))
+	_, err = suite.userService.GetUserProfile(model.User{}, "nobody")
+	assert.EqualError(suite.T(), err, commonModel.USER_NOTFOUND)
+}
+
 func TestUserServiceSuite(t *testing.T) {
 	suite.Run(t, new(UserServiceTestSuite))
 }
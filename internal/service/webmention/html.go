@@ -0,0 +1,226 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/webmention"
+	mdUtil "github.com/lin-snow/ech0/internal/util/md"
+	"golang.org/x/net/html"
+)
+
+// sourcePage 定义 source 页面中提取到的信息
+type sourcePage struct {
+	Linked     bool   // 是否包含指向 target 的链接
+	Title      string // 页面标题
+	Excerpt    string // 页面摘要
+	AuthorName string // 页面作者
+}
+
+// echoSourceTemplate 作为 source 的 Echo 页面（h-entry），前端为 SPA，远程页面无法从中读取到链接
+var echoSourceTemplate = template.Must(template.New("echo").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="canonical" href="{{.URL}}">
+<link rel="webmention" href="{{.Endpoint}}">
+</head>
+<body>
+<article class="h-entry">
+<a class="p-author h-card" href="{{.HomeURL}}">{{.Username}}</a>
+<a class="u-url" href="{{.URL}}"><time class="dt-published" datetime="{{.Published}}">{{.PublishedText}}</time></a>
+<div class="e-content">{{.Content}}</div>
+{{range .Images}}<img class="u-photo" src="{{.}}" alt="">
+{{end}}</article>
+</body>
+</html>
+`))
+
+// RenderEchoSource 渲染作为 source 的 Echo 页面，Echo 不存在或为私密时返回错误（对应 410）
+func (webmentionService *WebmentionService) RenderEchoSource(id uint) ([]byte, error) {
+	echo, err := webmentionService.echoRepository.GetEchosById(id)
+	if err != nil {
+		return nil, err
+	}
+	if echo == nil || echo.Private {
+		return nil, errors.New(commonModel.ECHO_NOT_FOUND)
+	}
+
+	serverURL, err := webmentionService.settingService.GetServerURL()
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := echo.CreatedAt.In(webmentionService.commonService.GetLocation())
+	data := struct {
+		Title         string
+		URL           string
+		HomeURL       string
+		Endpoint      string
+		Username      string
+		Published     string
+		PublishedText string
+		Content       template.HTML
+		Images        []string
+	}{
+		Title:         fmt.Sprintf("%s - %s", echo.Username, createdAt.Format("2006-01-02")),
+		URL:           fmt.Sprintf("%s/echo/%d", serverURL, echo.ID),
+		HomeURL:       serverURL + "/",
+		Endpoint:      serverURL + model.EndpointPath,
+		Username:      echo.Username,
+		Published:     createdAt.Format(time.RFC3339),
+		PublishedText: createdAt.Format("2006-01-02 15:04"),
+		Content:       template.HTML(mdUtil.MdToHTML([]byte(echo.Content))),
+	}
+	for _, image := range echo.Images {
+		if image.ImageSource == echoModel.ImageSourceLocal {
+			data.Images = append(data.Images, serverURL+"/api"+image.ImageURL)
+		} else if image.ImageURL != "" {
+			data.Images = append(data.Images, image.ImageURL)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := echoSourceTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// extractContentLinks 提取 Markdown 内容渲染后的所有链接
+func extractContentLinks(content string) []string {
+	var links []string
+	tokenizer := html.NewTokenizer(bytes.NewReader(mdUtil.MdToHTML([]byte(content))))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return links
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data == "a" {
+				if href, ok := attr(token, "href"); ok {
+					links = append(links, href)
+				}
+			}
+		}
+	}
+}
+
+// findWebmentionElement 查找 HTML 中第一个 rel 包含 webmention 的 <link> 或 <a> 元素
+func findWebmentionElement(body []byte) (string, bool) {
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return "", false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data != "link" && token.Data != "a" {
+				continue
+			}
+			rel, _ := attr(token, "rel")
+			if !hasRel(rel, "webmention") {
+				continue
+			}
+			// href 为空字符串时表示端点即页面本身
+			if href, ok := attr(token, "href"); ok {
+				return href, true
+			}
+		}
+	}
+}
+
+// inspectSource 检查 source 是否包含指向 target 的链接，并提取标题、摘要与作者
+func inspectSource(body []byte, contentType string, sourceURL *url.URL, target string) sourcePage {
+	var page sourcePage
+
+	// 非 HTML 内容只检查是否包含 target
+	if !strings.Contains(contentType, "html") {
+		page.Linked = bytes.Contains(body, []byte(target))
+		return page
+	}
+
+	inTitle := false
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			page.Excerpt = truncate(page.Excerpt, model.MaxExcerptLength)
+			return page
+		case html.TextToken:
+			if inTitle && page.Title == "" {
+				page.Title = truncate(strings.TrimSpace(string(tokenizer.Text())), 255)
+			}
+		case html.EndTagToken:
+			inTitle = false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = true
+			case "meta":
+				name, _ := attr(token, "name")
+				if name == "" {
+					name, _ = attr(token, "property")
+				}
+				content, _ := attr(token, "content")
+				switch strings.ToLower(name) {
+				case "description", "og:description":
+					if page.Excerpt == "" {
+						page.Excerpt = strings.TrimSpace(content)
+					}
+				case "author":
+					if page.AuthorName == "" {
+						page.AuthorName = truncate(strings.TrimSpace(content), 255)
+					}
+				}
+			case "a", "link":
+				if href, ok := attr(token, "href"); ok && resolvesTo(sourceURL, href, target) {
+					page.Linked = true
+				}
+			case "img", "video", "audio", "source":
+				if src, ok := attr(token, "src"); ok && resolvesTo(sourceURL, src, target) {
+					page.Linked = true
+				}
+			}
+		}
+	}
+}
+
+// resolvesTo 判断 href 解析后是否指向 target（忽略片段）
+func resolvesTo(base *url.URL, href, target string) bool {
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return false
+	}
+	resolved := base.ResolveReference(ref)
+	resolved.Fragment = ""
+	return resolved.String() == target || strings.TrimSuffix(resolved.String(), "/") == strings.TrimSuffix(target, "/")
+}
+
+// attr 获取元素的属性值
+func attr(token html.Token, key string) (string, bool) {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// truncate 按字符数截断字符串
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
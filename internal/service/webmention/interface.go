@@ -0,0 +1,34 @@
+package service
+
+import (
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	model "github.com/lin-snow/ech0/internal/model/webmention"
+)
+
+type WebmentionServiceInterface interface {
+	// ReceiveWebmention 接收 Webmention，校验 target 后异步验证 source
+	ReceiveWebmention(host string, webmentionDto model.WebmentionDto) error
+
+	// SendWebmentions 异步向 Echo 中链接的页面发送 Webmention（更新或删除时同时通知旧链接）
+	SendWebmentions(oldEcho, newEcho *echoModel.Echo)
+
+	// GetEchoWebmentions 获取 Echo 已通过审核的 Webmention
+	GetEchoWebmentions(echoID uint) []model.Webmention
+
+	// DeleteEchoWebmentions 删除 Echo 收到的所有 Webmention
+	DeleteEchoWebmentions(echoID uint)
+
+	// RenderEchoSource 渲染作为 source 的 Echo 页面（包含 h-entry）
+	RenderEchoSource(id uint) ([]byte, error)
+
+	// GetWebmentions 分页获取 Webmention（管理员审核）
+	GetWebmentions(user userModel.User, webmentionQueryDto model.WebmentionQueryDto) (commonModel.PageQueryResult[[]model.Webmention], error)
+
+	// UpdateWebmentionStatus 审核 Webmention
+	UpdateWebmentionStatus(user userModel.User, id uint, status model.Status) error
+
+	// DeleteWebmention 删除 Webmention
+	DeleteWebmention(user userModel.User, id uint) error
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/webmention"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// linkHeaderRegex 匹配 Link 响应头中的单个链接及其参数
+var linkHeaderRegex = regexp.MustCompile(`<([^>]*)>([^<]*)`)
+
+// relParamRegex 匹配 Link 响应头中的 rel 参数
+var relParamRegex = regexp.MustCompile(`(?i)rel\s*=\s*(?:"([^"]*)"|([^\s";,]+))`)
+
+// SendWebmentions 异步向 Echo 中链接的页面发送 Webmention（更新或删除时同时通知旧链接，对方会重新验证 source）
+func (webmentionService *WebmentionService) SendWebmentions(oldEcho, newEcho *echoModel.Echo) {
+	echo := newEcho
+	if echo == nil {
+		echo = oldEcho
+	}
+	if echo == nil {
+		return
+	}
+
+	// 私密 Echo 不对外发送，公开变为私密时需要通知旧链接
+	var targets []string
+	seen := make(map[string]struct{})
+	for _, e := range []*echoModel.Echo{oldEcho, newEcho} {
+		if e == nil || e.Private {
+			continue
+		}
+		for _, link := range extractEchoLinks(e) {
+			if _, ok := seen[link]; !ok {
+				seen[link] = struct{}{}
+				targets = append(targets, link)
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	serverURL, err := webmentionService.settingService.GetServerURL()
+	if err != nil {
+		logUtil.GetLogger().Warn("[Webmention 未发送]", zap.Uint("echo_id", echo.ID), zap.Error(err))
+		return
+	}
+	source := fmt.Sprintf("%s%s%d", serverURL, model.SourcePathPrefix, echo.ID)
+	serverHost := ""
+	if parsed, err := url.Parse(serverURL); err == nil {
+		serverHost = parsed.Host
+	}
+
+	for _, target := range targets {
+		// 站内链接无需发送
+		if parsed, err := url.Parse(target); err == nil && strings.EqualFold(parsed.Host, serverHost) {
+			continue
+		}
+		if !sendPool.Submit(func() {
+			if err := sendWebmention(source, target); err != nil {
+				logUtil.GetLogger().Info("[Webmention 发送失败]", zap.String("target", target), zap.Error(err))
+			}
+		}) {
+			logUtil.GetLogger().Warn("[Webmention 发送队列已满]", zap.String("target", target))
+		}
+	}
+}
+
+// extractEchoLinks 提取 Echo 内容与扩展中的外部链接
+func extractEchoLinks(echo *echoModel.Echo) []string {
+	links := extractContentLinks(echo.Content)
+
+	switch echo.ExtensionType {
+	case echoModel.Extension_GITHUBPROJ:
+		links = append(links, echo.Extension)
+	case echoModel.Extension_WEBSITE:
+		var website struct {
+			Site string `json:"site"`
+		}
+		if err := jsonUtil.JSONUnmarshal([]byte(echo.Extension), &website); err == nil {
+			links = append(links, website.Site)
+		}
+	}
+
+	result := make([]string, 0, len(links))
+	for _, link := range links {
+		parsed, err := url.Parse(strings.TrimSpace(link))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			continue
+		}
+		result = append(result, parsed.String())
+	}
+	return result
+}
+
+// sendWebmention 发现 target 的 Webmention 端点并发送通知
+func sendWebmention(source, target string) error {
+	endpoint, err := discoverEndpoint(target)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.PostForm(endpoint, url.Values{
+		"source": {source},
+		"target": {target},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, model.MaxBodySize))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webmention rejected: %s", resp.Status)
+	}
+	return nil
+}
+
+// discoverEndpoint 按规范发现 target 的 Webmention 端点：优先 Link 响应头，其次 HTML 中的 <link>/<a rel="webmention">
+func discoverEndpoint(target string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/html, */*;q=0.5")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("fetch target failed: %s", resp.Status)
+	}
+
+	// 相对地址以重定向后的最终地址为基准
+	base := resp.Request.URL
+
+	for _, header := range resp.Header.Values("Link") {
+		if href, ok := findWebmentionLink(header); ok {
+			return resolveEndpoint(base, href)
+		}
+	}
+
+	if strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, model.MaxBodySize))
+		if err != nil {
+			return "", err
+		}
+		if href, ok := findWebmentionElement(body); ok {
+			return resolveEndpoint(base, href)
+		}
+	}
+
+	return "", errors.New("webmention endpoint not found")
+}
+
+// findWebmentionLink 在 Link 响应头中查找 rel 包含 webmention 的链接
+func findWebmentionLink(header string) (string, bool) {
+	for _, match := range linkHeaderRegex.FindAllStringSubmatch(header, -1) {
+		rel := relParamRegex.FindStringSubmatch(match[2])
+		if rel == nil {
+			continue
+		}
+		if hasRel(rel[1]+rel[2], "webmention") {
+			return match[1], true
+		}
+	}
+	return "", false
+}
+
+// resolveEndpoint 将端点地址解析为绝对地址（保留查询参数）
+func resolveEndpoint(base *url.URL, href string) (string, error) {
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return "", err
+	}
+	endpoint := base.ResolveReference(ref)
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return "", errors.New("invalid webmention endpoint")
+	}
+	return endpoint.String(), nil
+}
+
+// hasRel 判断以空格分隔的 rel 值中是否包含指定关系
+func hasRel(rels, rel string) bool {
+	for _, r := range strings.Fields(rels) {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	model "github.com/lin-snow/ech0/internal/model/webmention"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	repository "github.com/lin-snow/ech0/internal/repository/webmention"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	settingService "github.com/lin-snow/ech0/internal/service/setting"
+	"github.com/lin-snow/ech0/internal/transaction"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	workerUtil "github.com/lin-snow/ech0/internal/util/worker"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type WebmentionService struct {
+	txManager            transaction.TransactionManager
+	webmentionRepository repository.WebmentionRepositoryInterface
+	echoRepository       echoRepository.EchoRepositoryInterface
+	commonService        commonService.CommonServiceInterface
+	settingService       settingService.SettingServiceInterface
+}
+
+var (
+	// httpClient 获取远程页面与发送 Webmention 使用的 HTTP 客户端（拒绝内网地址）
+	httpClient = httpUtil.NewSafeClient(model.RequestTimeout)
+	// verifyPool 验证收到的 Webmention 的任务池
+	verifyPool = workerUtil.NewPool(model.Workers, model.QueueSize)
+	// sendPool 向外发送 Webmention 的任务池
+	sendPool = workerUtil.NewPool(model.Workers, model.QueueSize)
+)
+
+func NewWebmentionService(
+	tm transaction.TransactionManager,
+	webmentionRepository repository.WebmentionRepositoryInterface,
+	echoRepository echoRepository.EchoRepositoryInterface,
+	commonService commonService.CommonServiceInterface,
+	settingService settingService.SettingServiceInterface,
+) WebmentionServiceInterface {
+	return &WebmentionService{
+		txManager:            tm,
+		webmentionRepository: webmentionRepository,
+		echoRepository:       echoRepository,
+		commonService:        commonService,
+		settingService:       settingService,
+	}
+}
+
+// ReceiveWebmention 接收 Webmention，target 必须是本站公开的 Echo，source 在后台异步验证
+func (webmentionService *WebmentionService) ReceiveWebmention(host string, webmentionDto model.WebmentionDto) error {
+	source, err := url.Parse(strings.TrimSpace(webmentionDto.Source))
+	if err != nil || (source.Scheme != "http" && source.Scheme != "https") || source.Host == "" {
+		return errors.New(commonModel.INVALID_WEBMENTION_SOURCE)
+	}
+	target, err := url.Parse(strings.TrimSpace(webmentionDto.Target))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New(commonModel.INVALID_WEBMENTION_TARGET)
+	}
+	if source.String() == target.String() {
+		return errors.New(commonModel.INVALID_WEBMENTION_SOURCE)
+	}
+
+	// target 必须指向本站（请求的 Host 或系统设置中的服务器地址）
+	if !webmentionService.isLocalHost(host, target.Host) {
+		return errors.New(commonModel.INVALID_WEBMENTION_TARGET)
+	}
+
+	echoID, ok := parseEchoID(target.Path)
+	if !ok {
+		return errors.New(commonModel.INVALID_WEBMENTION_TARGET)
+	}
+	echo, err := webmentionService.echoRepository.GetEchosById(echoID)
+	if err != nil || echo == nil || echo.Private {
+		return errors.New(commonModel.INVALID_WEBMENTION_TARGET)
+	}
+
+	if !verifyPool.Submit(func() { webmentionService.verifyWebmention(echoID, source.String(), target.String()) }) {
+		return errors.New(commonModel.WEBMENTION_QUEUE_FULL)
+	}
+
+	return nil
+}
+
+// verifyWebmention 获取 source 并确认其中包含指向 target 的链接，source 已删除或不再包含链接时删除已有的 Webmention
+func (webmentionService *WebmentionService) verifyWebmention(echoID uint, source, target string) {
+	logger := logUtil.GetLogger().With(zap.String("source", source), zap.String("target", target))
+
+	req, err := http.NewRequest(http.MethodGet, source, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Accept", "text/html, */*;q=0.5")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logger.Warn("[Webmention 获取 source 失败]", zap.Error(err))
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusGone {
+		webmentionService.removeWebmention(source, target)
+		return
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		logger.Warn("[Webmention 获取 source 失败]", zap.Int("status", resp.StatusCode))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, model.MaxBodySize))
+	if err != nil {
+		logger.Warn("[Webmention 读取 source 失败]", zap.Error(err))
+		return
+	}
+
+	page := inspectSource(body, resp.Header.Get("Content-Type"), resp.Request.URL, target)
+	if !page.Linked {
+		logger.Info("[Webmention source 未包含 target 的链接]")
+		webmentionService.removeWebmention(source, target)
+		return
+	}
+
+	webmention := &model.Webmention{
+		EchoID:     echoID,
+		Source:     source,
+		Target:     target,
+		Title:      page.Title,
+		Excerpt:    page.Excerpt,
+		AuthorName: page.AuthorName,
+		Status:     model.StatusPending,
+		VerifiedAt: time.Now(),
+	}
+	if err := webmentionService.txManager.Run(func(ctx context.Context) error {
+		return webmentionService.webmentionRepository.SaveWebmention(ctx, webmention)
+	}); err != nil {
+		logger.Error("[Webmention 保存失败]", zap.Error(err))
+	}
+}
+
+// removeWebmention 删除 source 与 target 对应的 Webmention
+func (webmentionService *WebmentionService) removeWebmention(source, target string) {
+	if err := webmentionService.txManager.Run(func(ctx context.Context) error {
+		return webmentionService.webmentionRepository.DeleteWebmention(ctx, source, target)
+	}); err != nil {
+		logUtil.GetLogger().Error("[Webmention 删除失败]", zap.String("source", source), zap.Error(err))
+	}
+}
+
+// GetEchoWebmentions 获取 Echo 已通过审核的 Webmention
+func (webmentionService *WebmentionService) GetEchoWebmentions(echoID uint) []model.Webmention {
+	webmentions, err := webmentionService.webmentionRepository.GetWebmentionsByEchoID(echoID, model.StatusApproved)
+	if err != nil {
+		logUtil.GetLogger().Error("[Webmention 获取失败]", zap.Uint("echo_id", echoID), zap.Error(err))
+		return nil
+	}
+	return webmentions
+}
+
+// DeleteEchoWebmentions 删除 Echo 收到的所有 Webmention
+func (webmentionService *WebmentionService) DeleteEchoWebmentions(echoID uint) {
+	if err := webmentionService.txManager.Run(func(ctx context.Context) error {
+		return webmentionService.webmentionRepository.DeleteWebmentionsByEchoID(ctx, echoID)
+	}); err != nil {
+		logUtil.GetLogger().Error("[Webmention 删除失败]", zap.Uint("echo_id", echoID), zap.Error(err))
+	}
+}
+
+// GetWebmentions 分页获取 Webmention（管理员审核）
+func (webmentionService *WebmentionService) GetWebmentions(user userModel.User, webmentionQueryDto model.WebmentionQueryDto) (commonModel.PageQueryResult[[]model.Webmention], error) {
+	if err := authz.Authorize(user, authz.PermWebmentionModerate); err != nil {
+		return commonModel.PageQueryResult[[]model.Webmention]{}, err
+	}
+
+	if webmentionQueryDto.Status != "" && !isValidStatus(webmentionQueryDto.Status) {
+		return commonModel.PageQueryResult[[]model.Webmention]{}, errors.New(commonModel.INVALID_WEBMENTION_STATUS)
+	}
+	if webmentionQueryDto.Page < 1 {
+		webmentionQueryDto.Page = 1
+	}
+	if webmentionQueryDto.PageSize < 1 || webmentionQueryDto.PageSize > 100 {
+		webmentionQueryDto.PageSize = 10
+	}
+
+	webmentions, total, err := webmentionService.webmentionRepository.GetWebmentionsByPage(webmentionQueryDto.Page, webmentionQueryDto.PageSize, webmentionQueryDto.Status)
+	if err != nil {
+		return commonModel.PageQueryResult[[]model.Webmention]{}, err
+	}
+
+	return commonModel.PageQueryResult[[]model.Webmention]{
+		Items: webmentions,
+		Total: total,
+	}, nil
+}
+
+// UpdateWebmentionStatus 审核 Webmention
+func (webmentionService *WebmentionService) UpdateWebmentionStatus(user userModel.User, id uint, status model.Status) error {
+	if err := authz.Authorize(user, authz.PermWebmentionModerate); err != nil {
+		return err
+	}
+	if !isValidStatus(status) {
+		return errors.New(commonModel.INVALID_WEBMENTION_STATUS)
+	}
+
+	return webmentionService.txManager.Run(func(ctx context.Context) error {
+		if err := webmentionService.webmentionRepository.UpdateStatus(ctx, id, status); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(commonModel.WEBMENTION_NOT_FOUND)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteWebmention 删除 Webmention
+func (webmentionService *WebmentionService) DeleteWebmention(user userModel.User, id uint) error {
+	if err := authz.Authorize(user, authz.PermWebmentionModerate); err != nil {
+		return err
+	}
+
+	return webmentionService.txManager.Run(func(ctx context.Context) error {
+		if err := webmentionService.webmentionRepository.DeleteWebmentionByID(ctx, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(commonModel.WEBMENTION_NOT_FOUND)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// isLocalHost 判断 host 是否为本站（请求的 Host 或系统设置中的服务器地址）
+func (webmentionService *WebmentionService) isLocalHost(requestHost, host string) bool {
+	if strings.EqualFold(host, requestHost) {
+		return true
+	}
+	if serverURL, err := webmentionService.settingService.GetServerURL(); err == nil {
+		if parsed, err := url.Parse(serverURL); err == nil && strings.EqualFold(parsed.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEchoID 从 /echo/{id} 或 /webmention/echos/{id} 中解析 Echo ID
+func parseEchoID(path string) (uint, bool) {
+	path = strings.TrimSuffix(path, "/")
+	var idStr string
+	switch {
+	case strings.HasPrefix(path, "/echo/"):
+		idStr = strings.TrimPrefix(path, "/echo/")
+	case strings.HasPrefix(path, model.SourcePathPrefix):
+		idStr = strings.TrimPrefix(path, model.SourcePathPrefix)
+	default:
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// isValidStatus 判断审核状态是否有效
+func isValidStatus(status model.Status) bool {
+	switch status {
+	case model.StatusPending, model.StatusApproved, model.StatusRejected:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	model "github.com/lin-snow/ech0/internal/model/webmention"
+	repository "github.com/lin-snow/ech0/internal/repository/webmention"
+	"github.com/lin-snow/ech0/internal/transaction"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+const (
+	testEchoID = 7
+	testTarget = "https://ech0.example/echo/7"
+)
+
+// testSource 模拟 source 页面，返回的状态码与内容可以在测试中修改
+type testSource struct {
+	url         string
+	status      int
+	contentType string
+	body        string
+}
+
+func newTestSource(t *testing.T) *testSource {
+	t.Helper()
+
+	source := &testSource{status: http.StatusOK, contentType: "text/html; charset=utf-8"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", source.contentType)
+		w.WriteHeader(source.status)
+		_, _ = io.WriteString(w, source.body)
+	}))
+	t.Cleanup(server.Close)
+	source.url = server.URL + "/posts/reply"
+
+	return source
+}
+
+func newTestWebmentionService(t *testing.T) (*WebmentionService, repository.WebmentionRepositoryInterface) {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+
+	// 测试中的 source 在本机，使用不检查内网地址的客户端
+	original := httpClient
+	httpClient = &http.Client{Timeout: model.RequestTimeout}
+	t.Cleanup(func() { httpClient = original })
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "webmention.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.Webmention{}))
+
+	repo := repository.NewWebmentionRepository(db)
+	return &WebmentionService{
+		txManager:            transaction.NewTransactionManager(db),
+		webmentionRepository: repo,
+	}, repo
+}
+
+// getWebmention 获取 source 对应的 Webmention，不存在时返回 nil
+func getWebmention(t *testing.T, repo repository.WebmentionRepositoryInterface, source string) *model.Webmention {
+	t.Helper()
+
+	webmentions, _, err := repo.GetWebmentionsByPage(1, 10, "")
+	require.NoError(t, err)
+	for i := range webmentions {
+		if webmentions[i].Source == source {
+			return &webmentions[i]
+		}
+	}
+	return nil
+}
+
+func replyPage(title, text string) string {
+	return `<html><head><title>` + title + `</title><meta name="author" content="Bob"><meta name="description" content="` + text + `"></head>` +
+		`<body><p>` + text + ` <a href="` + testTarget + `#comments">回复</a></p></body></html>`
+}
+
+func TestInspectSource(t *testing.T) {
+	base, err := url.Parse("https://blog.example/posts/reply")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        sourcePage
+	}{
+		{
+			name:        "absolute link with metadata",
+			body:        `<html><head><title> Reply </title><meta name="author" content="Bob"><meta property="og:description" content="Nice post"></head><body><a href="https://ech0.example/echo/7/">x</a></body></html>`,
+			contentType: "text/html",
+			want:        sourcePage{Linked: true, Title: "Reply", Excerpt: "Nice post", AuthorName: "Bob"},
+		},
+		{
+			name:        "relative link",
+			body:        `<a href="//ech0.example/echo/7#c">x</a>`,
+			contentType: "text/html",
+			want:        sourcePage{Linked: true},
+		},
+		{
+			name:        "image source",
+			body:        `<img src="https://ech0.example/echo/7">`,
+			contentType: "text/html",
+			want:        sourcePage{Linked: true},
+		},
+		{
+			name:        "other echo",
+			body:        `<a href="https://ech0.example/echo/70">x</a>`,
+			contentType: "text/html",
+			want:        sourcePage{},
+		},
+		{
+			name:        "target only in text",
+			body:        `<p>https://ech0.example/echo/7</p>`,
+			contentType: "text/html",
+			want:        sourcePage{},
+		},
+		{
+			name:        "plain text",
+			body:        "see https://ech0.example/echo/7",
+			contentType: "text/plain",
+			want:        sourcePage{Linked: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, inspectSource([]byte(tt.body), tt.contentType, base, testTarget))
+		})
+	}
+}
+
+// 摘要按字符截断
+func TestInspectSource_TruncatesExcerpt(t *testing.T) {
+	base, err := url.Parse("https://blog.example/")
+	require.NoError(t, err)
+
+	long := make([]rune, model.MaxExcerptLength+10)
+	for i := range long {
+		long[i] = '长'
+	}
+	page := inspectSource([]byte(`<meta name="description" content="`+string(long)+`">`), "text/html", base, testTarget)
+	assert.Equal(t, string(long[:model.MaxExcerptLength])+"…", page.Excerpt)
+}
+
+// 验证通过的 Webmention 保存为待审核，source 不再链接或已删除时移除
+func TestVerifyWebmention(t *testing.T) {
+	service, repo := newTestWebmentionService(t)
+	source := newTestSource(t)
+
+	source.status = http.StatusInternalServerError
+	service.verifyWebmention(testEchoID, source.url, testTarget)
+	assert.Nil(t, getWebmention(t, repo, source.url))
+
+	source.status = http.StatusOK
+	source.body = `<html><body>没有链接</body></html>`
+	service.verifyWebmention(testEchoID, source.url, testTarget)
+	assert.Nil(t, getWebmention(t, repo, source.url))
+
+	source.body = replyPage("Reply", "写得好")
+	service.verifyWebmention(testEchoID, source.url, testTarget)
+	webmention := getWebmention(t, repo, source.url)
+	require.NotNil(t, webmention)
+	assert.Equal(t, uint(testEchoID), webmention.EchoID)
+	assert.Equal(t, "Reply", webmention.Title)
+	assert.Equal(t, "Bob", webmention.AuthorName)
+	assert.Equal(t, model.StatusPending, webmention.Status)
+
+	source.body = `<html><body>链接已删除</body></html>`
+	service.verifyWebmention(testEchoID, source.url, testTarget)
+	assert.Nil(t, getWebmention(t, repo, source.url))
+
+	source.body = replyPage("Reply", "写得好")
+	service.verifyWebmention(testEchoID, source.url, testTarget)
+	require.NotNil(t, getWebmention(t, repo, source.url))
+
+	source.status = http.StatusGone
+	service.verifyWebmention(testEchoID, source.url, testTarget)
+	assert.Nil(t, getWebmention(t, repo, source.url))
+}
+
+// 重新验证时内容未变化则保留审核状态，内容变化则重新待审核
+func TestVerifyWebmention_ModerationOnUpdate(t *testing.T) {
+	service, repo := newTestWebmentionService(t)
+	source := newTestSource(t)
+
+	source.body = replyPage("Reply", "写得好")
+	service.verifyWebmention(testEchoID, source.url, testTarget)
+	webmention := getWebmention(t, repo, source.url)
+	require.NotNil(t, webmention)
+
+	require.NoError(t, repo.UpdateStatus(t.Context(), webmention.ID, model.StatusApproved))
+	service.verifyWebmention(testEchoID, source.url, testTarget)
+	webmention = getWebmention(t, repo, source.url)
+	assert.Equal(t, model.StatusApproved, webmention.Status)
+
+	source.body = replyPage("Reply", "广告内容")
+	service.verifyWebmention(testEchoID, source.url, testTarget)
+	webmention = getWebmention(t, repo, source.url)
+	assert.Equal(t, model.StatusPending, webmention.Status)
+	assert.Equal(t, "广告内容", webmention.Excerpt)
+
+	require.NoError(t, repo.UpdateStatus(t.Context(), webmention.ID, model.StatusRejected))
+	source.body = replyPage("Updated reply", "广告内容")
+	service.verifyWebmention(testEchoID, source.url, testTarget)
+	webmention = getWebmention(t, repo, source.url)
+	assert.Equal(t, model.StatusPending, webmention.Status)
+	assert.Equal(t, "Updated reply", webmention.Title)
+}
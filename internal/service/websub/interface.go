@@ -0,0 +1,11 @@
+package service
+
+import model "github.com/lin-snow/ech0/internal/model/websub"
+
+type WebSubServiceInterface interface {
+	// HandleSubscription 处理订阅/取消订阅请求，校验通过后异步验证订阅者意图
+	HandleSubscription(host string, subscriptionDto model.SubscriptionDto) error
+
+	// NotifySubscribers 通知投递协程检查订阅源变化并推送给订阅者
+	NotifySubscribers()
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/websub"
+	repository "github.com/lin-snow/ech0/internal/repository/websub"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	"github.com/lin-snow/ech0/internal/transaction"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	workerUtil "github.com/lin-snow/ech0/internal/util/worker"
+	"go.uber.org/zap"
+)
+
+type WebSubService struct {
+	txManager        transaction.TransactionManager
+	webSubRepository repository.WebSubRepositoryInterface
+	commonService    commonService.CommonServiceInterface
+}
+
+var (
+	// notifyWorkerOnce 保证整个进程只启动一个推送协程
+	notifyWorkerOnce sync.Once
+	// notifyCh 订阅源可能发生变化时唤醒推送协程（多次通知会合并）
+	notifyCh = make(chan struct{}, 1)
+	// httpClient 验证订阅与推送内容使用的 HTTP 客户端（拒绝内网地址的回调）
+	httpClient = httpUtil.NewSafeClient(model.RequestTimeout)
+	// verifyPool 验证订阅者意图的任务池
+	verifyPool = workerUtil.NewPool(model.VerifyWorkers, model.VerifyQueueSize)
+)
+
+func NewWebSubService(
+	tm transaction.TransactionManager,
+	webSubRepository repository.WebSubRepositoryInterface,
+	commonService commonService.CommonServiceInterface,
+) WebSubServiceInterface {
+	webSubService := &WebSubService{
+		txManager:        tm,
+		webSubRepository: webSubRepository,
+		commonService:    commonService,
+	}
+
+	notifyWorkerOnce.Do(func() {
+		go webSubService.runNotifyWorker()
+	})
+
+	return webSubService
+}
+
+// HandleSubscription 处理订阅/取消订阅请求，校验通过后异步验证订阅者意图
+func (webSubService *WebSubService) HandleSubscription(host string, subscriptionDto model.SubscriptionDto) error {
+	if subscriptionDto.Mode != model.ModeSubscribe && subscriptionDto.Mode != model.ModeUnsubscribe {
+		return errors.New(commonModel.INVALID_WEBSUB_MODE)
+	}
+
+	// 回调地址只支持 http/https
+	callback, err := url.Parse(subscriptionDto.Callback)
+	if err != nil || (callback.Scheme != "http" && callback.Scheme != "https") || callback.Host == "" {
+		return errors.New(commonModel.INVALID_WEBSUB_CALLBACK)
+	}
+
+	if len(subscriptionDto.Secret) > model.MaxSecretLength {
+		return errors.New(commonModel.INVALID_WEBSUB_SECRET)
+	}
+
+	// topic 必须是本站可生成的订阅源
+	topic, err := url.Parse(subscriptionDto.Topic)
+	if err != nil || !strings.EqualFold(topic.Host, host) {
+		return errors.New(commonModel.INVALID_WEBSUB_TOPIC)
+	}
+	if subscriptionDto.Mode == model.ModeSubscribe {
+		if _, err := webSubService.commonService.GenerateFeedByURL(subscriptionDto.Topic); err != nil {
+			return errors.New(commonModel.INVALID_WEBSUB_TOPIC)
+		}
+	}
+
+	// 订阅有效期
+	leaseSeconds := subscriptionDto.LeaseSeconds
+	if leaseSeconds <= 0 {
+		leaseSeconds = model.DefaultLeaseSeconds
+	}
+	leaseSeconds = min(max(leaseSeconds, model.MinLeaseSeconds), model.MaxLeaseSeconds)
+	subscriptionDto.LeaseSeconds = leaseSeconds
+
+	if !verifyPool.Submit(func() { webSubService.verifyIntent(subscriptionDto) }) {
+		return errors.New(commonModel.WEBSUB_QUEUE_FULL)
+	}
+
+	return nil
+}
+
+// NotifySubscribers 通知推送协程检查订阅源变化并推送给订阅者
+func (webSubService *WebSubService) NotifySubscribers() {
+	select {
+	case notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// verifyIntent 向订阅者的回调地址发送验证请求，订阅者原样返回 challenge 后才保存或删除订阅
+func (webSubService *WebSubService) verifyIntent(subscriptionDto model.SubscriptionDto) {
+	logger := logUtil.GetLogger().With(
+		zap.String("mode", string(subscriptionDto.Mode)),
+		zap.String("topic", subscriptionDto.Topic),
+		zap.String("callback", subscriptionDto.Callback))
+
+	challenge, err := newChallenge()
+	if err != nil {
+		logger.Error("[WebSub 生成 challenge 失败]", zap.Error(err))
+		return
+	}
+
+	callback, _ := url.Parse(subscriptionDto.Callback)
+	query := callback.Query()
+	query.Set("hub.mode", string(subscriptionDto.Mode))
+	query.Set("hub.topic", subscriptionDto.Topic)
+	query.Set("hub.challenge", challenge)
+	if subscriptionDto.Mode == model.ModeSubscribe {
+		query.Set("hub.lease_seconds", strconv.Itoa(subscriptionDto.LeaseSeconds))
+	}
+	callback.RawQuery = query.Encode()
+
+	resp, err := httpClient.Get(callback.String())
+	if err != nil {
+		logger.Warn("[WebSub 验证订阅失败]", zap.Error(err))
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, int64(len(challenge)+1)))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || string(body) != challenge {
+		logger.Warn("[WebSub 订阅者未确认]", zap.Int("status", resp.StatusCode))
+		return
+	}
+
+	if subscriptionDto.Mode == model.ModeUnsubscribe {
+		if err := webSubService.txManager.Run(func(ctx context.Context) error {
+			return webSubService.webSubRepository.DeleteSubscription(ctx, subscriptionDto.Topic, subscriptionDto.Callback)
+		}); err != nil {
+			logger.Error("[WebSub 删除订阅失败]", zap.Error(err))
+		}
+		return
+	}
+
+	// 记录当前内容的摘要，只有内容变化后才推送
+	var contentHash string
+	if feed, err := webSubService.commonService.GenerateFeedByURL(subscriptionDto.Topic); err == nil {
+		contentHash = hashContent(feed.Content)
+	}
+
+	subscription := &model.Subscription{
+		Topic:        subscriptionDto.Topic,
+		Callback:     subscriptionDto.Callback,
+		Secret:       subscriptionDto.Secret,
+		LeaseSeconds: subscriptionDto.LeaseSeconds,
+		ExpiresAt:    time.Now().Add(time.Duration(subscriptionDto.LeaseSeconds) * time.Second),
+		ContentHash:  contentHash,
+	}
+	if err := webSubService.txManager.Run(func(ctx context.Context) error {
+		return webSubService.webSubRepository.SaveSubscription(ctx, subscription)
+	}); err != nil {
+		logger.Error("[WebSub 保存订阅失败]", zap.Error(err))
+	}
+}
+
+// runNotifyWorker 在收到通知或定时检查订阅源变化
+func (webSubService *WebSubService) runNotifyWorker() {
+	ticker := time.NewTicker(model.NotifyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-notifyCh:
+		}
+		webSubService.distribute()
+	}
+}
+
+// distribute 向内容发生变化的订阅推送最新的订阅源（推送失败时保留旧摘要，下次检查时重试）
+func (webSubService *WebSubService) distribute() {
+	now := time.Now()
+	if err := webSubService.txManager.Run(func(ctx context.Context) error {
+		return webSubService.webSubRepository.DeleteExpiredSubscriptions(ctx, now)
+	}); err != nil {
+		logUtil.GetLogger().Error("[WebSub 清理过期订阅失败]", zap.Error(err))
+	}
+
+	subscriptions, err := webSubService.webSubRepository.GetActiveSubscriptions(now)
+	if err != nil {
+		logUtil.GetLogger().Error("[WebSub 获取订阅失败]", zap.Error(err))
+		return
+	}
+
+	// 同一 topic 只生成一次
+	feeds := make(map[string]commonModel.Feed)
+	for _, subscription := range subscriptions {
+		feed, ok := feeds[subscription.Topic]
+		if !ok {
+			if feed, err = webSubService.commonService.GenerateFeedByURL(subscription.Topic); err != nil {
+				logUtil.GetLogger().Warn("[WebSub 生成订阅源失败]", zap.String("topic", subscription.Topic), zap.Error(err))
+				continue
+			}
+			feeds[subscription.Topic] = feed
+		}
+
+		contentHash := hashContent(feed.Content)
+		if contentHash == subscription.ContentHash {
+			continue
+		}
+
+		if err := webSubService.push(&subscription, feed); err != nil {
+			logUtil.GetLogger().Warn("[WebSub 推送失败]", zap.String("callback", subscription.Callback), zap.Error(err))
+			continue
+		}
+
+		if err := webSubService.txManager.Run(func(ctx context.Context) error {
+			return webSubService.webSubRepository.UpdateContentHash(ctx, subscription.ID, contentHash)
+		}); err != nil {
+			logUtil.GetLogger().Error("[WebSub 更新推送记录失败]", zap.Error(err))
+		}
+	}
+}
+
+// push 向订阅者推送订阅源内容，订阅者返回 410 时删除订阅
+func (webSubService *WebSubService) push(subscription *model.Subscription, feed commonModel.Feed) error {
+	req, err := http.NewRequest(http.MethodPost, subscription.Callback, bytes.NewReader(feed.Content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", feed.ContentType)
+	req.Header.Set("Link", fmt.Sprintf("<%s>; rel=\"hub\", <%s>; rel=\"self\"", feed.HubURL, subscription.Topic))
+	if subscription.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(subscription.Secret))
+		mac.Write(feed.Content)
+		req.Header.Set("X-Hub-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode == http.StatusGone {
+		return webSubService.txManager.Run(func(ctx context.Context) error {
+			return webSubService.webSubRepository.DeleteSubscription(ctx, subscription.Topic, subscription.Callback)
+		})
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("push failed: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// newChallenge 生成随机的 challenge
+func newChallenge() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashContent 计算内容摘要
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
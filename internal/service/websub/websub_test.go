@@ -0,0 +1,244 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/websub"
+	repository "github.com/lin-snow/ech0/internal/repository/websub"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	"github.com/lin-snow/ech0/internal/transaction"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+)
+
+const (
+	testHost  = "ech0.example"
+	testTopic = "https://ech0.example/api/feed/atom"
+)
+
+// fakeCommonService 只生成 testTopic 的订阅源，内容可以在测试中修改
+type fakeCommonService struct {
+	commonService.CommonServiceInterface
+	content string
+}
+
+func (s *fakeCommonService) GenerateFeedByURL(feedURL string) (commonModel.Feed, error) {
+	if feedURL != testTopic {
+		return commonModel.Feed{}, errors.New(commonModel.INVALID_WEBSUB_TOPIC)
+	}
+	return commonModel.Feed{
+		Content:     []byte(s.content),
+		ContentType: "application/atom+xml",
+		SelfURL:     testTopic,
+		HubURL:      "https://ech0.example/api/websub",
+	}, nil
+}
+
+// testSubscriber 模拟订阅者：按需确认订阅，并记录收到的推送
+type testSubscriber struct {
+	url     string
+	confirm bool
+	status  int
+
+	mu         sync.Mutex
+	verifyURLs []url.Values
+	pushes     []*http.Request
+	bodies     []string
+}
+
+func newTestSubscriber(t *testing.T) *testSubscriber {
+	t.Helper()
+
+	subscriber := &testSubscriber{confirm: true, status: http.StatusNoContent}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subscriber.mu.Lock()
+		defer subscriber.mu.Unlock()
+
+		if r.Method == http.MethodGet {
+			query := r.URL.Query()
+			subscriber.verifyURLs = append(subscriber.verifyURLs, query)
+			if subscriber.confirm {
+				_, _ = io.WriteString(w, query.Get("hub.challenge"))
+			} else {
+				_, _ = io.WriteString(w, "nope")
+			}
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		subscriber.pushes = append(subscriber.pushes, r)
+		subscriber.bodies = append(subscriber.bodies, string(body))
+		w.WriteHeader(subscriber.status)
+	}))
+	t.Cleanup(server.Close)
+	subscriber.url = server.URL + "/callback"
+
+	return subscriber
+}
+
+func newTestWebSubService(t *testing.T) (*WebSubService, repository.WebSubRepositoryInterface, *fakeCommonService) {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+
+	// 测试中的订阅者在本机，使用不检查内网地址的客户端
+	original := httpClient
+	httpClient = &http.Client{Timeout: model.RequestTimeout}
+	t.Cleanup(func() { httpClient = original })
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "websub.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.Subscription{}))
+
+	repo := repository.NewWebSubRepository(db)
+	feeds := &fakeCommonService{content: "<feed>v1</feed>"}
+	return &WebSubService{
+		txManager:        transaction.NewTransactionManager(db),
+		webSubRepository: repo,
+		commonService:    feeds,
+	}, repo, feeds
+}
+
+func activeSubscriptions(t *testing.T, repo repository.WebSubRepositoryInterface) []model.Subscription {
+	t.Helper()
+
+	subscriptions, err := repo.GetActiveSubscriptions(time.Now())
+	require.NoError(t, err)
+	return subscriptions
+}
+
+// 模式、回调地址、密钥和 topic 不合法的请求被拒绝
+func TestHandleSubscription_RejectsInvalidRequests(t *testing.T) {
+	service, _, _ := newTestWebSubService(t)
+	valid := model.SubscriptionDto{Mode: model.ModeSubscribe, Topic: testTopic, Callback: "https://reader.example/cb"}
+
+	tests := []struct {
+		name   string
+		modify func(dto *model.SubscriptionDto)
+		err    string
+	}{
+		{"mode", func(dto *model.SubscriptionDto) { dto.Mode = "publish" }, commonModel.INVALID_WEBSUB_MODE},
+		{"callback scheme", func(dto *model.SubscriptionDto) { dto.Callback = "ftp://reader.example/cb" }, commonModel.INVALID_WEBSUB_CALLBACK},
+		{"callback host", func(dto *model.SubscriptionDto) { dto.Callback = "https:///cb" }, commonModel.INVALID_WEBSUB_CALLBACK},
+		{"secret", func(dto *model.SubscriptionDto) { dto.Secret = string(make([]byte, model.MaxSecretLength+1)) }, commonModel.INVALID_WEBSUB_SECRET},
+		{"other host", func(dto *model.SubscriptionDto) { dto.Topic = "https://other.example/api/feed/atom" }, commonModel.INVALID_WEBSUB_TOPIC},
+		{"unknown feed", func(dto *model.SubscriptionDto) { dto.Topic = "https://ech0.example/api/unknown" }, commonModel.INVALID_WEBSUB_TOPIC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dto := valid
+			tt.modify(&dto)
+			assert.EqualError(t, service.HandleSubscription(testHost, dto), tt.err)
+		})
+	}
+}
+
+// 订阅者原样返回 challenge 后才保存订阅，有效期限制在允许的范围内
+func TestVerifyIntent_Subscribe(t *testing.T) {
+	service, repo, _ := newTestWebSubService(t)
+	subscriber := newTestSubscriber(t)
+
+	subscriber.confirm = false
+	service.verifyIntent(model.SubscriptionDto{Mode: model.ModeSubscribe, Topic: testTopic, Callback: subscriber.url, LeaseSeconds: model.MinLeaseSeconds})
+	assert.Empty(t, activeSubscriptions(t, repo))
+
+	subscriber.confirm = true
+	service.verifyIntent(model.SubscriptionDto{Mode: model.ModeSubscribe, Topic: testTopic, Callback: subscriber.url, LeaseSeconds: model.MinLeaseSeconds})
+
+	require.Len(t, subscriber.verifyURLs, 2)
+	query := subscriber.verifyURLs[1]
+	assert.Equal(t, "subscribe", query.Get("hub.mode"))
+	assert.Equal(t, testTopic, query.Get("hub.topic"))
+	assert.Equal(t, "3600", query.Get("hub.lease_seconds"))
+	assert.NotEqual(t, subscriber.verifyURLs[0].Get("hub.challenge"), query.Get("hub.challenge"))
+
+	subscriptions := activeSubscriptions(t, repo)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, subscriber.url, subscriptions[0].Callback)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), subscriptions[0].ExpiresAt, 5*time.Second)
+	// 订阅时的内容不需要推送
+	assert.Equal(t, hashContent([]byte("<feed>v1</feed>")), subscriptions[0].ContentHash)
+}
+
+// 取消订阅同样需要订阅者确认
+func TestVerifyIntent_Unsubscribe(t *testing.T) {
+	service, repo, _ := newTestWebSubService(t)
+	subscriber := newTestSubscriber(t)
+	dto := model.SubscriptionDto{Mode: model.ModeSubscribe, Topic: testTopic, Callback: subscriber.url, LeaseSeconds: model.MinLeaseSeconds}
+	service.verifyIntent(dto)
+	require.Len(t, activeSubscriptions(t, repo), 1)
+
+	dto.Mode = model.ModeUnsubscribe
+	subscriber.confirm = false
+	service.verifyIntent(dto)
+	assert.Len(t, activeSubscriptions(t, repo), 1)
+
+	subscriber.confirm = true
+	service.verifyIntent(dto)
+	assert.Empty(t, activeSubscriptions(t, repo))
+	assert.Empty(t, subscriber.verifyURLs[2].Get("hub.lease_seconds"))
+}
+
+// 只有内容变化时才推送，推送附带 Link 和 HMAC 签名，失败时下次重试
+func TestDistribute_PushesChangedContent(t *testing.T) {
+	service, repo, feeds := newTestWebSubService(t)
+	subscriber := newTestSubscriber(t)
+	service.verifyIntent(model.SubscriptionDto{Mode: model.ModeSubscribe, Topic: testTopic, Callback: subscriber.url, Secret: "s3cret", LeaseSeconds: model.MinLeaseSeconds})
+	require.Len(t, activeSubscriptions(t, repo), 1)
+
+	service.distribute()
+	assert.Empty(t, subscriber.pushes)
+
+	// 订阅者暂时失败时保留旧摘要
+	feeds.content = "<feed>v2</feed>"
+	subscriber.status = http.StatusInternalServerError
+	service.distribute()
+	require.Len(t, subscriber.pushes, 1)
+
+	subscriber.status = http.StatusNoContent
+	service.distribute()
+	require.Len(t, subscriber.pushes, 2)
+
+	push := subscriber.pushes[1]
+	assert.Equal(t, "<feed>v2</feed>", subscriber.bodies[1])
+	assert.Equal(t, "application/atom+xml", push.Header.Get("Content-Type"))
+	assert.Equal(t, `<https://ech0.example/api/websub>; rel="hub", <`+testTopic+`>; rel="self"`, push.Header.Get("Link"))
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte("<feed>v2</feed>"))
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), push.Header.Get("X-Hub-Signature"))
+
+	service.distribute()
+	assert.Len(t, subscriber.pushes, 2)
+}
+
+// 订阅者返回 410 时删除订阅
+func TestDistribute_RemovesGoneSubscriber(t *testing.T) {
+	service, repo, feeds := newTestWebSubService(t)
+	subscriber := newTestSubscriber(t)
+	service.verifyIntent(model.SubscriptionDto{Mode: model.ModeSubscribe, Topic: testTopic, Callback: subscriber.url, LeaseSeconds: model.MinLeaseSeconds})
+
+	feeds.content = "<feed>v2</feed>"
+	subscriber.status = http.StatusGone
+	service.distribute()
+
+	assert.Len(t, subscriber.pushes, 1)
+	assert.Empty(t, activeSubscriptions(t, repo))
+}
@@ -5,7 +5,7 @@ import (
 	"encoding/hex"
 )
 
-// MD5Encrypt 对内容进行 MD5 编码
+// MD5Encrypt 对内容进行 MD5 编码（仅用于校验旧版本的密码哈希，新密码请使用 HashPassword）
 func MD5Encrypt(text string) string {
 	hash := md5.New()
 	hash.Write([]byte(text))
@@ -0,0 +1,125 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// argon2id 参数（RFC 9106 推荐的低内存配置）
+const (
+	argon2Memory  uint32 = 64 * 1024 // 内存开销（KiB）
+	argon2Time    uint32 = 3         // 迭代次数
+	argon2Threads uint8  = 4         // 并行度
+	argon2SaltLen        = 16        // 盐的字节数
+	argon2KeyLen  uint32 = 32        // 哈希的字节数
+
+	argon2Prefix = "$argon2id$"
+)
+
+// errInvalidHash 密码哈希格式无效
+var errInvalidHash = errors.New("invalid password hash")
+
+// HashPassword 使用 argon2id 计算密码哈希，结果使用 PHC 字符串格式编码（包含算法、参数和盐）
+//
+// 格式: $argon2id$v=19$m=65536,t=3,p=4$<盐>$<哈希>
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2Prefix, argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// VerifyPassword 校验密码是否与哈希匹配，支持 argon2id、bcrypt 和旧版本的 MD5 哈希
+func VerifyPassword(password, encoded string) bool {
+	switch {
+	case strings.HasPrefix(encoded, argon2Prefix):
+		return verifyArgon2id(password, encoded)
+	case isBcryptHash(encoded):
+		return bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)) == nil
+	case isMD5Hash(encoded):
+		return subtle.ConstantTimeCompare([]byte(MD5Encrypt(password)), []byte(strings.ToLower(encoded))) == 1
+	default:
+		return false
+	}
+}
+
+// NeedsRehash 检查密码哈希是否需要使用当前的算法和参数重新计算
+func NeedsRehash(encoded string) bool {
+	if !strings.HasPrefix(encoded, argon2Prefix) {
+		return true
+	}
+
+	version, memory, time, threads, _, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return version != argon2.Version || memory != argon2Memory || time != argon2Time ||
+		threads != argon2Threads || uint32(len(key)) != argon2KeyLen
+}
+
+// verifyArgon2id 使用哈希中记录的参数重新计算并比较
+func verifyArgon2id(password, encoded string) bool {
+	version, memory, time, threads, salt, key, err := decodeArgon2id(encoded)
+	if err != nil || version != argon2.Version {
+		return false
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(computed, key) == 1
+}
+
+// decodeArgon2id 解析 PHC 格式的 argon2id 哈希
+func decodeArgon2id(encoded string) (version int, memory, time uint32, threads uint8, salt, key []byte, err error) {
+	// "", "argon2id", "v=19", "m=65536,t=3,p=4", "<盐>", "<哈希>"
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		err = errInvalidHash
+		return
+	}
+
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return
+	}
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return
+	}
+	if memory == 0 || time == 0 || threads == 0 || len(key) == 0 {
+		err = errInvalidHash
+	}
+	return
+}
+
+// isBcryptHash 检查是否为 bcrypt 哈希
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// isMD5Hash 检查是否为旧版本使用的 MD5 哈希（32 位十六进制字符串）
+func isMD5Hash(encoded string) bool {
+	if len(encoded) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(encoded)
+	return err == nil
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 生成的哈希使用 PHC 格式记录算法、参数和盐，可以校验原密码且不需要重新计算
+func TestHashPassword_RoundTrip(t *testing.T) {
+	encoded, err := HashPassword("correct horse battery staple")
+	require.NoError(t, err)
+
+	parts := strings.Split(encoded, "$")
+	require.Len(t, parts, 6)
+	assert.Equal(t, "argon2id", parts[1])
+	assert.Equal(t, fmt.Sprintf("v=%d", argon2.Version), parts[2])
+	assert.Equal(t, "m=65536,t=3,p=4", parts[3])
+
+	version, memory, time, threads, salt, key, err := decodeArgon2id(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, argon2.Version, version)
+	assert.Equal(t, argon2Memory, memory)
+	assert.Equal(t, argon2Time, time)
+	assert.Equal(t, argon2Threads, threads)
+	assert.Len(t, salt, argon2SaltLen)
+	assert.Len(t, key, int(argon2KeyLen))
+
+	assert.True(t, VerifyPassword("correct horse battery staple", encoded))
+	assert.False(t, VerifyPassword("correct horse battery stapler", encoded))
+	assert.False(t, VerifyPassword("", encoded))
+	assert.False(t, NeedsRehash(encoded))
+
+	// 每次使用不同的盐
+	other, err := HashPassword("correct horse battery staple")
+	require.NoError(t, err)
+	assert.NotEqual(t, encoded, other)
+}
+
+// 使用哈希中记录的参数校验，参数与当前配置不同的哈希需要重新计算
+func TestVerifyPassword_Argon2idWithOtherParameters(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	key := argon2.IDKey([]byte("password"), salt, 1, 8*1024, 1, 16)
+	encoded := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, 8*1024, 1, 1,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+
+	assert.True(t, VerifyPassword("password", encoded))
+	assert.False(t, VerifyPassword("Password", encoded))
+	assert.True(t, NeedsRehash(encoded))
+}
+
+// 旧版本的 MD5 和 bcrypt 哈希可以校验，但都需要升级为 argon2id
+func TestVerifyPassword_LegacyHashes(t *testing.T) {
+	md5Hash := MD5Encrypt("password123")
+	assert.True(t, VerifyPassword("password123", md5Hash))
+	assert.True(t, VerifyPassword("password123", strings.ToUpper(md5Hash)))
+	assert.False(t, VerifyPassword("password124", md5Hash))
+	assert.True(t, NeedsRehash(md5Hash))
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	assert.True(t, VerifyPassword("password123", string(bcryptHash)))
+	assert.False(t, VerifyPassword("password124", string(bcryptHash)))
+	assert.True(t, NeedsRehash(string(bcryptHash)))
+}
+
+// 格式无效的哈希不能通过校验
+func TestVerifyPassword_InvalidHashes(t *testing.T) {
+	for _, encoded := range []string{
+		"",
+		"password123",
+		"$argon2id$v=19$m=65536,t=3,p=4$c2FsdA",
+		"$argon2id$v=19$m=0,t=3,p=4$c2FsdA$a2V5",
+		"$argon2id$v=16$m=65536,t=3,p=4$c2FsdA$a2V5",
+		"$argon2id$v=19$m=65536,t=3,p=4$!!!$a2V5",
+		"$argon2i$v=19$m=65536,t=3,p=4$c2FsdA$a2V5",
+		"zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz",
+	} {
+		assert.False(t, VerifyPassword("", encoded), encoded)
+		assert.False(t, VerifyPassword("password123", encoded), encoded)
+		assert.True(t, NeedsRehash(encoded), encoded)
+	}
+}
@@ -0,0 +1,128 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress 目标地址是内网、回环或链路本地等不能从公网访问的地址
+var ErrPrivateAddress = errors.New("禁止访问内网地址")
+
+// lookupTimeout CheckPublicURL 解析主机名的超时时间
+const lookupTimeout = 5 * time.Second
+
+// reservedNetworks 标准库没有覆盖、但同样不能从公网访问的地址段
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // 本网络
+	"100.64.0.0/10", // 运营商级 NAT
+	"192.0.0.0/24",  // IETF 协议分配
+	"198.18.0.0/15", // 基准测试
+	"240.0.0.0/4",   // 保留
+	"64:ff9b::/96",  // NAT64，可能映射到内网 IPv4
+)
+
+// IsPublicIP 判断 IP 是否为可以从公网访问的单播地址
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewSafeClient 创建访问远程地址（由外部请求提供的回调、source、actor 等）的 HTTP 客户端
+// 在建立连接时检查解析后的 IP，拒绝内网、回环和链路本地地址，重定向和 DNS 重绑定同样无法绕过
+func NewSafeClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   denyPrivateAddress,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // 不使用环境变量中的代理，否则只能检查代理的地址
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+	}
+}
+
+// CheckPublicURL 检查地址是否为 HTTP(S) 地址，且主机名解析到的所有 IP 都可以从公网访问
+// 用于在发起请求前尽早拒绝内网地址（例如对方声明的实例地址），连接时 NewSafeClient 仍会再次检查
+func CheckPublicURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return errors.New("仅支持 HTTP 和 HTTPS 地址")
+	}
+	host := parsed.Hostname()
+	if host == "" {
+		return errors.New("地址缺少主机名")
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return ErrPrivateAddress
+		}
+	}
+
+	return nil
+}
+
+// denyPrivateAddress 在建立连接前检查目标地址
+func denyPrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicIP(net.ParseIP(host)) {
+		return ErrPrivateAddress
+	}
+
+	return nil
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks
+}
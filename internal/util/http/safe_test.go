@@ -0,0 +1,54 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "64:ff9b::a00:1", "224.0.0.1"} {
+		assert.False(t, IsPublicIP(net.ParseIP(addr)), addr)
+	}
+	for _, addr := range []string{"1.1.1.1", "93.184.216.34", "2606:4700:4700::1111"} {
+		assert.True(t, IsPublicIP(net.ParseIP(addr)), addr)
+	}
+	assert.False(t, IsPublicIP(nil))
+}
+
+// 连接回环地址（包括重定向到回环地址）时在建立连接前被拒绝
+func TestNewSafeClient_RejectsLoopback(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	client := NewSafeClient(time.Second)
+	_, err := client.Get(server.URL)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrPrivateAddress))
+
+	_, err = client.Get(fmt.Sprintf("http://localhost:%d", server.Listener.Addr().(*net.TCPAddr).Port))
+	assert.True(t, errors.Is(err, ErrPrivateAddress))
+
+	assert.Zero(t, requests)
+}
+
+// 发起请求前检查地址，解析到内网的主机名和非 HTTP(S) 地址被拒绝
+func TestCheckPublicURL(t *testing.T) {
+	for _, rawURL := range []string{"http://127.0.0.1:8080", "https://localhost", "http://[::1]/", "http://169.254.169.254/latest", "https://10.0.0.1"} {
+		assert.True(t, errors.Is(CheckPublicURL(rawURL), ErrPrivateAddress), rawURL)
+	}
+	for _, rawURL := range []string{"ftp://1.1.1.1", "https://", "://bad"} {
+		assert.Error(t, CheckPublicURL(rawURL), rawURL)
+	}
+	assert.NoError(t, CheckPublicURL("https://1.1.1.1/api/connect"))
+}
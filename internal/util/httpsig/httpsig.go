@@ -0,0 +1,254 @@
+package util
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MaxClockSkew 允许的签名时间误差
+const MaxClockSkew = 12 * time.Hour
+
+// Signature 定义解析后的 Signature 请求头
+type Signature struct {
+	KeyID     string
+	Algorithm string
+	Headers   []string
+	Signature []byte
+}
+
+// GenerateKeyPair 生成 RSA 密钥对，返回 PEM 格式的私钥和公钥
+func GenerateKeyPair() (string, string, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", "", err
+	}
+
+	privateBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", "", err
+	}
+	publicBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	privatePem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes})
+	publicPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes})
+	return string(privatePem), string(publicPem), nil
+}
+
+// ParsePrivateKey 解析 PEM 格式的 RSA 私钥（支持 PKCS#1 与 PKCS#8）
+func ParsePrivateKey(privatePem string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privatePem))
+	if block == nil {
+		return nil, errors.New("invalid private key pem")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not rsa")
+	}
+	return rsaKey, nil
+}
+
+// ParsePublicKey 解析 PEM 格式的 RSA 公钥（支持 PKIX 与 PKCS#1）
+func ParsePublicKey(publicPem string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicPem))
+	if block == nil {
+		return nil, errors.New("invalid public key pem")
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not rsa")
+	}
+	return rsaKey, nil
+}
+
+// Digest 计算请求体的 Digest 请求头
+func Digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// SignRequest 使用 rsa-sha256 为请求签名（draft-cavage-http-signatures），会设置 Host、Date、Digest 与 Signature 请求头
+func SignRequest(req *http.Request, keyID string, privateKey *rsa.PrivateKey, body []byte) error {
+	headers := []string{"(request-target)", "host", "date"}
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	if body != nil {
+		req.Header.Set("Digest", Digest(body))
+		headers = append(headers, "digest")
+	}
+
+	signingString, err := buildSigningString(req, headers)
+	if err != nil {
+		return err
+	}
+
+	hashed := sha256.Sum256([]byte(signingString))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
+		keyID, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(signature)))
+	return nil
+}
+
+// ParseSignature 解析请求中的 Signature 请求头
+func ParseSignature(req *http.Request) (Signature, error) {
+	var sig Signature
+
+	header := req.Header.Get("Signature")
+	if header == "" {
+		// 兼容 Authorization: Signature ... 的写法
+		header = strings.TrimPrefix(req.Header.Get("Authorization"), "Signature ")
+	}
+	if header == "" {
+		return sig, errors.New("missing signature header")
+	}
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		switch key {
+		case "keyId":
+			sig.KeyID = value
+		case "algorithm":
+			sig.Algorithm = value
+		case "headers":
+			sig.Headers = strings.Fields(strings.ToLower(value))
+		case "signature":
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return sig, err
+			}
+			sig.Signature = decoded
+		}
+	}
+
+	if sig.KeyID == "" || len(sig.Signature) == 0 {
+		return sig, errors.New("incomplete signature header")
+	}
+	if len(sig.Headers) == 0 {
+		sig.Headers = []string{"date"}
+	}
+
+	return sig, nil
+}
+
+// CheckRequest 校验签名算法、签名覆盖的请求头、Date 是否过期以及 Digest 是否与请求体一致
+// 这些检查不需要公钥，应在获取发送方的公钥之前完成
+func CheckRequest(req *http.Request, sig Signature, body []byte) error {
+	if sig.Algorithm != "" && sig.Algorithm != "rsa-sha256" && sig.Algorithm != "hs2019" {
+		return fmt.Errorf("unsupported signature algorithm: %s", sig.Algorithm)
+	}
+
+	// 签名必须覆盖 (request-target)、date，有请求体时还必须覆盖 digest
+	required := []string{"(request-target)", "date"}
+	if len(body) > 0 {
+		required = append(required, "digest")
+	}
+	for _, header := range required {
+		if !containsHeader(sig.Headers, header) {
+			return fmt.Errorf("signature does not cover %s", header)
+		}
+	}
+
+	date, err := http.ParseTime(req.Header.Get("Date"))
+	if err != nil {
+		return err
+	}
+	if skew := time.Since(date); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return errors.New("signature date out of range")
+	}
+
+	if len(body) > 0 && req.Header.Get("Digest") != Digest(body) {
+		return errors.New("digest mismatch")
+	}
+
+	return nil
+}
+
+// VerifyRequest 校验请求签名，同时完成 CheckRequest 中的检查
+func VerifyRequest(req *http.Request, sig Signature, publicKey *rsa.PublicKey, body []byte) error {
+	if err := CheckRequest(req, sig, body); err != nil {
+		return err
+	}
+
+	signingString, err := buildSigningString(req, sig.Headers)
+	if err != nil {
+		return err
+	}
+
+	hashed := sha256.Sum256([]byte(signingString))
+	return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], sig.Signature)
+}
+
+// buildSigningString 按照签名的请求头列表构造待签名字符串
+func buildSigningString(req *http.Request, headers []string) (string, error) {
+	lines := make([]string, 0, len(headers))
+	for _, header := range headers {
+		switch header {
+		case "(request-target)":
+			lines = append(lines, fmt.Sprintf("(request-target): %s %s", strings.ToLower(req.Method), req.URL.RequestURI()))
+		case "host":
+			host := req.Header.Get("Host")
+			if host == "" {
+				host = req.Host
+			}
+			if host == "" {
+				host = req.URL.Host
+			}
+			lines = append(lines, "host: "+host)
+		default:
+			value := req.Header.Get(header)
+			if value == "" {
+				return "", fmt.Errorf("missing signed header: %s", header)
+			}
+			lines = append(lines, header+": "+value)
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// containsHeader 判断签名是否覆盖指定请求头
+func containsHeader(headers []string, header string) bool {
+	for _, h := range headers {
+		if h == header {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package util
+
+import (
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeyID = "https://remote.example/users/alice#main-key"
+
+// newTestKey 生成测试使用的密钥对
+func newTestKey(t *testing.T) (*rsa.PrivateKey, *rsa.PublicKey) {
+	t.Helper()
+
+	privatePem, publicPem, err := GenerateKeyPair()
+	require.NoError(t, err)
+	privateKey, err := ParsePrivateKey(privatePem)
+	require.NoError(t, err)
+	publicKey, err := ParsePublicKey(publicPem)
+	require.NoError(t, err)
+	return privateKey, publicKey
+}
+
+// newSignedRequest 创建已签名的投递请求，并解析其中的 Signature 请求头
+func newSignedRequest(t *testing.T, privateKey *rsa.PrivateKey, body string) (*http.Request, Signature) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "https://ech0.example/api/inbox", strings.NewReader(body))
+	require.NoError(t, SignRequest(req, testKeyID, privateKey, []byte(body)))
+	sig, err := ParseSignature(req)
+	require.NoError(t, err)
+	return req, sig
+}
+
+// 签名的请求可以使用对应的公钥校验
+func TestVerifyRequest_RoundTrip(t *testing.T) {
+	privateKey, publicKey := newTestKey(t)
+	body := `{"type":"Follow"}`
+	req, sig := newSignedRequest(t, privateKey, body)
+
+	assert.Equal(t, testKeyID, sig.KeyID)
+	assert.Equal(t, "rsa-sha256", sig.Algorithm)
+	assert.Equal(t, []string{"(request-target)", "host", "date", "digest"}, sig.Headers)
+	assert.NoError(t, VerifyRequest(req, sig, publicKey, []byte(body)))
+}
+
+// 被篡改的请求头、请求路径或请求体都会导致校验失败
+func TestVerifyRequest_RejectsTampering(t *testing.T) {
+	privateKey, publicKey := newTestKey(t)
+	body := `{"type":"Follow"}`
+
+	tests := []struct {
+		name   string
+		tamper func(req *http.Request) []byte
+	}{
+		{"host", func(req *http.Request) []byte {
+			req.Header.Set("Host", "evil.example")
+			return []byte(body)
+		}},
+		{"date", func(req *http.Request) []byte {
+			req.Header.Set("Date", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+			return []byte(body)
+		}},
+		{"request target", func(req *http.Request) []byte {
+			req.URL.Path = "/api/other"
+			return []byte(body)
+		}},
+		{"body and digest", func(req *http.Request) []byte {
+			tampered := []byte(`{"type":"Delete"}`)
+			req.Header.Set("Digest", Digest(tampered))
+			return tampered
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, sig := newSignedRequest(t, privateKey, body)
+			assert.Error(t, VerifyRequest(req, sig, publicKey, tt.tamper(req)))
+		})
+	}
+}
+
+// 使用其他密钥签名的请求校验失败
+func TestVerifyRequest_RejectsWrongKey(t *testing.T) {
+	privateKey, _ := newTestKey(t)
+	_, otherPublicKey := newTestKey(t)
+	body := `{"type":"Follow"}`
+	req, sig := newSignedRequest(t, privateKey, body)
+
+	assert.Error(t, VerifyRequest(req, sig, otherPublicKey, []byte(body)))
+}
+
+// 请求体与 Digest 不一致时，不需要公钥即可拒绝
+func TestCheckRequest_RejectsDigestMismatch(t *testing.T) {
+	privateKey, _ := newTestKey(t)
+	req, sig := newSignedRequest(t, privateKey, `{"type":"Follow"}`)
+
+	assert.EqualError(t, CheckRequest(req, sig, []byte(`{"type":"Delete"}`)), "digest mismatch")
+}
+
+// Date 超出允许的误差时拒绝，防止旧的请求被重放
+func TestCheckRequest_RejectsClockSkew(t *testing.T) {
+	privateKey, _ := newTestKey(t)
+	body := []byte(`{"type":"Follow"}`)
+
+	for _, offset := range []time.Duration{-MaxClockSkew - time.Minute, MaxClockSkew + time.Minute} {
+		req, sig := newSignedRequest(t, privateKey, string(body))
+		req.Header.Set("Date", time.Now().Add(offset).UTC().Format(http.TimeFormat))
+		assert.EqualError(t, CheckRequest(req, sig, body), "signature date out of range", "offset %s", offset)
+	}
+
+	req, sig := newSignedRequest(t, privateKey, string(body))
+	req.Header.Set("Date", time.Now().Add(MaxClockSkew-time.Minute).UTC().Format(http.TimeFormat))
+	assert.NoError(t, CheckRequest(req, sig, body))
+}
+
+// 签名必须覆盖 (request-target)、date 和 digest，且只接受 RSA 算法
+func TestCheckRequest_RequiresCoveredHeadersAndAlgorithm(t *testing.T) {
+	privateKey, _ := newTestKey(t)
+	body := []byte(`{"type":"Follow"}`)
+
+	for _, missing := range []string{"(request-target)", "date", "digest"} {
+		req, sig := newSignedRequest(t, privateKey, string(body))
+		headers := make([]string, 0, len(sig.Headers))
+		for _, header := range sig.Headers {
+			if header != missing {
+				headers = append(headers, header)
+			}
+		}
+		sig.Headers = headers
+		assert.EqualError(t, CheckRequest(req, sig, body), "signature does not cover "+missing)
+	}
+
+	req, sig := newSignedRequest(t, privateKey, string(body))
+	sig.Algorithm = "hmac-sha256"
+	assert.Error(t, CheckRequest(req, sig, body))
+}
+
+// 兼容 Authorization: Signature 写法，缺少 keyId 或签名时解析失败
+func TestParseSignature(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://ech0.example/", nil)
+	req.Header.Set("Authorization", `Signature keyId="`+testKeyID+`",signature="c2ln"`)
+	sig, err := ParseSignature(req)
+	require.NoError(t, err)
+	assert.Equal(t, testKeyID, sig.KeyID)
+	assert.Equal(t, []string{"date"}, sig.Headers)
+
+	for _, header := range []string{"", `keyId="` + testKeyID + `"`, `signature="c2ln"`, `keyId="a",signature="!!"`} {
+		req := httptest.NewRequest(http.MethodGet, "https://ech0.example/", nil)
+		if header != "" {
+			req.Header.Set("Signature", header)
+		}
+		_, err := ParseSignature(req)
+		assert.Error(t, err, header)
+	}
+}
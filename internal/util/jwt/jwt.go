@@ -1,6 +1,10 @@
 package util
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"log"
 	"time"
@@ -11,17 +15,24 @@ import (
 	userModel "github.com/lin-snow/ech0/internal/model/user"
 )
 
-// CreateClaims 创建Claims
-func CreateClaims(user userModel.User) jwt.Claims {
+// errInvalidKey 签名密钥无效
+var errInvalidKey = errors.New("invalid signing key")
+
+// KeyLookup 根据 kid 查找校验JWT使用的密钥
+type KeyLookup func(kid string) (authModel.SigningKey, error)
+
+// CreateClaims 创建Claims，令牌绑定到指定的会话，有效期为 expires
+func CreateClaims(user userModel.User, sessionID string, expires time.Duration) jwt.Claims {
 
 	claims := authModel.MyClaims{
-		Userid:   user.ID,
-		Username: user.Username,
+		Userid:    user.ID,
+		Username:  user.Username,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    config.Config.Auth.Jwt.Issuer,
 			Subject:   user.Username,
 			Audience:  jwt.ClaimStrings{config.Config.Auth.Jwt.Audience},
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(config.Config.Auth.Jwt.Expires) * time.Second)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expires)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
@@ -30,18 +41,50 @@ func CreateClaims(user userModel.User) jwt.Claims {
 	return claims
 }
 
-// GenerateToken 生成JWT Token
-func GenerateToken(claim jwt.Claims) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
-	return token.SignedString(config.JWT_SECRET)
+// GenerateToken 使用指定的密钥生成JWT Token，并在头部写入 kid
+func GenerateToken(claim jwt.Claims, key authModel.SigningKey) (string, error) {
+	var (
+		method  jwt.SigningMethod
+		signKey any
+	)
+	switch key.Algorithm {
+	case authModel.AlgorithmHS256:
+		secret, err := base64.StdEncoding.DecodeString(key.PrivateKey)
+		if err != nil || len(secret) == 0 {
+			return "", errInvalidKey
+		}
+		method, signKey = jwt.SigningMethodHS256, secret
+	case authModel.AlgorithmEdDSA:
+		privateKey, err := base64.StdEncoding.DecodeString(key.PrivateKey)
+		if err != nil || len(privateKey) != ed25519.PrivateKeySize {
+			return "", errInvalidKey
+		}
+		method, signKey = jwt.SigningMethodEdDSA, ed25519.PrivateKey(privateKey)
+	default:
+		return "", errInvalidKey
+	}
+
+	token := jwt.NewWithClaims(method, claim)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(signKey)
 }
 
-// ParseToken 解析JWT Token
-func ParseToken(tokenString string) (*authModel.MyClaims, error) {
+// ParseToken 解析JWT Token，根据头部的 kid 选择校验密钥
+func ParseToken(tokenString string, lookup KeyLookup) (*authModel.MyClaims, error) {
 	claims := &authModel.MyClaims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return config.JWT_SECRET, nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		key, err := lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		// 令牌声明的算法必须与密钥一致，防止算法混淆
+		if token.Method.Alg() != key.Algorithm {
+			return nil, errInvalidKey
+		}
+		return VerificationKey(key)
+	}, jwt.WithValidMethods([]string{authModel.AlgorithmHS256, authModel.AlgorithmEdDSA}))
 
 	if err != nil {
 		return nil, err
@@ -54,3 +97,65 @@ func ParseToken(tokenString string) (*authModel.MyClaims, error) {
 	log.Println("unknown claims type, cannot proceed")
 	return nil, errors.New("unknown claims type, cannot proceed")
 }
+
+// VerificationKey 获取校验签名使用的密钥（HS256 为密钥本身，EdDSA 为公钥）
+func VerificationKey(key authModel.SigningKey) (any, error) {
+	switch key.Algorithm {
+	case authModel.AlgorithmHS256:
+		secret, err := base64.StdEncoding.DecodeString(key.PrivateKey)
+		if err != nil || len(secret) == 0 {
+			return nil, errInvalidKey
+		}
+		return secret, nil
+	case authModel.AlgorithmEdDSA:
+		publicKey, err := base64.StdEncoding.DecodeString(key.PublicKey)
+		if err != nil || len(publicKey) != ed25519.PublicKeySize {
+			return nil, errInvalidKey
+		}
+		return ed25519.PublicKey(publicKey), nil
+	default:
+		return nil, errInvalidKey
+	}
+}
+
+// GenerateKey 生成新的签名密钥
+func GenerateKey(algorithm string) (authModel.SigningKey, error) {
+	kid := make([]byte, authModel.KeyIDBytes)
+	if _, err := rand.Read(kid); err != nil {
+		return authModel.SigningKey{}, err
+	}
+
+	key := authModel.SigningKey{
+		Kid:       hex.EncodeToString(kid),
+		Algorithm: algorithm,
+	}
+	switch algorithm {
+	case authModel.AlgorithmHS256:
+		secret := make([]byte, authModel.HMACKeyBytes)
+		if _, err := rand.Read(secret); err != nil {
+			return authModel.SigningKey{}, err
+		}
+		key.PrivateKey = base64.StdEncoding.EncodeToString(secret)
+	case authModel.AlgorithmEdDSA:
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return authModel.SigningKey{}, err
+		}
+		key.PrivateKey = base64.StdEncoding.EncodeToString(privateKey)
+		key.PublicKey = base64.StdEncoding.EncodeToString(publicKey)
+	default:
+		return authModel.SigningKey{}, errors.New("unsupported signing algorithm: " + algorithm)
+	}
+
+	return key, nil
+}
+
+// SecretKey 将通过环境变量指定的密钥包装为 HS256 签名密钥
+func SecretKey(secret []byte) authModel.SigningKey {
+	return authModel.SigningKey{
+		Kid:        authModel.EnvKeyID,
+		Algorithm:  authModel.AlgorithmHS256,
+		PrivateKey: base64.StdEncoding.EncodeToString(secret),
+		Active:     true,
+	}
+}
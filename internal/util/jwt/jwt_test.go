@@ -0,0 +1,115 @@
+package util
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+var errNotFound = errors.New("key not found")
+
+// lookupFrom 返回只包含指定密钥的查找函数
+func lookupFrom(keys ...authModel.SigningKey) KeyLookup {
+	return func(kid string) (authModel.SigningKey, error) {
+		for _, key := range keys {
+			if key.Kid == kid {
+				return key, nil
+			}
+		}
+		return authModel.SigningKey{}, errNotFound
+	}
+}
+
+func testClaims() jwt.Claims {
+	return CreateClaims(userModel.User{ID: 7, Username: "alice"}, "session-1", time.Hour)
+}
+
+// 两种算法签发的令牌都可以根据 kid 找到密钥并校验
+func TestParseToken_RoundTrip(t *testing.T) {
+	for _, algorithm := range []string{authModel.AlgorithmHS256, authModel.AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			key, err := GenerateKey(algorithm)
+			require.NoError(t, err)
+			other, err := GenerateKey(algorithm)
+			require.NoError(t, err)
+
+			token, err := GenerateToken(testClaims(), key)
+			require.NoError(t, err)
+
+			claims, err := ParseToken(token, lookupFrom(other, key))
+			require.NoError(t, err)
+			assert.Equal(t, uint(7), claims.Userid)
+			assert.Equal(t, "alice", claims.Username)
+			assert.Equal(t, "session-1", claims.SessionID)
+		})
+	}
+}
+
+// 使用 EdDSA 公钥作为 HS256 密钥伪造的令牌会因为算法与密钥不一致被拒绝
+func TestParseToken_RejectsAlgorithmMismatch(t *testing.T) {
+	key, err := GenerateKey(authModel.AlgorithmEdDSA)
+	require.NoError(t, err)
+	publicKey, err := base64.StdEncoding.DecodeString(key.PublicKey)
+	require.NoError(t, err)
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	forged.Header["kid"] = key.Kid
+	token, err := forged.SignedString(publicKey)
+	require.NoError(t, err)
+
+	_, err = ParseToken(token, lookupFrom(key))
+	assert.ErrorIs(t, err, errInvalidKey)
+}
+
+// 不允许 alg=none 的令牌
+func TestParseToken_RejectsNone(t *testing.T) {
+	key, err := GenerateKey(authModel.AlgorithmHS256)
+	require.NoError(t, err)
+
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, testClaims())
+	unsigned.Header["kid"] = key.Kid
+	token, err := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	_, err = ParseToken(token, lookupFrom(key))
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+// kid 未知或指向其他密钥时校验失败
+func TestParseToken_RejectsWrongKid(t *testing.T) {
+	key, err := GenerateKey(authModel.AlgorithmHS256)
+	require.NoError(t, err)
+	other, err := GenerateKey(authModel.AlgorithmHS256)
+	require.NoError(t, err)
+
+	token, err := GenerateToken(testClaims(), key)
+	require.NoError(t, err)
+
+	_, err = ParseToken(token, lookupFrom(other))
+	assert.ErrorIs(t, err, errNotFound)
+
+	// kid 相同但密钥不同
+	other.Kid = key.Kid
+	_, err = ParseToken(token, lookupFrom(other))
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+// 过期的令牌校验失败
+func TestParseToken_RejectsExpired(t *testing.T) {
+	key, err := GenerateKey(authModel.AlgorithmEdDSA)
+	require.NoError(t, err)
+
+	token, err := GenerateToken(CreateClaims(userModel.User{ID: 7}, "session-1", -time.Minute), key)
+	require.NoError(t, err)
+
+	_, err = ParseToken(token, lookupFrom(key))
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
@@ -0,0 +1,365 @@
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// DiscoveryPath OpenID Provider 元数据的路径
+	DiscoveryPath = "/.well-known/openid-configuration"
+	// RequestTimeout 请求身份提供者的超时时间
+	RequestTimeout = 10 * time.Second
+	// MaxResponseSize 身份提供者响应的最大字节数
+	MaxResponseSize = 1 << 20
+	// ClockSkew 校验 ID Token 时间时允许的误差
+	ClockSkew = time.Minute
+)
+
+// signingMethods ID Token 允许使用的签名算法（只接受非对称算法）
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+var (
+	errUnknownKey   = errors.New("unknown id token signing key")
+	errInvalidNonce = errors.New("id token nonce mismatch")
+)
+
+// client 请求身份提供者使用的 HTTP 客户端（校验证书）
+var client = &http.Client{Timeout: RequestTimeout}
+
+// Discovery 定义 OpenID Provider 元数据（只包含用到的字段）
+type Discovery struct {
+	Issuer                 string   `json:"issuer"`
+	AuthorizationEndpoint  string   `json:"authorization_endpoint"`
+	TokenEndpoint          string   `json:"token_endpoint"`
+	JWKSURI                string   `json:"jwks_uri"`
+	TokenEndpointAuthMeths []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// TokenResponse 定义令牌端点的响应
+type TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// JSONWebKey 定义 JWKS 中的公钥
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet 按 kid 索引的公钥
+type KeySet map[string]any
+
+// Discover 获取身份提供者的元数据，并校验元数据中的发行者与配置一致
+func Discover(issuer string) (Discovery, error) {
+	var discovery Discovery
+	if err := getJSON(strings.TrimSuffix(issuer, "/")+DiscoveryPath, &discovery); err != nil {
+		return discovery, err
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return discovery, fmt.Errorf("issuer mismatch: %s", discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return discovery, errors.New("incomplete provider metadata")
+	}
+
+	return discovery, nil
+}
+
+// FetchKeySet 获取身份提供者用于签名 ID Token 的公钥，忽略无法识别的密钥
+func FetchKeySet(jwksURI string) (KeySet, error) {
+	var jwks struct {
+		Keys []JSONWebKey `json:"keys"`
+	}
+	if err := getJSON(jwksURI, &jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(KeySet, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := ParseJWK(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable signing keys")
+	}
+
+	return keys, nil
+}
+
+// ParseJWK 将 JWK 转换为公钥，支持 RSA、EC（P-256/P-384/P-521）和 Ed25519
+func ParseJWK(jwk JSONWebKey) (any, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.New("unsupported curve: " + jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("invalid ec point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, errors.New("unsupported curve: " + jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, errors.New("unsupported key type: " + jwk.Kty)
+	}
+}
+
+// AuthorizationURL 构建授权请求地址（授权码模式，使用 PKCE S256）
+func AuthorizationURL(endpoint, clientID, redirectURI string, scopes []string, state, nonce, verifier string) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := parsed.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", clientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String(), nil
+}
+
+// ExchangeCode 使用授权码和 PKCE 校验码换取令牌
+// 设置了客户端密钥时按身份提供者支持的方式认证（优先 client_secret_basic）
+func ExchangeCode(discovery Discovery, clientID, clientSecret, code, redirectURI, verifier string) (TokenResponse, error) {
+	var tokens TokenResponse
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", clientID)
+
+	useBasic := clientSecret != "" &&
+		(len(discovery.TokenEndpointAuthMeths) == 0 || slices.Contains(discovery.TokenEndpointAuthMeths, "client_secret_basic"))
+	if clientSecret != "" && !useBasic {
+		form.Set("client_secret", clientSecret)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return tokens, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasic {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	if err := doJSON(req, &tokens); err != nil && tokens.Error == "" {
+		return tokens, err
+	}
+	if tokens.Error != "" {
+		return tokens, fmt.Errorf("token endpoint: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return tokens, errors.New("token response without id_token")
+	}
+
+	return tokens, nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、发行者、受众、有效期和 nonce，返回其中的声明
+func VerifyIDToken(rawToken string, keys KeySet, issuer, clientID, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := keys[kid]; ok {
+			return key, nil
+		}
+		// 只有一个密钥时允许省略 kid
+		if kid == "" && len(keys) == 1 {
+			for _, key := range keys {
+				return key, nil
+			}
+		}
+		return nil, errUnknownKey
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(ClockSkew),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// 存在多个受众时，授权方必须是当前客户端
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != clientID {
+			return nil, errors.New("id token azp mismatch")
+		}
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errInvalidNonce
+	}
+	if sub, _ := claims.GetSubject(); sub == "" {
+		return nil, errors.New("id token without subject")
+	}
+
+	return claims, nil
+}
+
+// IsUnknownKey 检查是否因为找不到签名密钥而校验失败（身份提供者可能已轮换密钥）
+func IsUnknownKey(err error) bool {
+	return errors.Is(err, errUnknownKey)
+}
+
+// RandomString 生成 URL 安全的随机字符串，用于 state、nonce 和 PKCE 校验码
+func RandomString(bytes int) (string, error) {
+	buf := make([]byte, bytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge 计算 PKCE S256 挑战码
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// StringClaim 获取字符串类型的声明
+func StringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// StringsClaim 获取字符串数组类型的声明（兼容单个字符串）
+func StringsClaim(claims jwt.MapClaims, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// decodeBigInt 解码 base64url 编码的大整数
+func decodeBigInt(value string) (*big.Int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(buf) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(buf), nil
+}
+
+// getJSON 发送 GET 请求并解析 JSON 响应
+func getJSON(endpoint string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	return doJSON(req, v)
+}
+
+// doJSON 发送请求并解析 JSON 响应，非 2xx 响应在解析后返回错误
+func doJSON(req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%s: unexpected response (%d)", req.URL.Host, resp.StatusCode)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: unexpected status %d", req.URL.Host, resp.StatusCode)
+	}
+
+	return nil
+}
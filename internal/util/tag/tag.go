@@ -0,0 +1,37 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+// tagPattern 匹配内容中的 #标签（要求 # 位于行首或空白之后，避免匹配到链接锚点和 Markdown 标题）
+var tagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_-]+)`)
+
+// ExtractTags 提取内容中的标签（统一转为小写并去重，不含 #）
+func ExtractTags(content string) []string {
+	var tags []string
+	seen := make(map[string]struct{})
+	for _, match := range tagPattern.FindAllStringSubmatch(content, -1) {
+		tag := strings.ToLower(match[1])
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// HasTag 判断内容中是否包含指定标签（不区分大小写）
+func HasTag(content, tag string) bool {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	for _, t := range ExtractTags(content) {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
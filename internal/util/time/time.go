@@ -0,0 +1,63 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LoadLocation 根据 IANA 时区名称加载时区，名称为空时返回服务器本地时区
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.Local, nil
+	}
+
+	return time.LoadLocation(name)
+}
+
+// StartOfDay 获取指定时间在给定时区下当天的开始时间
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// OffsetSpan 时区偏移量保持不变的一段时间 [Start, End)
+type OffsetSpan struct {
+	Start  time.Time
+	End    time.Time
+	Offset int // 相对 UTC 的偏移量，单位为秒
+}
+
+// SQLiteModifier 返回把时间平移到该段时区的 SQLite 日期函数修饰符，例如 "+28800 seconds"
+func (span OffsetSpan) SQLiteModifier() string {
+	return fmt.Sprintf("%+d seconds", span.Offset)
+}
+
+// CreatedAtInSpanSQL 筛选 created_at 位于 [Start, End) 内的 SQLite 条件，参数为 span.Start.Unix() 和 span.End.Unix()
+// 按 Unix 时间戳比较（数据库中保存的时间可能带有不同的偏移量，不能直接按字符串比较）
+const CreatedAtInSpanSQL = "CAST(strftime('%s', created_at) AS INTEGER) >= ? AND CAST(strftime('%s', created_at) AS INTEGER) < ?"
+
+// SplitByOffset 按夏令时等偏移量的变化，把 [start, end) 拆分为偏移量不变的若干段，
+// 每一段内可以直接用固定偏移量在数据库中按本地日期分组
+func SplitByOffset(start, end time.Time, loc *time.Location) []OffsetSpan {
+	var spans []OffsetSpan
+	for current := start; current.Before(end); {
+		localTime := current.In(loc)
+		_, offset := localTime.Zone()
+		_, next := localTime.ZoneBounds()
+		if next.IsZero() || next.After(end) {
+			next = end
+		}
+
+		// 只改变时区缩写、偏移量不变时合并到上一段
+		if n := len(spans); n > 0 && spans[n-1].Offset == offset {
+			spans[n-1].End = next
+		} else {
+			spans = append(spans, OffsetSpan{Start: current, End: next, Offset: offset})
+		}
+		current = next
+	}
+
+	return spans
+}
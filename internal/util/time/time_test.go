@@ -0,0 +1,47 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 没有夏令时的时区只有一段
+func TestSplitByOffset_FixedZone(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, loc)
+	end := time.Date(2025, 1, 1, 0, 0, 0, 0, loc)
+	spans := SplitByOffset(start, end, loc)
+
+	require.Len(t, spans, 1)
+	assert.True(t, spans[0].Start.Equal(start))
+	assert.True(t, spans[0].End.Equal(end))
+	assert.Equal(t, "+28800 seconds", spans[0].SQLiteModifier())
+}
+
+// 跨越夏令时切换时在切换的时刻拆分
+func TestSplitByOffset_DaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, loc)
+	end := time.Date(2024, 12, 1, 0, 0, 0, 0, loc)
+	spans := SplitByOffset(start, end, loc)
+
+	require.Len(t, spans, 3)
+	assert.Equal(t, []int{3600, 7200, 3600}, []int{spans[0].Offset, spans[1].Offset, spans[2].Offset})
+	assert.True(t, spans[0].End.Equal(time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC)))
+	assert.True(t, spans[1].End.Equal(time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC)))
+	assert.True(t, spans[1].Start.Equal(spans[0].End))
+	assert.True(t, spans[2].End.Equal(end))
+	assert.Equal(t, "-18000 seconds", OffsetSpan{Offset: -18000}.SQLiteModifier())
+}
+
+func TestSplitByOffset_EmptyRange(t *testing.T) {
+	now := time.Now()
+	assert.Empty(t, SplitByOffset(now, now, time.UTC))
+}
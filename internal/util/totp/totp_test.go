@@ -0,0 +1,100 @@
+package util
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret RFC 6238 附录 B 中 SHA1 测试向量使用的密钥 "12345678901234567890"
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+// RFC 6238 附录 B 的 SHA1 测试向量（8 位验证码取后 6 位）
+func TestCode_RFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		code, err := Code(rfcSecret, Counter(time.Unix(tt.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.code, code, "T=%d", tt.unix)
+
+		// 去掉填充或使用小写的密钥结果相同
+		code, err = Code(strings.ToLower(strings.TrimRight(rfcSecret, "=")), Counter(time.Unix(tt.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.code, code, "T=%d", tt.unix)
+	}
+}
+
+// 允许前后一个时间步的偏差，返回匹配的时间步
+func TestValidate_Skew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Counter(now)
+
+	for _, offset := range []int64{-1, 0, 1} {
+		code, err := Code(rfcSecret, current+offset)
+		require.NoError(t, err)
+
+		counter, ok := Validate(rfcSecret, code, now)
+		assert.True(t, ok, "offset %d", offset)
+		assert.Equal(t, current+offset, counter)
+	}
+
+	for _, offset := range []int64{-2, 2} {
+		code, err := Code(rfcSecret, current+offset)
+		require.NoError(t, err)
+
+		_, ok := Validate(rfcSecret, code, now)
+		assert.False(t, ok, "offset %d", offset)
+	}
+}
+
+// 格式不正确的验证码和无效的密钥校验失败
+func TestValidate_InvalidInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	counter, ok := Validate(rfcSecret, " 287082 ", now)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), counter)
+
+	for _, code := range []string{"", "28708", "2870822", "28708a", "+87082"} {
+		_, ok := Validate(rfcSecret, code, now)
+		assert.False(t, ok, code)
+	}
+
+	_, ok = Validate("not base32!", "287082", now)
+	assert.False(t, ok)
+}
+
+// 生成的密钥可以用于计算验证码，URI 包含身份验证器需要的参数
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	key, err := encoding.DecodeString(secret)
+	require.NoError(t, err)
+	assert.Len(t, key, SecretSize)
+
+	uri, err := url.Parse(URI("Ech0", "alice", secret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Ech0:alice", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "Ech0", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+	assert.Equal(t, "30", uri.Query().Get("period"))
+}
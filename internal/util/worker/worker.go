@@ -0,0 +1,44 @@
+package util
+
+import "sync"
+
+// Pool 使用固定数量的协程在后台执行任务，等待中的任务数量有上限，避免外部请求无限制地创建协程
+type Pool struct {
+	workers int
+	tasks   chan func()
+	once    sync.Once
+}
+
+// NewPool 创建任务池，协程在第一次提交任务时启动
+//
+// 参数:
+//   - workers: 同时执行任务的协程数量
+//   - queueSize: 最多等待执行的任务数量
+func NewPool(workers, queueSize int) *Pool {
+	return &Pool{
+		workers: max(workers, 1),
+		tasks:   make(chan func(), max(queueSize, 0)),
+	}
+}
+
+// Submit 提交任务，等待中的任务已满时不执行并返回 false
+func (pool *Pool) Submit(task func()) bool {
+	pool.once.Do(func() {
+		for i := 0; i < pool.workers; i++ {
+			go pool.run()
+		}
+	})
+
+	select {
+	case pool.tasks <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+func (pool *Pool) run() {
+	for task := range pool.tasks {
+		task()
+	}
+}
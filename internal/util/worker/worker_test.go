@@ -0,0 +1,31 @@
+package util
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 等待中的任务达到上限后拒绝新任务，已接受的任务全部执行
+func TestPool_BoundedQueue(t *testing.T) {
+	pool := NewPool(1, 2)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(3)
+	assert.True(t, pool.Submit(func() {
+		close(started)
+		<-block
+		wg.Done()
+	}))
+	<-started
+
+	assert.True(t, pool.Submit(wg.Done))
+	assert.True(t, pool.Submit(wg.Done))
+	assert.False(t, pool.Submit(func() { t.Error("任务不应被执行") }))
+
+	close(block)
+	wg.Wait()
+}
@@ -37,6 +37,7 @@ declare namespace App {
       type UserInfo = {
         username: string
         password: string
+        current_password?: string
         is_admin: boolean
         avatar: string
       }